@@ -55,6 +55,7 @@ type TransactionMessageType int
 const (
 	Send TransactionMessageType = iota
 	Unlock
+	Announce // hashes of new transactions, fetched by the nodes which miss them
 )
 
 // RoleType defines the role of the node
@@ -101,6 +102,7 @@ const (
 	CrossLink      // used for crosslink from beacon chain to shard chain
 	Receipt        // cross-shard transaction receipts
 	SlashCandidate // A report of a double-signing event
+	CompactSync    // a new block in compact form, rebuilt from the transaction pool
 )
 
 var (
@@ -110,17 +112,22 @@ var (
 	slashB     = byte(SlashCandidate)
 	txnB       = byte(Transaction)
 	sendB      = byte(Send)
+	announceB  = byte(Announce)
 	stakingB   = byte(Staking)
 	syncB      = byte(Sync)
 	crossLinkB = byte(CrossLink)
 	receiptB   = byte(Receipt)
+	compactB   = byte(CompactSync)
 	// H suffix means header
 	slashH           = []byte{nodeB, blockB, slashB}
 	transactionListH = []byte{nodeB, txnB, sendB}
 	stakingTxnListH  = []byte{nodeB, stakingB, sendB}
+	txnAnnounceH     = []byte{nodeB, txnB, announceB}
+	stakingAnnounceH = []byte{nodeB, stakingB, announceB}
 	syncH            = []byte{nodeB, blockB, syncB}
 	crossLinkH       = []byte{nodeB, blockB, crossLinkB}
 	cxReceiptH       = []byte{nodeB, blockB, receiptB}
+	compactSyncH     = []byte{nodeB, blockB, compactB}
 )
 
 // SerializeBlockchainSyncMessage serializes BlockchainSyncMessage.
@@ -171,6 +178,25 @@ func ConstructStakingTransactionListMessageAccount(
 	return byteBuffer.Bytes()
 }
 
+// ConstructTransactionAnnounceMessage constructs a message announcing the
+// hashes of new transactions
+func ConstructTransactionAnnounceMessage(hashes []common.Hash) []byte {
+	return constructAnnounceMessage(txnAnnounceH, hashes)
+}
+
+// ConstructStakingTransactionAnnounceMessage constructs a message announcing
+// the hashes of new staking transactions
+func ConstructStakingTransactionAnnounceMessage(hashes []common.Hash) []byte {
+	return constructAnnounceMessage(stakingAnnounceH, hashes)
+}
+
+func constructAnnounceMessage(header []byte, hashes []common.Hash) []byte {
+	byteBuffer := bytes.NewBuffer(header)
+	hashesData, _ := rlp.EncodeToBytes(hashes)
+	byteBuffer.Write(hashesData)
+	return byteBuffer.Bytes()
+}
+
 // ConstructBlocksSyncMessage constructs blocks sync message to send blocks to other nodes
 func ConstructBlocksSyncMessage(blocks []*types.Block) []byte {
 	byteBuffer := bytes.NewBuffer(syncH)
@@ -179,6 +205,21 @@ func ConstructBlocksSyncMessage(blocks []*types.Block) []byte {
 	return byteBuffer.Bytes()
 }
 
+// ConstructCompactBlockSyncMessage constructs a message sending a new block
+// to other nodes in compact form, prefilling the transactions of the hashes
+// for which prefill returns true
+func ConstructCompactBlockSyncMessage(
+	block *types.Block, prefill func(txHash common.Hash) bool,
+) ([]byte, error) {
+	compactData, err := types.EncodeCompactBlock(types.NewCompactBlock(block, prefill))
+	if err != nil {
+		return nil, err
+	}
+	byteBuffer := bytes.NewBuffer(compactSyncH)
+	byteBuffer.Write(compactData)
+	return byteBuffer.Bytes(), nil
+}
+
 // ConstructSlashMessage ..
 func ConstructSlashMessage(witnesses slash.Records) []byte {
 	byteBuffer := bytes.NewBuffer(slashH)
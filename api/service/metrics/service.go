@@ -5,6 +5,7 @@ import (
 	"math"
 	"math/big"
 	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/rpc"
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
@@ -25,6 +26,8 @@ const (
 	BlockRewardPush              int = 4
 	TxPoolPush                   int = 5
 	IsLeaderPush                 int = 6
+	RxQueuePush                  int = 7
+	ConsensusTimingPush          int = 8
 	metricsServicePortDifference     = 2000
 )
 
@@ -83,6 +86,26 @@ var (
 		Name: "block_reward",
 		Help: "Get last block reward.",
 	})
+	rxQueueLengthGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rx_queue_length",
+		Help: "Get current number of queued incoming messages per category.",
+	}, []string{"category"})
+	rxQueueDroppedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rx_queue_dropped",
+		Help: "Get number of incoming messages dropped on queue overrun per category.",
+	}, []string{"category"})
+	consensusPhaseGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "consensus_phase_seconds",
+		Help: "Get duration of the consensus phases of the last block.",
+	}, []string{"phase"})
+	consensusSignersGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "consensus_signers",
+		Help: "Get number of signers of the last block at the prepare and commit quorums, and after the commit quorum.",
+	}, []string{"phase"})
+	consensusViewChangesGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "consensus_view_changes",
+		Help: "Get number of view changes of the last block.",
+	})
 )
 
 // New returns metrics service.
@@ -122,7 +145,7 @@ func (s *Service) Run() {
 	// Init local storage for metrics.
 	s.storage = GetStorageInstance(s.IP, s.Port, true)
 	registry := prometheus.NewRegistry()
-	registry.MustRegister(blockHeightGauge, connectionsNumberGauge, nodeBalanceGauge, lastConsensusGauge, blockRewardGauge, blocksAcceptedGauge, txPoolGauge, isLeaderGauge)
+	registry.MustRegister(blockHeightGauge, connectionsNumberGauge, nodeBalanceGauge, lastConsensusGauge, blockRewardGauge, blocksAcceptedGauge, txPoolGauge, isLeaderGauge, rxQueueLengthGauge, rxQueueDroppedGauge, consensusPhaseGauge, consensusSignersGauge, consensusViewChangesGauge)
 
 	s.pusher = push.New("http://"+s.PushgatewayIP+":"+s.PushgatewayPort, "node_metrics").Gatherer(registry).Grouping("instance", s.IP+":"+s.Port).Grouping("bls_key", s.BlsPublicKey)
 	go s.PushMetrics()
@@ -178,6 +201,25 @@ func UpdateConnectionsNumber(connectionsNumber int) {
 	metricsPush <- ConnectionsNumberPush
 }
 
+// UpdateRxQueue updates the length and the dropped messages of an incoming message queue.
+func UpdateRxQueue(category string, length int, dropped uint64) {
+	rxQueueLengthGauge.WithLabelValues(category).Set(float64(length))
+	rxQueueDroppedGauge.WithLabelValues(category).Set(float64(dropped))
+	metricsPush <- RxQueuePush
+}
+
+// UpdateConsensusTiming updates the phase durations, the signers and the view changes of the last block.
+func UpdateConsensusTiming(phases map[string]time.Duration, signers map[string]int64, viewChanges uint64) {
+	for phase, duration := range phases {
+		consensusPhaseGauge.WithLabelValues(phase).Set(duration.Seconds())
+	}
+	for phase, count := range signers {
+		consensusSignersGauge.WithLabelValues(phase).Set(float64(count))
+	}
+	consensusViewChangesGauge.Set(float64(viewChanges))
+	metricsPush <- ConsensusTimingPush
+}
+
 // UpdateIsLeader updates if node is a leader.
 func UpdateIsLeader(isLeader bool) {
 	if isLeader {
@@ -0,0 +1,274 @@
+package syncing
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+const (
+	nodeDataPerRequest  = 384 // maximum number of trie nodes in one GetNodeData request
+	checkpointAncestors = 256 // headers kept before the checkpoint, as many as BLOCKHASH reaches
+)
+
+var (
+	errNotEpochBlock      = errors.New("[SYNC]: checkpoint is not the last block of an epoch")
+	errNoCheckpointHeader = errors.New("[SYNC]: no peer served the checkpoint headers")
+	errNoAncestorHeaders  = errors.New("[SYNC]: no peer served the headers before the checkpoint")
+	errNoEpochState       = errors.New("[SYNC]: no trusted shard state of the checkpoint epoch")
+	errNoValidatorList    = errors.New("[SYNC]: no peer served a valid validator list")
+)
+
+// Checkpoint is a trusted epoch block to sync a new node from, instead of
+// replaying all the blocks from genesis
+type Checkpoint struct {
+	// Hash is the hash of the last block of an epoch
+	Hash common.Hash
+	// ShardState is the shard state of the epoch of the block, accepted only if
+	// its committee signed the block. It is required: the commit signature is
+	// carried by the child of the block, which the hash does not authenticate,
+	// so a shard state from peers could be made up along with the signature,
+	// and this committee is paid the rewards of the checkpoint block.
+	ShardState *shard.State
+}
+
+// GetNodeData gets state trie nodes and contract codes by calling grpc request to the corresponding peer.
+func (peerConfig *SyncPeerConfig) GetNodeData(hashes [][]byte) ([][]byte, error) {
+	response := peerConfig.client.GetNodeData(hashes)
+	if response == nil {
+		return nil, ErrGetBlock
+	}
+	return response.Payload, nil
+}
+
+// CheckpointSync starts the chain from the checkpoint block if the chain holds
+// only the genesis block. The checkpoint header is authenticated by its hash and
+// carries the committee of the next epoch, so normal syncing continues from there.
+// The state trie of the block is downloaded from the peers and the block becomes
+// the head of the chain, the blocks before it are not downloaded.
+func (ss *StateSync) CheckpointSync(bc *core.BlockChain, checkpoint *Checkpoint) error {
+	if bc.CurrentBlock().NumberU64() != 0 {
+		return nil
+	}
+	if checkpoint.ShardState == nil {
+		return errNoEpochState
+	}
+	header, next, err := ss.fetchCheckpointHeaders(checkpoint.Hash)
+	if err != nil {
+		return err
+	}
+	if len(header.ShardState()) == 0 {
+		return errors.Wrapf(errNotEpochBlock, "block %d", header.Number().Uint64())
+	}
+	if err := verifyCheckpoint(bc, header, next, checkpoint.ShardState); err != nil {
+		return err
+	}
+	ancestors, err := ss.fetchAncestorHeaders(bc, header)
+	if err != nil {
+		return err
+	}
+	utils.Logger().Info().
+		Uint64("blockNum", header.Number().Uint64()).
+		Uint64("epoch", header.Epoch().Uint64()).
+		Str("root", header.Root().Hex()).
+		Msg("[SYNC] CheckpointSync: checkpoint verified, downloading state")
+
+	if err := ss.downloadState(bc, header.Root()); err != nil {
+		return err
+	}
+	validators, err := ss.fetchValidatorList(bc, header.Root())
+	if err != nil {
+		return err
+	}
+	blocks, err := ss.downloadBlocks([]*block.Header{header})
+	if err != nil {
+		return err
+	}
+	return bc.InsertCheckpoint(blocks[0], ancestors, checkpoint.ShardState, validators)
+}
+
+// fetchCheckpointHeaders gets the checkpoint header and the header of its child,
+// which carries the commit signature on the checkpoint
+func (ss *StateSync) fetchCheckpointHeaders(hash common.Hash) (*block.Header, *block.Header, error) {
+	var header, next *block.Header
+	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+		response := peerConfig.client.GetBlockHashes(hash[:], 1, ss.selfip, ss.selfport)
+		if response == nil || len(response.Payload) < 2 ||
+			common.BytesToHash(response.Payload[0]) != hash {
+			return
+		}
+		payload, err := peerConfig.GetBlockHeaders(response.Payload[:2])
+		if err != nil || len(payload) != 2 {
+			return
+		}
+		h, n := &block.Header{}, &block.Header{}
+		if rlp.DecodeBytes(payload[0], h) != nil || rlp.DecodeBytes(payload[1], n) != nil {
+			return
+		}
+		if h.Hash() != hash || n.ParentHash() != hash ||
+			n.Number().Uint64() != h.Number().Uint64()+1 {
+			return
+		}
+		header, next = h, n
+		return true
+	})
+	if header == nil {
+		return nil, nil, errors.Wrapf(errNoCheckpointHeader, "hash %s", hash.Hex())
+	}
+	return header, next, nil
+}
+
+// fetchAncestorHeaders gets the headers of the blocks before the checkpoint,
+// down to the genesis block or checkpointAncestors of them, parent first. Each
+// header is authenticated by the parent hash of the one after it.
+func (ss *StateSync) fetchAncestorHeaders(
+	bc *core.BlockChain, header *block.Header,
+) ([]*block.Header, error) {
+	count := header.Number().Uint64() - 1
+	if count > checkpointAncestors {
+		count = checkpointAncestors
+	}
+	ancestors := make([]*block.Header, 0, count)
+	parentHash := header.ParentHash()
+	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+		// the headers are asked one by one as only the hash of the next one
+		// is known, a peer failing to serve one leaves the rest to the next
+		for uint64(len(ancestors)) < count {
+			payload, err := peerConfig.GetBlockHeaders([][]byte{parentHash[:]})
+			if err != nil || len(payload) != 1 {
+				return
+			}
+			ancestor := &block.Header{}
+			if rlp.DecodeBytes(payload[0], ancestor) != nil || ancestor.Hash() != parentHash {
+				return
+			}
+			ancestors = append(ancestors, ancestor)
+			parentHash = ancestor.ParentHash()
+		}
+		return true
+	})
+	if uint64(len(ancestors)) < count {
+		return nil, errors.Wrapf(
+			errNoAncestorHeaders, "got %d of %d headers", len(ancestors), count,
+		)
+	}
+	if count < checkpointAncestors && parentHash != bc.Genesis().Hash() {
+		return nil, errors.Wrapf(errNoAncestorHeaders, "headers do not lead to the genesis block")
+	}
+	return ancestors, nil
+}
+
+// verifyCheckpoint verifies the commit signature on the checkpoint header,
+// carried by the next header, against the committee of the given shard state
+func verifyCheckpoint(
+	bc *core.BlockChain, header, next *block.Header, shardState *shard.State,
+) error {
+	reader := &syncChainReader{bc, map[uint64]*shard.State{
+		header.Epoch().Uint64(): shardState,
+	}}
+	sig := next.LastCommitSignature()
+	if err := bc.Engine().VerifyHeaderWithSignature(
+		reader, header, sig[:], next.LastCommitBitmap(), false,
+	); err != nil {
+		return errors.Wrapf(err, "checkpoint %d", header.Number().Uint64())
+	}
+	return nil
+}
+
+// downloadState downloads the state trie with the given root and the contract
+// codes it refers to, and writes them into the chain database
+func (ss *StateSync) downloadState(bc *core.BlockChain, root common.Hash) error {
+	sched := state.NewStateSync(root, bc.ChainDb())
+	done := 0
+	for sched.Pending() > 0 {
+		missing := sched.Missing(nodeDataPerRequest * (ss.GetActivePeerNumber() + 1))
+		hashes := make([][]byte, len(missing))
+		for i := range missing {
+			hashes[i] = missing[i][:]
+		}
+		results := make([]trie.SyncResult, len(missing))
+		if err := ss.parallelFetch(hashes, nodeDataPerRequest,
+			func(peerConfig *SyncPeerConfig, hashes [][]byte) ([][]byte, error) {
+				return peerConfig.GetNodeData(hashes)
+			},
+			func(start int, payload [][]byte) error {
+				end := start + len(payload)
+				if !fullRange(start, end, len(missing), nodeDataPerRequest) {
+					return errors.Wrapf(errUnexpectedPayload, "got %d nodes from %d", len(payload), start)
+				}
+				for i, data := range payload {
+					if crypto.Keccak256Hash(data) != missing[start+i] {
+						return errors.Wrapf(errUnexpectedPayload, "node %d does not match hash", start+i)
+					}
+				}
+				for i, data := range payload {
+					results[start+i] = trie.SyncResult{Hash: missing[start+i], Data: data}
+				}
+				return nil
+			},
+		); err != nil {
+			return err
+		}
+		if _, index, err := sched.Process(results); err != nil {
+			return errors.Wrapf(err, "cannot process state node %s", results[index].Hash.Hex())
+		}
+		batch := bc.ChainDb().NewBatch()
+		if _, err := sched.Commit(batch); err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		done += len(results)
+		utils.Logger().Info().
+			Int("nodes", done).
+			Int("pending", sched.Pending()).
+			Msg("[SYNC] downloadState: state nodes downloaded")
+	}
+	return nil
+}
+
+// fetchValidatorList gets the addresses of all validators from the first peer
+// whose list only holds validators found in the state with the given root.
+// A peer may still leave out validators, which makes the election at the end
+// of the epoch differ from the network, so it can stall the node but not make
+// it accept a wrong chain.
+func (ss *StateSync) fetchValidatorList(
+	bc *core.BlockChain, root common.Hash,
+) ([]common.Address, error) {
+	db, err := bc.StateAt(root)
+	if err != nil {
+		return nil, err
+	}
+	var validators []common.Address
+	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+		response := peerConfig.client.GetValidatorList()
+		if response == nil {
+			return
+		}
+		addrs := make([]common.Address, 0, len(response.Payload))
+		for _, data := range response.Payload {
+			if len(data) != common.AddressLength {
+				return
+			}
+			addr := common.BytesToAddress(data)
+			if _, err := db.ValidatorWrapper(addr); err != nil {
+				return
+			}
+			addrs = append(addrs, addr)
+		}
+		validators = addrs
+		return true
+	})
+	if validators == nil {
+		return nil, errNoValidatorList
+	}
+	return validators, nil
+}
@@ -14,7 +14,8 @@ import (
 type Client struct {
 	dlClient pb.DownloaderClient
 	opts     []grpc.DialOption
-	conn     *grpc.ClientConn
+	conn     *grpc.ClientConn // nil for a libp2p stream client
+	target   string
 }
 
 // ClientSetup setups a Client given ip and port.
@@ -28,12 +29,16 @@ func ClientSetup(ip, port string) *Client {
 		return nil
 	}
 	utils.Logger().Info().Str("ip", ip).Msg("[SYNC] grpc connect successfully")
+	client.target = client.conn.Target()
 	client.dlClient = pb.NewDownloaderClient(client.conn)
 	return &client
 }
 
 // Close closes the Client.
 func (client *Client) Close() {
+	if client.conn == nil {
+		return
+	}
 	err := client.conn.Close()
 	if err != nil {
 		utils.Logger().Info().Msg("[SYNC] unable to close connection")
@@ -49,7 +54,7 @@ func (client *Client) GetBlockHashes(startHash []byte, size uint32, ip, port str
 	request.Port = port
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Msg("[SYNC] GetBlockHashes query failed")
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] GetBlockHashes query failed")
 	}
 	return response
 }
@@ -66,7 +71,7 @@ func (client *Client) GetBlockHeaders(hashes [][]byte) *pb.DownloaderResponse {
 	}
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Msg("[SYNC] downloader/client.go:GetBlockHeaders query failed")
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetBlockHeaders query failed")
 	}
 	return response
 }
@@ -83,7 +88,50 @@ func (client *Client) GetBlocks(hashes [][]byte) *pb.DownloaderResponse {
 	}
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Msg("[SYNC] downloader/client.go:GetBlocks query failed")
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetBlocks query failed")
+	}
+	return response
+}
+
+// GetNodeData gets state trie nodes and contract codes by their hashes by calling a grpc request.
+func (client *Client) GetNodeData(hashes [][]byte) *pb.DownloaderResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request := &pb.DownloaderRequest{Type: pb.DownloaderRequest_NODEDATA}
+	request.Hashes = make([][]byte, len(hashes))
+	for i := range hashes {
+		request.Hashes[i] = make([]byte, len(hashes[i]))
+		copy(request.Hashes[i], hashes[i])
+	}
+	response, err := client.dlClient.Query(ctx, request)
+	if err != nil {
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetNodeData query failed")
+	}
+	return response
+}
+
+// GetValidatorList gets the addresses of all validators at the peer's current block by calling a grpc request.
+func (client *Client) GetValidatorList() *pb.DownloaderResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request := &pb.DownloaderRequest{Type: pb.DownloaderRequest_VALIDATORLIST}
+	response, err := client.dlClient.Query(ctx, request)
+	if err != nil {
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetValidatorList query failed")
+	}
+	return response
+}
+
+// GetEpochState gets the encoded shard state of the epoch of the given block by calling a grpc request.
+func (client *Client) GetEpochState(blockHash []byte) *pb.DownloaderResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request := &pb.DownloaderRequest{Type: pb.DownloaderRequest_EPOCHSTATE}
+	request.BlockHash = make([]byte, len(blockHash))
+	copy(request.BlockHash, blockHash)
+	response, err := client.dlClient.Query(ctx, request)
+	if err != nil {
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetEpochState query failed")
 	}
 	return response
 }
@@ -100,7 +148,7 @@ func (client *Client) Register(hash []byte, ip, port string) *pb.DownloaderRespo
 	request.Port = port
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil || response == nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Interface("response", response).Msg("[SYNC] client.go:Register failed")
+		utils.Logger().Error().Err(err).Str("target", client.target).Interface("response", response).Msg("[SYNC] client.go:Register failed")
 	}
 	return response
 }
@@ -122,7 +170,7 @@ func (client *Client) PushNewBlock(selfPeerHash [20]byte, blockHash []byte, time
 
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Msg("[SYNC] unable to send new block to unsync node")
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] unable to send new block to unsync node")
 	}
 	return response, err
 }
@@ -7,6 +7,7 @@ import (
 // DownloadInterface is the interface for downloader package.
 type DownloadInterface interface {
 	// State Syncing server-side interface, responsible for all kinds of state syncing grpc calls
-	// incomingPeer is incoming peer ip:port information
+	// incomingPeer is the ip:port of a gRPC peer, or the authenticated ID of a libp2p
+	// peer prefixed by /p2p/, see StreamPeerID
 	CalculateResponse(request *pb.DownloaderRequest, incomingPeer string) (*pb.DownloaderResponse, error)
 }
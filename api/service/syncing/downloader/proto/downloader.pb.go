@@ -35,17 +35,23 @@ const (
 	DownloaderRequest_REGISTERTIMEOUT DownloaderRequest_RequestType = 5
 	DownloaderRequest_UNKNOWN         DownloaderRequest_RequestType = 6
 	DownloaderRequest_BLOCKHEADER     DownloaderRequest_RequestType = 7
+	DownloaderRequest_NODEDATA        DownloaderRequest_RequestType = 8
+	DownloaderRequest_VALIDATORLIST   DownloaderRequest_RequestType = 9
+	DownloaderRequest_EPOCHSTATE      DownloaderRequest_RequestType = 10
 )
 
 var DownloaderRequest_RequestType_name = map[int32]string{
-	0: "BLOCKHASH",
-	1: "BLOCK",
-	2: "NEWBLOCK",
-	3: "BLOCKHEIGHT",
-	4: "REGISTER",
-	5: "REGISTERTIMEOUT",
-	6: "UNKNOWN",
-	7: "BLOCKHEADER",
+	0:  "BLOCKHASH",
+	1:  "BLOCK",
+	2:  "NEWBLOCK",
+	3:  "BLOCKHEIGHT",
+	4:  "REGISTER",
+	5:  "REGISTERTIMEOUT",
+	6:  "UNKNOWN",
+	7:  "BLOCKHEADER",
+	8:  "NODEDATA",
+	9:  "VALIDATORLIST",
+	10: "EPOCHSTATE",
 }
 
 var DownloaderRequest_RequestType_value = map[string]int32{
@@ -57,6 +63,9 @@ var DownloaderRequest_RequestType_value = map[string]int32{
 	"REGISTERTIMEOUT": 5,
 	"UNKNOWN":         6,
 	"BLOCKHEADER":     7,
+	"NODEDATA":        8,
+	"VALIDATORLIST":   9,
+	"EPOCHSTATE":      10,
 }
 
 func (x DownloaderRequest_RequestType) String() string {
@@ -253,33 +262,35 @@ func init() {
 func init() { proto.RegisterFile("downloader.proto", fileDescriptor_6a99ec95c7ab1ff1) }
 
 var fileDescriptor_6a99ec95c7ab1ff1 = []byte{
-	// 410 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x52, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xce, 0x3a, 0x8e, 0x93, 0x4c, 0xd2, 0x76, 0x19, 0x10, 0x5a, 0x55, 0x80, 0x2c, 0x9f, 0xcc,
-	0xc5, 0x87, 0xf6, 0xc4, 0x81, 0x43, 0x70, 0x4d, 0x6c, 0xb5, 0x38, 0x62, 0xed, 0x50, 0x71, 0x74,
-	0xe9, 0xaa, 0xb6, 0xa8, 0xea, 0xc5, 0xeb, 0x0a, 0x99, 0x17, 0xe0, 0x05, 0x79, 0x06, 0x9e, 0x03,
-	0x79, 0x9d, 0xd6, 0x96, 0x80, 0x9c, 0x76, 0xbf, 0x6f, 0x7e, 0x34, 0xf3, 0x7d, 0x03, 0xf4, 0xba,
-	0xfc, 0x7e, 0x77, 0x5b, 0x66, 0xd7, 0xa2, 0xf2, 0x64, 0x55, 0xd6, 0x25, 0x42, 0xcf, 0x38, 0xbf,
-	0x0d, 0x78, 0x72, 0xf6, 0x08, 0xb9, 0xf8, 0x76, 0x2f, 0x54, 0x8d, 0x6f, 0xc1, 0xac, 0x1b, 0x29,
-	0x18, 0xb1, 0x89, 0x7b, 0x78, 0xf2, 0xda, 0x1b, 0xb4, 0xf8, 0x2b, 0xd9, 0xdb, 0xbd, 0x69, 0x23,
-	0x05, 0xd7, 0x65, 0xf8, 0x1c, 0xac, 0x3c, 0x53, 0xb9, 0x50, 0xcc, 0xb0, 0xc7, 0xee, 0x92, 0xef,
-	0x10, 0x1e, 0xc3, 0x4c, 0x0a, 0x51, 0x85, 0x99, 0xca, 0xd9, 0xd8, 0x26, 0xee, 0x92, 0x3f, 0x62,
-	0x7c, 0x01, 0xf3, 0xab, 0xdb, 0xf2, 0xcb, 0x57, 0x1d, 0x34, 0x75, 0xb0, 0x27, 0xf0, 0x10, 0x8c,
-	0x42, 0xb2, 0x89, 0x4d, 0xdc, 0x39, 0x37, 0x0a, 0x89, 0x08, 0xa6, 0x2c, 0xab, 0x9a, 0x59, 0x9a,
-	0xd1, 0xff, 0x96, 0x53, 0xc5, 0x0f, 0xc1, 0xa6, 0x36, 0x71, 0x0f, 0xb8, 0xfe, 0x3b, 0x3f, 0x09,
-	0x2c, 0x06, 0xf3, 0xe1, 0x01, 0xcc, 0xdf, 0x5d, 0x6c, 0xfc, 0xf3, 0x70, 0x95, 0x84, 0x74, 0x84,
-	0x73, 0x98, 0x68, 0x48, 0x09, 0x2e, 0x61, 0x16, 0x07, 0x97, 0x1d, 0x32, 0xf0, 0x08, 0x16, 0x5d,
-	0x5e, 0x10, 0xad, 0xc3, 0x94, 0x8e, 0xdb, 0x30, 0x0f, 0xd6, 0x51, 0x92, 0x06, 0x9c, 0x9a, 0xf8,
-	0x14, 0x8e, 0x1e, 0x50, 0x1a, 0x7d, 0x08, 0x36, 0xdb, 0x94, 0x4e, 0x70, 0x01, 0xd3, 0x6d, 0x7c,
-	0x1e, 0x6f, 0x2e, 0x63, 0x6a, 0x0d, 0x1a, 0xac, 0xce, 0x02, 0x4e, 0xa7, 0xce, 0x2f, 0x02, 0x38,
-	0xd4, 0x4e, 0xc9, 0xf2, 0x4e, 0x09, 0x64, 0x30, 0x95, 0x59, 0xd3, 0x92, 0x8c, 0x68, 0xad, 0x1e,
-	0x20, 0xae, 0x77, 0x1e, 0x18, 0xda, 0x83, 0xd3, 0xff, 0x79, 0xd0, 0xf5, 0xf1, 0xb8, 0xb8, 0x29,
-	0x54, 0xdd, 0x13, 0x03, 0x37, 0x6c, 0x58, 0x74, 0x42, 0x8a, 0xe2, 0x26, 0xaf, 0xb5, 0xf0, 0x26,
-	0x1f, 0x52, 0xce, 0x1b, 0x78, 0xf6, 0xaf, 0xfa, 0x76, 0xa3, 0x64, 0xeb, 0xfb, 0x41, 0x92, 0xd0,
-	0x11, 0xce, 0xc0, 0x7c, 0xbf, 0x8a, 0x2e, 0x28, 0x41, 0x00, 0x2b, 0x8a, 0x93, 0xcf, 0xb1, 0x4f,
-	0x8d, 0x93, 0x4f, 0x00, 0xfd, 0x34, 0x18, 0xc2, 0xe4, 0xe3, 0xbd, 0xa8, 0x1a, 0x7c, 0xb9, 0xf7,
-	0x64, 0x8e, 0x5f, 0xed, 0xdf, 0xc6, 0x19, 0x5d, 0x59, 0xfa, 0x54, 0x4f, 0xff, 0x04, 0x00, 0x00,
-	0xff, 0xff, 0x00, 0xaf, 0x33, 0x33, 0xbe, 0x02, 0x00, 0x00,
+	// 442 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x52, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0xcd, 0x3a, 0x8e, 0x13, 0x4f, 0x3e, 0xba, 0x1d, 0x10, 0xb2, 0x2a, 0x40, 0x96, 0x4f, 0xe6,
+	0x92, 0x43, 0x7b, 0xe2, 0xc0, 0xc1, 0xd8, 0x4b, 0x6c, 0x35, 0xd8, 0xb0, 0xde, 0xb4, 0xe2, 0x98,
+	0xd2, 0x55, 0x63, 0x51, 0xd5, 0x8b, 0xed, 0x0a, 0x85, 0x3f, 0xc2, 0x5f, 0xe1, 0x87, 0xf0, 0x83,
+	0x50, 0x36, 0x69, 0x63, 0x09, 0x9a, 0x93, 0xf7, 0xbd, 0x99, 0x79, 0x1a, 0xbf, 0x37, 0x40, 0xaf,
+	0xcb, 0x1f, 0x77, 0xb7, 0xe5, 0xf2, 0x5a, 0x56, 0x53, 0x55, 0x95, 0x4d, 0x89, 0xb0, 0x67, 0xbc,
+	0x5f, 0x5d, 0x38, 0x8e, 0x1e, 0x21, 0x97, 0xdf, 0xef, 0x65, 0xdd, 0xe0, 0x3b, 0x30, 0x9b, 0xb5,
+	0x92, 0x0e, 0x71, 0x89, 0x3f, 0x39, 0x7d, 0x33, 0x6d, 0x49, 0xfc, 0xd3, 0x3c, 0xdd, 0x7d, 0xc5,
+	0x5a, 0x49, 0xae, 0xc7, 0xf0, 0x05, 0x58, 0xab, 0x65, 0xbd, 0x92, 0xb5, 0x63, 0xb8, 0x5d, 0x7f,
+	0xc4, 0x77, 0x08, 0x4f, 0x60, 0xa0, 0xa4, 0xac, 0xe2, 0x65, 0xbd, 0x72, 0xba, 0x2e, 0xf1, 0x47,
+	0xfc, 0x11, 0xe3, 0x4b, 0xb0, 0xaf, 0x6e, 0xcb, 0xaf, 0xdf, 0x74, 0xd1, 0xd4, 0xc5, 0x3d, 0x81,
+	0x13, 0x30, 0x0a, 0xe5, 0xf4, 0x5c, 0xe2, 0xdb, 0xdc, 0x28, 0x14, 0x22, 0x98, 0xaa, 0xac, 0x1a,
+	0xc7, 0xd2, 0x8c, 0x7e, 0x6f, 0xb8, 0xba, 0xf8, 0x29, 0x9d, 0xbe, 0x4b, 0xfc, 0x31, 0xd7, 0x6f,
+	0xef, 0x37, 0x81, 0x61, 0x6b, 0x3f, 0x1c, 0x83, 0xfd, 0x7e, 0x9e, 0x85, 0xe7, 0x71, 0x90, 0xc7,
+	0xb4, 0x83, 0x36, 0xf4, 0x34, 0xa4, 0x04, 0x47, 0x30, 0x48, 0xd9, 0xe5, 0x16, 0x19, 0x78, 0x04,
+	0xc3, 0x6d, 0x1f, 0x4b, 0x66, 0xb1, 0xa0, 0xdd, 0x4d, 0x99, 0xb3, 0x59, 0x92, 0x0b, 0xc6, 0xa9,
+	0x89, 0xcf, 0xe0, 0xe8, 0x01, 0x89, 0xe4, 0x23, 0xcb, 0x16, 0x82, 0xf6, 0x70, 0x08, 0xfd, 0x45,
+	0x7a, 0x9e, 0x66, 0x97, 0x29, 0xb5, 0x5a, 0x02, 0x41, 0xc4, 0x38, 0xed, 0x6b, 0xfd, 0x2c, 0x62,
+	0x51, 0x20, 0x02, 0x3a, 0xc0, 0x63, 0x18, 0x5f, 0x04, 0xf3, 0x24, 0x0a, 0x44, 0xc6, 0xe7, 0x49,
+	0x2e, 0xa8, 0x8d, 0x13, 0x00, 0xf6, 0x29, 0x0b, 0xe3, 0x5c, 0x04, 0x82, 0x51, 0xf0, 0xfe, 0x10,
+	0xc0, 0xb6, 0xd9, 0xb5, 0x2a, 0xef, 0x6a, 0x89, 0x0e, 0xf4, 0xd5, 0x72, 0xbd, 0x21, 0x1d, 0xa2,
+	0xcd, 0x7d, 0x80, 0x38, 0xdb, 0x85, 0x66, 0xe8, 0xd0, 0xce, 0x9e, 0x0a, 0x6d, 0xab, 0x33, 0xe5,
+	0xf2, 0xa6, 0xa8, 0x9b, 0x3d, 0xd1, 0x8a, 0xcf, 0x85, 0xe1, 0xd6, 0x79, 0x59, 0xdc, 0xac, 0x1a,
+	0x9d, 0x94, 0xc9, 0xdb, 0x94, 0xf7, 0x16, 0x9e, 0xff, 0x6f, 0x7e, 0x63, 0x41, 0xbe, 0x08, 0x43,
+	0x96, 0xe7, 0xb4, 0x83, 0x03, 0x30, 0x3f, 0x04, 0xc9, 0x9c, 0x12, 0x04, 0xb0, 0x92, 0x34, 0xff,
+	0x92, 0x86, 0xd4, 0x38, 0xbd, 0x00, 0xd8, 0x6f, 0x83, 0x31, 0xf4, 0x3e, 0xdf, 0xcb, 0x6a, 0x8d,
+	0xaf, 0x0e, 0xde, 0xd8, 0xc9, 0xeb, 0xc3, 0x7f, 0xe3, 0x75, 0xae, 0x2c, 0x7d, 0xdb, 0x67, 0x7f,
+	0x07, 0x00, 0xb5, 0x23, 0x6d, 0x15, 0xef, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -0,0 +1,170 @@
+package downloader
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	protobuf "github.com/golang/protobuf/proto"
+	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/libp2p/go-libp2p-core/helpers"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	libp2p_host "github.com/libp2p/go-libp2p-host"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// Constants for the libp2p sync protocol.
+const (
+	// MaxMessageSize is the maximum size of a request or response on a sync stream
+	MaxMessageSize = 64 << 20
+	// streamTimeout bounds a request served over a sync stream
+	streamTimeout = 30 * time.Second
+	// streamPeerPrefix prefixes the peer ID passed as incomingPeer to CalculateResponse
+	streamPeerPrefix = "/p2p/"
+)
+
+// Errors for the libp2p sync protocol.
+var (
+	ErrMessageTooLarge = errors.New("sync message too large")
+)
+
+// ProtocolID returns the ID of the libp2p sync protocol of the given shard.
+// A node serves the protocol of the shard chain it holds, so peers of a shard
+// are found by the protocols they support.
+func ProtocolID(shardID uint32) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/harmony/sync/%d/0.0.1", shardID))
+}
+
+// StreamPeerID returns the ID of the libp2p peer which sent a request, given
+// the incomingPeer passed to CalculateResponse. It returns false for a request
+// received over gRPC, whose incomingPeer is an unauthenticated ip:port.
+func StreamPeerID(incomingPeer string) (libp2p_peer.ID, bool) {
+	if !strings.HasPrefix(incomingPeer, streamPeerPrefix) {
+		return "", false
+	}
+	id, err := libp2p_peer.IDB58Decode(strings.TrimPrefix(incomingPeer, streamPeerPrefix))
+	if err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// writeMessage writes the length prefixed message to the stream
+func writeMessage(w io.Writer, msg protobuf.Message) error {
+	data, err := protobuf.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxMessageSize {
+		return errors.Wrapf(ErrMessageTooLarge, "%d bytes", len(data))
+	}
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(data))
+	buf = append(buf[:binary.PutUvarint(buf, uint64(len(data)))], data...)
+	_, err = w.Write(buf)
+	return err
+}
+
+// readMessage reads a length prefixed message from the stream
+func readMessage(r *bufio.Reader, msg protobuf.Message) error {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+	if size > MaxMessageSize {
+		return errors.Wrapf(ErrMessageTooLarge, "%d bytes", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	return protobuf.Unmarshal(data, msg)
+}
+
+// streamClient implements pb.DownloaderClient over the libp2p sync protocol,
+// opening one stream per request
+type streamClient struct {
+	host     libp2p_host.Host
+	peerID   libp2p_peer.ID
+	protocol protocol.ID
+}
+
+// Query sends the request to the peer and waits for its response.
+func (c *streamClient) Query(
+	ctx context.Context, request *pb.DownloaderRequest, _ ...grpc.CallOption,
+) (*pb.DownloaderResponse, error) {
+	stream, err := c.host.NewStream(ctx, c.peerID, c.protocol)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	}
+	if err := writeMessage(stream, request); err != nil {
+		stream.Reset()
+		return nil, err
+	}
+	response := &pb.DownloaderResponse{}
+	if err := readMessage(bufio.NewReader(stream), response); err != nil {
+		stream.Reset()
+		return nil, err
+	}
+	go helpers.FullClose(stream)
+	return response, nil
+}
+
+// StreamClientSetup setups a Client talking to the given peer over the libp2p
+// sync protocol of the given shard. Streams are opened per request, so the
+// peer is not dialed until the first one.
+func StreamClientSetup(host libp2p_host.Host, peerID libp2p_peer.ID, shardID uint32) *Client {
+	return &Client{
+		dlClient: &streamClient{host: host, peerID: peerID, protocol: ProtocolID(shardID)},
+		target:   streamPeerPrefix + peerID.Pretty(),
+	}
+}
+
+// StartStream serves the libp2p sync protocol of the given shard on the host.
+// The requests are answered like the gRPC ones, with the authenticated ID of
+// the remote peer passed as incomingPeer.
+func (s *Server) StartStream(host libp2p_host.Host, shardID uint32) {
+	host.SetStreamHandler(ProtocolID(shardID), s.handleStream)
+}
+
+// StopStream stops serving the libp2p sync protocol of the given shard.
+func (s *Server) StopStream(host libp2p_host.Host, shardID uint32) {
+	host.RemoveStreamHandler(ProtocolID(shardID))
+}
+
+// handleStream answers the request sent on an incoming sync stream
+func (s *Server) handleStream(stream network.Stream) {
+	stream.SetDeadline(time.Now().Add(streamTimeout))
+	remote := stream.Conn().RemotePeer()
+	request := &pb.DownloaderRequest{}
+	if err := readMessage(bufio.NewReader(stream), request); err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote.Pretty()).
+			Msg("[SYNC] cannot read sync stream request")
+		stream.Reset()
+		return
+	}
+	response, err := s.downloadInterface.CalculateResponse(request, streamPeerPrefix+remote.Pretty())
+	if err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote.Pretty()).
+			Msg("[SYNC] cannot answer sync stream request")
+		stream.Reset()
+		return
+	}
+	if err := writeMessage(stream, response); err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote.Pretty()).
+			Msg("[SYNC] cannot write sync stream response")
+		stream.Reset()
+		return
+	}
+	helpers.FullClose(stream)
+}
@@ -0,0 +1,84 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	libp2p "github.com/libp2p/go-libp2p"
+	libp2p_host "github.com/libp2p/go-libp2p-host"
+	libp2p_peerstore "github.com/libp2p/go-libp2p-peerstore"
+)
+
+type testDownloader struct {
+	incomingPeer string
+}
+
+func (d *testDownloader) CalculateResponse(
+	request *pb.DownloaderRequest, incomingPeer string,
+) (*pb.DownloaderResponse, error) {
+	d.incomingPeer = incomingPeer
+	if request.Type == pb.DownloaderRequest_UNKNOWN {
+		return nil, errors.New("unknown request")
+	}
+	return &pb.DownloaderResponse{Payload: request.Hashes, BlockHeight: 42}, nil
+}
+
+func newTestHost(t *testing.T) libp2p_host.Host {
+	host, err := libp2p.New(context.Background(), libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatalf("cannot create host: %v", err)
+	}
+	return host
+}
+
+func TestStreamQuery(t *testing.T) {
+	server, client := newTestHost(t), newTestHost(t)
+	defer server.Close()
+	defer client.Close()
+	client.Peerstore().AddAddrs(server.ID(), server.Addrs(), libp2p_peerstore.PermanentAddrTTL)
+
+	dl := &testDownloader{}
+	NewServer(dl).StartStream(server, 1)
+	c := StreamClientSetup(client, server.ID(), 1)
+
+	hashes := [][]byte{{1, 2, 3}, {4, 5}}
+	response := c.GetBlocks(hashes)
+	if response == nil {
+		t.Fatal("no response")
+	}
+	if len(response.Payload) != 2 || string(response.Payload[1]) != string(hashes[1]) {
+		t.Errorf("unexpected payload %x", response.Payload)
+	}
+	height, err := c.GetBlockChainHeight()
+	if err != nil || height.BlockHeight != 42 {
+		t.Errorf("unexpected height response %v, err %v", height, err)
+	}
+	if id, ok := StreamPeerID(dl.incomingPeer); !ok || id != client.ID() {
+		t.Errorf("incoming peer %q is not the client %s", dl.incomingPeer, client.ID().Pretty())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.dlClient.Query(ctx, &pb.DownloaderRequest{Type: pb.DownloaderRequest_UNKNOWN}); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+	if c := StreamClientSetup(client, server.ID(), 2); c.GetBlocks(hashes) != nil {
+		t.Error("expected no response on the protocol of another shard")
+	}
+}
+
+func TestStreamPeerID(t *testing.T) {
+	host := newTestHost(t)
+	defer host.Close()
+	if id, ok := StreamPeerID(streamPeerPrefix + host.ID().Pretty()); !ok || id != host.ID() {
+		t.Errorf("cannot parse stream peer %s", host.ID().Pretty())
+	}
+	for _, incomingPeer := range []string{"", "127.0.0.1:6000", "/p2p/notapeer"} {
+		if _, ok := StreamPeerID(incomingPeer); ok {
+			t.Errorf("%q parsed as a stream peer", incomingPeer)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package syncing
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// Constants for header-first syncing.
+const (
+	headersPerRequest = 50 // maximum number of headers in one GetBlockHeaders request
+	blocksPerRequest  = 10 // maximum number of blocks in one GetBlocks request
+	peerFailureLimit  = 3  // consecutive failures before a peer leaves a download
+)
+
+var (
+	errNoSyncPeers            = errors.New("[SYNC]: no peers to download from")
+	errIncompleteDownload     = errors.New("[SYNC]: download incomplete, all peers failed")
+	errUnexpectedPayload      = errors.New("[SYNC]: unexpected payload from peer")
+	errBrokenHeaderChain      = errors.New("[SYNC]: downloaded headers do not form a chain")
+	errInvalidHeaderSignature = errors.New("[SYNC]: invalid commit signature on header")
+)
+
+// syncPeerStats tracks the download throughput of a sync peer
+type syncPeerStats struct {
+	items    int
+	elapsed  time.Duration
+	failures int // consecutive failures
+	errors   int // total failures
+}
+
+// throughput returns the number of items delivered per second
+func (s syncPeerStats) throughput() float64 {
+	if s.elapsed == 0 {
+		return 0
+	}
+	return float64(s.items) / s.elapsed.Seconds()
+}
+
+// recordSuccess records the delivery of the given number of items
+func (peerConfig *SyncPeerConfig) recordSuccess(items int, elapsed time.Duration) {
+	peerConfig.mux.Lock()
+	defer peerConfig.mux.Unlock()
+	peerConfig.stats.items += items
+	peerConfig.stats.elapsed += elapsed
+	peerConfig.stats.failures = 0
+}
+
+// recordFailure records a failed request and returns the number of consecutive failures
+func (peerConfig *SyncPeerConfig) recordFailure() int {
+	peerConfig.mux.Lock()
+	defer peerConfig.mux.Unlock()
+	peerConfig.stats.failures++
+	peerConfig.stats.errors++
+	return peerConfig.stats.failures
+}
+
+// Stats returns the download statistics of the peer
+func (peerConfig *SyncPeerConfig) Stats() (items int, throughput float64) {
+	peerConfig.mux.Lock()
+	defer peerConfig.mux.Unlock()
+	return peerConfig.stats.items, peerConfig.stats.throughput()
+}
+
+// GetBlockHeaders gets block headers by calling grpc request to the corresponding peer.
+func (peerConfig *SyncPeerConfig) GetBlockHeaders(hashes [][]byte) ([][]byte, error) {
+	response := peerConfig.client.GetBlockHeaders(hashes)
+	if response == nil {
+		return nil, ErrGetBlock
+	}
+	return response.Payload, nil
+}
+
+// fetchTask is the range [start, end) of a hash list to download
+type fetchTask struct {
+	start, end int
+}
+
+// parallelFetch downloads the items of the hash list from all sync peers in ranges of
+// rangeSize. Every peer pulls the next range once done with the previous one, so
+// faster peers serve more ranges. A range which fails, or which handle rejects, is
+// put back for another peer, and a peer leaves the download after peerFailureLimit
+// consecutive failures. It returns an error if some ranges could not be downloaded.
+func (ss *StateSync) parallelFetch(
+	hashes [][]byte, rangeSize int,
+	fetch func(peerConfig *SyncPeerConfig, hashes [][]byte) ([][]byte, error),
+	handle func(start int, payload [][]byte) error,
+) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	numTasks := (len(hashes) + rangeSize - 1) / rangeSize
+	tasks := make(chan fetchTask, numTasks)
+	for start := 0; start < len(hashes); start += rangeSize {
+		end := start + rangeSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		tasks <- fetchTask{start, end}
+	}
+
+	remaining := int64(numTasks)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var task fetchTask
+				select {
+				case <-done:
+					return
+				case task = <-tasks:
+				}
+				begin := time.Now()
+				payload, err := fetch(peerConfig, hashes[task.start:task.end])
+				if err == nil {
+					err = handle(task.start, payload)
+				}
+				if err != nil {
+					tasks <- task
+					failures := peerConfig.recordFailure()
+					utils.Logger().Warn().Err(err).
+						Str("peerIP", peerConfig.ip).
+						Str("peerPort", peerConfig.port).
+						Int("failures", failures).
+						Msg("[SYNC] parallelFetch: request failed")
+					if failures >= peerFailureLimit {
+						return
+					}
+					continue
+				}
+				peerConfig.recordSuccess(task.end-task.start, time.Since(begin))
+				if atomic.AddInt64(&remaining, -1) == 0 {
+					close(done)
+					return
+				}
+			}
+		}()
+		return
+	})
+	wg.Wait()
+
+	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+		items, throughput := peerConfig.Stats()
+		utils.Logger().Debug().
+			Str("peerIP", peerConfig.ip).
+			Str("peerPort", peerConfig.port).
+			Int("items", items).
+			Float64("itemsPerSecond", throughput).
+			Msg("[SYNC] parallelFetch: peer throughput")
+		return
+	})
+	if left := atomic.LoadInt64(&remaining); left > 0 {
+		return errors.Wrapf(errIncompleteDownload, "%d of %d ranges left", left, numTasks)
+	}
+	return nil
+}
+
+// downloadHeaders downloads the headers of the given block hashes in parallel
+func (ss *StateSync) downloadHeaders(hashes [][]byte) ([]*block.Header, error) {
+	headers := make([]*block.Header, len(hashes))
+	err := ss.parallelFetch(hashes, headersPerRequest,
+		func(peerConfig *SyncPeerConfig, hashes [][]byte) ([][]byte, error) {
+			return peerConfig.GetBlockHeaders(hashes)
+		},
+		func(start int, payload [][]byte) error {
+			decoded := make([]*block.Header, 0, len(payload))
+			for i, data := range payload {
+				header := &block.Header{}
+				if err := rlp.DecodeBytes(data, header); err != nil {
+					return errors.Wrapf(errUnexpectedPayload, "cannot decode header: %v", err)
+				}
+				if start+i >= len(hashes) ||
+					header.Hash() != common.BytesToHash(hashes[start+i]) {
+					return errors.Wrapf(errUnexpectedPayload, "header %d does not match hash", start+i)
+				}
+				decoded = append(decoded, header)
+			}
+			if end := start + len(decoded); !fullRange(start, end, len(hashes), headersPerRequest) {
+				return errors.Wrapf(errUnexpectedPayload, "got %d headers from %d", len(decoded), start)
+			}
+			copy(headers[start:], decoded)
+			return nil
+		},
+	)
+	return headers, err
+}
+
+// downloadBlocks downloads the blocks of the given verified headers in parallel
+func (ss *StateSync) downloadBlocks(headers []*block.Header) ([]*types.Block, error) {
+	hashes := make([][]byte, len(headers))
+	for i, header := range headers {
+		hash := header.Hash()
+		hashes[i] = hash[:]
+	}
+	blocks := make([]*types.Block, len(headers))
+	err := ss.parallelFetch(hashes, blocksPerRequest,
+		func(peerConfig *SyncPeerConfig, hashes [][]byte) ([][]byte, error) {
+			return peerConfig.GetBlocks(hashes)
+		},
+		func(start int, payload [][]byte) error {
+			decoded := make([]*types.Block, 0, len(payload))
+			for i, data := range payload {
+				blockObj := &types.Block{}
+				if err := rlp.DecodeBytes(data, blockObj); err != nil {
+					return errors.Wrapf(errUnexpectedPayload, "cannot decode block: %v", err)
+				}
+				if start+i >= len(headers) || blockObj.Hash() != headers[start+i].Hash() {
+					return errors.Wrapf(errUnexpectedPayload, "block %d does not match header", start+i)
+				}
+				decoded = append(decoded, blockObj)
+			}
+			if end := start + len(decoded); !fullRange(start, end, len(headers), blocksPerRequest) {
+				return errors.Wrapf(errUnexpectedPayload, "got %d blocks from %d", len(decoded), start)
+			}
+			copy(blocks[start:], decoded)
+			return nil
+		},
+	)
+	return blocks, err
+}
+
+// fullRange returns whether [start, end) is a whole download range of rangeSize
+// of a list of the given length
+func fullRange(start, end, length, rangeSize int) bool {
+	want := start + rangeSize
+	if want > length {
+		want = length
+	}
+	return end == want
+}
+
+// syncChainReader is the chain with the shard states of the epoch headers which
+// are verified during syncing but not yet inserted into the chain
+type syncChainReader struct {
+	*core.BlockChain
+	shardStates map[uint64]*shard.State
+}
+
+// ReadShardState retrieves sharding state given the epoch number.
+func (r *syncChainReader) ReadShardState(epoch *big.Int) (*shard.State, error) {
+	if shardState, ok := r.shardStates[epoch.Uint64()]; ok {
+		return shardState, nil
+	}
+	return r.BlockChain.ReadShardState(epoch)
+}
+
+// verifyHeaders checks that the headers form a chain on top of the current block
+// and verifies the commit signature of each header, carried by the next header,
+// with the consensus engine. The signatures are verified in a batch once the
+// other checks are done. It returns the number of verified headers, the last
+// header is never verified since the commit signature on it is not known yet.
+func verifyHeaders(bc *core.BlockChain, headers []*block.Header) (int, error) {
+	signed, err := headerSignatures(bc, headers)
+	for i, valid := range bls_cosi.DefaultBatchVerifier().Verify(signed) {
+		if !valid {
+			return i, errors.Wrapf(errInvalidHeaderSignature, "header %d", headers[i].Number().Uint64())
+		}
+	}
+	if err != nil {
+		return len(signed), err
+	}
+	return len(headers) - 1, nil
+}
+
+// headerSignatures runs the checks of verifyHeaders but the signature
+// verification, and returns the commit signatures of the headers which pass
+// them, with the error of the first header which does not.
+func headerSignatures(bc *core.BlockChain, headers []*block.Header) ([]*bls_cosi.SignedHash, error) {
+	reader := &syncChainReader{bc, map[uint64]*shard.State{}}
+	parent := bc.CurrentHeader()
+	signed := []*bls_cosi.SignedHash{}
+	for i := 0; i+1 < len(headers); i++ {
+		header, next := headers[i], headers[i+1]
+		if header.ParentHash() != parent.Hash() ||
+			header.Number().Uint64() != parent.Number().Uint64()+1 {
+			return signed, errors.Wrapf(errBrokenHeaderChain, "header %d", header.Number().Uint64())
+		}
+		if next.ParentHash() != header.Hash() {
+			return signed, errors.Wrapf(errBrokenHeaderChain, "header %d", next.Number().Uint64())
+		}
+		sig := next.LastCommitSignature()
+		headerSig, err := bc.Engine().HeaderSignature(
+			reader, header, sig[:], next.LastCommitBitmap(), false,
+		)
+		if err != nil {
+			return signed, errors.Wrapf(err, "header %d", header.Number().Uint64())
+		}
+		if len(header.ShardState()) > 0 {
+			shardState, err := header.GetShardState()
+			if err != nil {
+				return signed, errors.Wrapf(err, "header %d shard state", header.Number().Uint64())
+			}
+			epoch := new(big.Int).Add(header.Epoch(), common.Big1)
+			if shardState.Epoch != nil {
+				epoch = shardState.Epoch
+			}
+			reader.shardStates[epoch.Uint64()] = &shardState
+		}
+		signed = append(signed, headerSig)
+		parent = header
+	}
+	return signed, nil
+}
+
+// headerFirstSync downloads the headers of the consensus block hashes, verifies
+// them, downloads the blocks of the verified headers and queues them for insertion.
+func (ss *StateSync) headerFirstSync(bc *core.BlockChain) error {
+	var hashes [][]byte
+	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+		hashes = peerConfig.blockHashes
+		return true
+	})
+	if hashes == nil {
+		return errNoSyncPeers
+	}
+	// The first hash is the current block
+	if len(hashes) < 2 {
+		return nil
+	}
+	hashes = hashes[1:]
+
+	headers, err := ss.downloadHeaders(hashes)
+	if err != nil {
+		return err
+	}
+	verified, err := verifyHeaders(bc, headers)
+	if err != nil {
+		utils.Logger().Warn().Err(err).
+			Int("verified", verified).
+			Msg("[SYNC] headerFirstSync: header verification failed")
+	}
+	if verified == 0 {
+		return err
+	}
+	utils.Logger().Info().
+		Int("verified", verified).
+		Uint64("from", headers[0].Number().Uint64()).
+		Msg("[SYNC] headerFirstSync: headers verified, downloading blocks")
+
+	blocks, err := ss.downloadBlocks(headers[:verified])
+	ss.syncMux.Lock()
+	for i, blockObj := range blocks {
+		if blockObj != nil {
+			ss.commonBlocks[i] = blockObj
+		}
+	}
+	ss.syncMux.Unlock()
+	return err
+}
@@ -0,0 +1,153 @@
+package syncing
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/harmony-one/harmony/core"
+)
+
+// SyncEventType is the type of a sync event
+type SyncEventType string
+
+// Sync event types
+const (
+	SyncStarted  SyncEventType = "sync-started"
+	SyncProgress SyncEventType = "sync-progress"
+	Synced       SyncEventType = "synced"
+)
+
+// SyncEvent is posted when a sync loop starts, makes progress and catches up
+type SyncEvent struct {
+	Type     SyncEventType `json:"type"`
+	IsBeacon bool          `json:"isBeacon"`
+	Progress Progress      `json:"progress"`
+}
+
+// PeerProgress is the status of a sync peer
+type PeerProgress struct {
+	IP             string  `json:"ip"`
+	Port           string  `json:"port"`
+	Height         uint64  `json:"height"`
+	Errors         int     `json:"errors"`
+	Downloaded     int     `json:"downloaded"`
+	ItemsPerSecond float64 `json:"itemsPerSecond"`
+}
+
+// Progress is the progress of syncing a chain
+type Progress struct {
+	Syncing         bool           `json:"syncing"`
+	StartingBlock   uint64         `json:"startingBlock"`
+	CurrentBlock    uint64         `json:"currentBlock"`
+	HighestBlock    uint64         `json:"highestBlock"`
+	BlocksPerSecond float64        `json:"blocksPerSecond"`
+	ETASeconds      float64        `json:"etaSeconds"`
+	Peers           []PeerProgress `json:"peers"`
+}
+
+// syncProgress tracks the sync loop of a StateSync, guarded by StateSync.progressMux
+type syncProgress struct {
+	syncing       bool
+	startingBlock uint64
+	startTime     time.Time
+	highestBlock  uint64
+}
+
+// SubscribeSyncEvent subscribes the channel to the sync events
+func (ss *StateSync) SubscribeSyncEvent(ch chan<- SyncEvent) event.Subscription {
+	return ss.syncFeed.Subscribe(ch)
+}
+
+// SetSyncEventFeed makes the sync events posted to the given feed, e.g. to
+// share one feed between the state sync of the shard chain and the beacon chain
+func (ss *StateSync) SetSyncEventFeed(feed *event.Feed) {
+	ss.syncFeed = feed
+}
+
+// recordHeight records the block height reported by the peer
+func (peerConfig *SyncPeerConfig) recordHeight(height uint64) {
+	peerConfig.mux.Lock()
+	defer peerConfig.mux.Unlock()
+	peerConfig.height = height
+	peerConfig.stats.failures = 0
+}
+
+// progress returns the status of the peer
+func (peerConfig *SyncPeerConfig) progress() PeerProgress {
+	peerConfig.mux.Lock()
+	defer peerConfig.mux.Unlock()
+	return PeerProgress{
+		IP:             peerConfig.ip,
+		Port:           peerConfig.port,
+		Height:         peerConfig.height,
+		Errors:         peerConfig.stats.errors,
+		Downloaded:     peerConfig.stats.items,
+		ItemsPerSecond: peerConfig.stats.throughput(),
+	}
+}
+
+// startProgress marks the start of a sync loop from the given block
+func (ss *StateSync) startProgress(current uint64) {
+	ss.progressMux.Lock()
+	defer ss.progressMux.Unlock()
+	ss.progress = syncProgress{
+		syncing:       true,
+		startingBlock: current,
+		startTime:     time.Now(),
+		highestBlock:  current,
+	}
+}
+
+// updateHighestBlock records the highest block height known from the peers
+func (ss *StateSync) updateHighestBlock(height uint64) {
+	ss.progressMux.Lock()
+	defer ss.progressMux.Unlock()
+	if height > ss.progress.highestBlock {
+		ss.progress.highestBlock = height
+	}
+}
+
+// finishProgress marks the end of a sync loop
+func (ss *StateSync) finishProgress() {
+	ss.progressMux.Lock()
+	defer ss.progressMux.Unlock()
+	ss.progress.syncing = false
+}
+
+// postSyncEvent posts a sync event with the current progress
+func (ss *StateSync) postSyncEvent(typ SyncEventType, bc *core.BlockChain, isBeacon bool) {
+	ss.syncFeed.Send(SyncEvent{Type: typ, IsBeacon: isBeacon, Progress: ss.Progress(bc)})
+}
+
+// Progress returns the progress of syncing the given chain. The rate and the
+// estimated time left are measured since the start of the running sync loop.
+func (ss *StateSync) Progress(bc *core.BlockChain) Progress {
+	ss.progressMux.Lock()
+	p := ss.progress
+	ss.progressMux.Unlock()
+
+	current := bc.CurrentBlock().NumberU64()
+	progress := Progress{
+		Syncing:       p.syncing,
+		StartingBlock: p.startingBlock,
+		CurrentBlock:  current,
+		HighestBlock:  p.highestBlock,
+		Peers:         []PeerProgress{},
+	}
+	if current > progress.HighestBlock {
+		progress.HighestBlock = current
+	}
+	if p.syncing && current > p.startingBlock {
+		if elapsed := time.Since(p.startTime).Seconds(); elapsed > 0 {
+			progress.BlocksPerSecond = float64(current-p.startingBlock) / elapsed
+			progress.ETASeconds = float64(progress.HighestBlock-current) / progress.BlocksPerSecond
+		}
+	}
+	if ss.syncConfig != nil {
+		ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+			progress.Peers = append(progress.Peers, peerConfig.progress())
+			return
+		})
+	}
+	return progress
+}
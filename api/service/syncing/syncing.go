@@ -2,7 +2,6 @@ package syncing
 
 import (
 	"bytes"
-	"encoding/hex"
 	"fmt"
 	"reflect"
 	"sort"
@@ -12,9 +11,8 @@ import (
 
 	"github.com/harmony-one/harmony/consensus/engine"
 
-	"github.com/Workiva/go-datastructures/queue"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/harmony-one/harmony/api/service/syncing/downloader"
 	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
 	"github.com/harmony-one/harmony/consensus"
@@ -24,19 +22,19 @@ import (
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/node/worker"
 	"github.com/harmony-one/harmony/p2p"
+	libp2p_host "github.com/libp2p/go-libp2p-host"
 )
 
 // Constants for syncing.
 const (
-	downloadBlocksRetryLimit        = 5 // downloadBlocks service retry limit
-	TimesToFail                     = 5 // downloadBlocks service retry limit
-	RegistrationNumber              = 3
-	SyncingPortDifference           = 3000
-	inSyncThreshold                 = 0    // when peerBlockHeight - myBlockHeight <= inSyncThreshold, it's ready to join consensus
-	SyncLoopBatchSize        uint32 = 1000 // maximum size for one query of block hashes
-	verifyHeaderBatchSize    uint64 = 100  // block chain header verification batch size
-	SyncLoopFrequency               = 1    // unit in second
-	LastMileBlocksSize              = 50
+	TimesToFail                  = 5 // downloadBlocks service retry limit
+	RegistrationNumber           = 3
+	SyncingPortDifference        = 3000
+	inSyncThreshold              = 0    // when peerBlockHeight - myBlockHeight <= inSyncThreshold, it's ready to join consensus
+	SyncLoopBatchSize     uint32 = 1000 // maximum size for one query of block hashes
+	verifyHeaderBatchSize uint64 = 100  // block chain header verification batch size
+	SyncLoopFrequency            = 1    // unit in second
+	LastMileBlocksSize           = 50
 )
 
 // SyncPeerConfig is peer config to sync.
@@ -47,6 +45,8 @@ type SyncPeerConfig struct {
 	client      *downloader.Client
 	blockHashes [][]byte       // block hashes before node doing sync
 	newBlocks   []*types.Block // blocks after node doing sync
+	stats       syncPeerStats  // download throughput, guarded by mux
+	height      uint64         // last block height reported by the peer, guarded by mux
 	mux         sync.Mutex
 }
 
@@ -55,12 +55,6 @@ func (peerConfig *SyncPeerConfig) GetClient() *downloader.Client {
 	return peerConfig.client
 }
 
-// SyncBlockTask is the task struct to sync a specific block.
-type SyncBlockTask struct {
-	index     int
-	blockHash []byte
-}
-
 // SyncConfig contains an array of SyncPeerConfig.
 type SyncConfig struct {
 	// mtx locks peers, and *SyncPeerConfig pointers in peers.
@@ -97,20 +91,32 @@ func CreateStateSync(ip string, port string, peerHash [20]byte) *StateSync {
 	stateSync.selfPeerHash = peerHash
 	stateSync.commonBlocks = make(map[int]*types.Block)
 	stateSync.lastMileBlocks = []*types.Block{}
+	stateSync.syncFeed = new(event.Feed)
 	return stateSync
 }
 
 // StateSync is the struct that implements StateSyncInterface.
 type StateSync struct {
-	selfip             string
-	selfport           string
-	selfPeerHash       [20]byte // hash of ip and address combination
-	commonBlocks       map[int]*types.Block
-	lastMileBlocks     []*types.Block // last mile blocks to catch up with the consensus
-	syncConfig         *SyncConfig
-	stateSyncTaskQueue *queue.Queue
-	syncMux            sync.Mutex
-	lastMileMux        sync.Mutex
+	selfip         string
+	selfport       string
+	selfPeerHash   [20]byte // hash of ip and address combination
+	commonBlocks   map[int]*types.Block
+	lastMileBlocks []*types.Block // last mile blocks to catch up with the consensus
+	syncConfig     *SyncConfig
+	syncMux        sync.Mutex
+	lastMileMux    sync.Mutex
+	progress       syncProgress
+	progressMux    sync.Mutex
+	syncFeed       *event.Feed
+	streamHost     libp2p_host.Host // download over the libp2p sync protocol if not nil
+	streamShardID  uint32
+}
+
+// SetStreamHost makes the state sync download from peers over the libp2p sync
+// protocol of the given shard on the host, instead of gRPC.
+func (ss *StateSync) SetStreamHost(host libp2p_host.Host, shardID uint32) {
+	ss.streamHost = host
+	ss.streamShardID = shardID
 }
 
 func (ss *StateSync) purgeAllBlocksFromCache() {
@@ -243,14 +249,22 @@ func (ss *StateSync) CreateSyncConfig(peers []p2p.Peer, isBeacon bool) error {
 		wg.Add(1)
 		go func(peer p2p.Peer) {
 			defer wg.Done()
-			client := downloader.ClientSetup(peer.IP, peer.Port)
-			if client == nil {
-				return
-			}
 			peerConfig := &SyncPeerConfig{
-				ip:     peer.IP,
-				port:   peer.Port,
-				client: client,
+				ip:   peer.IP,
+				port: peer.Port,
+			}
+			if ss.streamHost != nil {
+				if peer.PeerID == "" {
+					return
+				}
+				peerConfig.client = downloader.StreamClientSetup(ss.streamHost, peer.PeerID, ss.streamShardID)
+				peerConfig.peerHash = []byte(peer.PeerID)
+			} else {
+				client := downloader.ClientSetup(peer.IP, peer.Port)
+				if client == nil {
+					return
+				}
+				peerConfig.client = client
 			}
 			ss.syncConfig.AddPeer(peerConfig)
 		}(peer)
@@ -376,88 +390,6 @@ func (ss *StateSync) getConsensusHashes(startHash []byte, size uint32) {
 	utils.Logger().Info().Msg("[SYNC] Finished getting consensus block hashes")
 }
 
-func (ss *StateSync) generateStateSyncTaskQueue(bc *core.BlockChain) {
-	ss.stateSyncTaskQueue = queue.New(0)
-	ss.syncConfig.ForEachPeer(func(configPeer *SyncPeerConfig) (brk bool) {
-		for id, blockHash := range configPeer.blockHashes {
-			if err := ss.stateSyncTaskQueue.Put(SyncBlockTask{index: id, blockHash: blockHash}); err != nil {
-				utils.Logger().Warn().
-					Err(err).
-					Int("taskIndex", id).
-					Str("taskBlock", hex.EncodeToString(blockHash)).
-					Msg("[SYNC] generateStateSyncTaskQueue: cannot add task")
-			}
-		}
-		brk = true
-		return
-	})
-	utils.Logger().Info().Int64("length", ss.stateSyncTaskQueue.Len()).Msg("[SYNC] generateStateSyncTaskQueue: finished")
-}
-
-// downloadBlocks downloads blocks from state sync task queue.
-func (ss *StateSync) downloadBlocks(bc *core.BlockChain) {
-	// Initialize blockchain
-	var wg sync.WaitGroup
-	count := 0
-	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
-		wg.Add(1)
-		go func(stateSyncTaskQueue *queue.Queue, bc *core.BlockChain) {
-			defer wg.Done()
-			for !stateSyncTaskQueue.Empty() {
-				task, err := ss.stateSyncTaskQueue.Poll(1, time.Millisecond)
-				if err == queue.ErrTimeout || len(task) == 0 {
-					utils.Logger().Error().Err(err).Msg("[SYNC] downloadBlocks: ss.stateSyncTaskQueue poll timeout")
-					break
-				}
-				syncTask := task[0].(SyncBlockTask)
-				//id := syncTask.index
-				payload, err := peerConfig.GetBlocks([][]byte{syncTask.blockHash})
-				if err != nil || len(payload) == 0 {
-					count++
-					utils.Logger().Error().Err(err).Int("failNumber", count).Msg("[SYNC] downloadBlocks: GetBlocks failed")
-					if count > downloadBlocksRetryLimit {
-						break
-					}
-					if err := ss.stateSyncTaskQueue.Put(syncTask); err != nil {
-						utils.Logger().Warn().
-							Err(err).
-							Int("taskIndex", syncTask.index).
-							Str("taskBlock", hex.EncodeToString(syncTask.blockHash)).
-							Msg("downloadBlocks: cannot add task")
-					}
-					continue
-				}
-
-				var blockObj types.Block
-				// currently only send one block a time
-				err = rlp.DecodeBytes(payload[0], &blockObj)
-
-				if err != nil {
-					count++
-					utils.Logger().Error().Err(err).Msg("[SYNC] downloadBlocks: failed to DecodeBytes from received new block")
-					if count > downloadBlocksRetryLimit {
-						break
-					}
-					if err := ss.stateSyncTaskQueue.Put(syncTask); err != nil {
-						utils.Logger().Warn().
-							Err(err).
-							Int("taskIndex", syncTask.index).
-							Str("taskBlock", hex.EncodeToString(syncTask.blockHash)).
-							Msg("cannot add task")
-					}
-					continue
-				}
-				ss.syncMux.Lock()
-				ss.commonBlocks[syncTask.index] = &blockObj
-				ss.syncMux.Unlock()
-			}
-		}(ss.stateSyncTaskQueue, bc)
-		return
-	})
-	wg.Wait()
-	utils.Logger().Info().Msg("[SYNC] downloadBlocks: finished")
-}
-
 // CompareBlockByHash compares two block by hash, it will be used in sort the blocks
 func CompareBlockByHash(a *types.Block, b *types.Block) int {
 	ha := a.Hash()
@@ -663,10 +595,9 @@ func (ss *StateSync) generateNewState(bc *core.BlockChain, worker *worker.Worker
 func (ss *StateSync) ProcessStateSync(startHash []byte, size uint32, bc *core.BlockChain, worker *worker.Worker) error {
 	// Gets consensus hashes.
 	ss.getConsensusHashes(startHash, size)
-	ss.generateStateSyncTaskQueue(bc)
-	// Download blocks.
-	if ss.stateSyncTaskQueue.Len() > 0 {
-		ss.downloadBlocks(bc)
+	// Download and verify headers, then download the blocks of the verified headers.
+	if err := ss.headerFirstSync(bc); err != nil {
+		utils.Logger().Warn().Err(err).Msg("[SYNC] ProcessStateSync: header-first sync incomplete")
 	}
 	return ss.generateNewState(bc, worker)
 }
@@ -731,9 +662,11 @@ func (ss *StateSync) getMaxPeerHeight(isBeacon bool) uint64 {
 			// utils.Logger().Debug().Bool("isBeacon", isBeacon).Str("peerIP", peerConfig.ip).Str("peerPort", peerConfig.port).Msg("[Sync]getMaxPeerHeight")
 			response, err := peerConfig.client.GetBlockChainHeight()
 			if err != nil {
+				peerConfig.recordFailure()
 				utils.Logger().Warn().Err(err).Str("peerIP", peerConfig.ip).Str("peerPort", peerConfig.port).Msg("[Sync]GetBlockChainHeight failed")
 				return
 			}
+			peerConfig.recordHeight(response.BlockHeight)
 			ss.syncMux.Lock()
 			if response != nil && maxHeight < response.BlockHeight {
 				maxHeight = response.BlockHeight
@@ -743,6 +676,7 @@ func (ss *StateSync) getMaxPeerHeight(isBeacon bool) uint64 {
 		return
 	})
 	wg.Wait()
+	ss.updateHighestBlock(maxHeight)
 	return maxHeight
 }
 
@@ -770,6 +704,8 @@ func (ss *StateSync) SyncLoop(bc *core.BlockChain, worker *worker.Worker, isBeac
 	if !isBeacon {
 		ss.RegisterNodeInfo()
 	}
+	ss.startProgress(bc.CurrentBlock().NumberU64())
+	ss.postSyncEvent(SyncStarted, bc, isBeacon)
 	// remove SyncLoopFrequency
 	ticker := time.NewTicker(SyncLoopFrequency * time.Second)
 	defer ticker.Stop()
@@ -807,9 +743,12 @@ Loop:
 			if consensus != nil {
 				consensus.SetMode(consensus.UpdateConsensusInformation())
 			}
+			ss.postSyncEvent(SyncProgress, bc, isBeacon)
 		}
 	}
 	ss.purgeAllBlocksFromCache()
+	ss.finishProgress()
+	ss.postSyncEvent(Synced, bc, isBeacon)
 }
 
 // GetSyncingPort returns the syncing port.
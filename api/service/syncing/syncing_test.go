@@ -46,3 +46,48 @@ func TestCreateStateSync(t *testing.T) {
 		t.Error("Unable to create stateSync")
 	}
 }
+
+func TestFullRange(t *testing.T) {
+	assert.True(t, fullRange(0, 50, 120, 50))
+	assert.False(t, fullRange(0, 49, 120, 50))
+	assert.True(t, fullRange(100, 120, 120, 50))
+	assert.False(t, fullRange(100, 110, 120, 50))
+}
+
+func TestParallelFetch(t *testing.T) {
+	ss := &StateSync{syncConfig: &SyncConfig{}}
+	good := CreateTestSyncPeerConfig(&downloader.Client{}, nil)
+	bad := CreateTestSyncPeerConfig(&downloader.Client{}, nil)
+	ss.syncConfig.AddPeer(good)
+	ss.syncConfig.AddPeer(bad)
+
+	hashes := make([][]byte, 25)
+	for i := range hashes {
+		hashes[i] = []byte{byte(i)}
+	}
+	got := make([][]byte, len(hashes))
+	err := ss.parallelFetch(hashes, 10,
+		func(peerConfig *SyncPeerConfig, hashes [][]byte) ([][]byte, error) {
+			if peerConfig == bad {
+				return nil, ErrGetBlock
+			}
+			return hashes, nil
+		},
+		func(start int, payload [][]byte) error {
+			copy(got[start:], payload)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, hashes, got)
+	items, _ := good.Stats()
+	assert.Equal(t, len(hashes), items)
+
+	err = ss.parallelFetch(hashes, 10,
+		func(peerConfig *SyncPeerConfig, hashes [][]byte) ([][]byte, error) {
+			return nil, ErrGetBlock
+		},
+		func(start int, payload [][]byte) error { return nil },
+	)
+	assert.Error(t, err)
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/utils"
+	coredis "github.com/libp2p/go-libp2p-core/discovery"
+	"github.com/libp2p/go-libp2p-core/network"
+	libp2p_host "github.com/libp2p/go-libp2p-host"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+)
+
+// Constants for the peer directory.
+const (
+	// directoryRefreshInterval is how often the advertised topics are looked up
+	directoryRefreshInterval = time.Minute
+	// directoryHistoryLength is the number of peer count samples kept, a day's worth
+	directoryHistoryLength = 24 * 60
+	// findPeersLimit bounds the number of peers looked up per topic
+	findPeersLimit = 2000
+	// peerExpiry is how long a peer stays listed after it was last seen
+	peerExpiry = 24 * time.Hour
+	// knownPeerAddrTTL is how long the persisted addresses of a peer last, so a
+	// restarted bootnode can redial the peers it knew
+	knownPeerAddrTTL = peerExpiry
+	// agentVersionKey is the peerstore key of the version set by the identify protocol
+	agentVersionKey = "AgentVersion"
+)
+
+// peerRecord is a peer known to the bootnode
+type peerRecord struct {
+	ID        string    `json:"id"`
+	Addrs     []string  `json:"addrs"`
+	Topics    []string  `json:"topics"`
+	Version   string    `json:"version"`
+	Connected bool      `json:"connected"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// countsSample is the number of peers advertising each topic at a time
+type countsSample struct {
+	Time   time.Time      `json:"time"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+}
+
+// peersResponse is the response of the /peers endpoint
+type peersResponse struct {
+	Topics       map[string][]*peerRecord `json:"topics"`
+	Unadvertised []*peerRecord            `json:"unadvertised"`
+}
+
+// statsResponse is the response of the /stats endpoint
+type statsResponse struct {
+	Total    int            `json:"total"`
+	Counts   map[string]int `json:"counts"`
+	Short    []string       `json:"short"`
+	MinPeers int            `json:"minPeers"`
+	Versions map[string]int `json:"versions"`
+	History  []countsSample `json:"history"`
+}
+
+// directory keeps the peers known to the bootnode, grouped by the shard topics
+// they advertise on the DHT, along with the peer counts over time
+type directory struct {
+	host      libp2p_host.Host
+	discovery coredis.Discoverer
+	topics    []string
+	minPeers  int
+
+	mux     sync.Mutex
+	peers   map[libp2p_peer.ID]*peerRecord
+	history []countsSample
+}
+
+// shardTopics returns the rendezvous topics advertised by the nodes of the
+// given number of shards, as announced by the networkinfo service
+func shardTopics(numShards int) []string {
+	topics := []string{string(nodeconfig.NewClientGroupIDByShardID(0))}
+	for shardID := 0; shardID < numShards; shardID++ {
+		topics = append(topics, string(nodeconfig.NewGroupIDByShardID(nodeconfig.ShardID(shardID))))
+	}
+	return topics
+}
+
+func newDirectory(
+	host libp2p_host.Host, discovery coredis.Discoverer, topics []string, minPeers int,
+) *directory {
+	return &directory{
+		host:      host,
+		discovery: discovery,
+		topics:    topics,
+		minPeers:  minPeers,
+		peers:     map[libp2p_peer.ID]*peerRecord{},
+	}
+}
+
+// run refreshes the directory periodically, it never returns
+func (d *directory) run() {
+	for {
+		d.refresh()
+		time.Sleep(directoryRefreshInterval)
+	}
+}
+
+// refresh looks up the peers advertising each topic and records the counts
+func (d *directory) refresh() {
+	now := time.Now()
+	found := map[libp2p_peer.ID][]string{}
+	for _, topic := range d.topics {
+		ctx, cancel := context.WithTimeout(context.Background(), directoryRefreshInterval/2)
+		peers, err := d.discovery.FindPeers(ctx, topic, coredis.Limit(findPeersLimit))
+		if err != nil {
+			utils.Logger().Warn().Err(err).Str("topic", topic).Msg("[bootnode] cannot find peers")
+			cancel()
+			continue
+		}
+		for info := range peers {
+			if info.ID == d.host.ID() {
+				continue
+			}
+			found[info.ID] = append(found[info.ID], topic)
+			if len(info.Addrs) > 0 {
+				d.host.Peerstore().AddAddrs(info.ID, info.Addrs, knownPeerAddrTTL)
+			}
+		}
+		cancel()
+	}
+	connected := map[libp2p_peer.ID]bool{}
+	for _, id := range d.host.Network().Peers() {
+		connected[id] = true
+		if _, ok := found[id]; !ok {
+			found[id] = nil
+		}
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	for id, record := range d.peers {
+		record.Connected = connected[id]
+		if _, ok := found[id]; !ok && now.Sub(record.LastSeen) > peerExpiry {
+			delete(d.peers, id)
+		}
+	}
+	for id, topics := range found {
+		record, ok := d.peers[id]
+		if !ok {
+			record = &peerRecord{ID: id.Pretty()}
+			d.peers[id] = record
+		}
+		sort.Strings(topics)
+		record.Topics = topics
+		record.Connected = connected[id]
+		record.LastSeen = now
+		record.Addrs = []string{}
+		for _, addr := range d.host.Peerstore().Addrs(id) {
+			record.Addrs = append(record.Addrs, addr.String())
+		}
+		if version, err := d.host.Peerstore().Get(id, agentVersionKey); err == nil {
+			if version, ok := version.(string); ok {
+				record.Version = version
+			}
+		}
+	}
+
+	sample := countsSample{Time: now, Total: len(d.peers), Counts: d.counts()}
+	d.history = append(d.history, sample)
+	if len(d.history) > directoryHistoryLength {
+		d.history = d.history[len(d.history)-directoryHistoryLength:]
+	}
+	utils.Logger().Info().
+		Int("total", sample.Total).
+		Interface("counts", sample.Counts).
+		Msg("[bootnode] refreshed peer directory")
+}
+
+// counts returns the number of peers advertising each topic, the caller must hold d.mux
+func (d *directory) counts() map[string]int {
+	counts := map[string]int{}
+	for _, topic := range d.topics {
+		counts[topic] = 0
+	}
+	for _, record := range d.peers {
+		for _, topic := range record.Topics {
+			counts[topic]++
+		}
+	}
+	return counts
+}
+
+// listPeers returns the known peers grouped by topic
+func (d *directory) listPeers() peersResponse {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	response := peersResponse{Topics: map[string][]*peerRecord{}, Unadvertised: []*peerRecord{}}
+	for _, topic := range d.topics {
+		response.Topics[topic] = []*peerRecord{}
+	}
+	for _, record := range d.peers {
+		if len(record.Topics) == 0 {
+			response.Unadvertised = append(response.Unadvertised, record)
+		}
+		for _, topic := range record.Topics {
+			response.Topics[topic] = append(response.Topics[topic], record)
+		}
+	}
+	byID := func(records []*peerRecord) {
+		sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	}
+	for _, records := range response.Topics {
+		byID(records)
+	}
+	byID(response.Unadvertised)
+	return response
+}
+
+// stats returns the peer counts per topic, the topics short of peers, the
+// client versions and the count history
+func (d *directory) stats() statsResponse {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	response := statsResponse{
+		Total:    len(d.peers),
+		Counts:   d.counts(),
+		Short:    []string{},
+		MinPeers: d.minPeers,
+		Versions: map[string]int{},
+		History:  append([]countsSample{}, d.history...),
+	}
+	for _, topic := range d.topics {
+		if response.Counts[topic] < d.minPeers {
+			response.Short = append(response.Short, topic)
+		}
+	}
+	for _, record := range d.peers {
+		version := record.Version
+		if version == "" {
+			version = "unknown"
+		}
+		response.Versions[version]++
+	}
+	return response
+}
+
+// handler returns the HTTP handler of the /peers and /stats JSON endpoints
+func (d *directory) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/peers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, d.listPeers())
+	})
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, d.stats())
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		utils.Logger().Warn().Err(err).Msg("[bootnode] cannot write response")
+	}
+}
+
+// redialKnownPeers dials the peers with addresses in the persisted peerstore,
+// so a restarted bootnode rejoins the network without waiting to be dialed
+func redialKnownPeers(host libp2p_host.Host) {
+	for _, id := range host.Peerstore().PeersWithAddrs() {
+		if id == host.ID() || host.Network().Connectedness(id) == network.Connected {
+			continue
+		}
+		go func(id libp2p_peer.ID) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := host.Connect(ctx, host.Peerstore().PeerInfo(id)); err != nil {
+				utils.Logger().Debug().Err(err).Str("peer", id.Pretty()).
+					Msg("[bootnode] cannot redial known peer")
+			}
+		}(id)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	coredis "github.com/libp2p/go-libp2p-core/discovery"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+)
+
+// fakeDiscovery returns fixed peers for each topic
+type fakeDiscovery struct {
+	peers map[string][]host.Host
+	errs  map[string]error
+}
+
+func (f *fakeDiscovery) FindPeers(
+	ctx context.Context, ns string, opts ...coredis.Option,
+) (<-chan peer.AddrInfo, error) {
+	if err := f.errs[ns]; err != nil {
+		return nil, err
+	}
+	ch := make(chan peer.AddrInfo, len(f.peers[ns]))
+	for _, h := range f.peers[ns] {
+		ch <- peer.AddrInfo{ID: h.ID(), Addrs: h.Addrs()}
+	}
+	close(ch)
+	return ch, nil
+}
+
+// newTestHosts returns n linked but unconnected hosts on a mock network
+func newTestHosts(t *testing.T, n int) (mocknet.Mocknet, []host.Host) {
+	net := mocknet.New(context.Background())
+	hosts := make([]host.Host, n)
+	for i := range hosts {
+		h, err := net.GenPeer()
+		if err != nil {
+			t.Fatalf("cannot create host: %v", err)
+		}
+		hosts[i] = h
+	}
+	if err := net.LinkAll(); err != nil {
+		t.Fatalf("cannot link hosts: %v", err)
+	}
+	return net, hosts
+}
+
+func ids(records []*peerRecord) []string {
+	result := []string{}
+	for _, record := range records {
+		result = append(result, record.ID)
+	}
+	return result
+}
+
+func sortedIDs(hosts ...host.Host) []string {
+	result := []string{}
+	for _, h := range hosts {
+		result = append(result, h.ID().Pretty())
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestDirectoryRefresh(t *testing.T) {
+	net, hosts := newTestHosts(t, 5)
+	boot, a, b, c, d := hosts[0], hosts[1], hosts[2], hosts[3], hosts[4]
+	if _, err := net.ConnectPeers(boot.ID(), c.ID()); err != nil {
+		t.Fatalf("cannot connect peers: %v", err)
+	}
+	topics := shardTopics(2)
+	client, shard0, shard1 := topics[0], topics[1], topics[2]
+	discovery := &fakeDiscovery{
+		peers: map[string][]host.Host{
+			client: {boot},
+			shard0: {a, b},
+			shard1: {a, d},
+		},
+		errs: map[string]error{},
+	}
+	boot.Peerstore().Put(a.ID(), agentVersionKey, "harmony/v1")
+
+	dir := newDirectory(boot, discovery, topics, 2)
+	dir.refresh()
+
+	stats := dir.stats()
+	if stats.Total != 4 {
+		t.Errorf("total: got %d, want 4", stats.Total)
+	}
+	wantCounts := map[string]int{client: 0, shard0: 2, shard1: 2}
+	if !reflect.DeepEqual(stats.Counts, wantCounts) {
+		t.Errorf("counts: got %v, want %v", stats.Counts, wantCounts)
+	}
+	if !reflect.DeepEqual(stats.Short, []string{client}) {
+		t.Errorf("short: got %v, want [%s]", stats.Short, client)
+	}
+	wantVersions := map[string]int{"harmony/v1": 1, "unknown": 3}
+	if !reflect.DeepEqual(stats.Versions, wantVersions) {
+		t.Errorf("versions: got %v, want %v", stats.Versions, wantVersions)
+	}
+	if len(stats.History) != 1 || !reflect.DeepEqual(stats.History[0].Counts, wantCounts) {
+		t.Errorf("history: got %v, want one sample of %v", stats.History, wantCounts)
+	}
+	if got := boot.Peerstore().Addrs(a.ID()); len(got) == 0 {
+		t.Errorf("addresses of a discovered peer are not persisted")
+	}
+
+	peers := dir.listPeers()
+	if got, want := ids(peers.Topics[shard0]), sortedIDs(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s peers: got %v, want %v", shard0, got, want)
+	}
+	if got, want := ids(peers.Topics[shard1]), sortedIDs(a, d); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s peers: got %v, want %v", shard1, got, want)
+	}
+	if got := peers.Topics[client]; len(got) != 0 {
+		t.Errorf("%s peers: got %v, want none", client, ids(got))
+	}
+	if got, want := ids(peers.Unadvertised), sortedIDs(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("unadvertised peers: got %v, want %v", got, want)
+	}
+	if record := peers.Unadvertised[0]; !record.Connected {
+		t.Errorf("connected peer %s listed as disconnected", record.ID)
+	}
+	if record := dir.peers[a.ID()]; record.Connected {
+		t.Errorf("disconnected peer %s listed as connected", record.ID)
+	}
+
+	// b is no longer advertised but was seen recently, d expired, and a
+	// failed lookup keeps the topic counted as empty
+	discovery.peers[shard0] = []host.Host{a}
+	discovery.peers[shard1] = []host.Host{a}
+	discovery.errs[client] = errors.New("lookup failed")
+	dir.peers[d.ID()].LastSeen = time.Now().Add(-peerExpiry - time.Minute)
+	dir.refresh()
+
+	stats = dir.stats()
+	if stats.Total != 3 {
+		t.Errorf("total after expiry: got %d, want 3", stats.Total)
+	}
+	if _, ok := dir.peers[d.ID()]; ok {
+		t.Errorf("expired peer %s is still listed", d.ID().Pretty())
+	}
+	if _, ok := dir.peers[b.ID()]; !ok {
+		t.Errorf("recently seen peer %s is not listed", b.ID().Pretty())
+	}
+	if len(stats.History) != 2 {
+		t.Errorf("history length: got %d, want 2", len(stats.History))
+	}
+}
+
+func TestDirectoryHandler(t *testing.T) {
+	net, hosts := newTestHosts(t, 3)
+	boot, a, b := hosts[0], hosts[1], hosts[2]
+	if _, err := net.ConnectPeers(boot.ID(), b.ID()); err != nil {
+		t.Fatalf("cannot connect peers: %v", err)
+	}
+	topics := shardTopics(1)
+	discovery := &fakeDiscovery{peers: map[string][]host.Host{topics[1]: {a}}}
+	dir := newDirectory(boot, discovery, topics, 1)
+	dir.refresh()
+
+	server := httptest.NewServer(dir.handler())
+	defer server.Close()
+	get := func(path string, v interface{}) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("GET %s: content type %q", path, got)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("GET %s: cannot decode response: %v", path, err)
+		}
+	}
+
+	var peers peersResponse
+	get("/peers", &peers)
+	if got, want := ids(peers.Topics[topics[1]]), sortedIDs(a); !reflect.DeepEqual(got, want) {
+		t.Errorf("/peers %s: got %v, want %v", topics[1], got, want)
+	}
+	if got := peers.Topics[topics[0]]; got == nil || len(got) != 0 {
+		t.Errorf("/peers %s: got %v, want an empty list", topics[0], got)
+	}
+	if got, want := ids(peers.Unadvertised), sortedIDs(b); !reflect.DeepEqual(got, want) {
+		t.Errorf("/peers unadvertised: got %v, want %v", got, want)
+	}
+
+	var stats statsResponse
+	get("/stats", &stats)
+	if stats.Total != 2 || stats.MinPeers != 1 {
+		t.Errorf("/stats: got total %d min peers %d, want 2 and 1", stats.Total, stats.MinPeers)
+	}
+	if want := map[string]int{topics[0]: 0, topics[1]: 1}; !reflect.DeepEqual(stats.Counts, want) {
+		t.Errorf("/stats counts: got %v, want %v", stats.Counts, want)
+	}
+	if !reflect.DeepEqual(stats.Short, []string{topics[0]}) {
+		t.Errorf("/stats short: got %v, want [%s]", stats.Short, topics[0])
+	}
+	if len(stats.History) != 1 {
+		t.Errorf("/stats history length: got %d, want 1", len(stats.History))
+	}
+}
+
+func TestRedialKnownPeers(t *testing.T) {
+	net, hosts := newTestHosts(t, 4)
+	boot, known, connected, unknown := hosts[0], hosts[1], hosts[2], hosts[3]
+	if _, err := net.ConnectPeers(boot.ID(), connected.ID()); err != nil {
+		t.Fatalf("cannot connect peers: %v", err)
+	}
+	boot.Peerstore().AddAddrs(known.ID(), known.Addrs(), knownPeerAddrTTL)
+	boot.Peerstore().AddAddrs(connected.ID(), connected.Addrs(), knownPeerAddrTTL)
+
+	redialKnownPeers(boot)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for boot.Network().Connectedness(known.ID()) != network.Connected {
+		if time.Now().After(deadline) {
+			t.Fatalf("known peer %s was not redialed", known.ID().Pretty())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := len(boot.Network().ConnsToPeer(connected.ID())); got != 1 {
+		t.Errorf("connected peer dialed again: got %d connections, want 1", got)
+	}
+	if boot.Network().Connectedness(unknown.ID()) == network.Connected {
+		t.Errorf("peer %s without persisted addresses was dialed", unknown.ID().Pretty())
+	}
+}
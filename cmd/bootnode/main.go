@@ -6,18 +6,24 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"path"
 
 	"github.com/ethereum/go-ethereum/log"
 
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/p2p"
 	"github.com/harmony-one/harmony/p2p/p2pimpl"
+	"github.com/harmony-one/harmony/p2p/reputation"
 
 	badger "github.com/ipfs/go-ds-badger"
 
+	libp2p "github.com/libp2p/go-libp2p"
+	libp2pdis "github.com/libp2p/go-libp2p-discovery"
 	kaddht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p-peerstore/pstoreds"
 )
 
 var (
@@ -41,6 +47,10 @@ func main() {
 	versionFlag := flag.Bool("version", false, "Output version info")
 	verbosity := flag.Int("verbosity", 5, "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail (default: 5)")
 	logConn := flag.Bool("log_conn", false, "log incoming/outgoing connections")
+	networkType := flag.String("network_type", "mainnet", "type of the network whose shard topics are listed: mainnet, testnet, pangaea, partner, stressnet, devnet, localnet")
+	numShards := flag.Int("shards", 4, "number of shards whose topics are listed")
+	minPeers := flag.Int("min_peers", 32, "number of peers below which a shard topic is reported short of peers")
+	httpAddr := flag.String("http_addr", "127.0.0.1:9880", "address of the HTTP/JSON peer directory endpoint, disabled if empty")
 
 	flag.Parse()
 
@@ -58,9 +68,25 @@ func main() {
 		utils.FatalErrMsg(err, "cannot load key from %s", *keyFile)
 	}
 
+	nodeconfig.SetNetworkType(nodeconfig.NetworkType(*networkType))
+
+	// the DHT cache also persists the peerstore, so a restarted bootnode
+	// still knows the peers
+	dataStorePath := fmt.Sprintf(".dht-%s-%s", *ip, *port)
+	dataStore, err := badger.NewDatastore(dataStorePath, nil)
+	if err != nil {
+		utils.FatalErrMsg(err, "cannot initialize DHT cache at %s", dataStorePath)
+	}
+	peerstore, err := pstoreds.NewPeerstore(context.Background(), dataStore, pstoreds.DefaultOpts())
+	if err != nil {
+		utils.FatalErrMsg(err, "cannot initialize peerstore at %s", dataStorePath)
+	}
+
 	var selfPeer = p2p.Peer{IP: *ip, Port: *port}
 
-	host, err := p2pimpl.NewHost(&selfPeer, privKey)
+	host, err := p2pimpl.NewHostWithReputation(
+		&selfPeer, privKey, reputation.DefaultConfig(), libp2p.Peerstore(peerstore),
+	)
 	if err != nil {
 		utils.FatalErrMsg(err, "cannot initialize network")
 	}
@@ -74,16 +100,24 @@ func main() {
 	// set the KValue to 50 for DHT
 	// 50 is the size of every bucket in the DHT
 	kaddht.KValue = 50
-	dataStorePath := fmt.Sprintf(".dht-%s-%s", *ip, *port)
-	dataStore, err := badger.NewDatastore(dataStorePath, nil)
-	if err != nil {
-		utils.FatalErrMsg(err, "cannot initialize DHT cache at %s", dataStorePath)
-	}
 	dht := kaddht.NewDHT(context.Background(), host.GetP2PHost(), dataStore)
 
 	if err := dht.Bootstrap(context.Background()); err != nil {
 		utils.FatalErrMsg(err, "cannot bootstrap DHT")
 	}
+	redialKnownPeers(host.GetP2PHost())
+
+	dir := newDirectory(
+		host.GetP2PHost(), libp2pdis.NewRoutingDiscovery(dht), shardTopics(*numShards), *minPeers,
+	)
+	go dir.run()
+	if *httpAddr != "" {
+		go func() {
+			if err := http.ListenAndServe(*httpAddr, dir.handler()); err != nil {
+				utils.FatalErrMsg(err, "cannot serve peer directory at %s", *httpAddr)
+			}
+		}()
+	}
 
 	select {}
 }
@@ -21,11 +21,13 @@ import (
 	"github.com/harmony-one/harmony/numeric"
 
 	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/harmony/api/service/syncing"
 	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/recorder"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/internal/blsgen"
 	"github.com/harmony-one/harmony/internal/common"
@@ -41,6 +43,7 @@ import (
 	"github.com/harmony-one/harmony/node"
 	"github.com/harmony-one/harmony/p2p"
 	"github.com/harmony-one/harmony/p2p/p2pimpl"
+	"github.com/harmony-one/harmony/p2p/reputation"
 	p2putils "github.com/harmony-one/harmony/p2p/utils"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/webhooks"
@@ -90,6 +93,8 @@ var (
 	isArchival = flag.Bool("is_archival", false, "false will enable cached state pruning")
 	// delayCommit is the commit-delay timer, used by Harmony nodes
 	delayCommit = flag.String("delay_commit", "0ms", "how long to delay sending commit messages in consensus, ex: 500ms, 1s")
+	// aggregateSig aggregates the votes of the bls keys of the node into one message
+	aggregateSig = flag.Bool("aggregate_sig", false, "send a single prepare and commit message with the signatures of all the bls keys of the node aggregated, from the aggregate vote epoch on")
 	// nodeType indicates the type of the node: validator, explorer
 	nodeType = flag.String("node_type", "validator", "node type: validator, explorer")
 	// networkType indicates the type of the network
@@ -98,6 +103,12 @@ var (
 	syncFreq = flag.Int("sync_freq", 60, "unit in seconds")
 	// beaconSyncFreq indicates beaconchain sync frequency
 	beaconSyncFreq = flag.Int("beacon_sync_freq", 60, "unit in seconds")
+	// syncGRPC indicates whether to sync blocks over the legacy gRPC downloader
+	syncGRPC = flag.Bool("sync_grpc", false, "sync blocks over the legacy gRPC downloader on port-3000 instead of the libp2p sync protocol, both are served either way")
+	// syncCheckpoint is the hash of a trusted epoch block to sync a new non-archival node from
+	syncCheckpoint = flag.String("sync_checkpoint", "", "hash of a trusted last block of an epoch to sync a new non-archival node from")
+	// syncCheckpointShardState is the shard state of the checkpoint epoch
+	syncCheckpointShardState = flag.String("sync_checkpoint_shard_state", "", "file with the hex encoded shard state of the checkpoint epoch, required with sync_checkpoint")
 	// blockPeriod indicates the how long the leader waits to propose a new block.
 	blockPeriod    = flag.Int("block_period", 8, "how long in second the leader waits to propose a new block.")
 	leaderOverride = flag.Bool("leader_override", false, "true means override the default leader role and acts as validator")
@@ -120,6 +131,14 @@ var (
 	logConn     = flag.Bool("log_conn", false, "log incoming/outgoing connections")
 	keystoreDir = flag.String("keystore", hmykey.DefaultKeyStoreDir, "The default keystore directory")
 
+	// peerBanDuration is how long a misbehaving peer is banned
+	peerBanDuration = flag.String("peer_ban_duration", "1h", "how long a misbehaving p2p peer is banned, ex: 30m, 24h")
+	// peersFile lists the static and trusted peers kept connected
+	peersFile = flag.String("peers_file", "", "JSON file of the static and trusted p2p peers kept connected (default <db_dir>/peers.json)")
+
+	// recordConsensus records the consensus messages for replaying them
+	recordConsensus = flag.String("record_consensus", "", "file to record the consensus messages received and sent to, for replaying with the replay command; empty to not record")
+
 	// Use a separate log file to log libp2p traces
 	logP2P = flag.Bool("log_p2p", false, "log libp2p debug info")
 
@@ -397,13 +416,35 @@ func createGlobalConfig() (*nodeconfig.ConfigType, error) {
 
 	selfPeer := p2p.Peer{IP: *ip, Port: *port, ConsensusPubKey: nodeConfig.ConsensusPubKey.PublicKey[0]}
 
-	myHost, err = p2pimpl.NewHost(&selfPeer, nodeConfig.P2pPriKey)
+	reputationConfig := reputation.DefaultConfig()
+	if reputationConfig.BanDuration, err = time.ParseDuration(*peerBanDuration); err != nil {
+		return nil, errors.Wrapf(err, "invalid peer ban duration %#v", *peerBanDuration)
+	}
+	reputationConfig.BanFile = path.Join(*dbDir, "banned_peers.json")
+	myHost, err = p2pimpl.NewHostWithReputation(&selfPeer, nodeConfig.P2pPriKey, reputationConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot create P2P network host")
 	}
 	if *logConn && nodeConfig.GetNetworkType() != nodeconfig.Mainnet {
 		myHost.GetP2PHost().Network().Notify(utils.NewConnLogger(utils.GetLogger()))
 	}
+	if *peersFile == "" {
+		*peersFile = path.Join(*dbDir, "peers.json")
+	}
+	staticPeers, trustedPeers, err := p2putils.LoadPeersFile(*peersFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range staticPeers {
+		if err := myHost.AddStaticPeer(addr); err != nil {
+			return nil, errors.Wrapf(err, "cannot add static peer %s", addr)
+		}
+	}
+	for _, addr := range trustedPeers {
+		if err := myHost.AddTrustedPeer(addr); err != nil {
+			return nil, errors.Wrapf(err, "cannot add trusted peer %s", addr)
+		}
+	}
 
 	nodeConfig.DBDir = *dbDir
 
@@ -427,8 +468,15 @@ func setupConsensusAndNode(nodeConfig *nodeconfig.ConfigType) *node.Node {
 	// TODO(minhdoan): During refactoring, found out that the peers list is actually empty. Need to clean up the logic of consensus later.
 	decider := quorum.NewDecider(quorum.SuperMajorityVote, uint32(*shardID))
 
+	// the consensus sends through the recording host, the node records the
+	// consensus messages it hands over to the consensus
+	consensusHost, consensusRecorder := myHost, (*recorder.Recorder)(nil)
+	if *recordConsensus != "" {
+		consensusRecorder = recorder.New(recorder.DefaultConfig(*recordConsensus))
+		consensusHost = recorder.WrapHost(myHost, consensusRecorder)
+	}
 	currentConsensus, err := consensus.New(
-		myHost, nodeConfig.ShardID, p2p.Peer{}, nodeConfig.ConsensusPriKey, decider,
+		consensusHost, nodeConfig.ShardID, p2p.Peer{}, nodeConfig.ConsensusPriKey, decider,
 	)
 	currentConsensus.Decider.SetMyPublicKeyProvider(func() (*multibls.PublicKey, error) {
 		return currentConsensus.PubKey, nil
@@ -450,6 +498,7 @@ func setupConsensusAndNode(nodeConfig *nodeconfig.ConfigType) *node.Node {
 		os.Exit(1)
 	}
 	currentConsensus.SetCommitDelay(commitDelay)
+	currentConsensus.SetAggregateSig(*aggregateSig)
 	currentConsensus.MinPeers = *minPeers
 
 	if *disableViewChange {
@@ -465,8 +514,12 @@ func setupConsensusAndNode(nodeConfig *nodeconfig.ConfigType) *node.Node {
 	chainDBFactory := &shardchain.LDBFactory{RootDir: nodeConfig.DBDir}
 
 	currentNode := node.New(myHost, currentConsensus, chainDBFactory, blacklist, *isArchival)
+	currentNode.ConsensusRecorder = consensusRecorder
 
+	currentNode.NodeConfig.SyncGRPC = *syncGRPC
 	switch {
+	case !*syncGRPC:
+		currentNode.SyncingPeerProvider = node.NewStreamSyncingPeerProvider(myHost.GetP2PHost())
 	case *networkType == nodeconfig.Localnet:
 		epochConfig := shard.Schedule.InstanceForEpoch(ethCommon.Big0)
 		selfPort, err := strconv.ParseUint(*port, 10, 16)
@@ -550,12 +603,28 @@ func setupConsensusAndNode(nodeConfig *nodeconfig.ConfigType) *node.Node {
 
 	// Assign closure functions to the consensus object
 	currentConsensus.BlockVerifier = currentNode.VerifyNewBlock
+	currentConsensus.BlockReconstructor = currentNode.ReconstructBlock
+	currentConsensus.TxPrefill = currentNode.PrefillTx
+	if consensusRecorder != nil {
+		currentConsensus.BlockReconstructor = consensusRecorder.WrapReconstructor(currentNode.ReconstructBlock)
+	}
 	currentConsensus.OnConsensusDone = currentNode.PostConsensusProcessing
 	currentNode.State = node.NodeWaitToJoin
 
 	// update consensus information based on the blockchain
 	currentConsensus.SetMode(currentConsensus.UpdateConsensusInformation())
 
+	// restore the consensus state persisted before a restart
+	fbftStore, err := consensus.NewFBFTStore(
+		path.Join(nodeConfig.DBDir, fmt.Sprintf("fbft_%d", nodeConfig.ShardID)),
+	)
+	if err != nil {
+		utils.Logger().Fatal().Err(err).Msg("cannot open FBFT store")
+	}
+	if err := currentConsensus.SetFBFTStore(fbftStore); err != nil {
+		utils.Logger().Fatal().Err(err).Msg("cannot restore FBFT state")
+	}
+
 	// Watching currentNode and currentConsensus.
 	memprofiling.GetMemProfiling().Add("currentNode", currentNode)
 	memprofiling.GetMemProfiling().Add("currentConsensus", currentConsensus)
@@ -582,6 +651,29 @@ func setupBlacklist() (map[ethCommon.Address]struct{}, error) {
 	return addrMap, nil
 }
 
+func setupSyncCheckpoint() (*syncing.Checkpoint, error) {
+	hash, err := hexutil.Decode(*syncCheckpoint)
+	if err != nil || len(hash) != ethCommon.HashLength {
+		return nil, errors.Errorf("invalid checkpoint hash %s", *syncCheckpoint)
+	}
+	checkpoint := &syncing.Checkpoint{Hash: ethCommon.BytesToHash(hash)}
+	if *syncCheckpointShardState == "" {
+		return nil, errors.New("sync_checkpoint_shard_state is required with sync_checkpoint")
+	}
+	dat, err := ioutil.ReadFile(*syncCheckpointShardState)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := hexutil.Decode(strings.TrimSpace(string(dat)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid shard state in %s", *syncCheckpointShardState)
+	}
+	if checkpoint.ShardState, err = shard.DecodeWrapper(encoded); err != nil {
+		return nil, errors.Wrapf(err, "invalid shard state in %s", *syncCheckpointShardState)
+	}
+	return checkpoint, nil
+}
+
 func setupViperConfig() {
 
 	// read from environment
@@ -608,11 +700,16 @@ func setupViperConfig() {
 	viperconfig.ResetConfBool(isGenesis, envViper, configFileViper, "", "is_genesis")
 	viperconfig.ResetConfBool(isArchival, envViper, configFileViper, "", "is_archival")
 	viperconfig.ResetConfString(delayCommit, envViper, configFileViper, "", "delay_commit")
+	viperconfig.ResetConfBool(aggregateSig, envViper, configFileViper, "", "aggregate_sig")
+	viperconfig.ResetConfString(recordConsensus, envViper, configFileViper, "", "record_consensus")
 	viperconfig.ResetConfString(nodeType, envViper, configFileViper, "", "node_type")
 	viperconfig.ResetConfString(networkType, envViper, configFileViper, "", "network_type")
 
 	viperconfig.ResetConfInt(syncFreq, envViper, configFileViper, "", "sync_freq")
 	viperconfig.ResetConfInt(beaconSyncFreq, envViper, configFileViper, "", "beacon_sync_freq")
+	viperconfig.ResetConfBool(syncGRPC, envViper, configFileViper, "", "sync_grpc")
+	viperconfig.ResetConfString(syncCheckpoint, envViper, configFileViper, "", "sync_checkpoint")
+	viperconfig.ResetConfString(syncCheckpointShardState, envViper, configFileViper, "", "sync_checkpoint_shard_state")
 	viperconfig.ResetConfInt(blockPeriod, envViper, configFileViper, "", "block_period")
 	viperconfig.ResetConfBool(leaderOverride, envViper, configFileViper, "", "leader_override")
 	viperconfig.ResetConfBool(stakingFlag, envViper, configFileViper, "", "staking")
@@ -629,6 +726,8 @@ func setupViperConfig() {
 	viperconfig.ResetConfBool(logConn, envViper, configFileViper, "", "log_conn")
 	viperconfig.ResetConfString(keystoreDir, envViper, configFileViper, "", "keystore")
 	viperconfig.ResetConfBool(logP2P, envViper, configFileViper, "", "log_p2p")
+	viperconfig.ResetConfString(peerBanDuration, envViper, configFileViper, "", "peer_ban_duration")
+	viperconfig.ResetConfString(peersFile, envViper, configFileViper, "", "peers_file")
 	viperconfig.ResetConfInt(verbosity, envViper, configFileViper, "", "verbosity")
 	viperconfig.ResetConfString(dbDir, envViper, configFileViper, "", "db_dir")
 	viperconfig.ResetConfBool(disableViewChange, envViper, configFileViper, "", "disable_view_change")
@@ -767,6 +866,19 @@ func main() {
 	currentNode.SetSyncFreq(*syncFreq)
 	currentNode.SetBeaconSyncFreq(*beaconSyncFreq)
 
+	if *syncCheckpoint != "" {
+		if *isArchival {
+			utils.Logger().Warn().Msg("sync_checkpoint is ignored by archival nodes")
+		} else {
+			checkpoint, err := setupSyncCheckpoint()
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "ERROR invalid sync checkpoint: %s\n", err)
+				os.Exit(1)
+			}
+			currentNode.SetSyncCheckpoint(checkpoint)
+		}
+	}
+
 	if nodeConfig.ShardID != shard.BeaconChainShardID &&
 		currentNode.NodeConfig.Role() != nodeconfig.ExplorerNode {
 		utils.Logger().Info().
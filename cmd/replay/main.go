@@ -0,0 +1,153 @@
+// replay replays the consensus recordings of a node, made with the
+// -record_consensus flag of harmony, step by step
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	"github.com/harmony-one/harmony/consensus/recorder"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/replay"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/multibls"
+	"github.com/harmony-one/harmony/shard"
+)
+
+var (
+	version string
+	builtBy string
+	builtAt string
+	commit  string
+)
+
+func printVersion(me string) {
+	fmt.Fprintf(os.Stderr, "Harmony (C) 2020. %v, version %v-%v (%v %v)\n", path.Base(me), version, commit, builtBy, builtAt)
+	os.Exit(0)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] recording [rotated recordings...]\n", path.Base(os.Args[0]))
+	flag.PrintDefaults()
+}
+
+func main() {
+	networkType := flag.String("network_type", "mainnet", "type of the recorded network: mainnet, testnet, pangaea, partner, stressnet, localnet")
+	shardID := flag.Uint("shard_id", 0, "the shard ID of the recorded node")
+	blsKeys := flag.String("bls_keys", "", "comma separated BLS secret keys in hex of the recorded node, a random key if empty")
+	tick := flag.Duration("tick", replay.DefaultTickInterval, "the interval the timeouts are checked at")
+	verbosity := flag.Int("verbosity", 2, "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail (default: 2)")
+	versionFlag := flag.Bool("version", false, "Output version info")
+	flag.Usage = usage
+
+	flag.Parse()
+
+	if *versionFlag {
+		printVersion(os.Args[0])
+	}
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	utils.SetLogVerbosity(log.Lvl(*verbosity))
+
+	switch *networkType {
+	case nodeconfig.Mainnet:
+		shard.Schedule = shardingconfig.MainnetSchedule
+	case nodeconfig.Testnet:
+		shard.Schedule = shardingconfig.TestnetSchedule
+	case nodeconfig.Pangaea:
+		shard.Schedule = shardingconfig.PangaeaSchedule
+	case nodeconfig.Localnet:
+		shard.Schedule = shardingconfig.LocalnetSchedule
+	case nodeconfig.Partner:
+		shard.Schedule = shardingconfig.PartnerSchedule
+	case nodeconfig.Stressnet:
+		shard.Schedule = shardingconfig.StressNetSchedule
+	default:
+		fmt.Fprintf(os.Stderr, "invalid network type: %#v\n", *networkType)
+		os.Exit(2)
+	}
+	nodeconfig.SetNetworkType(nodeconfig.NetworkType(*networkType))
+
+	var key *multibls.PrivateKey
+	if *blsKeys != "" {
+		key = &multibls.PrivateKey{}
+		for _, hex := range strings.Split(*blsKeys, ",") {
+			secretKey := &bls.SecretKey{}
+			if err := secretKey.DeserializeHexStr(strings.TrimSpace(hex)); err != nil {
+				utils.FatalErrMsg(err, "cannot decode BLS key %s", hex)
+			}
+			multibls.AppendPriKey(key, secretKey)
+		}
+	}
+
+	records, err := recorder.ReadFiles(flag.Args()...)
+	if err != nil {
+		utils.FatalErrMsg(err, "cannot read recordings")
+	}
+	replayer, err := replay.New(replay.Config{
+		ShardID:      uint32(*shardID),
+		Key:          key,
+		TickInterval: *tick,
+	})
+	if err != nil {
+		utils.FatalErrMsg(err, "cannot set up replay")
+	}
+	if err := replayer.Replay(records, printStep); err != nil {
+		utils.FatalErrMsg(err, "cannot replay recordings")
+	}
+}
+
+func printStep(step *replay.Step) {
+	at := step.Time.UTC().Format(time.RFC3339Nano)
+	if step.Record == nil {
+		fmt.Printf("%s tick\n", at)
+	} else {
+		fmt.Printf("%s %-8s %s from %s\n",
+			at, step.Record.Direction, describe(step.Message), step.Record.Sender.Pretty())
+	}
+	before, after := step.Before, step.After
+	if before.Mode != after.Mode {
+		fmt.Printf("    mode %s -> %s\n", before.Mode, after.Mode)
+	}
+	if before.Phase != after.Phase {
+		fmt.Printf("    phase %s -> %s\n", before.Phase, after.Phase)
+	}
+	if before.BlockNum != after.BlockNum {
+		fmt.Printf("    block %d -> %d\n", before.BlockNum, after.BlockNum)
+	}
+	if before.ViewID != after.ViewID {
+		fmt.Printf("    view %d -> %d\n", before.ViewID, after.ViewID)
+	}
+	if before.ViewChangingID != after.ViewChangingID {
+		fmt.Printf("    view changing %d -> %d\n", before.ViewChangingID, after.ViewChangingID)
+	}
+	if before.Leader != after.Leader {
+		fmt.Printf("    leader %s -> %s\n", before.Leader, after.Leader)
+	}
+	for _, msg := range step.Sent {
+		fmt.Printf("    sent %s\n", describe(msg))
+	}
+}
+
+// describe returns the type, block number and view ID of a consensus message
+func describe(msg *msg_pb.Message) string {
+	if request := msg.GetConsensus(); request != nil {
+		return fmt.Sprintf("%s block %d view %d", msg.GetType(), request.GetBlockNum(), request.GetViewId())
+	}
+	if request := msg.GetViewchange(); request != nil {
+		return fmt.Sprintf("%s block %d view %d", msg.GetType(), request.GetBlockNum(), request.GetViewId())
+	}
+	return msg.GetType().String()
+}
@@ -4,13 +4,15 @@ import (
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/internal/chain"
+	"github.com/harmony-one/harmony/p2p/reputation"
 	"github.com/harmony-one/harmony/shard"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
 )
 
 // MaxBlockNumDiff limits the received block number to only 100 further from the current block number
 const MaxBlockNumDiff = 100
 
-func (consensus *Consensus) validatorSanityChecks(msg *msg_pb.Message) bool {
+func (consensus *Consensus) validatorSanityChecks(msg *msg_pb.Message, sender libp2p_peer.ID) bool {
 	consensus.getLogger().Debug().
 		Uint64("blockNum", msg.GetConsensus().BlockNum).
 		Uint64("viewID", msg.GetConsensus().ViewId).
@@ -40,13 +42,14 @@ func (consensus *Consensus) validatorSanityChecks(msg *msg_pb.Message) bool {
 		consensus.getLogger().Error().Err(err).Msg(
 			"Failed to verify sender's signature",
 		)
+		consensus.reportPeer(sender, reputation.InvalidSignature)
 		return false
 	}
 
 	return true
 }
 
-func (consensus *Consensus) leaderSanityChecks(msg *msg_pb.Message) bool {
+func (consensus *Consensus) leaderSanityChecks(msg *msg_pb.Message, sender libp2p_peer.ID) bool {
 	consensus.getLogger().Debug().
 		Uint64("blockNum", msg.GetConsensus().BlockNum).
 		Uint64("viewID", msg.GetConsensus().ViewId).
@@ -72,6 +75,7 @@ func (consensus *Consensus) leaderSanityChecks(msg *msg_pb.Message) bool {
 			"[%s] Failed to verify sender's signature",
 			msg.GetType().String(),
 		)
+		consensus.reportPeer(sender, reputation.InvalidSignature)
 		return false
 	}
 
@@ -174,12 +178,25 @@ func (consensus *Consensus) onPreparedSanityChecks(
 			consensus.getLogger().Error().Err(err).Msg("[OnPrepared] Block verification failed")
 			return false
 		}
+		// the randomness of the block is served to the contracts, so its VRF
+		// must be the leader's one
+		if len(blockObj.Header().Vrf()) > 0 && !consensus.ValidateVrfAndProof(blockObj.Header()) {
+			return false
+		}
+		// the proof of the VDF is checked instead of rerunning the delay, from
+		// an epoch all the nodes started with the VRFs of the seed stored, as a
+		// node upgraded during an epoch misses the ones before
+		if len(blockObj.Header().Vdf()) > 0 &&
+			consensus.ChainReader.Config().IsVdfProof(blockObj.Header().Epoch()) &&
+			!consensus.ValidateVdfAndProof(blockObj.Header()) {
+			return false
+		}
 	}
 
 	return true
 }
 
-func (consensus *Consensus) viewChangeSanityCheck(msg *msg_pb.Message) bool {
+func (consensus *Consensus) viewChangeSanityCheck(msg *msg_pb.Message, sender libp2p_peer.ID) bool {
 	consensus.getLogger().Debug().
 		Msg("[viewChangeSanityCheck] Checking new message")
 	senderKey, err := consensus.verifyViewChangeSenderKey(msg)
@@ -202,6 +219,7 @@ func (consensus *Consensus) viewChangeSanityCheck(msg *msg_pb.Message) bool {
 			"[%s] Failed To Verify Sender's Signature",
 			msg.GetType().String(),
 		)
+		consensus.reportPeer(sender, reputation.InvalidSignature)
 		return false
 	}
 	return true
@@ -2,17 +2,11 @@ package consensus
 
 import "time"
 
-// timeout constant
+// The durations of the announce/prepare/commit, bootstrap and view change
+// timeouts are part of the sharding configuration of the network, see
+// shardingconfig.ConsensusTimeouts.
 const (
-	// The duration of viewChangeTimeout; when a view change is initialized with v+1
-	// timeout will be equal to viewChangeDuration; if view change failed and start v+2
-	// timeout will be 2*viewChangeDuration; timeout of view change v+n is n*viewChangeDuration
-	viewChangeDuration time.Duration = 60 * time.Second
-
-	// timeout duration for announce/prepare/commit
-	phaseDuration     time.Duration = 60 * time.Second
-	bootstrapDuration time.Duration = 600 * time.Second
-	maxLogSize        uint32        = 1000
+	maxLogSize uint32 = 1000
 	// threshold between received consensus message blockNum and my blockNum
 	consensusBlockNumBuffer uint64 = 2
 )
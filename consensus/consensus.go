@@ -12,26 +12,43 @@ import (
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
 	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/crypto/vdf"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
 	"github.com/harmony-one/harmony/internal/memprofiling"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/multibls"
 	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/staking/slash"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
 	"github.com/pkg/errors"
 )
 
 const (
-	vdFAndProofSize = 516 // size of VDF and Proof
-	vdfAndSeedSize  = 548 // size of VDF/Proof and Seed
+	vdFAndProofSize = vdf.Size      // size of VDF and Proof
+	vdfAndSeedSize  = vdf.Size + 32 // size of VDF/Proof and Seed
+	vrfAndProofSize = 32 + 96       // size of VRF and Proof
 )
 
 var errLeaderPriKeyNotFound = errors.New("getting leader private key from consensus public keys failed")
 
+// IncomingMessage is a consensus message received from a p2p peer
+type IncomingMessage struct {
+	Payload []byte
+	Sender  libp2p_peer.ID
+	// Block is the block of a prepared message sent in compact form, rebuilt
+	// before the message is queued, nil if not rebuilt
+	Block *types.Block
+}
+
 // Consensus is the main struct with all states and data related to consensus process.
 type Consensus struct {
 	Decider quorum.Decider
 	// FBFTLog stores the pbft messages and blocks during FBFT process
 	FBFTLog *FBFTLog
+	// fbftStore persists the FBFT log, the signatures of the node and the
+	// view change state across restarts, nil if not persisted
+	fbftStore *FBFTStore
 	// phase: different phase of FBFT protocol: pre-prepare, prepare, commit, finish etc
 	phase FBFTPhase
 	// current indicates what state a node is in
@@ -42,13 +59,15 @@ type Consensus struct {
 	// should be equal to the blockNumber of next block
 	blockNum uint64
 	// channel to receive consensus message
-	MsgChan chan []byte
+	MsgChan chan IncomingMessage
 	// How long to delay sending commit messages.
 	delayCommit time.Duration
 	// Consensus rounds whose commit phase finished
 	commitFinishChan chan uint64
 	// 2 types of timeouts: normal and viewchange
 	consensusTimeout map[TimeoutType]*utils.Timeout
+	// durations of the timeouts in the current epoch
+	timeouts shardingconfig.ConsensusTimeouts
 	// Commits collected from validators.
 	aggregatedPrepareSig *bls.Sign
 	aggregatedCommitSig  *bls.Sign
@@ -90,6 +109,9 @@ type Consensus struct {
 	blockHash [32]byte
 	// Block to run consensus on
 	block []byte
+	// Compact encoding of the block, sent in the prepared message in place of
+	// the block when set
+	compactBlock []byte
 	// BlockHeader to run consensus on
 	blockHeader []byte
 	// Array of block hashes.
@@ -109,6 +131,12 @@ type Consensus struct {
 	OnConsensusDone func(*types.Block, []byte)
 	// The verifier func passed from Node object
 	BlockVerifier func(*types.Block) error
+	// The block reconstructor passed from Node object, rebuilding the block of
+	// a compact block from the transactions the node holds or gets from the sender
+	BlockReconstructor func(*types.CompactBlock, libp2p_peer.ID) (*types.Block, error)
+	// The prefill policy passed from Node object, telling the transactions
+	// sent in full in the compact block of the prepared message
+	TxPrefill func(txHash common.Hash) bool
 	// verified block to state sync broadcast
 	VerifiedNewBlock chan *types.Block
 	// will trigger state syncing when blockNum is low
@@ -130,10 +158,20 @@ type Consensus struct {
 	syncNotReadyChan chan struct{}
 	// If true, this consensus will not propose view change.
 	disableViewChange bool
+	// If true, the prepare and commit of the keys of the node are sent as a
+	// single message with their signatures aggregated
+	aggregateSig bool
+	// validator owning each key of the committee, guarded by pubKeyLock; an
+	// aggregated vote must be by the keys of a single validator
+	keyOwners map[shard.BlsPublicKey]common.Address
 	// last node block reward for metrics
 	lastBlockReward *big.Int
 	// Have a dedicated reader thread pull from this chan, like in node
 	SlashChan chan slash.Record
+	// timing of the phases of the last blocks
+	timings *Timings
+	// verifies the signatures of the votes the leader receives in batches
+	voteVerifier *voteVerifier
 }
 
 // SetCommitDelay sets the commit message delay.  If set to non-zero,
@@ -142,6 +180,21 @@ func (consensus *Consensus) SetCommitDelay(delay time.Duration) {
 	consensus.delayCommit = delay
 }
 
+// SetAggregateSig sets whether the node aggregates the votes of its keys into
+// a single prepare and commit message.  The votes are only aggregated from the
+// aggregate vote epoch on, since leaders before it drop aggregated votes.
+func (consensus *Consensus) SetAggregateSig(aggregateSig bool) {
+	consensus.aggregateSig = aggregateSig
+}
+
+// SetClock sets the clock the consensus and view change timeouts are measured
+// by, such as the virtual clock of a replay.  It must be set before the
+// consensus starts.
+func (consensus *Consensus) SetClock(now func() time.Time) {
+	consensus.consensusTimeout = createTimeout(consensus.timeouts, now)
+	consensus.timings.setClock(now)
+}
+
 // DisableViewChangeForTestingOnly makes the receiver not propose view
 // changes when it should, e.g. leader timeout.
 //
@@ -165,7 +218,24 @@ func (consensus *Consensus) BlocksNotSynchronized() {
 
 // VdfSeedSize returns the number of VRFs for VDF computation
 func (consensus *Consensus) VdfSeedSize() int {
-	return int(consensus.Decider.ParticipantsCount()) * 2 / 3
+	return VdfSeedSize(int(consensus.Decider.ParticipantsCount()))
+}
+
+// VdfSeedSize returns the number of VRFs for the VDF computation of a
+// committee with the given number of keys, two thirds of them
+func VdfSeedSize(committeeSize int) int {
+	return committeeSize * 2 / 3
+}
+
+// Timeouts returns the durations of the consensus and view change timeouts
+// the node runs with
+func (consensus *Consensus) Timeouts() shardingconfig.ConsensusTimeouts {
+	return consensus.timeouts
+}
+
+// Timings returns the timing of the consensus phases of the last blocks
+func (consensus *Consensus) Timings() *Timings {
+	return consensus.timings
 }
 
 // GetBlockReward returns last node block reward
@@ -198,7 +268,8 @@ func New(
 	consensus := Consensus{}
 	consensus.Decider = Decider
 	consensus.host = host
-	consensus.msgSender = NewMessageSender(host)
+	consensus.timeouts = shardingconfig.DefaultConsensusTimeouts
+	consensus.msgSender = NewMessageSender(host, consensus.timeouts.Phase)
 	consensus.BlockNumLowChan = make(chan struct{})
 	// FBFT related
 	consensus.FBFTLog = NewFBFTLog()
@@ -206,7 +277,9 @@ func New(
 	// TODO Refactor consensus.block* into State?
 	consensus.current = State{mode: Normal}
 	// FBFT timeout
-	consensus.consensusTimeout = createTimeout()
+	consensus.consensusTimeout = createTimeout(consensus.timeouts, time.Now)
+	consensus.timings = NewTimings(DefaultTimingHistory, time.Now)
+	consensus.voteVerifier = newVoteVerifier(bls_cosi.DefaultBatchVerifier())
 	consensus.validators.Store(leader.ConsensusPubKey.SerializeToHexStr(), leader)
 
 	if multiBlsPriKey != nil {
@@ -224,7 +297,7 @@ func New(
 	// displayed on explorer as Height right now
 	consensus.viewID = 0
 	consensus.ShardID = shard
-	consensus.MsgChan = make(chan []byte)
+	consensus.MsgChan = make(chan IncomingMessage)
 	consensus.syncReadyChan = make(chan struct{})
 	consensus.syncNotReadyChan = make(chan struct{})
 	consensus.SlashChan = make(chan slash.Record)
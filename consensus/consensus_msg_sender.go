@@ -33,13 +33,23 @@ type MessageRetry struct {
 	p2pMsg        []byte
 	msgType       msg_pb.MessageType
 	retryCount    int
+	retryTimes    int
 	isActive      bool
 	isActiveMutex sync.Mutex
 }
 
-// NewMessageSender initializes the consensus message sender.
-func NewMessageSender(host p2p.Host) *MessageSender {
-	return &MessageSender{host: host, retryTimes: int(phaseDuration.Seconds()) / RetryIntervalInSec}
+// NewMessageSender initializes the consensus message sender, retrying the
+// messages for the duration of a consensus phase.
+func NewMessageSender(host p2p.Host, phaseDuration time.Duration) *MessageSender {
+	sender := &MessageSender{host: host}
+	sender.SetPhaseDuration(phaseDuration)
+	return sender
+}
+
+// SetPhaseDuration sets the duration of a consensus phase, which the messages
+// sent from then on are retried for.
+func (sender *MessageSender) SetPhaseDuration(phaseDuration time.Duration) {
+	sender.retryTimes = int(phaseDuration.Seconds()) / RetryIntervalInSec
 }
 
 // Reset resets the sender's state for new block
@@ -61,7 +71,7 @@ func (sender *MessageSender) Reset(blockNum uint64) {
 // SendWithRetry sends message with retry logic.
 func (sender *MessageSender) SendWithRetry(blockNum uint64, msgType msg_pb.MessageType, groups []nodeconfig.GroupID, p2pMsg []byte) error {
 	willRetry := sender.retryTimes != 0
-	msgRetry := MessageRetry{blockNum: blockNum, groups: groups, p2pMsg: p2pMsg, msgType: msgType, retryCount: 0, isActive: willRetry}
+	msgRetry := MessageRetry{blockNum: blockNum, groups: groups, p2pMsg: p2pMsg, msgType: msgType, retryCount: 0, retryTimes: sender.retryTimes, isActive: willRetry}
 	if willRetry {
 		sender.messagesToRetry.Store(msgType, &msgRetry)
 		go func() {
@@ -81,7 +91,7 @@ func (sender *MessageSender) Retry(msgRetry *MessageRetry) {
 	for {
 		time.Sleep(RetryIntervalInSec * time.Second)
 
-		if msgRetry.retryCount >= sender.retryTimes {
+		if msgRetry.retryCount >= msgRetry.retryTimes {
 			// Retried enough times
 			return
 		}
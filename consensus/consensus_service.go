@@ -22,6 +22,7 @@ import (
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/multibls"
 	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/reputation"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/shard/committee"
 	libp2p_peer "github.com/libp2p/go-libp2p-peer"
@@ -114,6 +115,37 @@ func (consensus *Consensus) UpdatePublicKeys(pubKeys []*bls.PublicKey) int64 {
 	return consensus.Decider.ParticipantsCount()
 }
 
+// setKeyOwners records the validator owning each key of the committee
+func (consensus *Consensus) setKeyOwners(committee *shard.Committee) {
+	owners := make(map[shard.BlsPublicKey]common.Address, len(committee.Slots))
+	for _, slot := range committee.Slots {
+		owners[slot.BlsPublicKey] = slot.EcdsaAddress
+	}
+	consensus.pubKeyLock.Lock()
+	consensus.keyOwners = owners
+	consensus.pubKeyLock.Unlock()
+}
+
+// keyOwner returns the validator owning the key of the committee
+func (consensus *Consensus) keyOwner(pubKey *bls.PublicKey) (common.Address, bool) {
+	key := shard.BlsPublicKey{}
+	if err := key.FromLibBLSPublicKey(pubKey); err != nil {
+		return common.Address{}, false
+	}
+	consensus.pubKeyLock.Lock()
+	defer consensus.pubKeyLock.Unlock()
+	owner, ok := consensus.keyOwners[key]
+	return owner, ok
+}
+
+// isAggregateVote returns whether the votes of several keys of a validator
+// may be aggregated into one message in the current epoch
+func (consensus *Consensus) isAggregateVote() bool {
+	return consensus.ChainReader.Config().IsAggregateVote(
+		new(big.Int).SetUint64(consensus.epoch),
+	)
+}
+
 // NewFaker returns a faker consensus.
 func NewFaker() *Consensus {
 	return &Consensus{}
@@ -183,6 +215,7 @@ func (consensus *Consensus) ResetState() {
 	consensus.blockHash = [32]byte{}
 	consensus.blockHeader = []byte{}
 	consensus.block = []byte{}
+	consensus.compactBlock = []byte{}
 	consensus.Decider.ResetPrepareAndCommitVotes()
 	members := consensus.Decider.Participants()
 	prepareBitmap, _ := bls_cosi.NewMask(members, nil)
@@ -248,6 +281,13 @@ func verifyMessageSig(signerPubKey *bls.PublicKey, message *msg_pb.Message) erro
 	return nil
 }
 
+// reportPeer reports the misbehaviour of the p2p peer which sent the message being handled
+func (consensus *Consensus) reportPeer(sender libp2p_peer.ID, misbehaviour reputation.Misbehaviour) {
+	if consensus.host != nil && sender != "" {
+		consensus.host.ReportPeer(sender, misbehaviour)
+	}
+}
+
 // verifySenderKey verifys the message senderKey is properly signed and senderAddr is valid
 func (consensus *Consensus) verifySenderKey(msg *msg_pb.Message) (*bls.PublicKey, error) {
 	consensusMsg := msg.GetConsensus()
@@ -281,6 +321,23 @@ func (consensus *Consensus) SetViewID(height uint64) {
 	consensus.current.viewID = height
 }
 
+// Phase returns the FBFT phase of the consensus
+func (consensus *Consensus) Phase() FBFTPhase {
+	return consensus.phase
+}
+
+// ViewChangingID returns the view the consensus changes to when view changing
+func (consensus *Consensus) ViewChangingID() uint64 {
+	return consensus.current.ViewID()
+}
+
+// BlockNum returns the number of the block the consensus agrees on
+func (consensus *Consensus) BlockNum() uint64 {
+	consensus.infoMutex.Lock()
+	defer consensus.infoMutex.Unlock()
+	return consensus.blockNum
+}
+
 // SetMode sets the mode of consensus
 func (consensus *Consensus) SetMode(m Mode) {
 	consensus.current.SetMode(m)
@@ -545,6 +602,9 @@ func (consensus *Consensus) UpdateConsensusInformation() Mode {
 		hasError = true
 	}
 
+	// the network may change its timeouts from an epoch on
+	consensus.setTimeouts(shard.Schedule.ConsensusTimeouts(epochToSet))
+
 	// update public keys in the committee
 	oldLeader := consensus.LeaderPubKey
 	pubKeys, _ := committeeToSet.BLSPublicKeys()
@@ -553,6 +613,7 @@ func (consensus *Consensus) UpdateConsensusInformation() Mode {
 		Int("numPubKeys", len(pubKeys)).
 		Msg("[UpdateConsensusInformation] Successfully updated public keys")
 	consensus.UpdatePublicKeys(pubKeys)
+	consensus.setKeyOwners(committeeToSet)
 
 	// Update voters in the committee
 	if _, err := consensus.Decider.SetVoters(
@@ -588,6 +649,26 @@ func (consensus *Consensus) UpdateConsensusInformation() Mode {
 		}
 	}
 
+	// the leadership passes to the key whose turn it is by rotation
+	if chain.IsLeaderRotationBlock(consensus.ChainReader.Config(), curHeader) {
+		leaderPubKey := new(bls.PublicKey)
+		slot, _, err := chain.RotationLeader(consensus.ChainReader, curHeader)
+		if err == nil {
+			err = slot.BlsPublicKey.ToLibBLSPublicKey(leaderPubKey)
+		}
+		if err != nil {
+			consensus.getLogger().Warn().Err(err).
+				Msg("[UpdateConsensusInformation] Unable to get the leader of the rotation turn")
+			hasError = true
+		} else {
+			consensus.getLogger().Info().
+				Str("leaderPubKey", leaderPubKey.SerializeToHexStr()).
+				Uint64("blockNum", curHeader.Number().Uint64()+1).
+				Msg("[UpdateConsensusInformation] Leader rotated")
+			consensus.LeaderPubKey = leaderPubKey
+		}
+	}
+
 	for _, key := range pubKeys {
 		// in committee
 		if consensus.PubKey.Contains(key) {
@@ -657,3 +738,82 @@ func (consensus *Consensus) addViewIDKeyIfNotExist(viewID uint64) {
 		consensus.viewIDBitmap[viewID] = viewIDBitmap
 	}
 }
+
+// SetFBFTStore restores the FBFT log and the view change state persisted in
+// the store before a restart, and persists them from now on.  It is called
+// once the consensus is set up from the blockchain, before it starts.
+func (consensus *Consensus) SetFBFTStore(store *FBFTStore) error {
+	if err := consensus.FBFTLog.SetStore(store); err != nil {
+		return errors.Wrap(err, "cannot restore FBFT log")
+	}
+	consensus.fbftStore = store
+	record, err := store.ReadViewChangeState()
+	if err != nil {
+		return errors.Wrap(err, "cannot restore view change state")
+	}
+	// a state of an earlier block or view is stale, the node has synced since
+	if record == nil || record.BlockNum != consensus.blockNum || record.ViewID < consensus.viewID {
+		return nil
+	}
+	leaderKey, err := bls_cosi.BytesToBlsPublicKey(record.LeaderPubKey)
+	if err != nil {
+		return errors.Wrap(err, "cannot restore view change leader")
+	}
+	consensus.SetViewID(record.ViewID)
+	consensus.LeaderPubKey = leaderKey
+	if record.Mode == ViewChanging && consensus.current.Mode() == Normal {
+		consensus.current.SetViewID(record.ViewChangingID)
+		consensus.current.SetMode(ViewChanging)
+		consensus.m1Payload = append(record.M1Payload[:0:0], record.M1Payload...)
+	}
+	consensus.getLogger().Info().
+		Uint64("viewChangingID", consensus.current.ViewID()).
+		Str("leader", leaderKey.SerializeToHexStr()).
+		Msg("[SetFBFTStore] Restored view change state")
+	return nil
+}
+
+// writeViewChangeState persists the view change state, if a store is set
+func (consensus *Consensus) writeViewChangeState() {
+	if consensus.fbftStore == nil || consensus.LeaderPubKey == nil {
+		return
+	}
+	if err := consensus.fbftStore.WriteViewChangeState(&ViewChangeRecord{
+		BlockNum:       consensus.blockNum,
+		ViewID:         consensus.viewID,
+		ViewChangingID: consensus.current.ViewID(),
+		Mode:           consensus.current.Mode(),
+		LeaderPubKey:   consensus.LeaderPubKey.Serialize(),
+		M1Payload:      consensus.m1Payload,
+	}); err != nil {
+		consensus.getLogger().Warn().Err(err).Msg("cannot persist view change state")
+	}
+}
+
+// canSign returns whether the node may sign the block hash in the phase of the
+// block number and view ID, recording the signature first if a store is set.
+// It keeps a restarted node from signing another block in a view it already
+// signed in.
+func (consensus *Consensus) canSign(
+	phase FBFTPhase, blockNum, viewID uint64, blockHash common.Hash,
+) bool {
+	if consensus.fbftStore == nil {
+		return true
+	}
+	ok, err := consensus.fbftStore.CheckAndWriteSigned(phase, blockNum, viewID, blockHash)
+	if err != nil {
+		consensus.getLogger().Error().Err(err).
+			Str("phase", phase.String()).
+			Msg("cannot check for an earlier signature, not signing")
+		return false
+	}
+	if !ok {
+		consensus.getLogger().Warn().
+			Str("phase", phase.String()).
+			Uint64("blockNum", blockNum).
+			Uint64("viewID", viewID).
+			Hex("blockHash", blockHash[:]).
+			Msg("already signed another block in this view, not signing")
+	}
+	return ok
+}
@@ -10,22 +10,27 @@ import (
 	"github.com/harmony-one/harmony/block"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/crypto/vdf"
 	vrf_bls "github.com/harmony-one/harmony/crypto/vrf/bls"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/ctxerror"
 	"github.com/harmony-one/harmony/p2p/host"
+	"github.com/harmony-one/harmony/p2p/reputation"
 	"github.com/harmony-one/harmony/shard"
-	"github.com/harmony-one/vdf/src/vdf_go"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
 )
 
 // handlemessageupdate will update the consensus state according to received message
-func (consensus *Consensus) handleMessageUpdate(payload []byte) {
+func (consensus *Consensus) handleMessageUpdate(
+	payload []byte, sender libp2p_peer.ID, rebuilt *types.Block,
+) {
 	if len(payload) == 0 {
 		return
 	}
 	msg := &msg_pb.Message{}
 	if err := protobuf.Unmarshal(payload, msg); err != nil {
 		consensus.getLogger().Error().Err(err).Msg("Failed to unmarshal message payload.")
+		consensus.reportPeer(sender, reputation.MalformedMessage)
 		return
 	}
 
@@ -46,6 +51,7 @@ func (consensus *Consensus) handleMessageUpdate(payload []byte) {
 				Uint32("myShardId", consensus.ShardID).
 				Uint32("receivedShardId", msg.GetViewchange().ShardId).
 				Msg("Received view change message from different shard")
+			consensus.reportPeer(sender, reputation.WrongShard)
 			return
 		}
 	} else {
@@ -55,6 +61,7 @@ func (consensus *Consensus) handleMessageUpdate(payload []byte) {
 				Uint32("myShardId", consensus.ShardID).
 				Uint32("receivedShardId", msg.GetConsensus().ShardId).
 				Msg("Received consensus message from different shard")
+			consensus.reportPeer(sender, reputation.WrongShard)
 			return
 		}
 	}
@@ -67,30 +74,30 @@ func (consensus *Consensus) handleMessageUpdate(payload []byte) {
 	// Handle validator intended messages first
 	case t == msg_pb.MessageType_ANNOUNCE &&
 		intendedForValidator &&
-		consensus.validatorSanityChecks(msg):
+		consensus.validatorSanityChecks(msg, sender):
 		consensus.onAnnounce(msg)
 	case t == msg_pb.MessageType_PREPARED &&
 		intendedForValidator &&
-		consensus.validatorSanityChecks(msg):
-		consensus.onPrepared(msg)
+		consensus.validatorSanityChecks(msg, sender):
+		consensus.onPrepared(msg, rebuilt)
 	case t == msg_pb.MessageType_COMMITTED &&
 		intendedForValidator &&
-		consensus.validatorSanityChecks(msg):
+		consensus.validatorSanityChecks(msg, sender):
 		consensus.onCommitted(msg)
 	// Handle leader intended messages now
 	case t == msg_pb.MessageType_PREPARE &&
 		intendedForLeader &&
-		consensus.leaderSanityChecks(msg):
+		consensus.leaderSanityChecks(msg, sender):
 		consensus.onPrepare(msg)
 	case t == msg_pb.MessageType_COMMIT &&
 		intendedForLeader &&
-		consensus.leaderSanityChecks(msg):
+		consensus.leaderSanityChecks(msg, sender):
 		consensus.onCommit(msg)
 	case t == msg_pb.MessageType_VIEWCHANGE &&
-		consensus.viewChangeSanityCheck(msg):
+		consensus.viewChangeSanityCheck(msg, sender):
 		consensus.onViewChange(msg)
 	case t == msg_pb.MessageType_NEWVIEW &&
-		consensus.viewChangeSanityCheck(msg):
+		consensus.viewChangeSanityCheck(msg, sender):
 		consensus.onNewView(msg)
 	}
 }
@@ -156,6 +163,7 @@ func (consensus *Consensus) finalizeCommits() {
 			Uint64("blockNum", consensus.blockNum).
 			Msg("[Finalizing] Sent Committed Message")
 	}
+	consensus.timings.finalized(block.NumberU64())
 
 	consensus.reportMetrics(*block)
 
@@ -330,11 +338,16 @@ func (consensus *Consensus) Start(
 		defer close(stoppedChan)
 		ticker := time.NewTicker(3 * time.Second)
 		defer ticker.Stop()
-		consensus.consensusTimeout[timeoutBootstrap].Start()
-		consensus.getLogger().Debug().
-			Uint64("viewID", consensus.viewID).
-			Uint64("blockNum", consensus.blockNum).
-			Msg("[ConsensusMainLoop] Start bootstrap timeout (only once)")
+		if consensus.current.Mode() == ViewChanging {
+			// rejoin the view change the node was in before a restart
+			consensus.startViewChange(consensus.current.ViewID())
+		} else {
+			consensus.consensusTimeout[timeoutBootstrap].Start()
+			consensus.getLogger().Debug().
+				Uint64("viewID", consensus.viewID).
+				Uint64("blockNum", consensus.blockNum).
+				Msg("[ConsensusMainLoop] Start bootstrap timeout (only once)")
+		}
 
 		vdfInProgress := false
 		for {
@@ -344,25 +357,7 @@ func (consensus *Consensus) Start(
 				if toStart == false && isInitialLeader {
 					continue
 				}
-				for k, v := range consensus.consensusTimeout {
-					if consensus.current.Mode() == Syncing ||
-						consensus.current.Mode() == Listening {
-						v.Stop()
-					}
-					if !v.CheckExpire() {
-						continue
-					}
-					if k != timeoutViewChange {
-						consensus.getLogger().Debug().Msg("[ConsensusMainLoop] Ops Consensus Timeout!!!")
-						consensus.startViewChange(consensus.viewID + 1)
-						break
-					} else {
-						consensus.getLogger().Debug().Msg("[ConsensusMainLoop] Ops View Change Timeout!!!")
-						viewID := consensus.current.ViewID()
-						consensus.startViewChange(viewID + 1)
-						break
-					}
-				}
+				consensus.CheckTimeouts()
 			case <-consensus.syncReadyChan:
 				consensus.getLogger().Debug().Msg("[ConsensusMainLoop] syncReadyChan")
 				consensus.SetBlockNum(consensus.ChainReader.CurrentHeader().Number().Uint64() + 1)
@@ -435,8 +430,7 @@ func (consensus *Consensus) Start(
 					if err == nil {
 						vdfInProgress = false
 						// Verify the randomness
-						vdfObject := vdf_go.New(shard.Schedule.VdfDifficulty(), seed)
-						if !vdfObject.Verify(vdfOutput) {
+						if !vdf.Verify(seed, shard.Schedule.VdfDifficulty(), vdfOutput) {
 							consensus.getLogger().Warn().
 								Uint64("MsgBlockNum", newBlock.NumberU64()).
 								Uint64("Epoch", newBlock.Header().Epoch().Uint64()).
@@ -474,7 +468,7 @@ func (consensus *Consensus) Start(
 				consensus.announce(newBlock)
 
 			case msg := <-consensus.MsgChan:
-				consensus.handleMessageUpdate(msg)
+				consensus.handleMessageUpdate(msg.Payload, msg.Sender, msg.Block)
 
 			case viewID := <-consensus.commitFinishChan:
 				consensus.getLogger().Debug().Msg("[ConsensusMainLoop] commitFinishChan")
@@ -496,6 +490,37 @@ func (consensus *Consensus) Start(
 	}()
 }
 
+// CheckTimeouts starts a view change if the consensus or the view change timed
+// out.  The main loop checks on every tick; a replay checks at the ticks of its
+// virtual clock.
+func (consensus *Consensus) CheckTimeouts() {
+	for k, v := range consensus.consensusTimeout {
+		if consensus.current.Mode() == Syncing ||
+			consensus.current.Mode() == Listening {
+			v.Stop()
+		}
+		if !v.CheckExpire() {
+			continue
+		}
+		if k != timeoutViewChange {
+			consensus.getLogger().Debug().Msg("[ConsensusMainLoop] Ops Consensus Timeout!!!")
+			consensus.startViewChange(consensus.viewID + 1)
+			break
+		} else {
+			consensus.getLogger().Debug().Msg("[ConsensusMainLoop] Ops View Change Timeout!!!")
+			viewID := consensus.current.ViewID()
+			consensus.startViewChange(viewID + 1)
+			break
+		}
+	}
+}
+
+// HandleMessageUpdate handles a consensus message as the main loop does, for
+// feeding recorded messages to a consensus which is not started
+func (consensus *Consensus) HandleMessageUpdate(payload []byte, sender libp2p_peer.ID) {
+	consensus.handleMessageUpdate(payload, sender, consensus.RebuildBlock(payload, sender))
+}
+
 // GenerateVrfAndProof generates new VRF/Proof from hash of previous block
 func (consensus *Consensus) GenerateVrfAndProof(newBlock *types.Block, vrfBlockNumbers []uint64) []uint64 {
 	key, err := consensus.GetConsensusLeaderPrivateKey()
@@ -527,11 +552,21 @@ func (consensus *Consensus) GenerateVrfAndProof(newBlock *types.Block, vrfBlockN
 
 // ValidateVrfAndProof validates a VRF/Proof from hash of previous block
 func (consensus *Consensus) ValidateVrfAndProof(headerObj *block.Header) bool {
+	if len(headerObj.Vrf()) != vrfAndProofSize {
+		consensus.getLogger().Warn().
+			Str("MsgBlockNum", headerObj.Number().String()).
+			Int("VrfSize", len(headerObj.Vrf())).
+			Msg("[OnAnnounce] VRF proof has a wrong size")
+		return false
+	}
 	vrfPk := vrf_bls.NewVRFVerifier(consensus.LeaderPubKey)
 	var blockHash [32]byte
 	previousHeader := consensus.ChainReader.GetHeaderByNumber(
 		headerObj.Number().Uint64() - 1,
 	)
+	if previousHeader == nil {
+		return false
+	}
 	previousHash := previousHeader.Hash()
 	copy(blockHash[:], previousHash[:])
 	vrfProof := [96]byte{}
@@ -564,16 +599,22 @@ func (consensus *Consensus) ValidateVrfAndProof(headerObj *block.Header) bool {
 	return true
 }
 
-// GenerateVdfAndProof generates new VDF/Proof from VRFs in the current epoch
-func (consensus *Consensus) GenerateVdfAndProof(newBlock *types.Block, vrfBlockNumbers []uint64) {
-	//derive VDF seed from VRFs generated in the current epoch
+// vdfSeed returns the seed of the VDF of the epoch, the XOR of the first VRFs
+// generated in the epoch
+func (consensus *Consensus) vdfSeed(vrfBlockNumbers []uint64) [32]byte {
 	seed := [32]byte{}
 	for i := 0; i < consensus.VdfSeedSize(); i++ {
 		previousVrf := consensus.ChainReader.GetVrfByNumber(vrfBlockNumbers[i])
-		for j := 0; j < len(seed); j++ {
+		for j := 0; j < len(seed) && j < len(previousVrf); j++ {
 			seed[j] = seed[j] ^ previousVrf[j]
 		}
 	}
+	return seed
+}
+
+// GenerateVdfAndProof generates new VDF/Proof from VRFs in the current epoch
+func (consensus *Consensus) GenerateVdfAndProof(newBlock *types.Block, vrfBlockNumbers []uint64) {
+	seed := consensus.vdfSeed(vrfBlockNumbers)
 
 	consensus.getLogger().Info().
 		Uint64("MsgBlockNum", newBlock.NumberU64()).
@@ -583,20 +624,17 @@ func (consensus *Consensus) GenerateVdfAndProof(newBlock *types.Block, vrfBlockN
 
 	// TODO ek – limit concurrency
 	go func() {
-		vdf := vdf_go.New(shard.Schedule.VdfDifficulty(), seed)
-		outputChannel := vdf.GetOutputChannel()
 		start := time.Now()
-		vdf.Execute()
+		output := vdf.Prove(seed, shard.Schedule.VdfDifficulty())
 		duration := time.Now().Sub(start)
 		consensus.getLogger().Info().
 			Dur("duration", duration).
 			Msg("[ConsensusMainLoop] VDF computation finished")
-		output := <-outputChannel
 
-		// The first 516 bytes are the VDF+proof and the last 32 bytes are XORed VRF as seed
-		rndBytes := [548]byte{}
-		copy(rndBytes[:516], output[:])
-		copy(rndBytes[516:], seed[:])
+		// The first bytes are the VDF+proof and the last 32 bytes are XORed VRF as seed
+		rndBytes := [vdfAndSeedSize]byte{}
+		copy(rndBytes[:vdFAndProofSize], output[:])
+		copy(rndBytes[vdFAndProofSize:], seed[:])
 		consensus.RndChannel <- rndBytes
 	}()
 }
@@ -616,18 +654,11 @@ func (consensus *Consensus) ValidateVdfAndProof(headerObj *block.Header) bool {
 		return false
 	}
 
-	seed := [32]byte{}
-	for i := 0; i < consensus.VdfSeedSize(); i++ {
-		previousVrf := consensus.ChainReader.GetVrfByNumber(vrfBlockNumbers[i])
-		for j := 0; j < len(seed); j++ {
-			seed[j] = seed[j] ^ previousVrf[j]
-		}
-	}
-
-	vdfObject := vdf_go.New(shard.Schedule.VdfDifficulty(), seed)
-	vdfOutput := [516]byte{}
+	seed := consensus.vdfSeed(vrfBlockNumbers)
+	vdfOutput := [vdf.Size]byte{}
 	copy(vdfOutput[:], headerObj.Vdf())
-	if vdfObject.Verify(vdfOutput) {
+	if len(headerObj.Vdf()) == vdf.Size &&
+		vdf.Verify(seed, shard.Schedule.VdfDifficulty(), vdfOutput) {
 		consensus.getLogger().Info().
 			Str("MsgBlockNum", headerObj.Number().String()).
 			Int("Num of VRF", consensus.VdfSeedSize()).
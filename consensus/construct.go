@@ -7,6 +7,7 @@ import (
 	"github.com/harmony-one/harmony/api/proto"
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	"github.com/harmony-one/harmony/consensus/quorum"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	"github.com/harmony-one/harmony/internal/utils"
 )
 
@@ -38,6 +39,17 @@ func (consensus *Consensus) populateMessageFields(
 func (consensus *Consensus) construct(
 	p msg_pb.MessageType, payloadForSign []byte, pubKey *bls.PublicKey, priKey *bls.SecretKey,
 ) (*NetworkMessage, error) {
+	return consensus.constructByKeys(
+		p, payloadForSign, []*bls.PublicKey{pubKey}, []*bls.SecretKey{priKey},
+	)
+}
+
+// constructByKeys constructs the message signed by the first of the keys.  A
+// prepare or commit by several keys carries their aggregated vote.
+func (consensus *Consensus) constructByKeys(
+	p msg_pb.MessageType, payloadForSign []byte, pubKeys []*bls.PublicKey, priKeys []*bls.SecretKey,
+) (*NetworkMessage, error) {
+	pubKey, priKey := pubKeys[0], priKeys[0]
 	message := &msg_pb.Message{
 		ServiceType: msg_pb.ServiceType_CONSENSUS,
 		Type:        p,
@@ -58,6 +70,9 @@ func (consensus *Consensus) construct(
 	switch p {
 	case msg_pb.MessageType_PREPARED:
 		consensusMsg.Block = consensus.block
+		if len(consensus.compactBlock) > 0 {
+			consensusMsg.Block = consensus.compactBlock
+		}
 		// Payload
 		buffer := bytes.Buffer{}
 		// 96 bytes aggregated signature
@@ -67,13 +82,9 @@ func (consensus *Consensus) construct(
 		buffer.Write(consensus.prepareBitmap.Bitmap)
 		consensusMsg.Payload = buffer.Bytes()
 	case msg_pb.MessageType_PREPARE:
-		if s := priKey.SignHash(consensusMsg.BlockHash); s != nil {
-			consensusMsg.Payload = s.Serialize()
-		}
+		consensusMsg.Payload = signVote(consensusMsg.BlockHash, pubKeys, priKeys)
 	case msg_pb.MessageType_COMMIT:
-		if s := priKey.SignHash(payloadForSign); s != nil {
-			consensusMsg.Payload = s.Serialize()
-		}
+		consensusMsg.Payload = signVote(payloadForSign, pubKeys, priKeys)
 	case msg_pb.MessageType_COMMITTED:
 		buffer := bytes.Buffer{}
 		// 96 bytes aggregated signature
@@ -110,3 +121,16 @@ func (consensus *Consensus) construct(
 		OptionalAggregateSignature: aggSig,
 	}, nil
 }
+
+// signVote returns the payload of the vote of the keys on the hash
+func signVote(hash []byte, pubKeys []*bls.PublicKey, priKeys []*bls.SecretKey) []byte {
+	sigs := make([]*bls.Sign, 0, len(priKeys))
+	for _, priKey := range priKeys {
+		s := priKey.SignHash(hash)
+		if s == nil {
+			return nil
+		}
+		sigs = append(sigs, s)
+	}
+	return votePayload(bls_cosi.AggregateSig(sigs), pubKeys)
+}
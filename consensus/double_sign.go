@@ -13,93 +13,112 @@ import (
 )
 
 // Check for double sign and if any, send it out to beacon chain for slashing.
+// Every key of the vote is checked; the evidence of a vote by several keys
+// covers all of them, so it is sent once.
 // Returns true when it is a double-sign or there is error, otherwise, false.
-func (consensus *Consensus) checkDoubleSign(recvMsg *FBFTMessage) bool {
+func (consensus *Consensus) checkDoubleSign(recvMsg *FBFTMessage, vote *vote) bool {
 	if consensus.couldThisBeADoubleSigner(recvMsg) {
-		if alreadyCastBallot := consensus.Decider.ReadBallot(
-			quorum.Commit, recvMsg.SenderPubkey,
-		); alreadyCastBallot != nil {
-			firstPubKey := bls.PublicKey{}
-			alreadyCastBallot.SignerPubKey.ToLibBLSPublicKey(&firstPubKey)
-			if recvMsg.SenderPubkey.IsEqual(&firstPubKey) {
-				for _, blk := range consensus.FBFTLog.GetBlocksByNumber(recvMsg.BlockNum) {
-					firstSignedBlock := blk.Header()
-					areHeightsEqual := firstSignedBlock.Number().Uint64() == recvMsg.BlockNum
-					areViewIDsEqual := firstSignedBlock.ViewID().Uint64() == recvMsg.ViewID
-					areHeadersEqual := firstSignedBlock.Hash() == recvMsg.BlockHash
-
-					// If signer already firstSignedBlock, and the block height is the same
-					// and the viewID is the same, then we need to verify the block
-					// hash, and if block hash is different, then that is a clear
-					// case of double signing
-					if areHeightsEqual && areViewIDsEqual && !areHeadersEqual {
-						var doubleSign bls.Sign
-						if err := doubleSign.Deserialize(recvMsg.Payload); err != nil {
-							consensus.getLogger().Err(err).Str("msg", recvMsg.String()).
-								Msg("could not deserialize potential double signer")
-							return true
-						}
+		for _, pubKey := range vote.pubKeys {
+			if consensus.checkDoubleSignByKey(recvMsg, vote, pubKey) {
+				break
+			}
+		}
+		return true
+	}
+	return false
+}
 
-						curHeader := consensus.ChainReader.CurrentHeader()
-						committee, err := consensus.ChainReader.ReadShardState(curHeader.Epoch())
-						if err != nil {
-							consensus.getLogger().Err(err).
-								Uint32("shard", consensus.ShardID).
-								Uint64("epoch", curHeader.Epoch().Uint64()).
-								Msg("could not read shard state")
-							return true
-						}
-						offender := *shard.FromLibBLSPublicKeyUnsafe(recvMsg.SenderPubkey)
-						subComm, err := committee.FindCommitteeByID(
-							consensus.ShardID,
-						)
-						if err != nil {
-							consensus.getLogger().Err(err).
-								Str("msg", recvMsg.String()).
-								Msg("could not find subcommittee for bls key")
-							return true
-						}
+// checkDoubleSignByKey sends out the double sign of the key in the vote, if
+// any, and returns whether it did.  The double signed ballot of a vote by
+// several keys carries all of them, its signature verifies with their
+// aggregated key, and the validator owning them is slashed for each.
+func (consensus *Consensus) checkDoubleSignByKey(
+	recvMsg *FBFTMessage, vote *vote, pubKey *bls.PublicKey,
+) bool {
+	if alreadyCastBallot := consensus.Decider.ReadBallot(
+		quorum.Commit, pubKey,
+	); alreadyCastBallot != nil {
+		firstPubKey := bls.PublicKey{}
+		alreadyCastBallot.SignerPubKey.ToLibBLSPublicKey(&firstPubKey)
+		if pubKey.IsEqual(&firstPubKey) {
+			for _, blk := range consensus.FBFTLog.GetBlocksByNumber(recvMsg.BlockNum) {
+				firstSignedBlock := blk.Header()
+				areHeightsEqual := firstSignedBlock.Number().Uint64() == recvMsg.BlockNum
+				areViewIDsEqual := firstSignedBlock.ViewID().Uint64() == recvMsg.ViewID
+				areHeadersEqual := firstSignedBlock.Hash() == recvMsg.BlockHash
 
-						addr, err := subComm.AddressForBLSKey(offender)
+				// If signer already firstSignedBlock, and the block height is the same
+				// and the viewID is the same, then we need to verify the block
+				// hash, and if block hash is different, then that is a clear
+				// case of double signing
+				if areHeightsEqual && areViewIDsEqual && !areHeadersEqual {
+					doubleSign := vote.sig
 
-						if err != nil {
-							consensus.getLogger().Err(err).Str("msg", recvMsg.String()).
-								Msg("could not find address for bls key")
-							return true
+					curHeader := consensus.ChainReader.CurrentHeader()
+					committee, err := consensus.ChainReader.ReadShardState(curHeader.Epoch())
+					if err != nil {
+						consensus.getLogger().Err(err).
+							Uint32("shard", consensus.ShardID).
+							Uint64("epoch", curHeader.Epoch().Uint64()).
+							Msg("could not read shard state")
+						return false
+					}
+					offender := *shard.FromLibBLSPublicKeyUnsafe(pubKey)
+					signers := []shard.BlsPublicKey{}
+					if len(vote.pubKeys) > 1 {
+						for _, key := range vote.pubKeys {
+							signers = append(signers, *shard.FromLibBLSPublicKeyUnsafe(key))
 						}
+					}
+					subComm, err := committee.FindCommitteeByID(
+						consensus.ShardID,
+					)
+					if err != nil {
+						consensus.getLogger().Err(err).
+							Str("msg", recvMsg.String()).
+							Msg("could not find subcommittee for bls key")
+						return false
+					}
 
-						now := big.NewInt(time.Now().UnixNano())
+					addr, err := subComm.AddressForBLSKey(offender)
 
-						go func(reporter common.Address) {
-							evid := slash.Evidence{
-								ConflictingBallots: slash.ConflictingBallots{
-									AlreadyCastBallot: *alreadyCastBallot,
-									DoubleSignedBallot: votepower.Ballot{
-										SignerPubKey:    offender,
-										BlockHeaderHash: recvMsg.BlockHash,
-										Signature:       common.Hex2Bytes(doubleSign.SerializeToHexStr()),
-										Height:          recvMsg.BlockNum,
-										ViewID:          recvMsg.ViewID,
-									}},
-								Moment: slash.Moment{
-									Epoch:        curHeader.Epoch(),
-									ShardID:      consensus.ShardID,
-									TimeUnixNano: now,
-								},
-							}
-							proof := slash.Record{
-								Evidence: evid,
-								Reporter: reporter,
-								Offender: *addr,
-							}
-							consensus.SlashChan <- proof
-						}(consensus.SelfAddresses[consensus.LeaderPubKey.SerializeToHexStr()])
-						return true
+					if err != nil {
+						consensus.getLogger().Err(err).Str("msg", recvMsg.String()).
+							Msg("could not find address for bls key")
+						return false
 					}
+
+					now := big.NewInt(time.Now().UnixNano())
+
+					go func(reporter common.Address) {
+						evid := slash.Evidence{
+							ConflictingBallots: slash.ConflictingBallots{
+								AlreadyCastBallot: *alreadyCastBallot,
+								DoubleSignedBallot: votepower.Ballot{
+									SignerPubKey:     offender,
+									BlockHeaderHash:  recvMsg.BlockHash,
+									Signature:        common.Hex2Bytes(doubleSign.SerializeToHexStr()),
+									Height:           recvMsg.BlockNum,
+									ViewID:           recvMsg.ViewID,
+									AggregateSigners: signers,
+								}},
+							Moment: slash.Moment{
+								Epoch:        curHeader.Epoch(),
+								ShardID:      consensus.ShardID,
+								TimeUnixNano: now,
+							},
+						}
+						proof := slash.Record{
+							Evidence: evid,
+							Reporter: reporter,
+							Offender: *addr,
+						}
+						consensus.SlashChan <- proof
+					}(consensus.SelfAddresses[consensus.LeaderPubKey.SerializeToHexStr()])
+					return true
 				}
 			}
 		}
-		return true
 	}
 	return false
 }
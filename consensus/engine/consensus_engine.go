@@ -8,6 +8,7 @@ import (
 	"github.com/harmony-one/harmony/consensus/reward"
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/crypto/bls"
 	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/shard/committee"
@@ -92,6 +93,14 @@ type Engine interface {
 		commitSig, commitBitmap []byte, reCalculate bool,
 	) error
 
+	// HeaderSignature runs the checks of VerifyHeaderWithSignature but the
+	// signature verification itself, and returns the signature so that the
+	// signatures of many headers can be verified in a batch.
+	HeaderSignature(
+		chain ChainReader, header *block.Header,
+		commitSig, commitBitmap []byte, reCalculate bool,
+	) (*bls.SignedHash, error)
+
 	// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers
 	// concurrently. The method returns a quit channel to abort the operations and
 	// a results channel to retrieve the async verifications (the order is that of
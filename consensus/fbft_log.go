@@ -19,6 +19,8 @@ type FBFTLog struct {
 	messages   mapset.Set // store messages received in FBFT
 	maxLogSize uint32
 	mutex      sync.Mutex
+	// store persists the log across restarts, nil to keep it in memory only
+	store *FBFTStore
 }
 
 // FBFTMessage is the record of pbft messages received by a node during FBFT process
@@ -71,9 +73,37 @@ func (log *FBFTLog) Messages() mapset.Set {
 	return log.messages
 }
 
+// SetStore restores the log persisted in the store, and persists the log in
+// the store from now on
+func (log *FBFTLog) SetStore(store *FBFTStore) error {
+	blocks, err := store.ReadBlocks()
+	if err != nil {
+		return err
+	}
+	msgs, err := store.ReadMessages()
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		log.blocks.Add(block)
+	}
+	for _, msg := range msgs {
+		log.messages.Add(msg)
+	}
+	log.store = store
+	return nil
+}
+
 // AddBlock add a new block into the log
 func (log *FBFTLog) AddBlock(block *types.Block) {
 	log.blocks.Add(block)
+	if log.store != nil {
+		if err := log.store.WriteBlock(block); err != nil {
+			utils.Logger().Warn().Err(err).
+				Uint64("blockNum", block.NumberU64()).
+				Msg("[FBFTLog] cannot persist block")
+		}
+	}
 }
 
 // GetBlockByHash returns the block matches the given block hash
@@ -111,6 +141,11 @@ func (log *FBFTLog) DeleteBlocksLessThan(number uint64) {
 		}
 	}
 	log.blocks = log.blocks.Difference(found)
+	if log.store != nil {
+		if err := log.store.DeleteBlocksLessThan(number); err != nil {
+			utils.Logger().Warn().Err(err).Msg("[FBFTLog] cannot prune persisted blocks")
+		}
+	}
 }
 
 // DeleteBlockByNumber deletes block of specific number
@@ -123,6 +158,11 @@ func (log *FBFTLog) DeleteBlockByNumber(number uint64) {
 		}
 	}
 	log.blocks = log.blocks.Difference(found)
+	if log.store != nil {
+		if err := log.store.DeleteBlockByNumber(number); err != nil {
+			utils.Logger().Warn().Err(err).Msg("[FBFTLog] cannot delete persisted blocks")
+		}
+	}
 }
 
 // DeleteMessagesLessThan deletes messages less than given block number
@@ -135,11 +175,24 @@ func (log *FBFTLog) DeleteMessagesLessThan(number uint64) {
 		}
 	}
 	log.messages = log.messages.Difference(found)
+	if log.store != nil {
+		if err := log.store.DeleteMessagesLessThan(number); err != nil {
+			utils.Logger().Warn().Err(err).Msg("[FBFTLog] cannot prune persisted messages")
+		}
+	}
 }
 
 // AddMessage adds a pbft message into the log
 func (log *FBFTLog) AddMessage(msg *FBFTMessage) {
 	log.messages.Add(msg)
+	if log.store != nil {
+		if err := log.store.WriteMessage(msg); err != nil {
+			utils.Logger().Warn().Err(err).
+				Str("msg", msg.MessageType.String()).
+				Uint64("blockNum", msg.BlockNum).
+				Msg("[FBFTLog] cannot persist message")
+		}
+	}
 }
 
 // GetMessagesByTypeSeqViewHash returns pbft messages with matching type, blockNum, viewID and blockHash
@@ -0,0 +1,344 @@
+package consensus
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/crypto/hash"
+	"github.com/pkg/errors"
+)
+
+// Key prefixes of the FBFT store.  The block number follows the prefix in big
+// endian, so the entries of a block number are contiguous and sorted.
+var (
+	fbftBlockPrefix   = []byte("b") // b + num + hash -> rlp(block)
+	fbftMessagePrefix = []byte("m") // m + num + hash(message) -> rlp(storedFBFTMessage)
+	fbftSignedPrefix  = []byte("s") // s + num + phase -> rlp(SignedRecord)
+	fbftViewChangeKey = []byte("v") // v -> rlp(ViewChangeRecord)
+)
+
+const (
+	fbftBlockNumLength = 8
+	// the store holds a few rounds only, it needs little cache and few files
+	fbftStoreCache       = 16
+	fbftStoreFileHandles = 16
+)
+
+// SignedRecord is the last block hash a node signed in a phase of a block
+// number, and the view ID it signed in
+type SignedRecord struct {
+	ViewID    uint64
+	BlockHash common.Hash
+}
+
+// ViewChangeRecord is the view change state of a node for a block number
+type ViewChangeRecord struct {
+	BlockNum uint64
+	// ViewID is the view of the last block agreed on
+	ViewID uint64
+	// ViewChangingID is the view the node changes to, if view changing
+	ViewChangingID uint64
+	Mode           Mode
+	LeaderPubKey   []byte
+	M1Payload      []byte
+}
+
+// storedFBFTMessage is the serialized form of a FBFTMessage.  The bitmaps of
+// NEWVIEW messages are checked on receipt only, and are not stored.
+type storedFBFTMessage struct {
+	MessageType   uint32
+	ViewID        uint64
+	BlockNum      uint64
+	BlockHash     common.Hash
+	Block         []byte
+	SenderPubkey  []byte
+	LeaderPubkey  []byte
+	Payload       []byte
+	ViewchangeSig []byte
+	ViewidSig     []byte
+	M2AggSig      []byte
+	M3AggSig      []byte
+}
+
+// FBFTStore persists the FBFT log, the last block hash signed in each phase
+// of a block and the view change state of a node, so a node restarted
+// mid-round rejoins consensus where it stopped instead of blind.
+type FBFTStore struct {
+	db *ethdb.LDBDatabase
+}
+
+// NewFBFTStore opens the FBFT store in the given directory
+func NewFBFTStore(dir string) (*FBFTStore, error) {
+	db, err := ethdb.NewLDBDatabase(dir, fbftStoreCache, fbftStoreFileHandles)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open FBFT store %s", dir)
+	}
+	return &FBFTStore{db: db}, nil
+}
+
+// Close closes the store
+func (s *FBFTStore) Close() {
+	s.db.Close()
+}
+
+// WriteBlock stores a block of the FBFT log
+func (s *FBFTStore) WriteBlock(block *types.Block) error {
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return err
+	}
+	blockHash := block.Hash()
+	return s.db.Put(fbftKey(fbftBlockPrefix, block.NumberU64(), blockHash[:]), data)
+}
+
+// ReadBlocks returns the blocks of the FBFT log
+func (s *FBFTStore) ReadBlocks() ([]*types.Block, error) {
+	blocks := []*types.Block{}
+	it := s.db.NewIteratorWithPrefix(fbftBlockPrefix)
+	defer it.Release()
+	for it.Next() {
+		block := &types.Block{}
+		if err := rlp.DecodeBytes(it.Value(), block); err != nil {
+			return nil, errors.Wrapf(err, "cannot decode FBFT block %x", it.Key())
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, it.Error()
+}
+
+// DeleteBlocksLessThan deletes the blocks less than the given block number
+func (s *FBFTStore) DeleteBlocksLessThan(number uint64) error {
+	return s.deleteLessThan(fbftBlockPrefix, number)
+}
+
+// DeleteBlockByNumber deletes the blocks of the given block number
+func (s *FBFTStore) DeleteBlockByNumber(number uint64) error {
+	batch := s.db.NewBatch()
+	it := s.db.NewIteratorWithPrefix(fbftKey(fbftBlockPrefix, number, nil))
+	defer it.Release()
+	for it.Next() {
+		if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// WriteMessage stores a message of the FBFT log
+func (s *FBFTStore) WriteMessage(msg *FBFTMessage) error {
+	data, err := rlp.EncodeToBytes(newStoredFBFTMessage(msg))
+	if err != nil {
+		return err
+	}
+	return s.db.Put(fbftKey(fbftMessagePrefix, msg.BlockNum, hash.Keccak256(data)), data)
+}
+
+// ReadMessages returns the messages of the FBFT log
+func (s *FBFTStore) ReadMessages() ([]*FBFTMessage, error) {
+	msgs := []*FBFTMessage{}
+	it := s.db.NewIteratorWithPrefix(fbftMessagePrefix)
+	defer it.Release()
+	for it.Next() {
+		stored := &storedFBFTMessage{}
+		if err := rlp.DecodeBytes(it.Value(), stored); err != nil {
+			return nil, errors.Wrapf(err, "cannot decode FBFT message %x", it.Key())
+		}
+		msg, err := stored.message()
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode FBFT message %x", it.Key())
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, it.Error()
+}
+
+// DeleteMessagesLessThan deletes the messages less than the given block
+// number, and the signed records of those block numbers
+func (s *FBFTStore) DeleteMessagesLessThan(number uint64) error {
+	if err := s.deleteLessThan(fbftMessagePrefix, number); err != nil {
+		return err
+	}
+	return s.deleteLessThan(fbftSignedPrefix, number)
+}
+
+// ReadSigned returns the record of the last block hash signed in the phase of
+// the block number, or nil if none was signed
+func (s *FBFTStore) ReadSigned(phase FBFTPhase, blockNum uint64) (*SignedRecord, error) {
+	key := fbftKey(fbftSignedPrefix, blockNum, []byte{byte(phase)})
+	if has, err := s.db.Has(key); err != nil || !has {
+		return nil, err
+	}
+	data, err := s.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	record := &SignedRecord{}
+	if err := rlp.DecodeBytes(data, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// CheckAndWriteSigned returns whether the node may sign the block hash in the
+// phase of the block number and view ID, and if so records it before the
+// signature is sent.  A node must not sign another block hash in a view it
+// already signed in, nor sign in a view older than its last signature.
+func (s *FBFTStore) CheckAndWriteSigned(
+	phase FBFTPhase, blockNum, viewID uint64, blockHash common.Hash,
+) (bool, error) {
+	signed, err := s.ReadSigned(phase, blockNum)
+	if err != nil {
+		return false, err
+	}
+	if signed != nil &&
+		(signed.ViewID > viewID || (signed.ViewID == viewID && signed.BlockHash != blockHash)) {
+		return false, nil
+	}
+	data, err := rlp.EncodeToBytes(&SignedRecord{ViewID: viewID, BlockHash: blockHash})
+	if err != nil {
+		return false, err
+	}
+	if err := s.db.Put(fbftKey(fbftSignedPrefix, blockNum, []byte{byte(phase)}), data); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WriteViewChangeState stores the view change state
+func (s *FBFTStore) WriteViewChangeState(record *ViewChangeRecord) error {
+	data, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		return err
+	}
+	return s.db.Put(fbftViewChangeKey, data)
+}
+
+// ReadViewChangeState returns the view change state, or nil if none was stored
+func (s *FBFTStore) ReadViewChangeState() (*ViewChangeRecord, error) {
+	if has, err := s.db.Has(fbftViewChangeKey); err != nil || !has {
+		return nil, err
+	}
+	data, err := s.db.Get(fbftViewChangeKey)
+	if err != nil {
+		return nil, err
+	}
+	record := &ViewChangeRecord{}
+	if err := rlp.DecodeBytes(data, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// deleteLessThan deletes the entries of the prefix less than the block number
+func (s *FBFTStore) deleteLessThan(prefix []byte, number uint64) error {
+	batch := s.db.NewBatch()
+	it := s.db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		if len(key) < len(prefix)+fbftBlockNumLength ||
+			binary.BigEndian.Uint64(key[len(prefix):]) >= number {
+			break
+		}
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func fbftKey(prefix []byte, number uint64, suffix []byte) []byte {
+	key := make([]byte, len(prefix)+fbftBlockNumLength, len(prefix)+fbftBlockNumLength+len(suffix))
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], number)
+	return append(key, suffix...)
+}
+
+func newStoredFBFTMessage(msg *FBFTMessage) *storedFBFTMessage {
+	stored := &storedFBFTMessage{
+		MessageType: uint32(msg.MessageType),
+		ViewID:      msg.ViewID,
+		BlockNum:    msg.BlockNum,
+		BlockHash:   msg.BlockHash,
+		Block:       msg.Block,
+		Payload:     msg.Payload,
+	}
+	if msg.SenderPubkey != nil {
+		stored.SenderPubkey = msg.SenderPubkey.Serialize()
+	}
+	if msg.LeaderPubkey != nil {
+		stored.LeaderPubkey = msg.LeaderPubkey.Serialize()
+	}
+	if msg.ViewchangeSig != nil {
+		stored.ViewchangeSig = msg.ViewchangeSig.Serialize()
+	}
+	if msg.ViewidSig != nil {
+		stored.ViewidSig = msg.ViewidSig.Serialize()
+	}
+	if msg.M2AggSig != nil {
+		stored.M2AggSig = msg.M2AggSig.Serialize()
+	}
+	if msg.M3AggSig != nil {
+		stored.M3AggSig = msg.M3AggSig.Serialize()
+	}
+	return stored
+}
+
+func (stored *storedFBFTMessage) message() (*FBFTMessage, error) {
+	msg := &FBFTMessage{
+		MessageType: msg_pb.MessageType(stored.MessageType),
+		ViewID:      stored.ViewID,
+		BlockNum:    stored.BlockNum,
+		BlockHash:   stored.BlockHash,
+		Block:       stored.Block,
+		Payload:     stored.Payload,
+	}
+	var err error
+	if len(stored.SenderPubkey) > 0 {
+		if msg.SenderPubkey, err = bls_cosi.BytesToBlsPublicKey(stored.SenderPubkey); err != nil {
+			return nil, err
+		}
+	}
+	if len(stored.LeaderPubkey) > 0 {
+		if msg.LeaderPubkey, err = bls_cosi.BytesToBlsPublicKey(stored.LeaderPubkey); err != nil {
+			return nil, err
+		}
+	}
+	if msg.ViewchangeSig, err = deserializeSign(stored.ViewchangeSig); err != nil {
+		return nil, err
+	}
+	if msg.ViewidSig, err = deserializeSign(stored.ViewidSig); err != nil {
+		return nil, err
+	}
+	if msg.M2AggSig, err = deserializeSign(stored.M2AggSig); err != nil {
+		return nil, err
+	}
+	if msg.M3AggSig, err = deserializeSign(stored.M3AggSig); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// deserializeSign returns the deserialized signature, or nil if empty
+func deserializeSign(data []byte) (*bls.Sign, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	sign := &bls.Sign{}
+	if err := sign.Deserialize(data); err != nil {
+		return nil, err
+	}
+	return sign, nil
+}
@@ -0,0 +1,137 @@
+package consensus
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+func newTestFBFTStore(t *testing.T) (*FBFTStore, func()) {
+	dir, err := ioutil.TempDir("", "fbft_store")
+	if err != nil {
+		t.Fatalf("TempDir() error = %v", err)
+	}
+	store, err := NewFBFTStore(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("NewFBFTStore() error = %v", err)
+	}
+	return store, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestFBFTStore_RestoreAndPrune(t *testing.T) {
+	store, cleanup := newTestFBFTStore(t)
+	defer cleanup()
+
+	log := NewFBFTLog()
+	if err := log.SetStore(store); err != nil {
+		t.Fatalf("SetStore() error = %v", err)
+	}
+	for num := uint64(1); num <= 3; num++ {
+		header := blockfactory.NewTestHeader().With().Number(new(big.Int).SetUint64(num)).Header()
+		log.AddBlock(types.NewBlockWithHeader(header))
+		log.AddMessage(&FBFTMessage{
+			MessageType: msg_pb.MessageType_PREPARED,
+			BlockNum:    num,
+			ViewID:      num + 10,
+			BlockHash:   common.Hash{byte(num)},
+			Payload:     []byte{byte(num)},
+		})
+	}
+	log.DeleteBlocksLessThan(2)
+	log.DeleteMessagesLessThan(3)
+
+	restored := NewFBFTLog()
+	if err := restored.SetStore(store); err != nil {
+		t.Fatalf("SetStore() error = %v", err)
+	}
+	if got := restored.Blocks().Cardinality(); got != 2 {
+		t.Errorf("restored %d blocks, want 2", got)
+	}
+	if len(restored.GetBlocksByNumber(1)) != 0 {
+		t.Error("pruned block restored")
+	}
+	msgs := restored.GetMessagesByTypeSeqViewHash(
+		msg_pb.MessageType_PREPARED, 3, 13, common.Hash{3},
+	)
+	if len(msgs) != 1 || restored.Messages().Cardinality() != 1 {
+		t.Fatalf("restored %d messages, want the one of block 3", restored.Messages().Cardinality())
+	}
+	if msgs[0].Payload[0] != 3 || msgs[0].SenderPubkey != nil {
+		t.Errorf("restored message %+v does not match", msgs[0])
+	}
+}
+
+func TestFBFTStore_CheckAndWriteSigned(t *testing.T) {
+	store, cleanup := newTestFBFTStore(t)
+	defer cleanup()
+
+	tests := []struct {
+		phase  FBFTPhase
+		viewID uint64
+		hash   common.Hash
+		want   bool
+	}{
+		{FBFTPrepare, 5, common.Hash{1}, true},
+		{FBFTPrepare, 5, common.Hash{1}, true},  // the same signature again
+		{FBFTPrepare, 5, common.Hash{2}, false}, // another block in the view
+		{FBFTPrepare, 4, common.Hash{1}, false}, // an older view
+		{FBFTCommit, 5, common.Hash{2}, true},   // phases are apart
+		{FBFTPrepare, 6, common.Hash{2}, true},  // another block in a newer view
+		{FBFTPrepare, 5, common.Hash{1}, false},
+	}
+	for i, test := range tests {
+		got, err := store.CheckAndWriteSigned(test.phase, 7, test.viewID, test.hash)
+		if err != nil {
+			t.Fatalf("test %d: CheckAndWriteSigned() error = %v", i, err)
+		}
+		if got != test.want {
+			t.Errorf("test %d: CheckAndWriteSigned() = %v, want %v", i, got, test.want)
+		}
+	}
+	if err := store.DeleteMessagesLessThan(8); err != nil {
+		t.Fatalf("DeleteMessagesLessThan() error = %v", err)
+	}
+	if signed, err := store.ReadSigned(FBFTPrepare, 7); err != nil || signed != nil {
+		t.Errorf("ReadSigned() = %v, %v after pruning, want nil", signed, err)
+	}
+}
+
+func TestFBFTStore_ViewChangeState(t *testing.T) {
+	store, cleanup := newTestFBFTStore(t)
+	defer cleanup()
+
+	if record, err := store.ReadViewChangeState(); err != nil || record != nil {
+		t.Fatalf("ReadViewChangeState() = %v, %v, want nil", record, err)
+	}
+	want := &ViewChangeRecord{
+		BlockNum:       9,
+		ViewID:         20,
+		ViewChangingID: 22,
+		Mode:           ViewChanging,
+		LeaderPubKey:   []byte{1, 2, 3},
+		M1Payload:      []byte{4, 5},
+	}
+	if err := store.WriteViewChangeState(want); err != nil {
+		t.Fatalf("WriteViewChangeState() error = %v", err)
+	}
+	got, err := store.ReadViewChangeState()
+	if err != nil {
+		t.Fatalf("ReadViewChangeState() error = %v", err)
+	}
+	if got.BlockNum != want.BlockNum || got.ViewID != want.ViewID ||
+		got.ViewChangingID != want.ViewChangingID || got.Mode != want.Mode ||
+		string(got.LeaderPubKey) != string(want.LeaderPubKey) ||
+		string(got.M1Payload) != string(want.M1Payload) {
+		t.Errorf("ReadViewChangeState() = %+v, want %+v", got, want)
+	}
+}
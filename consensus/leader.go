@@ -6,12 +6,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
-	"github.com/harmony-one/bls/ffi/go/bls"
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/p2p/host"
+	"github.com/rs/zerolog"
 )
 
 func (consensus *Consensus) announce(block *types.Block) {
@@ -29,7 +30,21 @@ func (consensus *Consensus) announce(block *types.Block) {
 		return
 	}
 
+	// validators rebuild the block from their pools once the epoch allows
+	// it, the full block is only sent if it cannot be compacted
+	var encodedCompactBlock []byte
+	if consensus.ChainReader.Config().IsCompactBlock(block.Epoch()) {
+		encodedCompactBlock, err = types.EncodeCompactBlock(
+			types.NewCompactBlock(block, consensus.TxPrefill),
+		)
+		if err != nil {
+			consensus.getLogger().Debug().Err(err).Msg("[Announce] Failed encoding compact block")
+			encodedCompactBlock = nil
+		}
+	}
+
 	consensus.block = encodedBlock
+	consensus.compactBlock = encodedCompactBlock
 	consensus.blockHeader = encodedBlockHeader
 
 	key, err := consensus.GetConsensusLeaderPrivateKey()
@@ -37,6 +52,9 @@ func (consensus *Consensus) announce(block *types.Block) {
 		consensus.getLogger().Warn().Err(err).Msg("[Announce] Node not a leader")
 		return
 	}
+	if !consensus.canSign(FBFTAnnounce, consensus.blockNum, consensus.viewID, block.Hash()) {
+		return
+	}
 	networkMessage, err := consensus.construct(msg_pb.MessageType_ANNOUNCE, nil, key.GetPublicKey(), key)
 	if err != nil {
 		consensus.getLogger().Err(err).
@@ -91,6 +109,8 @@ func (consensus *Consensus) announce(block *types.Block) {
 			Msg("[Announce] Sent Announce Message!!")
 	}
 
+	consensus.timings.announce(block.NumberU64(), consensus.viewID, blockHash, true)
+
 	consensus.getLogger().Debug().
 		Str("From", consensus.phase.String()).
 		Str("To", FBFTPrepare.String()).
@@ -125,58 +145,98 @@ func (consensus *Consensus) onPrepare(msg *msg_pb.Message) {
 		//return
 	}
 
-	validatorPubKey := recvMsg.SenderPubkey
-	prepareSig := recvMsg.Payload
-	prepareBitmap := consensus.prepareBitmap
+	// Check BLS signature for the multi-sig
+	vote, err := consensus.readVote(recvMsg)
+	if err != nil {
+		consensus.getLogger().Error().Err(err).
+			Msg("[OnPrepare] Failed to read the vote")
+		return
+	}
 
 	consensus.mutex.Lock()
-	defer consensus.mutex.Unlock()
 	logger := consensus.getLogger().With().
-		Str("validatorPubKey", validatorPubKey.SerializeToHexStr()).Logger()
+		Str("validatorPubKey", recvMsg.SenderPubkey.SerializeToHexStr()).
+		Int("voteKeys", len(vote.pubKeys)).Logger()
+	if !consensus.isNewPrepare(vote, &logger) {
+		consensus.mutex.Unlock()
+		return
+	}
+	blockHash := consensus.blockHash
+	consensus.mutex.Unlock()
 
+	// the signatures of a burst of prepares are verified in batches
+	consensus.voteVerifier.verify(&pendingVote{
+		signed: &bls_cosi.SignedHash{Sig: vote.sig, PubKey: vote.aggregatePubKey(), Hash: blockHash[:]},
+		done: func(valid bool) {
+			if !valid {
+				consensus.getLogger().Error().Msg("[OnPrepare] Received invalid BLS signature")
+				return
+			}
+			consensus.onVerifiedPrepare(recvMsg, vote)
+		},
+	})
+}
+
+// isNewPrepare returns whether the prepare of the validator is still needed.
+// A vote is counted whole, so none of its keys may have voted already.
+func (consensus *Consensus) isNewPrepare(vote *vote, logger *zerolog.Logger) bool {
 	// proceed only when the message is not received before
-	signed := consensus.Decider.ReadBallot(quorum.Prepare, validatorPubKey)
-	if signed != nil {
-		logger.Debug().
-			Msg("[OnPrepare] Already Received prepare message from the validator")
-		return
+	for _, pubKey := range vote.pubKeys {
+		if consensus.Decider.ReadBallot(quorum.Prepare, pubKey) != nil {
+			logger.Debug().
+				Str("votePubKey", pubKey.SerializeToHexStr()).
+				Msg("[OnPrepare] Already Received prepare message from the validator")
+			return false
+		}
 	}
 
 	if consensus.Decider.IsQuorumAchieved(quorum.Prepare) {
 		// already have enough signatures
 		logger.Debug().Msg("[OnPrepare] Received Additional Prepare Message")
-		return
+		return false
 	}
+	return true
+}
 
-	// Check BLS signature for the multi-sig
-	var sign bls.Sign
-	err = sign.Deserialize(prepareSig)
-	if err != nil {
-		consensus.getLogger().Error().Err(err).
-			Msg("[OnPrepare] Failed to deserialize bls signature")
+// onVerifiedPrepare counts a prepare whose signature is verified
+func (consensus *Consensus) onVerifiedPrepare(recvMsg *FBFTMessage, vote *vote) {
+	consensus.mutex.Lock()
+	defer consensus.mutex.Unlock()
+	logger := consensus.getLogger().With().
+		Str("validatorPubKey", recvMsg.SenderPubkey.SerializeToHexStr()).
+		Int("voteKeys", len(vote.pubKeys)).Logger()
+
+	// the round may have moved on, or the prepare come twice, during the verification
+	if recvMsg.ViewID != consensus.viewID || recvMsg.BlockNum != consensus.blockNum {
+		logger.Debug().
+			Uint64("MsgViewID", recvMsg.ViewID).
+			Uint64("MsgBlockNum", recvMsg.BlockNum).
+			Msg("[OnPrepare] Round ended during the signature verification")
 		return
 	}
-	if !sign.VerifyHash(recvMsg.SenderPubkey, consensus.blockHash[:]) {
-		consensus.getLogger().Error().Msg("[OnPrepare] Received invalid BLS signature")
+	if !consensus.isNewPrepare(vote, &logger) {
 		return
 	}
 
+	prepareBitmap := consensus.prepareBitmap
 	logger = logger.With().
 		Int64("NumReceivedSoFar", consensus.Decider.SignersCount(quorum.Prepare)).
 		Int64("PublicKeys", consensus.Decider.ParticipantsCount()).Logger()
 	logger.Info().Msg("[OnPrepare] Received New Prepare Signature")
-	if _, err := consensus.Decider.SubmitVote(
-		quorum.Prepare, validatorPubKey,
-		&sign, recvMsg.BlockHash,
+	if _, err := consensus.Decider.SubmitVotes(
+		quorum.Prepare, vote.pubKeys,
+		vote.sig, recvMsg.BlockHash,
 		recvMsg.BlockNum, recvMsg.ViewID,
 	); err != nil {
 		consensus.getLogger().Warn().Err(err).Msg("submit vote prepare failed")
 		return
 	}
 	// Set the bitmap indicating that this validator signed.
-	if err := prepareBitmap.SetKey(recvMsg.SenderPubkey, true); err != nil {
-		consensus.getLogger().Warn().Err(err).Msg("[OnPrepare] prepareBitmap.SetKey failed")
-		return
+	for _, pubKey := range vote.pubKeys {
+		if err := prepareBitmap.SetKey(pubKey, true); err != nil {
+			consensus.getLogger().Warn().Err(err).Msg("[OnPrepare] prepareBitmap.SetKey failed")
+			return
+		}
 	}
 
 	if consensus.Decider.IsQuorumAchieved(quorum.Prepare) {
@@ -184,6 +244,10 @@ func (consensus *Consensus) onPrepare(msg *msg_pb.Message) {
 		if err := consensus.didReachPrepareQuorum(); err != nil {
 			return
 		}
+		consensus.timings.prepared(
+			recvMsg.BlockNum, consensus.Decider.SignersCount(quorum.Prepare),
+			consensus.Decider.ComputeTotalPowerByMask(prepareBitmap),
+		)
 		consensus.switchPhase(FBFTCommit, true)
 	}
 }
@@ -200,25 +264,20 @@ func (consensus *Consensus) onCommit(msg *msg_pb.Message) {
 		return
 	}
 
-	consensus.mutex.Lock()
-	defer consensus.mutex.Unlock()
-
-	// Check for potential double signing
-	if consensus.checkDoubleSign(recvMsg) {
+	logger := consensus.getLogger().With().
+		Str("validatorPubKey", recvMsg.SenderPubkey.SerializeToHexStr()).Logger()
+	vote, err := consensus.readVote(recvMsg)
+	if err != nil {
+		logger.Debug().Err(err).Msg("[OnCommit] Failed to read the vote")
 		return
 	}
+	logger = logger.With().Int("voteKeys", len(vote.pubKeys)).Logger()
 
-	validatorPubKey, commitSig, commitBitmap :=
-		recvMsg.SenderPubkey, recvMsg.Payload, consensus.commitBitmap
-	logger := consensus.getLogger().With().
-		Str("validatorPubKey", validatorPubKey.SerializeToHexStr()).Logger()
-
-	// has to be called before verifying signature
-	quorumWasMet := consensus.Decider.IsQuorumAchieved(quorum.Commit)
-	// Verify the signature on commitPayload is correct
-	var sign bls.Sign
-	if err := sign.Deserialize(commitSig); err != nil {
-		logger.Debug().Msg("[OnCommit] Failed to deserialize bls signature")
+	consensus.mutex.Lock()
+	// Check for potential double signing
+	isDoubleSign := consensus.checkDoubleSign(recvMsg, vote)
+	consensus.mutex.Unlock()
+	if isDoubleSign {
 		return
 	}
 
@@ -231,33 +290,71 @@ func (consensus *Consensus) onCommit(msg *msg_pb.Message) {
 		Uint64("MsgBlockNum", recvMsg.BlockNum).
 		Logger()
 
-	if !sign.VerifyHash(recvMsg.SenderPubkey, commitPayload) {
-		logger.Error().Msg("[OnCommit] Cannot verify commit message")
+	// the signatures of a burst of commits are verified in batches
+	consensus.voteVerifier.verify(&pendingVote{
+		signed: &bls_cosi.SignedHash{Sig: vote.sig, PubKey: vote.aggregatePubKey(), Hash: commitPayload},
+		done: func(valid bool) {
+			if !valid {
+				logger.Error().Msg("[OnCommit] Cannot verify commit message")
+				return
+			}
+			consensus.onVerifiedCommit(recvMsg, vote, logger)
+		},
+	})
+}
+
+// onVerifiedCommit counts a commit whose signature is verified
+func (consensus *Consensus) onVerifiedCommit(recvMsg *FBFTMessage, vote *vote, logger zerolog.Logger) {
+	consensus.mutex.Lock()
+	defer consensus.mutex.Unlock()
+
+	// the round may have moved on, or the commit come twice, during the verification
+	if recvMsg.ViewID != consensus.viewID || recvMsg.BlockNum != consensus.blockNum {
+		logger.Debug().Msg("[OnCommit] Round ended during the signature verification")
 		return
 	}
+	for _, pubKey := range vote.pubKeys {
+		if consensus.Decider.ReadBallot(quorum.Commit, pubKey) != nil {
+			logger.Debug().
+				Str("votePubKey", pubKey.SerializeToHexStr()).
+				Msg("[OnCommit] Already Received commit message from the validator")
+			return
+		}
+	}
 
+	commitBitmap := consensus.commitBitmap
+	quorumWasMet := consensus.Decider.IsQuorumAchieved(quorum.Commit)
 	logger = logger.With().
 		Int64("numReceivedSoFar", consensus.Decider.SignersCount(quorum.Commit)).
 		Logger()
 	logger.Info().Msg("[OnCommit] Received new commit message")
 
-	if _, err := consensus.Decider.SubmitVote(
-		quorum.Commit, validatorPubKey,
-		&sign, recvMsg.BlockHash,
+	if _, err := consensus.Decider.SubmitVotes(
+		quorum.Commit, vote.pubKeys,
+		vote.sig, recvMsg.BlockHash,
 		recvMsg.BlockNum, recvMsg.ViewID,
 	); err != nil {
 		return
 	}
 	// Set the bitmap indicating that this validator signed.
-	if err := commitBitmap.SetKey(recvMsg.SenderPubkey, true); err != nil {
-		consensus.getLogger().Warn().Err(err).
-			Msg("[OnCommit] commitBitmap.SetKey failed")
-		return
+	for _, pubKey := range vote.pubKeys {
+		if err := commitBitmap.SetKey(pubKey, true); err != nil {
+			consensus.getLogger().Warn().Err(err).
+				Msg("[OnCommit] commitBitmap.SetKey failed")
+			return
+		}
+		if quorumWasMet {
+			consensus.timings.lateSigner(recvMsg.BlockNum, pubKey.SerializeToHexStr())
+		}
 	}
 
 	quorumIsMet := consensus.Decider.IsQuorumAchieved(quorum.Commit)
 	if !quorumWasMet && quorumIsMet {
 		logger.Info().Msg("[OnCommit] 2/3 Enough commits received")
+		consensus.timings.committed(
+			recvMsg.BlockNum, consensus.Decider.SignersCount(quorum.Commit),
+			consensus.Decider.ComputeTotalPowerByMask(commitBitmap),
+		)
 		go func(viewID uint64) {
 			time.Sleep(2 * time.Second)
 			logger.Debug().Msg("[OnCommit] Commit Grace Period Ended")
@@ -47,6 +47,15 @@ func (v *uniformVoteWeight) IsQuorumAchievedByMask(mask *bls_cosi.Mask) bool {
 	return true
 }
 
+// ComputeTotalPowerByMask computes the share of the participants indicated by bitmap mask
+func (v *uniformVoteWeight) ComputeTotalPowerByMask(mask *bls_cosi.Mask) *numeric.Dec {
+	currentTotal := numeric.ZeroDec()
+	if count := v.ParticipantsCount(); count > 0 {
+		currentTotal = numeric.NewDec(utils.CountOneBits(mask.Bitmap)).QuoInt64(count)
+	}
+	return &currentTotal
+}
+
 // QuorumThreshold ..
 func (v *uniformVoteWeight) QuorumThreshold() numeric.Dec {
 	return numeric.NewDec(v.TwoThirdsSignersCount())
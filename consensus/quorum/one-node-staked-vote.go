@@ -74,7 +74,7 @@ func (v *stakedVoteWeight) IsQuorumAchieved(p Phase) bool {
 // IsQuorumAchivedByMask ..
 func (v *stakedVoteWeight) IsQuorumAchievedByMask(mask *bls_cosi.Mask) bool {
 	threshold := v.QuorumThreshold()
-	currentTotalPower := v.computeTotalPowerByMask(mask)
+	currentTotalPower := v.ComputeTotalPowerByMask(mask)
 	if currentTotalPower == nil {
 		return false
 	}
@@ -114,7 +114,7 @@ func (v *stakedVoteWeight) computeCurrentTotalPower(p Phase) (*numeric.Dec, erro
 }
 
 // ComputeTotalPowerByMask computes the total power indicated by bitmap mask
-func (v *stakedVoteWeight) computeTotalPowerByMask(mask *bls_cosi.Mask) *numeric.Dec {
+func (v *stakedVoteWeight) ComputeTotalPowerByMask(mask *bls_cosi.Mask) *numeric.Dec {
 	pubKeys := mask.Publics
 	w := shard.BlsPublicKey{}
 	currentTotal := numeric.ZeroDec()
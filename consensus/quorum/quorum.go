@@ -82,6 +82,13 @@ type SignatoryTracker interface {
 		sig *bls.Sign, headerHash common.Hash,
 		height, viewID uint64,
 	) (*votepower.Ballot, error)
+	// SubmitVotes credits every key of a vote signed by several keys with
+	// their aggregated signature
+	SubmitVotes(
+		p Phase, pubKeys []*bls.PublicKey,
+		sig *bls.Sign, headerHash common.Hash,
+		height, viewID uint64,
+	) ([]*votepower.Ballot, error)
 	// Caller assumes concurrency protection
 	SignersCount(Phase) int64
 	reset([]Phase)
@@ -116,6 +123,7 @@ type Decider interface {
 	Policy() Policy
 	IsQuorumAchieved(Phase) bool
 	IsQuorumAchievedByMask(mask *bls_cosi.Mask) bool
+	ComputeTotalPowerByMask(mask *bls_cosi.Mask) *numeric.Dec
 	QuorumThreshold() numeric.Dec
 	AmIMemberOfCommitee() bool
 	IsRewardThresholdAchieved() bool
@@ -160,11 +168,17 @@ type depInject struct {
 func (s *cIdentities) AggregateVotes(p Phase) *bls.Sign {
 	ballots := s.ReadAllBallots(p)
 	sigs := make([]*bls.Sign, 0, len(ballots))
+	// the keys of a vote by several keys share its signature, added once
+	added := map[string]struct{}{}
 	for _, ballot := range ballots {
 		sig := &bls.Sign{}
 		// NOTE invariant that shouldn't happen by now
 		// but pointers are pointers
 		if ballot != nil {
+			if _, ok := added[string(ballot.Signature)]; ok {
+				continue
+			}
+			added[string(ballot.Signature)] = struct{}{}
 			sig.DeserializeHexStr(common.Bytes2Hex(ballot.Signature))
 			sigs = append(sigs, sig)
 		}
@@ -250,6 +264,30 @@ func (s *cIdentities) SubmitVote(
 	return ballot, nil
 }
 
+func (s *cIdentities) SubmitVotes(
+	p Phase, pubKeys []*bls.PublicKey,
+	sig *bls.Sign, headerHash common.Hash,
+	height, viewID uint64,
+) ([]*votepower.Ballot, error) {
+	signers := []shard.BlsPublicKey{}
+	if len(pubKeys) > 1 {
+		for _, pubKey := range pubKeys {
+			signers = append(signers, *shard.FromLibBLSPublicKeyUnsafe(pubKey))
+		}
+	}
+	ballots := make([]*votepower.Ballot, len(pubKeys))
+	for i, pubKey := range pubKeys {
+		ballot, err := s.SubmitVote(p, pubKey, sig, headerHash, height, viewID)
+		if err != nil {
+			return nil, err
+		}
+		// the evidence of a double sign by the key needs the other keys
+		ballot.AggregateSigners = signers
+		ballots[i] = ballot
+	}
+	return ballots, nil
+}
+
 func (s *cIdentities) reset(ps []Phase) {
 	for i := range ps {
 		switch m := votepower.NewRound(); ps[i] {
@@ -0,0 +1,41 @@
+package quorum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestSubmitVotes(t *testing.T) {
+	decider := NewDecider(SuperMajorityVote, shard.BeaconChainShardID)
+	pubKeys, sigs := []*bls.PublicKey{}, []*bls.Sign{}
+	for i := 0; i < 4; i++ {
+		priKey := bls_cosi.RandPrivateKey()
+		pubKeys, sigs = append(pubKeys, priKey.GetPublicKey()), append(sigs, priKey.Sign(msg))
+	}
+	decider.UpdateParticipants(pubKeys)
+
+	// a validator votes with three keys at once, another with its only key
+	aggregated := bls_cosi.AggregateSig(sigs[:3])
+	if _, err := decider.SubmitVotes(Prepare, pubKeys[:3], aggregated, common.Hash{}, 0, 0); err != nil {
+		t.Fatalf("SubmitVotes() failed: %v", err)
+	}
+	if _, err := decider.SubmitVote(Prepare, pubKeys[3], sigs[3], common.Hash{}, 0, 0); err != nil {
+		t.Fatalf("SubmitVote() failed: %v", err)
+	}
+
+	if count := decider.SignersCount(Prepare); count != 4 {
+		t.Errorf("SignersCount() = %d, want 4", count)
+	}
+	for _, pubKey := range pubKeys[:3] {
+		if decider.ReadBallot(Prepare, pubKey) == nil {
+			t.Errorf("no ballot of key %s", pubKey.SerializeToHexStr())
+		}
+	}
+	if !decider.AggregateVotes(Prepare).Verify(bls_cosi.AggregatePubKeys(pubKeys), msg) {
+		t.Error("aggregated votes do not verify against all the keys")
+	}
+}
@@ -0,0 +1,224 @@
+// Package recorder records the consensus messages a node receives and sends,
+// for replaying them later into a consensus instance to diagnose view changes.
+//
+// A recording is a sequence of RLP encoded records in rotating files.
+package recorder
+
+import (
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/api/proto"
+	"github.com/harmony-one/harmony/core/types"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/p2p"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/natefinch/lumberjack"
+	"github.com/pkg/errors"
+)
+
+// Direction tells whether a recorded message was received or sent
+type Direction byte
+
+// Directions of recorded messages
+const (
+	Received Direction = iota
+	Sent
+	// Rebuilt records a block the consensus rebuilt from a compact block, so a
+	// replay rebuilds it without the transaction pool of the node
+	Rebuilt
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Sent:
+		return "sent"
+	case Rebuilt:
+		return "rebuilt"
+	}
+	return "received"
+}
+
+// p2pMsgPrefixSize is the size of the header of a p2p message, which precedes
+// the message content
+const p2pMsgPrefixSize = 5
+
+// Record is a recorded consensus message
+type Record struct {
+	// Time is the Unix time in nanoseconds the message was received or sent at
+	Time      uint64
+	Direction Direction
+	// Sender is the peer which published the message
+	Sender libp2p_peer.ID
+	// Payload is the marshaled msg_pb.Message, or the RLP encoded block if
+	// rebuilt
+	Payload []byte
+}
+
+// Timestamp returns the time of the record
+func (r *Record) Timestamp() time.Time {
+	return time.Unix(0, int64(r.Time))
+}
+
+// Config is the configuration of a Recorder
+type Config struct {
+	// Path is the file recorded to, rotated files are kept beside it
+	Path string
+	// MaxSize is the size in megabytes a file is rotated at
+	MaxSize int
+	// MaxBackups is the number of rotated files kept
+	MaxBackups int
+}
+
+// DefaultConfig returns the default configuration recording to the given file
+func DefaultConfig(path string) Config {
+	return Config{Path: path, MaxSize: 100, MaxBackups: 10}
+}
+
+// Recorder appends consensus messages to a recording
+type Recorder struct {
+	mux sync.Mutex
+	out io.WriteCloser
+	now func() time.Time
+}
+
+// New returns a recorder writing to rotating files
+func New(config Config) *Recorder {
+	return NewWithWriter(&lumberjack.Logger{
+		Filename:   config.Path,
+		MaxSize:    config.MaxSize,
+		MaxBackups: config.MaxBackups,
+	})
+}
+
+// NewWithWriter returns a recorder writing to the given writer
+func NewWithWriter(out io.WriteCloser) *Recorder {
+	return &Recorder{out: out, now: time.Now}
+}
+
+// Record appends the consensus message payload, a marshaled msg_pb.Message,
+// received from or sent by the given peer
+func (r *Recorder) Record(direction Direction, sender libp2p_peer.ID, payload []byte) {
+	data, err := rlp.EncodeToBytes(&Record{
+		Time:      uint64(r.now().UnixNano()),
+		Direction: direction,
+		Sender:    sender,
+		Payload:   payload,
+	})
+	if err != nil {
+		utils.Logger().Warn().Err(err).Msg("[Recorder] cannot encode consensus message")
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	// one write per record, so a file is never rotated within a record
+	if _, err := r.out.Write(data); err != nil {
+		utils.Logger().Warn().Err(err).Msg("[Recorder] cannot record consensus message")
+	}
+}
+
+// WrapReconstructor returns the block reconstructor recording the blocks the
+// given reconstructor rebuilds
+func (r *Recorder) WrapReconstructor(
+	reconstruct func(*types.CompactBlock, libp2p_peer.ID) (*types.Block, error),
+) func(*types.CompactBlock, libp2p_peer.ID) (*types.Block, error) {
+	return func(compact *types.CompactBlock, sender libp2p_peer.ID) (*types.Block, error) {
+		block, err := reconstruct(compact, sender)
+		if err != nil {
+			return nil, err
+		}
+		if data, err := rlp.EncodeToBytes(block); err == nil {
+			r.Record(Rebuilt, sender, data)
+		}
+		return block, nil
+	}
+}
+
+// Close closes the recording
+func (r *Recorder) Close() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.out.Close()
+}
+
+// Reader reads the records of a recording
+type Reader struct {
+	stream *rlp.Stream
+}
+
+// NewReader returns a reader of the recording read from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{stream: rlp.NewStream(r, 0)}
+}
+
+// Next returns the next record, or io.EOF at the end of the recording
+func (r *Reader) Next() (*Record, error) {
+	record := &Record{}
+	if err := r.stream.Decode(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// ReadFiles returns the records of the given recording files, the current file
+// and its rotated files in any order, sorted by time
+func ReadFiles(paths ...string) ([]*Record, error) {
+	records := []*Record{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		reader := NewReader(f)
+		for {
+			record, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err == io.ErrUnexpectedEOF {
+				// the last record of a node which crashed while recording
+				utils.Logger().Warn().Str("path", path).Msg("[Recorder] truncated record skipped")
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, errors.Wrapf(err, "cannot read record %d of %s", len(records), path)
+			}
+			records = append(records, record)
+		}
+		f.Close()
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time < records[j].Time
+	})
+	return records, nil
+}
+
+// Host is a p2p.Host recording the consensus messages it sends
+type Host struct {
+	p2p.Host
+	recorder *Recorder
+}
+
+// WrapHost returns the host recording the consensus messages it sends
+func WrapHost(host p2p.Host, recorder *Recorder) *Host {
+	return &Host{Host: host, recorder: recorder}
+}
+
+// SendMessageToGroups records the message if a consensus message, and sends it
+func (host *Host) SendMessageToGroups(groups []nodeconfig.GroupID, msg []byte) error {
+	if len(msg) > p2pMsgPrefixSize {
+		content := msg[p2pMsgPrefixSize:]
+		if category, err := proto.GetMessageCategory(content); err == nil &&
+			category == proto.Consensus {
+			payload, _ := proto.GetConsensusMessagePayload(content)
+			host.recorder.Record(Sent, host.GetID(), payload)
+		}
+	}
+	return host.Host.SendMessageToGroups(groups, msg)
+}
@@ -0,0 +1,143 @@
+package recorder
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/api/proto"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/host"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// newTestRecorder returns a recorder whose clock advances a second a record
+func newTestRecorder(buf *bytes.Buffer) *Recorder {
+	r := NewWithWriter(nopCloser{buf})
+	now := time.Unix(1000, 0)
+	r.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return r
+}
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := newTestRecorder(buf)
+	r.Record(Received, libp2p_peer.ID("a"), []byte{1, 2})
+	r.Record(Sent, libp2p_peer.ID("b"), []byte{3})
+
+	reader := NewReader(buf)
+	first, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if first.Direction != Received || first.Sender != "a" ||
+		!bytes.Equal(first.Payload, []byte{1, 2}) || first.Timestamp().Unix() != 1001 {
+		t.Errorf("first record = %+v", first)
+	}
+	second, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if second.Direction != Sent || second.Sender != "b" || second.Time <= first.Time {
+		t.Errorf("second record = %+v", second)
+	}
+	if _, err := reader.Next(); err == nil {
+		t.Error("Next() returned a record past the end")
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatalf("TempDir() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a rotated file and the current file, whose last record is truncated
+	rotated, current := &bytes.Buffer{}, &bytes.Buffer{}
+	r := newTestRecorder(rotated)
+	r.Record(Received, "a", []byte{1})
+	r.Record(Received, "a", []byte{2})
+	r.out = nopCloser{current}
+	r.Record(Sent, "b", []byte{3})
+	truncated, _ := rlp.EncodeToBytes(&Record{Time: 1, Payload: []byte{4, 4, 4}})
+	current.Write(truncated[:len(truncated)-2])
+
+	rotatedPath := filepath.Join(dir, "consensus-rotated.rec")
+	currentPath := filepath.Join(dir, "consensus.rec")
+	if err := ioutil.WriteFile(rotatedPath, rotated.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(currentPath, current.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadFiles(currentPath, rotatedPath)
+	if err != nil {
+		t.Fatalf("ReadFiles() error = %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("ReadFiles() returned %d records, want 3", len(records))
+	}
+	for i, record := range records {
+		if record.Payload[0] != byte(i+1) {
+			t.Errorf("record %d has payload %v, records are not sorted by time", i, record.Payload)
+		}
+	}
+}
+
+type fakeHost struct {
+	p2p.Host
+	sent int
+}
+
+func (h *fakeHost) GetID() libp2p_peer.ID { return "self" }
+
+func (h *fakeHost) SendMessageToGroups(groups []nodeconfig.GroupID, msg []byte) error {
+	h.sent++
+	return nil
+}
+
+func TestHost_SendMessageToGroups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	fake := &fakeHost{}
+	h := WrapHost(fake, newTestRecorder(buf))
+	groups := []nodeconfig.GroupID{nodeconfig.NewGroupIDByShardID(0)}
+
+	consensusMsg := host.ConstructP2pMessage(byte(17), proto.ConstructConsensusMessage([]byte{7, 8}))
+	nodeMsg := host.ConstructP2pMessage(byte(17), []byte{byte(proto.Node), 0, 9})
+	for _, msg := range [][]byte{consensusMsg, nodeMsg} {
+		if err := h.SendMessageToGroups(groups, msg); err != nil {
+			t.Fatalf("SendMessageToGroups() error = %v", err)
+		}
+	}
+	if fake.sent != 2 {
+		t.Errorf("sent %d messages, want 2", fake.sent)
+	}
+
+	reader := NewReader(buf)
+	record, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if record.Direction != Sent || record.Sender != "self" || !bytes.Equal(record.Payload, []byte{7, 8}) {
+		t.Errorf("record = %+v", record)
+	}
+	if _, err := reader.Next(); err == nil {
+		t.Error("a node message was recorded")
+	}
+}
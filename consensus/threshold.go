@@ -9,6 +9,7 @@ import (
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/p2p/host"
+	"github.com/pkg/errors"
 )
 
 func (consensus *Consensus) didReachPrepareQuorum() error {
@@ -44,6 +45,11 @@ func (consensus *Consensus) didReachPrepareQuorum() error {
 
 	// so by this point, everyone has committed to the blockhash of this block
 	// in prepare and so this is the actual block.
+	if !consensus.canSign(
+		FBFTCommit, consensus.blockNum, consensus.viewID, common.BytesToHash(consensus.blockHash[:]),
+	) {
+		return errors.New("[OnPrepare] already committed to another block")
+	}
 	for i, key := range consensus.PubKey.PublicKey {
 		if _, err := consensus.Decider.SubmitVote(
 			quorum.Commit,
@@ -0,0 +1,209 @@
+package consensus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+// DefaultTimingHistory is the number of blocks whose consensus timing is kept
+const DefaultTimingHistory = 256
+
+// BlockTiming is the timing of the consensus on a block as seen by the node.
+// The leader stamps the phases when it sends the announce, reaches the
+// prepare and commit quorums and sends the committed message; a validator
+// stamps them when it receives the announce, prepared and committed messages,
+// so its commit and finalize times are the same.  A phase the node did not
+// see is left zero.
+type BlockTiming struct {
+	BlockNum  uint64
+	ViewID    uint64
+	BlockHash common.Hash
+	IsLeader  bool
+	Announce  time.Time
+	Prepared  time.Time
+	Committed time.Time
+	Finalized time.Time
+	// signers and their share of the voting power at the quorums
+	PrepareSigners int64
+	PreparePower   numeric.Dec
+	CommitSigners  int64
+	CommitPower    numeric.Dec
+	// LateSigners are the keys whose commit reached the leader after the
+	// commit quorum, during the grace period before the committed message
+	LateSigners []string
+	// ViewChanges is the number of view changes seen for the block number
+	ViewChanges uint64
+	// last view changed to, so a view change is counted once
+	viewChangingID uint64
+}
+
+func between(from, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// AnnounceToPrepared returns the time from the announce to the prepare quorum
+func (t *BlockTiming) AnnounceToPrepared() time.Duration {
+	return between(t.Announce, t.Prepared)
+}
+
+// PreparedToCommitted returns the time from the prepare quorum to the commit quorum
+func (t *BlockTiming) PreparedToCommitted() time.Duration {
+	return between(t.Prepared, t.Committed)
+}
+
+// CommittedToFinalized returns the time from the commit quorum to the
+// committed message
+func (t *BlockTiming) CommittedToFinalized() time.Duration {
+	return between(t.Committed, t.Finalized)
+}
+
+// Timings keeps the consensus timing of the last blocks, oldest first
+type Timings struct {
+	mutex   sync.Mutex
+	now     func() time.Time
+	size    int
+	current *BlockTiming
+	history []BlockTiming
+}
+
+// NewTimings returns the timing history of the last size blocks
+func NewTimings(size int, now func() time.Time) *Timings {
+	return &Timings{now: now, size: size}
+}
+
+// History returns the timing of the last blocks, oldest first
+func (t *Timings) History() []BlockTiming {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	history := make([]BlockTiming, len(t.history))
+	copy(history, t.history)
+	return history
+}
+
+// Latest returns the timing of the last block done, false if there is none
+func (t *Timings) Latest() (BlockTiming, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.history) == 0 {
+		return BlockTiming{}, false
+	}
+	return t.history[len(t.history)-1], true
+}
+
+func (t *Timings) setClock(now func() time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.now = now
+}
+
+// timing returns the timing of the block number, starting it if it is
+// ahead of the current one.  The current timing is kept unfinished in the
+// history when the node moves past it.  It returns nil for a past block.
+func (t *Timings) timing(blockNum uint64) *BlockTiming {
+	if t.current != nil {
+		if t.current.BlockNum == blockNum {
+			return t.current
+		}
+		if t.current.BlockNum > blockNum {
+			return nil
+		}
+		t.push()
+	}
+	t.current = &BlockTiming{
+		BlockNum: blockNum, PreparePower: numeric.ZeroDec(), CommitPower: numeric.ZeroDec(),
+	}
+	return t.current
+}
+
+func (t *Timings) push() {
+	t.history = append(t.history, *t.current)
+	if len(t.history) > t.size {
+		t.history = t.history[len(t.history)-t.size:]
+	}
+	t.current = nil
+}
+
+// announce stamps the announce of a block, restarting the phases of an
+// earlier view
+func (t *Timings) announce(blockNum, viewID uint64, blockHash common.Hash, isLeader bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	timing := t.timing(blockNum)
+	if timing == nil {
+		return
+	}
+	*timing = BlockTiming{
+		BlockNum:       blockNum,
+		ViewID:         viewID,
+		BlockHash:      blockHash,
+		IsLeader:       isLeader,
+		Announce:       t.now(),
+		PreparePower:   numeric.ZeroDec(),
+		CommitPower:    numeric.ZeroDec(),
+		ViewChanges:    timing.ViewChanges,
+		viewChangingID: timing.viewChangingID,
+	}
+}
+
+// prepared stamps the prepare quorum of a block
+func (t *Timings) prepared(blockNum uint64, signers int64, power *numeric.Dec) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if timing := t.timing(blockNum); timing != nil && timing.Prepared.IsZero() {
+		timing.Prepared = t.now()
+		timing.PrepareSigners, timing.PreparePower = signers, powerOf(power)
+	}
+}
+
+// committed stamps the commit quorum of a block
+func (t *Timings) committed(blockNum uint64, signers int64, power *numeric.Dec) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if timing := t.timing(blockNum); timing != nil && timing.Committed.IsZero() {
+		timing.Committed = t.now()
+		timing.CommitSigners, timing.CommitPower = signers, powerOf(power)
+	}
+}
+
+// lateSigner records a commit received after the commit quorum
+func (t *Timings) lateSigner(blockNum uint64, key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if timing := t.timing(blockNum); timing != nil {
+		timing.LateSigners = append(timing.LateSigners, key)
+	}
+}
+
+// finalized stamps the end of the consensus on a block and moves its
+// timing to the history
+func (t *Timings) finalized(blockNum uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if timing := t.timing(blockNum); timing != nil {
+		timing.Finalized = t.now()
+		t.push()
+	}
+}
+
+// viewChange counts a view change to viewID while agreeing on a block
+func (t *Timings) viewChange(blockNum, viewID uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if timing := t.timing(blockNum); timing != nil && viewID > timing.viewChangingID {
+		timing.ViewChanges++
+		timing.viewChangingID = viewID
+	}
+}
+
+func powerOf(power *numeric.Dec) numeric.Dec {
+	if power == nil {
+		return numeric.ZeroDec()
+	}
+	return *power
+}
@@ -0,0 +1,106 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestTimings_Phases(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	timings := NewTimings(DefaultTimingHistory, clock.Now)
+	power := numeric.NewDecWithPrec(7, 1)
+
+	timings.announce(5, 3, common.Hash{1}, true)
+	clock.advance(100 * time.Millisecond)
+	timings.prepared(5, 3, &power)
+	clock.advance(200 * time.Millisecond)
+	timings.committed(5, 3, &power)
+	timings.lateSigner(5, "late")
+	clock.advance(2 * time.Second)
+	timings.finalized(5)
+
+	timing, ok := timings.Latest()
+	if !ok {
+		t.Fatal("Latest() found no timing")
+	}
+	if timing.BlockNum != 5 || timing.ViewID != 3 || !timing.IsLeader {
+		t.Errorf("timing of block %d, view %d, leader %v", timing.BlockNum, timing.ViewID, timing.IsLeader)
+	}
+	if got := timing.AnnounceToPrepared(); got != 100*time.Millisecond {
+		t.Errorf("AnnounceToPrepared() = %v", got)
+	}
+	if got := timing.PreparedToCommitted(); got != 200*time.Millisecond {
+		t.Errorf("PreparedToCommitted() = %v", got)
+	}
+	if got := timing.CommittedToFinalized(); got != 2*time.Second {
+		t.Errorf("CommittedToFinalized() = %v", got)
+	}
+	if timing.CommitSigners != 3 || !timing.CommitPower.Equal(power) {
+		t.Errorf("commit quorum of %d signers with %s power", timing.CommitSigners, timing.CommitPower)
+	}
+	if len(timing.LateSigners) != 1 || timing.LateSigners[0] != "late" {
+		t.Errorf("LateSigners = %v", timing.LateSigners)
+	}
+}
+
+func TestTimings_ViewChanges(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	timings := NewTimings(DefaultTimingHistory, clock.Now)
+
+	timings.announce(5, 3, common.Hash{1}, false)
+	timings.viewChange(5, 4)
+	timings.viewChange(5, 4)
+	timings.viewChange(5, 5)
+	timings.announce(5, 5, common.Hash{2}, false)
+	timings.finalized(5)
+
+	timing, _ := timings.Latest()
+	if timing.ViewChanges != 2 || timing.ViewID != 5 || timing.BlockHash != (common.Hash{2}) {
+		t.Errorf("%d view changes, view %d, hash %s", timing.ViewChanges, timing.ViewID, timing.BlockHash.Hex())
+	}
+	if len(timings.History()) != 1 {
+		t.Errorf("History() has %d timings, want 1", len(timings.History()))
+	}
+}
+
+func TestTimings_History(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	timings := NewTimings(3, clock.Now)
+
+	for blockNum := uint64(1); blockNum <= 5; blockNum++ {
+		timings.announce(blockNum, blockNum, common.Hash{}, false)
+		if blockNum != 4 {
+			timings.finalized(blockNum)
+		}
+	}
+	// a past block is not recorded
+	timings.prepared(2, 1, nil)
+
+	history := timings.History()
+	if len(history) != 3 {
+		t.Fatalf("History() has %d timings, want 3", len(history))
+	}
+	for i, want := range []uint64{3, 4, 5} {
+		if history[i].BlockNum != want {
+			t.Errorf("history[%d] of block %d, want %d", i, history[i].BlockNum, want)
+		}
+	}
+	if !history[1].Finalized.IsZero() {
+		t.Error("unfinished block 4 stamped as finalized")
+	}
+}
@@ -8,10 +8,16 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/harmony-one/bls/ffi/go/bls"
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/p2p/host"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/pkg/errors"
 )
 
 func (consensus *Consensus) onAnnounce(msg *msg_pb.Message) {
@@ -37,6 +43,7 @@ func (consensus *Consensus) onAnnounce(msg *msg_pb.Message) {
 	consensus.mutex.Lock()
 	defer consensus.mutex.Unlock()
 	consensus.blockHash = recvMsg.BlockHash
+	consensus.timings.announce(recvMsg.BlockNum, recvMsg.ViewID, recvMsg.BlockHash, false)
 	// we have already added message and block, skip check viewID
 	// and send prepare message if is in ViewChanging mode
 	if consensus.current.Mode() == ViewChanging {
@@ -58,16 +65,20 @@ func (consensus *Consensus) onAnnounce(msg *msg_pb.Message) {
 }
 
 func (consensus *Consensus) prepare() {
+	if !consensus.canSign(
+		FBFTPrepare, consensus.blockNum, consensus.viewID, common.BytesToHash(consensus.blockHash[:]),
+	) {
+		return
+	}
 	groupID := []nodeconfig.GroupID{nodeconfig.NewGroupIDByShardID(nodeconfig.ShardID(consensus.ShardID))}
-	for i, key := range consensus.PubKey.PublicKey {
-		networkMessage, err := consensus.construct(msg_pb.MessageType_PREPARE, nil, key, consensus.priKey.PrivateKey[i])
-		if err != nil {
-			consensus.getLogger().Err(err).
-				Str("message-type", msg_pb.MessageType_PREPARE.String()).
-				Msg("could not construct message")
-			return
-		}
-
+	networkMessages, err := consensus.constructVotes(msg_pb.MessageType_PREPARE, nil)
+	if err != nil {
+		consensus.getLogger().Err(err).
+			Str("message-type", msg_pb.MessageType_PREPARE.String()).
+			Msg("could not construct message")
+		return
+	}
+	for _, networkMessage := range networkMessages {
 		// TODO: this will not return immediatey, may block
 		if consensus.current.Mode() != Listening {
 			if err := consensus.msgSender.SendWithoutRetry(
@@ -89,9 +100,54 @@ func (consensus *Consensus) prepare() {
 	consensus.switchPhase(FBFTPrepare, true)
 }
 
+// constructVotes constructs the prepare or commit messages of the keys of the
+// node: a message per key, or a message per validator owning keys of the node
+// in the committee, with the aggregated vote of its keys, when the votes are
+// aggregated from the aggregate vote epoch on
+func (consensus *Consensus) constructVotes(
+	p msg_pb.MessageType, payloadForSign []byte,
+) ([]*NetworkMessage, error) {
+	if !consensus.aggregateSig || len(consensus.PubKey.PublicKey) == 1 ||
+		!consensus.isAggregateVote() {
+		networkMessages := []*NetworkMessage{}
+		for i, key := range consensus.PubKey.PublicKey {
+			networkMessage, err := consensus.construct(p, payloadForSign, key, consensus.priKey.PrivateKey[i])
+			if err != nil {
+				return nil, err
+			}
+			networkMessages = append(networkMessages, networkMessage)
+		}
+		return networkMessages, nil
+	}
+
+	owners := []common.Address{}
+	pubKeys := map[common.Address][]*bls.PublicKey{}
+	priKeys := map[common.Address][]*bls.SecretKey{}
+	for i, key := range consensus.PubKey.PublicKey {
+		owner, ok := consensus.keyOwner(key)
+		if !ok || !consensus.IsValidatorInCommittee(key) {
+			continue
+		}
+		if _, ok := pubKeys[owner]; !ok {
+			owners = append(owners, owner)
+		}
+		pubKeys[owner] = append(pubKeys[owner], key)
+		priKeys[owner] = append(priKeys[owner], consensus.priKey.PrivateKey[i])
+	}
+	networkMessages := []*NetworkMessage{}
+	for _, owner := range owners {
+		networkMessage, err := consensus.constructByKeys(p, payloadForSign, pubKeys[owner], priKeys[owner])
+		if err != nil {
+			return nil, err
+		}
+		networkMessages = append(networkMessages, networkMessage)
+	}
+	return networkMessages, nil
+}
+
 // if onPrepared accepts the prepared message from the leader, then
 // it will send a COMMIT message for the leader to receive on the network.
-func (consensus *Consensus) onPrepared(msg *msg_pb.Message) {
+func (consensus *Consensus) onPrepared(msg *msg_pb.Message, rebuilt *types.Block) {
 	recvMsg, err := ParseFBFTMessage(msg)
 	if err != nil {
 		consensus.getLogger().Debug().Err(err).Msg("[OnPrepared] Unparseable validator message")
@@ -133,8 +189,8 @@ func (consensus *Consensus) onPrepared(msg *msg_pb.Message) {
 	}
 
 	// check validity of block
-	var blockObj types.Block
-	if err := rlp.DecodeBytes(recvMsg.Block, &blockObj); err != nil {
+	blockObj, err := decodePreparedBlock(recvMsg.Block, rebuilt)
+	if err != nil {
 		consensus.getLogger().Warn().
 			Err(err).
 			Uint64("MsgBlockNum", recvMsg.BlockNum).
@@ -142,16 +198,22 @@ func (consensus *Consensus) onPrepared(msg *msg_pb.Message) {
 		return
 	}
 	// let this handle it own logs
-	if !consensus.onPreparedSanityChecks(&blockObj, recvMsg) {
+	if !consensus.onPreparedSanityChecks(blockObj, recvMsg) {
 		return
 	}
 	consensus.mutex.Lock()
 	defer consensus.mutex.Unlock()
 
-	consensus.FBFTLog.AddBlock(&blockObj)
-	// add block field
+	consensus.FBFTLog.AddBlock(blockObj)
+	// add block field, in full even if it was received compact
 	blockPayload := make([]byte, len(recvMsg.Block))
 	copy(blockPayload[:], recvMsg.Block[:])
+	if types.IsCompactBlock(blockPayload) {
+		if blockPayload, err = rlp.EncodeToBytes(blockObj); err != nil {
+			consensus.getLogger().Warn().Err(err).Msg("[OnPrepared] Failed encoding block")
+			return
+		}
+	}
 	consensus.block = blockPayload
 	recvMsg.Block = []byte{} // save memory space
 	consensus.FBFTLog.AddMessage(recvMsg)
@@ -184,6 +246,10 @@ func (consensus *Consensus) onPrepared(msg *msg_pb.Message) {
 		return
 	}
 
+	consensus.timings.prepared(
+		recvMsg.BlockNum, utils.CountOneBits(mask.Bitmap), consensus.Decider.ComputeTotalPowerByMask(mask),
+	)
+
 	// TODO: genesis account node delay for 1 second,
 	// this is a temp fix for allows FN nodes to earning reward
 	if consensus.delayCommit > 0 {
@@ -201,15 +267,24 @@ func (consensus *Consensus) onPrepared(msg *msg_pb.Message) {
 	}
 	blockNumBytes := make([]byte, 8)
 	binary.LittleEndian.PutUint64(blockNumBytes, consensus.blockNum)
+	if !consensus.canSign(
+		FBFTCommit, consensus.blockNum, consensus.viewID, common.BytesToHash(consensus.blockHash[:]),
+	) {
+		return
+	}
 	groupID := []nodeconfig.GroupID{nodeconfig.NewGroupIDByShardID(nodeconfig.ShardID(consensus.ShardID))}
-	for i, key := range consensus.PubKey.PublicKey {
-		networkMessage, _ := consensus.construct(
-			// TODO(audit): sign signature on hash+blockNum+viewID (add a hard fork)
-			msg_pb.MessageType_COMMIT,
-			append(blockNumBytes, consensus.blockHash[:]...),
-			key, consensus.priKey.PrivateKey[i],
-		)
-
+	networkMessages, err := consensus.constructVotes(
+		// TODO(audit): sign signature on hash+blockNum+viewID (add a hard fork)
+		msg_pb.MessageType_COMMIT,
+		append(blockNumBytes, consensus.blockHash[:]...),
+	)
+	if err != nil {
+		consensus.getLogger().Err(err).
+			Str("message-type", msg_pb.MessageType_COMMIT.String()).
+			Msg("could not construct message")
+		return
+	}
+	for _, networkMessage := range networkMessages {
 		if consensus.current.Mode() != Listening {
 			if err := consensus.msgSender.SendWithoutRetry(
 				groupID,
@@ -293,6 +368,11 @@ func (consensus *Consensus) onCommitted(msg *msg_pb.Message) {
 		return
 	}
 
+	consensus.timings.committed(
+		recvMsg.BlockNum, utils.CountOneBits(mask.Bitmap), consensus.Decider.ComputeTotalPowerByMask(mask),
+	)
+	consensus.timings.finalized(recvMsg.BlockNum)
+
 	consensus.tryCatchup()
 	if consensus.current.Mode() == ViewChanging {
 		consensus.getLogger().Debug().Msg("[OnCommitted] Still in ViewChanging mode, Exiting!!")
@@ -307,3 +387,80 @@ func (consensus *Consensus) onCommitted(msg *msg_pb.Message) {
 	}
 	consensus.consensusTimeout[timeoutConsensus].Start()
 }
+
+var errCompactBlockNotRebuilt = errors.New("compact block of prepared message not rebuilt")
+
+// RebuildBlock rebuilds the compact block of a prepared message sent by a
+// committee member, before the message reaches the consensus loop, as
+// fetching the transactions the node misses may take a while. It returns nil
+// for other messages and for blocks which cannot be rebuilt.
+func (consensus *Consensus) RebuildBlock(
+	payload []byte, sender libp2p_peer.ID,
+) *types.Block {
+	msg := &msg_pb.Message{}
+	if err := protobuf.Unmarshal(payload, msg); err != nil ||
+		msg.Type != msg_pb.MessageType_PREPARED || msg.GetConsensus() == nil ||
+		!types.IsCompactBlock(msg.GetConsensus().Block) {
+		return nil
+	}
+	if msg.GetConsensus().ShardId != consensus.ShardID ||
+		msg.GetConsensus().BlockNum <= consensus.ChainReader.CurrentHeader().Number().Uint64() {
+		return nil
+	}
+	// only the signed messages of the committee make the node fetch, with
+	// the committee read under its lock as this runs off the consensus loop
+	senderKey, err := bls_cosi.BytesToBlsPublicKey(msg.GetConsensus().SenderPubkey)
+	if err != nil {
+		return nil
+	}
+	if _, ok := consensus.keyOwner(senderKey); !ok || verifyMessageSig(senderKey, msg) != nil {
+		return nil
+	}
+	block, err := consensus.DecodeBlock(msg.GetConsensus().Block, sender)
+	if err != nil {
+		consensus.getLogger().Warn().Err(err).
+			Uint64("MsgBlockNum", msg.GetConsensus().BlockNum).
+			Msg("[RebuildBlock] cannot rebuild compact block")
+		return nil
+	}
+	return block
+}
+
+// decodePreparedBlock decodes the block of a prepared message in the
+// consensus loop, where a compact block is the one rebuilt before.
+func decodePreparedBlock(payload []byte, rebuilt *types.Block) (*types.Block, error) {
+	if !types.IsCompactBlock(payload) {
+		blockObj := &types.Block{}
+		if err := rlp.DecodeBytes(payload, blockObj); err != nil {
+			return nil, err
+		}
+		return blockObj, nil
+	}
+	if rebuilt == nil {
+		return nil, errCompactBlockNotRebuilt
+	}
+	return rebuilt, nil
+}
+
+// DecodeBlock decodes the block of a prepared message, sent either in full or
+// as a compact block rebuilt by the block reconstructor with the help of the
+// sender of the message.
+func (consensus *Consensus) DecodeBlock(
+	payload []byte, sender libp2p_peer.ID,
+) (*types.Block, error) {
+	if !types.IsCompactBlock(payload) {
+		blockObj := &types.Block{}
+		if err := rlp.DecodeBytes(payload, blockObj); err != nil {
+			return nil, err
+		}
+		return blockObj, nil
+	}
+	compact, err := types.DecodeCompactBlock(payload)
+	if err != nil {
+		return nil, err
+	}
+	if consensus.BlockReconstructor == nil {
+		return nil, errors.New("no block reconstructor for compact block")
+	}
+	return consensus.BlockReconstructor(compact, sender)
+}
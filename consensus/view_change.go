@@ -12,6 +12,7 @@ import (
 	"github.com/harmony-one/harmony/consensus/quorum"
 	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/p2p/host"
 )
@@ -101,16 +102,38 @@ func (consensus *Consensus) ResetViewChangeState() {
 	consensus.nilBitmap = map[uint64]*bls_cosi.Mask{}
 	consensus.viewIDBitmap = map[uint64]*bls_cosi.Mask{}
 	consensus.Decider.ResetViewChangeVotes()
+	consensus.writeViewChangeState()
 }
 
-func createTimeout() map[TimeoutType]*utils.Timeout {
+func createTimeout(
+	durations shardingconfig.ConsensusTimeouts, now func() time.Time,
+) map[TimeoutType]*utils.Timeout {
 	timeouts := make(map[TimeoutType]*utils.Timeout)
-	timeouts[timeoutConsensus] = utils.NewTimeout(phaseDuration)
-	timeouts[timeoutViewChange] = utils.NewTimeout(viewChangeDuration)
-	timeouts[timeoutBootstrap] = utils.NewTimeout(bootstrapDuration)
+	timeouts[timeoutConsensus] = utils.NewTimeoutWithClock(durations.Phase, now)
+	timeouts[timeoutViewChange] = utils.NewTimeoutWithClock(durations.ViewChange, now)
+	timeouts[timeoutBootstrap] = utils.NewTimeoutWithClock(durations.Bootstrap, now)
 	return timeouts
 }
 
+// setTimeouts sets the durations of the consensus and view change timeouts,
+// from the next time each of them starts
+func (consensus *Consensus) setTimeouts(durations shardingconfig.ConsensusTimeouts) {
+	if durations == consensus.timeouts {
+		return
+	}
+	consensus.getLogger().Info().
+		Dur("phase", durations.Phase).
+		Dur("bootstrap", durations.Bootstrap).
+		Dur("viewChange", durations.ViewChange).
+		Dur("maxViewChange", durations.MaxViewChange).
+		Bool("exponentialBackoff", durations.ExponentialBackoff).
+		Msg("[setTimeouts] Consensus timeouts updated")
+	consensus.timeouts = durations
+	consensus.consensusTimeout[timeoutConsensus].SetDuration(durations.Phase)
+	consensus.consensusTimeout[timeoutBootstrap].SetDuration(durations.Bootstrap)
+	consensus.msgSender.SetPhaseDuration(durations.Phase)
+}
+
 // startViewChange send a  new view change
 func (consensus *Consensus) startViewChange(viewID uint64) {
 	if consensus.disableViewChange {
@@ -121,9 +144,10 @@ func (consensus *Consensus) startViewChange(viewID uint64) {
 	consensus.current.SetMode(ViewChanging)
 	consensus.current.SetViewID(viewID)
 	consensus.LeaderPubKey = consensus.GetNextLeaderKey()
+	consensus.writeViewChangeState()
+	consensus.timings.viewChange(consensus.blockNum, viewID)
 
-	diff := int64(viewID - consensus.viewID)
-	duration := time.Duration(diff * diff * int64(viewChangeDuration))
+	duration := consensus.timeouts.ViewChangeDuration(viewID - consensus.viewID)
 	consensus.getLogger().Info().
 		Uint64("ViewChangingID", viewID).
 		Dur("timeoutDuration", duration).
@@ -275,6 +299,7 @@ func (consensus *Consensus) onViewChange(msg *msg_pb.Message) {
 			// if m1Payload is empty, we just add one
 			if len(consensus.m1Payload) == 0 {
 				consensus.m1Payload = append(recvMsg.Payload[:0:0], recvMsg.Payload...)
+				consensus.writeViewChangeState()
 				// create prepared message for new leader
 				preparedMsg := FBFTMessage{
 					MessageType: msg_pb.MessageType_PREPARED,
@@ -356,6 +381,12 @@ func (consensus *Consensus) onViewChange(msg *msg_pb.Message) {
 			blockNumBytes := [8]byte{}
 			binary.LittleEndian.PutUint64(blockNumBytes[:], consensus.blockNum)
 			commitPayload := append(blockNumBytes[:], consensus.blockHash[:]...)
+			if !consensus.canSign(
+				FBFTCommit, consensus.blockNum, recvMsg.ViewID,
+				common.BytesToHash(consensus.blockHash[:]),
+			) {
+				return
+			}
 			if _, err := consensus.Decider.SubmitVote(
 				quorum.Commit,
 				newLeaderKey,
@@ -507,6 +538,7 @@ func (consensus *Consensus) onNewView(msg *msg_pb.Message) {
 	consensus.current.SetViewID(recvMsg.ViewID)
 	consensus.LeaderPubKey = senderKey
 	consensus.ResetViewChangeState()
+	consensus.timings.viewChange(recvMsg.BlockNum, recvMsg.ViewID)
 
 	// change view and leaderKey to keep in sync with network
 	if consensus.blockNum != recvMsg.BlockNum {
@@ -520,6 +552,12 @@ func (consensus *Consensus) onNewView(msg *msg_pb.Message) {
 	// NewView message is verified, change state to normal consensus
 	// TODO: check magic number 32
 	if len(recvMsg.Payload) > 32 {
+		if !consensus.canSign(
+			FBFTCommit, consensus.blockNum, consensus.viewID,
+			common.BytesToHash(consensus.blockHash[:]),
+		) {
+			return
+		}
 		// Construct and send the commit message
 		blockNumHash := make([]byte, 8)
 		binary.LittleEndian.PutUint64(blockNumHash, consensus.blockNum)
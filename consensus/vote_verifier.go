@@ -0,0 +1,62 @@
+package consensus
+
+import (
+	"sync"
+
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+)
+
+// pendingVote is a prepare or commit vote whose signature is to be verified
+type pendingVote struct {
+	signed *bls_cosi.SignedHash
+	// done is called with the result of the verification
+	done func(valid bool)
+}
+
+// voteVerifier verifies the signatures of the votes the leader receives in
+// batches.  The votes which arrive while a batch is verified make up the next
+// one, so a lone vote is verified at once and a burst of votes in a few
+// batches.  The first caller verifies the batches of all the others.
+type voteVerifier struct {
+	mutex    sync.Mutex
+	pending  []*pendingVote
+	running  bool
+	verifier *bls_cosi.BatchVerifier
+}
+
+func newVoteVerifier(verifier *bls_cosi.BatchVerifier) *voteVerifier {
+	return &voteVerifier{verifier: verifier}
+}
+
+// verify verifies the vote, now or in the batch in progress
+func (v *voteVerifier) verify(vote *pendingVote) {
+	v.mutex.Lock()
+	v.pending = append(v.pending, vote)
+	if v.running {
+		v.mutex.Unlock()
+		return
+	}
+	v.running = true
+	v.mutex.Unlock()
+
+	for {
+		v.mutex.Lock()
+		batch := v.pending
+		v.pending = nil
+		if len(batch) == 0 {
+			v.running = false
+			v.mutex.Unlock()
+			return
+		}
+		v.mutex.Unlock()
+
+		signed := make([]*bls_cosi.SignedHash, len(batch))
+		for i, vote := range batch {
+			signed[i] = vote.signed
+		}
+		valid := v.verifier.Verify(signed)
+		for i, vote := range batch {
+			vote.done(valid[i])
+		}
+	}
+}
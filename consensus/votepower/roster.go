@@ -28,25 +28,45 @@ type Ballot struct {
 	Signature       []byte             `json:"bls-signature"`
 	Height          uint64             `json:"block-height"`
 	ViewID          uint64             `json:"view-id"`
+	// AggregateSigners are the keys whose signatures are aggregated in
+	// Signature, the signer key among them, when it voted along with other
+	// keys of its validator; empty when the signer key voted alone, so that
+	// the ballot encodes as before
+	AggregateSigners []shard.BlsPublicKey `json:"aggregate-signers" rlp:"tail"`
 }
 
 // MarshalJSON ..
 func (b Ballot) MarshalJSON() ([]byte, error) {
+	signers := []string{}
+	for _, key := range b.AggregateSigners {
+		signers = append(signers, key.Hex())
+	}
 	return json.Marshal(struct {
-		A string `json:"bls-public-key"`
-		B string `json:"block-header-hash"`
-		C string `json:"bls-signature"`
-		E uint64 `json:"block-height"`
-		F uint64 `json:"view-id"`
+		A string   `json:"bls-public-key"`
+		B string   `json:"block-header-hash"`
+		C string   `json:"bls-signature"`
+		E uint64   `json:"block-height"`
+		F uint64   `json:"view-id"`
+		G []string `json:"aggregate-signers,omitempty"`
 	}{
 		b.SignerPubKey.Hex(),
 		b.BlockHeaderHash.Hex(),
 		hex.EncodeToString(b.Signature),
 		b.Height,
 		b.ViewID,
+		signers,
 	})
 }
 
+// SignerKeys returns the keys the signature of the ballot verifies with: the
+// aggregate signers, or the signer key when it voted alone
+func (b Ballot) SignerKeys() []shard.BlsPublicKey {
+	if len(b.AggregateSigners) > 0 {
+		return b.AggregateSigners
+	}
+	return []shard.BlsPublicKey{b.SignerPubKey}
+}
+
 // Round is a round of voting in any FBFT phase
 type Round struct {
 	AggregatedVote *bls.Sign
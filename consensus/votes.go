@@ -0,0 +1,118 @@
+package consensus
+
+import (
+	"bytes"
+
+	"github.com/harmony-one/bls/ffi/go/bls"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+var (
+	errVotePayloadSize = errors.New("vote payload is not a signature followed by public keys")
+	errVoteSender      = errors.New("sender key is not the first key of the vote")
+	errVoteDuplicate   = errors.New("key comes twice in the vote")
+	errVoteOwners      = errors.New("keys of the vote belong to different validators")
+	errVoteAggregated  = errors.New("aggregated vote before the aggregate vote epoch")
+)
+
+// vote is the signature of a prepare or commit by one or more keys of a
+// validator.  A validator running several keys may aggregate their
+// signatures into a single message, whose payload is the aggregated
+// signature followed by the keys; the sender key signing the message is the
+// first of them.
+type vote struct {
+	sig     *bls.Sign
+	pubKeys []*bls.PublicKey
+}
+
+// aggregatePubKey returns the key verifying the signature of the vote
+func (v *vote) aggregatePubKey() *bls.PublicKey {
+	if len(v.pubKeys) == 1 {
+		return v.pubKeys[0]
+	}
+	return bls_cosi.AggregatePubKeys(v.pubKeys)
+}
+
+// votePayload returns the payload of the vote of the keys: the signature
+// alone for a single key, so that it reads as before to every node
+func votePayload(sig *bls.Sign, pubKeys []*bls.PublicKey) []byte {
+	if len(pubKeys) == 1 {
+		return sig.Serialize()
+	}
+	buffer := bytes.Buffer{}
+	buffer.Write(sig.Serialize())
+	for _, pubKey := range pubKeys {
+		buffer.Write(pubKey.Serialize())
+	}
+	return buffer.Bytes()
+}
+
+// parseVote reads the vote in the payload of a prepare or commit message
+func parseVote(recvMsg *FBFTMessage) (*vote, error) {
+	payload := recvMsg.Payload
+	if len(payload) < shard.BLSSignatureSizeInBytes {
+		return nil, errVotePayloadSize
+	}
+	sig := &bls.Sign{}
+	if err := sig.Deserialize(payload[:shard.BLSSignatureSizeInBytes]); err != nil {
+		return nil, errors.Wrap(err, "cannot deserialize vote signature")
+	}
+	keys := payload[shard.BLSSignatureSizeInBytes:]
+	if len(keys) == 0 {
+		return &vote{sig: sig, pubKeys: []*bls.PublicKey{recvMsg.SenderPubkey}}, nil
+	}
+	if len(keys)%shard.PublicKeySizeInBytes != 0 {
+		return nil, errVotePayloadSize
+	}
+
+	pubKeys := []*bls.PublicKey{}
+	seen := map[string]struct{}{}
+	for i := 0; i < len(keys); i += shard.PublicKeySizeInBytes {
+		serialized := keys[i : i+shard.PublicKeySizeInBytes]
+		if _, ok := seen[string(serialized)]; ok {
+			return nil, errVoteDuplicate
+		}
+		seen[string(serialized)] = struct{}{}
+		pubKey := &bls.PublicKey{}
+		if err := pubKey.Deserialize(serialized); err != nil {
+			return nil, errors.Wrap(err, "cannot deserialize vote key")
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	if !pubKeys[0].IsEqual(recvMsg.SenderPubkey) {
+		return nil, errVoteSender
+	}
+	return &vote{sig: sig, pubKeys: pubKeys}, nil
+}
+
+// readVote reads the vote of a prepare or commit message, whose keys must all
+// be in the committee and, for an aggregated vote, owned by one validator and
+// sent from the aggregate vote epoch on
+func (consensus *Consensus) readVote(recvMsg *FBFTMessage) (*vote, error) {
+	v, err := parseVote(recvMsg)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.pubKeys) > 1 && !consensus.isAggregateVote() {
+		return nil, errVoteAggregated
+	}
+	for _, pubKey := range v.pubKeys {
+		if !consensus.IsValidatorInCommittee(pubKey) {
+			return nil, shard.ErrValidNotInCommittee
+		}
+	}
+	if len(v.pubKeys) > 1 {
+		owner, ok := consensus.keyOwner(v.pubKeys[0])
+		if !ok {
+			return nil, errVoteOwners
+		}
+		for _, pubKey := range v.pubKeys[1:] {
+			if keyOwner, ok := consensus.keyOwner(pubKey); !ok || keyOwner != owner {
+				return nil, errVoteOwners
+			}
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,56 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+)
+
+func TestVotePayload(test *testing.T) {
+	hash := crypto.Keccak256([]byte("block"))
+	pubKeys, priKeys := []*bls.PublicKey{}, []*bls.SecretKey{}
+	for i := 0; i < 3; i++ {
+		priKey := bls_cosi.RandPrivateKey()
+		pubKeys, priKeys = append(pubKeys, priKey.GetPublicKey()), append(priKeys, priKey)
+	}
+
+	// a single key vote reads as before
+	single := &FBFTMessage{SenderPubkey: pubKeys[0], Payload: signVote(hash, pubKeys[:1], priKeys[:1])}
+	if len(single.Payload) != 96 {
+		test.Fatalf("single key vote payload of %d bytes", len(single.Payload))
+	}
+	v, err := parseVote(single)
+	if err != nil {
+		test.Fatalf("cannot parse single key vote: %v", err)
+	}
+	if len(v.pubKeys) != 1 || !v.sig.VerifyHash(v.aggregatePubKey(), hash) {
+		test.Error("single key vote does not verify")
+	}
+
+	aggregated := &FBFTMessage{SenderPubkey: pubKeys[0], Payload: signVote(hash, pubKeys, priKeys)}
+	v, err = parseVote(aggregated)
+	if err != nil {
+		test.Fatalf("cannot parse aggregated vote: %v", err)
+	}
+	if len(v.pubKeys) != 3 || !v.sig.VerifyHash(v.aggregatePubKey(), hash) {
+		test.Error("aggregated vote does not verify")
+	}
+
+	aggregated.SenderPubkey = pubKeys[1]
+	if _, err := parseVote(aggregated); err != errVoteSender {
+		test.Errorf("vote of another sender: %v", err)
+	}
+	duplicate := &FBFTMessage{
+		SenderPubkey: pubKeys[0],
+		Payload:      votePayload(v.sig, []*bls.PublicKey{pubKeys[0], pubKeys[0]}),
+	}
+	if _, err := parseVote(duplicate); err != errVoteDuplicate {
+		test.Errorf("vote with a duplicate key: %v", err)
+	}
+	truncated := &FBFTMessage{SenderPubkey: pubKeys[0], Payload: aggregated.Payload[:100]}
+	if _, err := parseVote(truncated); err != errVotePayloadSize {
+		test.Errorf("truncated vote: %v", err)
+	}
+}
@@ -461,6 +461,99 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	return nil
 }
 
+// InsertCheckpoint makes the given trusted epoch block the head of a chain which
+// holds only the genesis block, so that the chain continues from the checkpoint
+// without the history before it. The state of the block must be in the database.
+// ancestors are the headers of the blocks right before it, parent first, kept for
+// BLOCKHASH and the randomness of the recent blocks; each one must be the parent
+// of the one before. The shard state of the block's epoch is optional, the one of
+// the next epoch is carried by the block itself. validators are the addresses of
+// all validators at the block, their snapshots are taken for the next epoch.
+func (bc *BlockChain) InsertCheckpoint(
+	block *types.Block, ancestors []*block.Header,
+	shardState *shard.State, validators []common.Address,
+) error {
+	header := block.Header()
+	if len(header.ShardState()) == 0 {
+		return errors.Errorf("checkpoint block %d is not an epoch block", block.NumberU64())
+	}
+	parentHash := header.ParentHash()
+	for _, ancestor := range ancestors {
+		if ancestor.Hash() != parentHash {
+			return errors.Errorf(
+				"checkpoint ancestor %d is not the parent of the block after it",
+				ancestor.Number().Uint64(),
+			)
+		}
+		parentHash = ancestor.ParentHash()
+	}
+	state, err := bc.StateAt(block.Root())
+	if err != nil {
+		return errors.Wrapf(err, "missing state of checkpoint block %d", block.NumberU64())
+	}
+
+	bc.wg.Add(1)
+	defer bc.wg.Done()
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if current := bc.CurrentBlock(); current.NumberU64() != 0 {
+		return errors.Errorf(
+			"cannot insert checkpoint on a non-empty chain, current block %d",
+			current.NumberU64(),
+		)
+	}
+
+	batch := bc.db.NewBatch()
+	for _, ancestor := range ancestors {
+		rawdb.WriteHeader(batch, ancestor)
+		rawdb.WriteCanonicalHash(batch, ancestor.Hash(), ancestor.Number().Uint64())
+	}
+	rawdb.WriteBlock(batch, block)
+	if shardState != nil {
+		encoded, err := shard.EncodeWrapper(*shardState, bc.chainConfig.IsStaking(header.Epoch()))
+		if err != nil {
+			return err
+		}
+		if _, err := bc.WriteShardStateBytes(batch, header.Epoch(), encoded); err != nil {
+			return err
+		}
+	}
+	nextEpoch := new(big.Int).Add(header.Epoch(), common.Big1)
+	nextShardState, err := header.GetShardState()
+	if err == nil && nextShardState.Epoch != nil && bc.chainConfig.IsStaking(nextShardState.Epoch) {
+		nextEpoch = new(big.Int).Set(nextShardState.Epoch)
+	}
+	newShardState, err := bc.WriteShardStateBytes(batch, nextEpoch, header.ShardState())
+	if err != nil {
+		return err
+	}
+	if err := bc.WriteValidatorList(batch, validators); err != nil {
+		return err
+	}
+	if err := bc.WriteElectedValidatorList(
+		batch, newShardState.StakedValidators().Addrs,
+	); err != nil {
+		return err
+	}
+	if err := bc.writeValidatorSnapshots(batch, validators, nextEpoch, state); err != nil {
+		return err
+	}
+	bc.insertWithWriter(batch, block)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	utils.Logger().Info().
+		Uint64("number", block.NumberU64()).
+		Str("hash", block.Hash().Hex()).
+		Uint64("epoch", header.Epoch().Uint64()).
+		Msg("Inserted checkpoint block")
+	return nil
+}
+
 // ShardID returns the shard Id of the blockchain.
 // TODO: use a better solution before resharding shuffle nodes to different shards
 func (bc *BlockChain) ShardID() uint32 {
@@ -910,19 +1003,30 @@ func SetReceiptsData(config *params.ChainConfig, block *types.Block, receipts ty
 	signer := types.MakeSigner(config, block.Epoch())
 
 	transactions, logIndex := block.Transactions(), uint(0)
-	if len(transactions) != len(receipts) {
+	stakingTransactions := block.StakingTransactions()
+	calls := types.CXReceiptsProofs(block.IncomingReceipts()).Calls()
+	numTxs := len(transactions) + len(stakingTransactions)
+	if len(receipts) != numTxs && len(receipts) != numTxs+len(calls) {
 		return errors.New("transaction and receipt count mismatch")
 	}
 
 	for j := 0; j < len(receipts); j++ {
-		// The transaction hash can be retrieved from the transaction itself
-		receipts[j].TxHash = transactions[j].Hash()
-
-		// The contract address can be derived from the transaction itself
-		if transactions[j].To() == nil {
-			// Deriving the signer is expensive, only do if it's actually needed
-			from, _ := types.Sender(signer, transactions[j])
-			receipts[j].ContractAddress = crypto.CreateAddress(from, transactions[j].Nonce())
+		switch {
+		case j < len(transactions):
+			// The transaction hash can be retrieved from the transaction itself
+			receipts[j].TxHash = transactions[j].Hash()
+
+			// The contract address can be derived from the transaction itself
+			if transactions[j].To() == nil {
+				// Deriving the signer is expensive, only do if it's actually needed
+				from, _ := types.Sender(signer, transactions[j])
+				receipts[j].ContractAddress = crypto.CreateAddress(from, transactions[j].Nonce())
+			}
+		case j < numTxs:
+			receipts[j].TxHash = stakingTransactions[j-len(transactions)].Hash()
+		default:
+			// Cross-shard calls are keyed by their source transaction
+			receipts[j].TxHash = calls[j-numTxs].TxHash
 		}
 		// The used gas can be calculated based on previous receipts
 		if j == 0 {
@@ -993,6 +1097,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
 		rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
 		rawdb.WriteTxLookupEntries(batch, block)
+		rawdb.WriteCXCallLookupEntries(batch, block, receipts)
 
 		stats.processed++
 
@@ -1145,6 +1250,7 @@ func (bc *BlockChain) WriteBlockWithState(
 	// Write the positional metadata for transaction/receipt lookups and preimages
 	rawdb.WriteTxLookupEntries(batch, block)
 	rawdb.WriteCxLookupEntries(batch, block)
+	rawdb.WriteCXCallLookupEntries(batch, block, receipts)
 	rawdb.WritePreimages(batch, block.NumberU64(), state.Preimages())
 
 	// Update current block
@@ -1887,6 +1993,9 @@ func (bc *BlockChain) DeleteCrossLinks(cls []types.CrossLink) error {
 	for i := 0; i < len(cls); i++ {
 		cl := cls[i]
 		err = rawdb.DeleteCrossLinkShardBlock(bc.db, cl.ShardID(), cl.BlockNum())
+		if err == nil {
+			err = rawdb.DeleteCrossLinkLookupEntry(bc.db, cl.ShardID(), cl.BlockNum())
+		}
 	}
 	return err
 }
@@ -1902,6 +2011,14 @@ func (bc *BlockChain) ReadCrossLink(shardID uint32, blockNum uint64) (*types.Cro
 	return crossLink, err
 }
 
+// ReadCrossLinkBeaconBlock retrieves the hash and number of the beacon block which
+// included the crosslink given shardID and blockNum, and its index in the block header.
+func (bc *BlockChain) ReadCrossLinkBeaconBlock(
+	shardID uint32, blockNum uint64,
+) (common.Hash, uint64, uint64) {
+	return rawdb.ReadCrossLinkLookupEntry(bc.db, shardID, blockNum)
+}
+
 // LastContinuousCrossLink saves the last crosslink of a shard
 // This function will update the latest crosslink in the sense that
 // any previous block's crosslink is received up to this point
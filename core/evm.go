@@ -53,17 +53,18 @@ func NewEVMContext(msg Message, header *block.Header, chain ChainContext, author
 		beneficiary = *author
 	}
 	return vm.Context{
-		CanTransfer: CanTransfer,
-		Transfer:    Transfer,
-		IsValidator: IsValidator,
-		GetHash:     GetHashFn(header, chain),
-		Origin:      msg.From(),
-		Coinbase:    beneficiary,
-		BlockNumber: header.Number(),
-		EpochNumber: header.Epoch(),
-		Time:        header.Time(),
-		GasLimit:    header.GasLimit(),
-		GasPrice:    new(big.Int).Set(msg.GasPrice()),
+		CanTransfer:   CanTransfer,
+		Transfer:      Transfer,
+		IsValidator:   IsValidator,
+		GetHash:       GetHashFn(header, chain),
+		GetRandomness: GetRandomnessFn(header, chain),
+		Origin:        msg.From(),
+		Coinbase:      beneficiary,
+		BlockNumber:   header.Number(),
+		EpochNumber:   header.Epoch(),
+		Time:          header.Time(),
+		GasLimit:      header.GasLimit(),
+		GasPrice:      new(big.Int).Set(msg.GasPrice()),
 	}
 }
 
@@ -93,6 +94,47 @@ func GetHashFn(ref *block.Header, chain ChainContext) func(n uint64) common.Hash
 	}
 }
 
+// GetRandomnessFn returns a GetRandomnessFunc which retrieves the VRF output
+// of the ancestors of the header by number
+func GetRandomnessFn(ref *block.Header, chain ChainContext) func(n uint64) []byte {
+	var (
+		cache map[uint64][]byte
+		// next is the highest ancestor not cached yet, where the walk resumes
+		next *block.Header
+	)
+
+	return func(n uint64) []byte {
+		// If there's no randomness cache yet, make one
+		if cache == nil {
+			cache = map[uint64][]byte{}
+			next = chain.GetHeader(ref.ParentHash(), ref.Number().Uint64()-1)
+		}
+		// Try to fulfill the request from the cache
+		if vrf, ok := cache[n]; ok {
+			return vrf
+		}
+		// Not cached, iterate the blocks from where the last walk stopped and
+		// cache their randomness
+		for next != nil && next.Number().Uint64() >= n {
+			header := next
+			var vrf []byte
+			if output := header.Vrf(); len(output) >= 32 {
+				vrf = output[:32]
+			}
+			cache[header.Number().Uint64()] = vrf
+			if header.Number().Uint64() == 0 {
+				next = nil
+			} else {
+				next = chain.GetHeader(header.ParentHash(), header.Number().Uint64()-1)
+			}
+			if header.Number().Uint64() == n {
+				return vrf
+			}
+		}
+		return nil
+	}
+}
+
 // CanTransfer checks whether there are enough funds in the address' account to make a transfer.
 // This does not take the necessary gas in to account to make the transfer valid.
 func CanTransfer(db vm.StateDB, addr common.Address, amount *big.Int) bool {
@@ -106,7 +148,8 @@ func IsValidator(db vm.StateDB, addr common.Address) bool {
 
 // Transfer subtracts amount from sender and adds amount to recipient using the given Db
 func Transfer(db vm.StateDB, sender, recipient common.Address, amount *big.Int, txType types.TransactionType) {
-	if txType == types.SameShardTx || txType == types.SubtractionOnly {
+	if txType == types.SameShardTx || txType == types.SubtractionOnly ||
+		txType == types.CrossShardCall {
 		db.SubBalance(sender, amount)
 	}
 	if txType == types.SameShardTx {
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/block"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+)
+
+// headerChain serves the headers of a chain and counts the headers read
+type headerChain struct {
+	ChainContext
+	headers map[common.Hash]*block.Header
+	reads   int
+}
+
+func (c *headerChain) GetHeader(hash common.Hash, number uint64) *block.Header {
+	c.reads++
+	return c.headers[hash]
+}
+
+// newHeaderChain makes a chain of the given length, the VRF output of each
+// block being filled with its number
+func newHeaderChain(length int) (*headerChain, *block.Header) {
+	chain := &headerChain{headers: map[common.Hash]*block.Header{}}
+	parentHash := common.Hash{}
+	var header *block.Header
+	for i := 0; i < length; i++ {
+		header = blockfactory.ForTest.NewHeader(common.Big0).With().
+			ParentHash(parentHash).
+			Number(big.NewInt(int64(i))).
+			Vrf(bytes.Repeat([]byte{byte(i)}, 32+96)).
+			Header()
+		parentHash = header.Hash()
+		chain.headers[parentHash] = header
+	}
+	return chain, header
+}
+
+func TestGetRandomnessFn(t *testing.T) {
+	chain, head := newHeaderChain(10)
+	getRandomness := GetRandomnessFn(head, chain)
+
+	tests := []struct {
+		number uint64
+		want   []byte
+		reads  int // headers read in total after the call
+	}{
+		{number: 7, want: bytes.Repeat([]byte{7}, 32), reads: 3},
+		{number: 8, want: bytes.Repeat([]byte{8}, 32), reads: 3},
+		{number: 7, want: bytes.Repeat([]byte{7}, 32), reads: 3},
+		{number: 2, want: bytes.Repeat([]byte{2}, 32), reads: 8},
+		{number: 0, want: bytes.Repeat([]byte{0}, 32), reads: 9},
+		{number: 9, want: nil, reads: 9},
+		{number: 5, want: bytes.Repeat([]byte{5}, 32), reads: 9},
+	}
+	for _, test := range tests {
+		if got := getRandomness(test.number); !bytes.Equal(got, test.want) {
+			t.Errorf("randomness of block %d is %x, want %x", test.number, got, test.want)
+		}
+		if chain.reads != test.reads {
+			t.Errorf("read %d headers after block %d, want %d", chain.reads, test.number, test.reads)
+		}
+	}
+}
@@ -57,37 +57,31 @@ func (bc *BlockChain) CommitOffChainData(
 
 	// VRF + VDF
 	// check non zero VRF field in header and add to local db
-	// if len(block.Vrf()) > 0 {
-	//	vrfBlockNumbers, _ := bc.ReadEpochVrfBlockNums(block.Header().Epoch())
-	//	if (len(vrfBlockNumbers) > 0) && (vrfBlockNumbers[len(vrfBlockNumbers)-1] == block.NumberU64()) {
-	//		utils.Logger().Error().
-	//			Str("number", block.Number().String()).
-	//			Str("epoch", block.Header().Epoch().String()).
-	//			Msg("VRF block number is already in local db")
-	//	} else {
-	//		vrfBlockNumbers = append(vrfBlockNumbers, block.NumberU64())
-	//		err = bc.WriteEpochVrfBlockNums(block.Header().Epoch(), vrfBlockNumbers)
-	//		if err != nil {
-	//			utils.Logger().Error().
-	//				Str("number", block.Number().String()).
-	//				Str("epoch", block.Header().Epoch().String()).
-	//				Msg("failed to write VRF block number to local db")
-	//			return NonStatTy, err
-	//		}
-	//	}
-	//}
-	//
-	////check non zero Vdf in header and add to local db
-	//if len(block.Vdf()) > 0 {
-	//	err = bc.WriteEpochVdfBlockNum(block.Header().Epoch(), block.Number())
-	//	if err != nil {
-	//		utils.Logger().Error().
-	//			Str("number", block.Number().String()).
-	//			Str("epoch", block.Header().Epoch().String()).
-	//			Msg("failed to write VDF block number to local db")
-	//		return NonStatTy, err
-	//	}
-	//}
+	if len(block.Vrf()) > 0 {
+		vrfBlockNumbers, _ := bc.ReadEpochVrfBlockNums(block.Header().Epoch())
+		if len(vrfBlockNumbers) == 0 || vrfBlockNumbers[len(vrfBlockNumbers)-1] != block.NumberU64() {
+			vrfBlockNumbers = append(vrfBlockNumbers, block.NumberU64())
+			if err := bc.WriteEpochVrfBlockNums(block.Header().Epoch(), vrfBlockNumbers); err != nil {
+				utils.Logger().Error().
+					Str("number", block.Number().String()).
+					Str("epoch", block.Header().Epoch().String()).
+					Msg("failed to write VRF block number to local db")
+				return NonStatTy, err
+			}
+		}
+	}
+
+	// check non zero VDF in header and add to local db, the proof of the VDF
+	// was checked by the validators of the block
+	if len(block.Vdf()) > 0 {
+		if err := bc.WriteEpochVdfBlockNum(block.Header().Epoch(), block.Number()); err != nil {
+			utils.Logger().Error().
+				Str("number", block.Number().String()).
+				Str("epoch", block.Header().Epoch().String()).
+				Msg("failed to write VDF block number to local db")
+			return NonStatTy, err
+		}
+	}
 
 	// Do bookkeeping for new staking txns
 	if err := bc.UpdateStakingMetaData(
@@ -163,7 +157,7 @@ func (bc *BlockChain) CommitOffChainData(
 				Msg("[insertChain/crosslinks] cross links are not sorted")
 			return NonStatTy, errors.New("proposed cross links are not sorted")
 		}
-		for _, crossLink := range *crossLinks {
+		for i, crossLink := range *crossLinks {
 			// Process crosslink
 			if err := bc.WriteCrossLinks(
 				batch, types.CrossLinks{crossLink},
@@ -172,6 +166,15 @@ func (bc *BlockChain) CommitOffChainData(
 					Uint64("blockNum", crossLink.BlockNum()).
 					Uint32("shardID", crossLink.ShardID()).
 					Msg("[insertChain/crosslinks] Cross Link Added to Beaconchain")
+				if err := rawdb.WriteCrossLinkLookupEntry(
+					batch, crossLink.ShardID(), crossLink.BlockNum(),
+					block.Hash(), block.NumberU64(), uint64(i),
+				); err != nil {
+					utils.Logger().Error().Err(err).
+						Uint64("blockNum", crossLink.BlockNum()).
+						Uint32("shardID", crossLink.ShardID()).
+						Msg("[insertChain/crosslinks] cannot write crosslink lookup entry")
+				}
 			}
 
 			cl0, _ := bc.ReadShardLastCrossLink(crossLink.ShardID())
@@ -137,9 +137,13 @@ func ReadStakingTransaction(db DatabaseReader, hash common.Hash) (*staking.Staki
 }
 
 // ReadReceipt retrieves a specific transaction receipt from the database, along with
-// its added positional metadata.
+// its added positional metadata.  The receipt of a cross-shard call is found by
+// the hash of its source transaction.
 func ReadReceipt(db DatabaseReader, hash common.Hash) (*types.Receipt, common.Hash, uint64, uint64) {
 	blockHash, blockNumber, receiptIndex := ReadTxLookupEntry(db, hash)
+	if blockHash == (common.Hash{}) {
+		blockHash, blockNumber, receiptIndex = ReadCXCallLookupEntry(db, hash)
+	}
 	if blockHash == (common.Hash{}) {
 		return nil, common.Hash{}, 0, 0
 	}
@@ -214,6 +218,47 @@ func DeleteCxLookupEntry(db DatabaseDeleter, hash common.Hash) {
 	db.Delete(cxLookupKey(hash))
 }
 
+// ReadCXCallLookupEntry retrieves the positional metadata of the receipt of the
+// cross-shard call run by the incoming receipt of the given source transaction
+func ReadCXCallLookupEntry(db DatabaseReader, hash common.Hash) (common.Hash, uint64, uint64) {
+	data, _ := db.Get(cxCallLookupKey(hash))
+	if len(data) == 0 {
+		return common.Hash{}, 0, 0
+	}
+	var entry TxLookupEntry
+	if err := rlp.DecodeBytes(data, &entry); err != nil {
+		utils.Logger().Error().Err(err).Str("hash", hash.Hex()).Msg("Invalid cross-shard call lookup entry RLP")
+		return common.Hash{}, 0, 0
+	}
+	return entry.BlockHash, entry.BlockIndex, entry.Index
+}
+
+// WriteCXCallLookupEntries stores a positional metadata for the receipt of every
+// cross-shard call run by the incoming receipts of a block, which follow the
+// receipts of its transactions, enabling receipt lookups by source transaction hash.
+func WriteCXCallLookupEntries(db DatabaseWriter, block *types.Block, receipts types.Receipts) {
+	numTxs := len(block.Transactions()) + len(block.StakingTransactions())
+	for i := numTxs; i < len(receipts); i++ {
+		entry := TxLookupEntry{
+			BlockHash:  block.Hash(),
+			BlockIndex: block.NumberU64(),
+			Index:      uint64(i),
+		}
+		data, err := rlp.EncodeToBytes(entry)
+		if err != nil {
+			utils.Logger().Error().Err(err).Msg("Failed to encode cross-shard call lookup entry")
+		}
+		if err := db.Put(cxCallLookupKey(receipts[i].TxHash), data); err != nil {
+			utils.Logger().Error().Err(err).Msg("Failed to store cross-shard call lookup entry")
+		}
+	}
+}
+
+// DeleteCXCallLookupEntry removes the cross-shard call lookup metadata of a source transaction hash.
+func DeleteCXCallLookupEntry(db DatabaseDeleter, hash common.Hash) {
+	db.Delete(cxCallLookupKey(hash))
+}
+
 // ReadCXReceipt retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func ReadCXReceipt(db DatabaseReader, hash common.Hash) (*types.CXReceipt, common.Hash, uint64, uint64) {
@@ -80,6 +80,48 @@ func DeleteCrossLinkShardBlock(db DatabaseDeleter, shardID uint32, blockNum uint
 	return db.Delete(crosslinkKey(shardID, blockNum))
 }
 
+// ReadCrossLinkLookupEntry retrieves the beacon block hash, number and position in
+// the block header of the crosslink for the given shardID and blockNum
+func ReadCrossLinkLookupEntry(
+	db DatabaseReader, shardID uint32, blockNum uint64,
+) (common.Hash, uint64, uint64) {
+	data, _ := db.Get(crosslinkLookupKey(shardID, blockNum))
+	if len(data) == 0 {
+		return common.Hash{}, 0, 0
+	}
+	var entry TxLookupEntry
+	if err := rlp.DecodeBytes(data, &entry); err != nil {
+		utils.Logger().Error().Err(err).
+			Uint32("shardID", shardID).
+			Uint64("blockNum", blockNum).
+			Msg("Invalid crosslink lookup entry RLP")
+		return common.Hash{}, 0, 0
+	}
+	return entry.BlockHash, entry.BlockIndex, entry.Index
+}
+
+// WriteCrossLinkLookupEntry stores the beacon block hash, number and position in
+// the block header of the crosslink for the given shardID and blockNum
+func WriteCrossLinkLookupEntry(
+	db DatabaseWriter, shardID uint32, blockNum uint64,
+	beaconHash common.Hash, beaconNum uint64, index uint64,
+) error {
+	data, err := rlp.EncodeToBytes(TxLookupEntry{
+		BlockHash:  beaconHash,
+		BlockIndex: beaconNum,
+		Index:      index,
+	})
+	if err != nil {
+		return err
+	}
+	return db.Put(crosslinkLookupKey(shardID, blockNum), data)
+}
+
+// DeleteCrossLinkLookupEntry deletes the crosslink lookup entry given shardID and blockNum
+func DeleteCrossLinkLookupEntry(db DatabaseDeleter, shardID uint32, blockNum uint64) error {
+	return db.Delete(crosslinkLookupKey(shardID, blockNum))
+}
+
 // ReadShardLastCrossLink read the last cross link of a shard
 func ReadShardLastCrossLink(db DatabaseReader, shardID uint32) ([]byte, error) {
 	return db.Get(shardLastCrosslinkKey(shardID))
@@ -50,6 +50,7 @@ var (
 
 	txLookupPrefix  = []byte("l")  // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	cxLookupPrefix  = []byte("cx") // cxLookupPrefix + hash -> cxReceipt lookup metadata
+	cxCallPrefix    = []byte("cc") // cxCallPrefix + hash -> cross-shard call receipt lookup metadata
 	bloomBitsPrefix = []byte("B")  // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
 	shardStatePrefix = []byte("ss") // shardStatePrefix + num (uint64 big endian) + hash -> shardState
@@ -62,6 +63,7 @@ var (
 
 	shardLastCrosslinkPrefix = []byte("lcl") // prefix for shard last crosslink
 	crosslinkPrefix          = []byte("cl")  // prefix for crosslink
+	crosslinkLookupPrefix    = []byte("clb") // prefix for beacon block including a crosslink
 
 	delegatorValidatorListPrefix = []byte("dvl") // prefix for delegator's validator list
 
@@ -150,6 +152,11 @@ func cxLookupKey(hash common.Hash) []byte {
 	return append(cxLookupPrefix, hash.Bytes()...)
 }
 
+// cxCallLookupKey = cxCallPrefix + hash
+func cxCallLookupKey(hash common.Hash) []byte {
+	return append(cxCallPrefix, hash.Bytes()...)
+}
+
 // bloomBitsKey = bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash
 func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	key := append(append(bloomBitsPrefix, make([]byte, 10)...), hash.Bytes()...)
@@ -202,6 +209,14 @@ func crosslinkKey(shardID uint32, blockNum uint64) []byte {
 	return key
 }
 
+// crosslinkLookupKey = crosslinkLookupPrefix + shardID + num (uint64 big endian)
+func crosslinkLookupKey(shardID uint32, blockNum uint64) []byte {
+	sbKey := make([]byte, 12)
+	binary.BigEndian.PutUint32(sbKey, shardID)
+	binary.BigEndian.PutUint64(sbKey[4:], blockNum)
+	return append(crosslinkLookupPrefix, sbKey...)
+}
+
 func delegatorValidatorListKey(delegator common.Address) []byte {
 	return append(delegatorValidatorListPrefix, delegator.Bytes()...)
 }
@@ -0,0 +1,87 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// makeSyncTestState creates a state with accounts, storage and code to sync
+func makeSyncTestState(t *testing.T) (ethdb.Database, common.Hash) {
+	db := ethdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+	for i := byte(0); i < 64; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		state.AddBalance(addr, big.NewInt(int64(11*i)))
+		state.SetNonce(addr, uint64(42*i))
+		if i%3 == 0 {
+			state.SetCode(addr, []byte{i, i, i, i})
+		}
+		for j := byte(0); j < i%4; j++ {
+			state.SetState(addr, common.BytesToHash([]byte{j}), common.BytesToHash([]byte{i, j}))
+		}
+	}
+	root, err := state.Commit(false)
+	if err != nil {
+		t.Fatalf("cannot commit state: %v", err)
+	}
+	if err := state.Database().TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("cannot commit trie: %v", err)
+	}
+	return db, root
+}
+
+func TestStateSync(t *testing.T) {
+	srcDb, root := makeSyncTestState(t)
+	srcTrieDb := trie.NewDatabase(srcDb)
+
+	dstDb := ethdb.NewMemDatabase()
+	sched := NewStateSync(root, dstDb)
+	for queue := sched.Missing(16); len(queue) > 0; queue = sched.Missing(16) {
+		results := make([]trie.SyncResult, len(queue))
+		for i, hash := range queue {
+			data, err := srcTrieDb.Node(hash)
+			if err != nil {
+				t.Fatalf("failed to retrieve node data for %x: %v", hash, err)
+			}
+			if crypto.Keccak256Hash(data) != hash {
+				t.Fatalf("node data of %x does not match its hash", hash)
+			}
+			results[i] = trie.SyncResult{Hash: hash, Data: data}
+		}
+		if _, index, err := sched.Process(results); err != nil {
+			t.Fatalf("failed to process result #%d: %v", index, err)
+		}
+		if _, err := sched.Commit(dstDb); err != nil {
+			t.Fatalf("failed to commit data: %v", err)
+		}
+	}
+
+	src, _ := New(root, NewDatabase(srcDb))
+	dst, err := New(root, NewDatabase(dstDb))
+	if err != nil {
+		t.Fatalf("cannot open synced state: %v", err)
+	}
+	for i := byte(0); i < 64; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		if src.GetBalance(addr).Cmp(dst.GetBalance(addr)) != 0 {
+			t.Errorf("account %d: balance mismatch", i)
+		}
+		if src.GetNonce(addr) != dst.GetNonce(addr) {
+			t.Errorf("account %d: nonce mismatch", i)
+		}
+		if string(src.GetCode(addr)) != string(dst.GetCode(addr)) {
+			t.Errorf("account %d: code mismatch", i)
+		}
+		for j := byte(0); j < i%4; j++ {
+			key := common.BytesToHash([]byte{j})
+			if src.GetState(addr, key) != dst.GetState(addr, key) {
+				t.Errorf("account %d: storage slot %d mismatch", i, j)
+			}
+		}
+	}
+}
@@ -120,12 +120,18 @@ func (p *StateProcessor) Process(
 	// incomingReceipts should always be processed
 	// after transactions (to be consistent with the block proposal)
 	for _, cx := range block.IncomingReceipts() {
-		if err := ApplyIncomingReceipt(
-			p.config, statedb, header, cx,
-		); err != nil {
+		cxReceipts, err := ApplyIncomingReceipt(
+			p.config, p.bc, &beneficiary, gp, statedb, header, block.Hash(),
+			len(receipts), cx, usedGas, cfg,
+		)
+		if err != nil {
 			return nil, nil,
 				nil, 0, nil, ctxerror.New("[Process] Cannot apply incoming receipts").WithCause(err)
 		}
+		for _, receipt := range cxReceipts {
+			receipts = append(receipts, receipt)
+			allLogs = append(allLogs, receipt.Logs...)
+		}
 	}
 
 	slashes := slash.Records{}
@@ -163,6 +169,10 @@ func getTransactionType(
 	if tx.ShardID() != tx.ToShardID() &&
 		header.ShardID() == tx.ShardID() &&
 		tx.ToShardID() < numShards {
+		if config.IsCrossShardCall(header.Epoch()) &&
+			len(tx.Data()) > 0 && tx.To() != nil {
+			return types.CrossShardCall
+		}
 		return types.SubtractionOnly
 	}
 	return types.InvalidTx
@@ -229,7 +239,22 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	var cxReceipt *types.CXReceipt
 	// Do not create cxReceipt if EVM call failed
 	if txType == types.SubtractionOnly && !failed {
-		cxReceipt = &types.CXReceipt{tx.Hash(), msg.From(), msg.To(), tx.ShardID(), tx.ToShardID(), msg.Value()}
+		cxReceipt = &types.CXReceipt{tx.Hash(), msg.From(), msg.To(), tx.ShardID(), tx.ToShardID(), msg.Value(), nil}
+	} else if txType == types.CrossShardCall && !failed {
+		// Forward the calldata and the gas left after the intrinsic gas
+		cxReceipt = &types.CXReceipt{
+			TxHash:    tx.Hash(),
+			From:      msg.From(),
+			To:        msg.To(),
+			ShardID:   tx.ShardID(),
+			ToShardID: tx.ToShardID(),
+			Amount:    msg.Value(),
+			Calls: []*types.CXCall{{
+				Data:     msg.Data(),
+				GasLimit: msg.Gas() - gas,
+				GasPrice: msg.GasPrice(),
+			}},
+		}
 	} else {
 		cxReceipt = nil
 	}
@@ -285,15 +310,42 @@ func ApplyStakingTransaction(
 	return receipt, gas, nil
 }
 
-// ApplyIncomingReceipt will add amount into ToAddress in the receipt
-func ApplyIncomingReceipt(config *params.ChainConfig, db *state.DB, header *block.Header, cxp *types.CXReceiptsProof) error {
+// ApplyIncomingReceipt will add amount into ToAddress in the receipt.
+// Receipts carrying a cross-shard call run it against the destination contract
+// and return one receipt per call, logs are indexed from txIndex on. Before
+// the fork of this shard, the call is dropped and its prepaid gas refunded.
+func ApplyIncomingReceipt(
+	config *params.ChainConfig, bc ChainContext, author *common.Address,
+	gp *GasPool, db *state.DB, header *block.Header, blockHash common.Hash,
+	txIndex int, cxp *types.CXReceiptsProof, usedGas *uint64, cfg vm.Config,
+) (types.Receipts, error) {
 	if cxp == nil {
-		return nil
+		return nil, nil
 	}
 
+	receipts := types.Receipts{}
 	for _, cx := range cxp.Receipts {
 		if cx == nil || cx.To == nil { // should not happend
-			return ctxerror.New("ApplyIncomingReceipts: Invalid incomingReceipt!", "receipt", cx)
+			return nil, ctxerror.New("ApplyIncomingReceipts: Invalid incomingReceipt!", "receipt", cx)
+		}
+		if call := cx.Call(); call != nil && config.IsCrossShardCall(header.Epoch()) {
+			db.Prepare(cx.TxHash, blockHash, txIndex+len(receipts))
+			receipt, err := applyCXCall(config, bc, author, gp, db, header, cx, call, usedGas, cfg)
+			if err != nil {
+				return nil, err
+			}
+			receipts = append(receipts, receipt)
+			continue
+		}
+		if call := cx.Call(); call != nil {
+			// the source shard passed the fork before this one, so the call
+			// cannot run here yet: the value is a plain transfer and the
+			// prepaid gas goes back to the sender
+			prepaid := new(big.Int).Mul(new(big.Int).SetUint64(call.GasLimit), call.GasPrice)
+			if !db.Exist(cx.From) {
+				db.CreateAccount(cx.From)
+			}
+			db.AddBalance(cx.From, prepaid)
 		}
 		utils.Logger().Info().Interface("receipt", cx).Msgf("ApplyIncomingReceipts: ADDING BALANCE %d", cx.Amount)
 
@@ -303,7 +355,64 @@ func ApplyIncomingReceipt(config *params.ChainConfig, db *state.DB, header *bloc
 		db.AddBalance(*cx.To, cx.Amount)
 		db.IntermediateRoot(config.IsS3(header.Epoch()))
 	}
-	return nil
+	return receipts, nil
+}
+
+// applyCXCall runs the contract call of an incoming cross-shard receipt.
+// The value and the prepaid gas are credited back to the sender on this shard
+// first, so that a failed call (or a call that does not fit in the block gas)
+// refunds the value, and the unused gas is refunded to the sender as for a
+// regular transaction.
+func applyCXCall(
+	config *params.ChainConfig, bc ChainContext, author *common.Address,
+	gp *GasPool, db *state.DB, header *block.Header, cx *types.CXReceipt,
+	call *types.CXCall, usedGas *uint64, cfg vm.Config,
+) (*types.Receipt, error) {
+	prepaid := new(big.Int).Mul(new(big.Int).SetUint64(call.GasLimit), call.GasPrice)
+	if !db.Exist(cx.From) {
+		db.CreateAccount(cx.From)
+	}
+	db.AddBalance(cx.From, cx.Amount)
+
+	gas, failed := uint64(0), true
+	if err := gp.SubGas(call.GasLimit); err == nil {
+		msg := types.NewMessage(
+			cx.From, cx.To, 0, cx.Amount, call.GasLimit, call.GasPrice, call.Data, false,
+		)
+		vmenv := vm.NewEVM(NewEVMContext(msg, header, bc, author), db, config, cfg)
+		_, leftOverGas, vmerr := vmenv.Call(
+			vm.AccountRef(cx.From), *cx.To, call.Data, call.GasLimit, cx.Amount,
+		)
+		if vmerr != nil {
+			utils.Logger().Debug().Err(vmerr).
+				Str("txHash", cx.TxHash.Hex()).
+				Msg("ApplyIncomingReceipts: cross-shard call failed")
+		}
+		gp.AddGas(leftOverGas)
+		gas, failed = call.GasLimit-leftOverGas, vmerr != nil
+
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(gas), call.GasPrice)
+		db.AddBalance(vmenv.Coinbase, fee)
+		prepaid.Sub(prepaid, fee)
+	}
+	db.AddBalance(cx.From, prepaid)
+
+	var root []byte
+	if config.IsS3(header.Epoch()) {
+		db.Finalise(true)
+	} else {
+		root = db.IntermediateRoot(config.IsS3(header.Epoch())).Bytes()
+	}
+	*usedGas += gas
+
+	receipt := types.NewReceipt(root, failed, *usedGas)
+	receipt.TxHash = cx.TxHash
+	receipt.GasUsed = gas
+	if config.IsReceiptLog(header.Epoch()) {
+		receipt.Logs = db.GetLogs(cx.TxHash)
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	return receipt, nil
 }
 
 // StakingToMessage returns the staking transaction as a core.Message.
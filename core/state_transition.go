@@ -235,6 +235,9 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		// error.
 		vmerr error
 	)
+	if evm.Context.TxType == types.CrossShardCall {
+		return st.transitionCrossShardCall()
+	}
 	if contractCreation {
 		ret, _, st.gas, vmerr = evm.Create(sender, st.data, st.gas, st.value)
 	} else {
@@ -259,6 +262,26 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	return ret, st.gasUsed(), vmerr != nil, err
 }
 
+// transitionCrossShardCall only moves the value out of the source shard. The
+// call itself runs on the destination shard, so the gas left after the
+// intrinsic gas stays prepaid (neither refunded nor paid to the coinbase) and
+// is forwarded with the cross-shard receipt.
+func (st *StateTransition) transitionCrossShardCall() (ret []byte, usedGas uint64, failed bool, err error) {
+	msg, evm := st.msg, st.evm
+	st.state.SetNonce(msg.From(), st.state.GetNonce(msg.From())+1)
+	if !evm.Context.CanTransfer(st.state, msg.From(), st.value) {
+		return nil, 0, false, vm.ErrInsufficientBalance
+	}
+	evm.Context.Transfer(st.state, msg.From(), st.to(), st.value, types.CrossShardCall)
+
+	// Return the prepaid gas to the block gas counter, it is spent on the destination block
+	st.gp.AddGas(st.gas)
+	used := st.gasUsed()
+	txFee := new(big.Int).Mul(new(big.Int).SetUint64(used), st.gasPrice)
+	st.state.AddBalance(evm.Coinbase, txFee)
+	return nil, used, false, nil
+}
+
 func (st *StateTransition) refundGas() {
 	// Apply refund counter, capped to half of the used gas.
 	refund := st.gasUsed() / 2
@@ -290,6 +290,9 @@ func init() {
 // The values of TxHash, UncleHash, ReceiptHash and Bloom in header
 // are ignored and set to values derived from the given txs,
 // and receipts.
+//
+// The receipts of the transactions and staking transactions are followed by
+// those of the cross-shard calls run by the incoming receipts, if they ran.
 func NewBlock(
 	header *block.Header, txs []*Transaction,
 	receipts []*Receipt, outcxs []*CXReceipt, incxs []*CXReceiptsProof,
@@ -297,11 +300,13 @@ func NewBlock(
 
 	b := &Block{header: CopyHeader(header)}
 
-	if len(receipts) != len(txs)+len(stks) {
+	numTxs, numCalls := len(txs)+len(stks), len(CXReceiptsProofs(incxs).Calls())
+	if len(receipts) != numTxs && len(receipts) != numTxs+numCalls {
 		utils.Logger().Error().
 			Int("receiptsLen", len(receipts)).
 			Int("txnsLen", len(txs)).
 			Int("stakingTxnsLen", len(stks)).
+			Int("cxCallsLen", numCalls).
 			Msg("Length of receipts doesn't match length of transactions")
 		return nil
 	}
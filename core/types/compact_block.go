@@ -0,0 +1,253 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/crypto/hash"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+// CompactBlockPrefix is the first byte of an encoded compact block.  It never
+// starts the RLP encoding of a full block, which is a list, so a receiver can
+// tell the two encodings apart.
+const CompactBlockPrefix = 0x00
+
+// ShortTxIDLength is the length of a short transaction ID.
+const ShortTxIDLength = 6
+
+// ShortTxID is a short identifier of a transaction in a compact block.  It is
+// keyed by the block hash, so a collision cannot be crafted ahead of the block.
+type ShortTxID [ShortTxIDLength]byte
+
+// NewShortTxID returns the short ID of the transaction in the block.
+func NewShortTxID(blockHash, txHash common.Hash) ShortTxID {
+	var id ShortTxID
+	copy(id[:], hash.Keccak256(blockHash[:], txHash[:]))
+	return id
+}
+
+// PrefilledTx is a transaction sent in full in a compact block.
+type PrefilledTx struct {
+	Index uint32
+	Tx    *Transaction
+}
+
+// PrefilledStakingTx is a staking transaction sent in full in a compact block.
+type PrefilledStakingTx struct {
+	Index uint32
+	Tx    *staking.StakingTransaction
+}
+
+// CompactBlock is a block whose transactions are replaced by short IDs and
+// whose staking transactions are replaced by their hashes.  The receiver
+// rebuilds the block from the transactions in its pool, and requests the
+// ones it misses from the sender.  The transactions the sender expects its
+// peers to miss are prefilled.
+type CompactBlock struct {
+	Header              *block.Header
+	Uncles              []*block.Header
+	ShortIDs            []ShortTxID
+	StakingTxHashes     []common.Hash
+	PrefilledTxs        []PrefilledTx
+	PrefilledStakingTxs []PrefilledStakingTx
+	IncomingReceipts    CXReceiptsProofs
+
+	// the transactions found so far, nil if missing
+	txs        []*Transaction
+	stakingTxs []*staking.StakingTransaction
+}
+
+// NewCompactBlock returns the compact form of the block, prefilling the
+// transactions of the hashes for which prefill returns true.  prefill may be
+// nil to prefill none.
+func NewCompactBlock(b *Block, prefill func(txHash common.Hash) bool) *CompactBlock {
+	blockHash := b.Hash()
+	cb := &CompactBlock{
+		Header:           b.Header(),
+		Uncles:           b.Uncles(),
+		ShortIDs:         make([]ShortTxID, len(b.Transactions())),
+		StakingTxHashes:  make([]common.Hash, len(b.StakingTransactions())),
+		IncomingReceipts: b.IncomingReceipts(),
+	}
+	for i, tx := range b.Transactions() {
+		txHash := tx.Hash()
+		cb.ShortIDs[i] = NewShortTxID(blockHash, txHash)
+		if prefill != nil && prefill(txHash) {
+			cb.PrefilledTxs = append(cb.PrefilledTxs, PrefilledTx{Index: uint32(i), Tx: tx})
+		}
+	}
+	for i, tx := range b.StakingTransactions() {
+		txHash := tx.Hash()
+		cb.StakingTxHashes[i] = txHash
+		if prefill != nil && prefill(txHash) {
+			cb.PrefilledStakingTxs = append(
+				cb.PrefilledStakingTxs, PrefilledStakingTx{Index: uint32(i), Tx: tx},
+			)
+		}
+	}
+	return cb
+}
+
+// EncodeCompactBlock returns the prefixed RLP encoding of the compact block.
+func EncodeCompactBlock(cb *CompactBlock) ([]byte, error) {
+	data, err := rlp.EncodeToBytes(cb)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{CompactBlockPrefix}, data...), nil
+}
+
+// IsCompactBlock returns whether the encoded block is a compact block.
+func IsCompactBlock(data []byte) bool {
+	return len(data) > 0 && data[0] == CompactBlockPrefix
+}
+
+// DecodeCompactBlock decodes a compact block encoded by EncodeCompactBlock,
+// filling in its prefilled transactions.
+func DecodeCompactBlock(data []byte) (*CompactBlock, error) {
+	if !IsCompactBlock(data) {
+		return nil, ErrNotCompactBlock
+	}
+	cb := &CompactBlock{}
+	if err := rlp.DecodeBytes(data[1:], cb); err != nil {
+		return nil, err
+	}
+	if cb.Header == nil {
+		return nil, errors.Wrap(ErrInvalidCompactBlock, "missing header")
+	}
+	cb.txs = make([]*Transaction, len(cb.ShortIDs))
+	cb.stakingTxs = make([]*staking.StakingTransaction, len(cb.StakingTxHashes))
+	for _, prefilled := range cb.PrefilledTxs {
+		if int(prefilled.Index) >= len(cb.txs) || prefilled.Tx == nil {
+			return nil, errors.Wrapf(
+				ErrInvalidCompactBlock, "prefilled transaction index %d", prefilled.Index,
+			)
+		}
+		cb.txs[prefilled.Index] = prefilled.Tx
+	}
+	for _, prefilled := range cb.PrefilledStakingTxs {
+		if int(prefilled.Index) >= len(cb.stakingTxs) || prefilled.Tx == nil {
+			return nil, errors.Wrapf(
+				ErrInvalidCompactBlock, "prefilled staking transaction index %d", prefilled.Index,
+			)
+		}
+		cb.stakingTxs[prefilled.Index] = prefilled.Tx
+	}
+	return cb, nil
+}
+
+// Hash returns the hash of the block.
+func (cb *CompactBlock) Hash() common.Hash {
+	return cb.Header.Hash()
+}
+
+// Fill fills in the missing transactions of the compact block from the given
+// pooled transactions, and returns the indexes of the transactions and of the
+// staking transactions still missing.  A short ID matching several pooled
+// transactions is left missing.
+func (cb *CompactBlock) Fill(
+	pooled []PoolTransaction,
+) (missingTxs []uint32, missingStakingTxs []uint32) {
+	blockHash := cb.Hash()
+	byShortID := make(map[ShortTxID]*Transaction, len(pooled))
+	ambiguous := map[ShortTxID]struct{}{}
+	byHash := make(map[common.Hash]*staking.StakingTransaction)
+	for _, pooledTx := range pooled {
+		switch tx := pooledTx.(type) {
+		case *Transaction:
+			id := NewShortTxID(blockHash, tx.Hash())
+			if _, ok := byShortID[id]; ok {
+				ambiguous[id] = struct{}{}
+			}
+			byShortID[id] = tx
+		case *staking.StakingTransaction:
+			byHash[tx.Hash()] = tx
+		}
+	}
+	for i, id := range cb.ShortIDs {
+		if cb.txs[i] != nil {
+			continue
+		}
+		if _, ok := ambiguous[id]; !ok {
+			cb.txs[i] = byShortID[id]
+		}
+		if cb.txs[i] == nil {
+			missingTxs = append(missingTxs, uint32(i))
+		}
+	}
+	for i, txHash := range cb.StakingTxHashes {
+		if cb.stakingTxs[i] != nil {
+			continue
+		}
+		if cb.stakingTxs[i] = byHash[txHash]; cb.stakingTxs[i] == nil {
+			missingStakingTxs = append(missingStakingTxs, uint32(i))
+		}
+	}
+	return missingTxs, missingStakingTxs
+}
+
+// FillMissing fills in the transactions of the given indexes, as returned by
+// Fill and answered by a peer holding the block.
+func (cb *CompactBlock) FillMissing(
+	txIndexes []uint32, txs []*Transaction,
+	stakingTxIndexes []uint32, stakingTxs []*staking.StakingTransaction,
+) error {
+	if len(txIndexes) != len(txs) || len(stakingTxIndexes) != len(stakingTxs) {
+		return errors.Wrapf(ErrInvalidCompactBlock,
+			"got %d/%d transactions and %d/%d staking transactions",
+			len(txs), len(txIndexes), len(stakingTxs), len(stakingTxIndexes),
+		)
+	}
+	for i, index := range txIndexes {
+		if int(index) >= len(cb.txs) || txs[i] == nil {
+			return errors.Wrapf(ErrInvalidCompactBlock, "transaction index %d", index)
+		}
+		cb.txs[index] = txs[i]
+	}
+	for i, index := range stakingTxIndexes {
+		if int(index) >= len(cb.stakingTxs) || stakingTxs[i] == nil {
+			return errors.Wrapf(ErrInvalidCompactBlock, "staking transaction index %d", index)
+		}
+		cb.stakingTxs[index] = stakingTxs[i]
+	}
+	return nil
+}
+
+// Block returns the block rebuilt from the compact block once all its
+// transactions are filled in.  The transactions are checked against the
+// transaction root of the header, which catches short ID collisions.
+func (cb *CompactBlock) Block() (*Block, error) {
+	for i, tx := range cb.txs {
+		if tx == nil {
+			return nil, errors.Wrapf(ErrIncompleteCompactBlock, "transaction %d missing", i)
+		}
+	}
+	for i, tx := range cb.stakingTxs {
+		if tx == nil {
+			return nil, errors.Wrapf(
+				ErrIncompleteCompactBlock, "staking transaction %d missing", i,
+			)
+		}
+	}
+	txRoot := EmptyRootHash
+	if len(cb.txs) > 0 || len(cb.stakingTxs) > 0 {
+		txRoot = DeriveSha(Transactions(cb.txs), staking.StakingTransactions(cb.stakingTxs))
+	}
+	if txRoot != cb.Header.TxHash() {
+		return nil, errors.Wrapf(ErrInvalidCompactBlock,
+			"transaction root mismatch: have %x, want %x", txRoot, cb.Header.TxHash(),
+		)
+	}
+	return NewBlockWithHeader(cb.Header).WithBody(
+		cb.txs, cb.stakingTxs, cb.Uncles, cb.IncomingReceipts,
+	), nil
+}
+
+// Errors of compact blocks.
+var (
+	ErrNotCompactBlock        = errors.New("not a compact block")
+	ErrInvalidCompactBlock    = errors.New("invalid compact block")
+	ErrIncompleteCompactBlock = errors.New("compact block missing transactions")
+)
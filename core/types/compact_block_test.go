@@ -0,0 +1,147 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+func newCompactTestBlock(t *testing.T, numTxs int) *Block {
+	key, _ := crypto.GenerateKey()
+	to := common.HexToAddress("0x0a")
+	txs := make([]*Transaction, numTxs)
+	for i := range txs {
+		tx, err := SignTx(
+			NewTransaction(uint64(i), to, 0, big.NewInt(1), 21000, big.NewInt(1), nil),
+			HomesteadSigner{}, key,
+		)
+		if err != nil {
+			t.Fatalf("SignTx() error = %v", err)
+		}
+		txs[i] = tx
+	}
+	stx, err := staking.NewStakingTransaction(0, 1e10, big.NewInt(1),
+		func() (staking.Directive, interface{}) {
+			return staking.DirectiveDelegate, staking.Delegate{
+				DelegatorAddress: crypto.PubkeyToAddress(key.PublicKey),
+				ValidatorAddress: to,
+				Amount:           big.NewInt(1e18),
+			}
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewStakingTransaction() error = %v", err)
+	}
+	receipts := make(Receipts, numTxs+1)
+	for i := range receipts {
+		receipts[i] = &Receipt{}
+	}
+	header := blockfactory.NewTestHeader().With().Number(big.NewInt(314)).Header()
+	return NewBlock(header, txs, receipts, nil, nil, []*staking.StakingTransaction{stx})
+}
+
+func poolOf(b *Block) []PoolTransaction {
+	pooled := []PoolTransaction{}
+	for _, tx := range b.Transactions() {
+		pooled = append(pooled, tx)
+	}
+	for _, tx := range b.StakingTransactions() {
+		pooled = append(pooled, tx)
+	}
+	return pooled
+}
+
+func encodeDecodeCompactBlock(t *testing.T, cb *CompactBlock) *CompactBlock {
+	data, err := EncodeCompactBlock(cb)
+	if err != nil {
+		t.Fatalf("EncodeCompactBlock() error = %v", err)
+	}
+	if !IsCompactBlock(data) {
+		t.Fatal("encoded compact block not recognized")
+	}
+	decoded, err := DecodeCompactBlock(data)
+	if err != nil {
+		t.Fatalf("DecodeCompactBlock() error = %v", err)
+	}
+	return decoded
+}
+
+func TestCompactBlock_Fill(t *testing.T) {
+	b := newCompactTestBlock(t, 4)
+	cb := encodeDecodeCompactBlock(t, NewCompactBlock(b, nil))
+	if cb.Hash() != b.Hash() {
+		t.Fatalf("compact block hash %x, want %x", cb.Hash(), b.Hash())
+	}
+	missing, missingStaking := cb.Fill(poolOf(b))
+	if len(missing) != 0 || len(missingStaking) != 0 {
+		t.Fatalf("missing %v and %v, want none", missing, missingStaking)
+	}
+	rebuilt, err := cb.Block()
+	if err != nil {
+		t.Fatalf("Block() error = %v", err)
+	}
+	if rebuilt.Hash() != b.Hash() || len(rebuilt.Transactions()) != 4 ||
+		len(rebuilt.StakingTransactions()) != 1 {
+		t.Errorf("rebuilt block does not match the original")
+	}
+}
+
+func TestCompactBlock_FillMissing(t *testing.T) {
+	b := newCompactTestBlock(t, 4)
+	prefilled := b.Transactions()[3].Hash()
+	cb := encodeDecodeCompactBlock(t, NewCompactBlock(b, func(txHash common.Hash) bool {
+		return txHash == prefilled
+	}))
+	// the pool lacks the first transaction and the staking transaction
+	pooled := poolOf(b)[1:3]
+	missing, missingStaking := cb.Fill(pooled)
+	if len(missing) != 1 || missing[0] != 0 || len(missingStaking) != 1 {
+		t.Fatalf("missing %v and %v, want [0] and [0]", missing, missingStaking)
+	}
+	if _, err := cb.Block(); errors.Cause(err) != ErrIncompleteCompactBlock {
+		t.Fatalf("Block() error = %v, want %v", err, ErrIncompleteCompactBlock)
+	}
+	// a wrong transaction is caught by the transaction root
+	wrong := newCompactTestBlock(t, 1).Transactions()[0]
+	if err := cb.FillMissing(
+		missing, []*Transaction{wrong}, missingStaking, b.StakingTransactions(),
+	); err != nil {
+		t.Fatalf("FillMissing() error = %v", err)
+	}
+	if _, err := cb.Block(); errors.Cause(err) != ErrInvalidCompactBlock {
+		t.Fatalf("Block() error = %v, want %v", err, ErrInvalidCompactBlock)
+	}
+	if err := cb.FillMissing(
+		missing, b.Transactions()[:1], missingStaking, b.StakingTransactions(),
+	); err != nil {
+		t.Fatalf("FillMissing() error = %v", err)
+	}
+	rebuilt, err := cb.Block()
+	if err != nil {
+		t.Fatalf("Block() error = %v", err)
+	}
+	if rebuilt.Hash() != b.Hash() {
+		t.Errorf("rebuilt block hash %x, want %x", rebuilt.Hash(), b.Hash())
+	}
+}
+
+func TestDecodeCompactBlock_Invalid(t *testing.T) {
+	b := newCompactTestBlock(t, 1)
+	cb := NewCompactBlock(b, nil)
+	cb.PrefilledTxs = []PrefilledTx{{Index: 5, Tx: b.Transactions()[0]}}
+	data, err := EncodeCompactBlock(cb)
+	if err != nil {
+		t.Fatalf("EncodeCompactBlock() error = %v", err)
+	}
+	if _, err := DecodeCompactBlock(data); errors.Cause(err) != ErrInvalidCompactBlock {
+		t.Errorf("DecodeCompactBlock() error = %v, want %v", err, ErrInvalidCompactBlock)
+	}
+	if _, err := DecodeCompactBlock(data[1:]); err != ErrNotCompactBlock {
+		t.Errorf("DecodeCompactBlock() error = %v, want %v", err, ErrNotCompactBlock)
+	}
+}
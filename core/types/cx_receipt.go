@@ -22,6 +22,17 @@ type CXReceipt struct {
 	ShardID   uint32
 	ToShardID uint32
 	Amount    *big.Int
+	// Calls holds the contract call to run on the destination shard, if any.
+	// It is a tail list of at most one element so that the encoding of
+	// plain value transfer receipts stays unchanged.
+	Calls []*CXCall `rlp:"tail"`
+}
+
+// CXCall is a contract call carried by a cross-shard receipt
+type CXCall struct {
+	Data     []byte   // calldata for the destination contract
+	GasLimit uint64   // gas prepaid on the source shard for the destination call
+	GasPrice *big.Int // price the prepaid gas was bought at
 }
 
 // Copy makes a deep copy of the receiver.
@@ -35,9 +46,27 @@ func (r *CXReceipt) Copy() *CXReceipt {
 		cpy.To = &to
 	}
 	cpy.Amount = new(big.Int).Set(cpy.Amount)
+	if len(r.Calls) > 0 {
+		cpy.Calls = make([]*CXCall, len(r.Calls))
+		for i, call := range r.Calls {
+			cpy.Calls[i] = &CXCall{
+				Data:     common.CopyBytes(call.Data),
+				GasLimit: call.GasLimit,
+				GasPrice: new(big.Int).Set(call.GasPrice),
+			}
+		}
+	}
 	return &cpy
 }
 
+// Call returns the contract call carried by the receipt, nil for a value transfer
+func (r *CXReceipt) Call() *CXCall {
+	if len(r.Calls) == 0 {
+		return nil
+	}
+	return r.Calls[0]
+}
+
 // CXReceipts is a list of CXReceipt
 type CXReceipts []*CXReceipt
 
@@ -199,6 +228,23 @@ func (cs CXReceiptsProofs) MaxToShardID() uint32 {
 	return 0
 }
 
+// Calls returns the incoming receipts carrying a contract call, in the order
+// their calls run and their receipts follow those of the block transactions
+func (cs CXReceiptsProofs) Calls() CXReceipts {
+	calls := CXReceipts{}
+	for _, cxp := range cs {
+		if cxp == nil {
+			continue
+		}
+		for _, cx := range cxp.Receipts {
+			if cx != nil && cx.Call() != nil {
+				calls = append(calls, cx)
+			}
+		}
+	}
+	return calls
+}
+
 // GetToShardID get the destination shardID, return error if there is more than one unique shardID
 func (cxp *CXReceiptsProof) GetToShardID() (uint32, error) {
 	var shardID uint32
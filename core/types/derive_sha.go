@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/pkg/errors"
 )
 
 // DerivableBase ..
@@ -42,6 +43,13 @@ type DerivableList interface {
 
 // DeriveSha calculates the hash of the trie generated by DerivableList.
 func DeriveSha(list ...DerivableBase) common.Hash {
+	trie, _ := deriveShaTrie(list...)
+	return trie.Hash()
+}
+
+// deriveShaTrie builds the trie of the items of all lists keyed by the
+// RLP encoding of their position, it also returns the number of items
+func deriveShaTrie(list ...DerivableBase) (*trie.Trie, uint) {
 	keybuf := new(bytes.Buffer)
 	trie := new(trie.Trie)
 	var num uint
@@ -54,7 +62,32 @@ func DeriveSha(list ...DerivableBase) common.Hash {
 			num++
 		}
 	}
-	return trie.Hash()
+	return trie, num
+}
+
+// shaProofList collects the encoded trie nodes of a merkle proof
+type shaProofList [][]byte
+
+func (n *shaProofList) Put(key []byte, value []byte) error {
+	*n = append(*n, value)
+	return nil
+}
+
+// DeriveShaProof returns the merkle proof of the item at the given position
+// of the trie generated by DeriveSha over the same lists. The proof is keyed
+// by the RLP encoding of the position and verifies against DeriveSha(list...).
+func DeriveShaProof(index uint, list ...DerivableBase) ([][]byte, error) {
+	trie, num := deriveShaTrie(list...)
+	if index >= num {
+		return nil, errors.Errorf("index %d out of range, %d items", index, num)
+	}
+	key, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		return nil, err
+	}
+	var proof shaProofList
+	err = trie.Prove(key, 0, &proof)
+	return [][]byte(proof), err
 }
 
 //// Legacy forked logic. Keep as is, but do not use it anymore ->
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func TestDeriveShaProof(t *testing.T) {
+	receipts := Receipts{}
+	for i := 0; i < 20; i++ {
+		receipt := NewReceipt(nil, i%2 == 0, uint64(21000*(i+1)))
+		receipt.TxHash = common.BigToHash(big.NewInt(int64(i)))
+		receipts = append(receipts, receipt)
+	}
+	root := DeriveSha(receipts)
+
+	for i := range receipts {
+		proof, err := DeriveShaProof(uint(i), receipts)
+		if err != nil {
+			t.Fatalf("receipt %d: cannot create proof: %v", i, err)
+		}
+		db := ethdb.NewMemDatabase()
+		for _, node := range proof {
+			db.Put(crypto.Keccak256(node), node)
+		}
+		key, _ := rlp.EncodeToBytes(uint(i))
+		value, _, err := trie.VerifyProof(root, key, db)
+		if err != nil {
+			t.Fatalf("receipt %d: invalid proof: %v", i, err)
+		}
+		if want := receipts.GetRlp(i); string(value) != string(want) {
+			t.Errorf("receipt %d: proven value mismatch", i)
+		}
+	}
+
+	if _, err := DeriveShaProof(uint(len(receipts)), receipts); err == nil {
+		t.Error("expected error for out of range index")
+	}
+}
@@ -55,6 +55,7 @@ const (
 	Delegate
 	Undelegate
 	CollectRewards
+	CrossShardCall // subtract tokens from source shard account and call a contract on destination shard
 )
 
 // StakingTypeMap is the map from staking type to transactionType
@@ -105,6 +106,8 @@ func (txType TransactionType) String() string {
 		return "Undelegate"
 	} else if txType == CollectRewards {
 		return "CollectRewards"
+	} else if txType == CrossShardCall {
+		return "CrossShardCall"
 	}
 	return "Unknown"
 }
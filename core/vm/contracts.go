@@ -59,6 +59,22 @@ var PrecompiledContractsByzantium = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{8}): &bn256Pairing{},
 }
 
+// BlockRandomnessAddress is the address of the precompiled contract returning
+// the VRF randomness of the recent blocks.
+var BlockRandomnessAddress = common.BytesToAddress([]byte{254})
+
+// precompiledContractsRandomness returns the pre-compiled contracts from the
+// randomness precompile epoch: the Byzantium ones and the randomness of the
+// blocks of the chain the EVM runs on.
+func precompiledContractsRandomness(evm *EVM) map[common.Address]PrecompiledContract {
+	contracts := make(map[common.Address]PrecompiledContract, len(PrecompiledContractsByzantium)+1)
+	for addr, contract := range PrecompiledContractsByzantium {
+		contracts[addr] = contract
+	}
+	contracts[BlockRandomnessAddress] = &blockRandomness{evm: evm}
+	return contracts
+}
+
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 func RunPrecompiledContract(p PrecompiledContract, input []byte, contract *Contract) (ret []byte, err error) {
 	gas := p.RequiredGas(input)
@@ -358,3 +374,33 @@ func (c *bn256Pairing) Run(input []byte) ([]byte, error) {
 	}
 	return false32Byte, nil
 }
+
+var errNoBlockRandomness = errors.New("no randomness for the block")
+
+// blockRandomness implemented as a native contract reading the chain of the EVM.
+type blockRandomness struct {
+	evm *EVM
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *blockRandomness) RequiredGas(input []byte) uint64 {
+	return params.BlockRandomnessGas
+}
+
+// Run returns the VRF output of the block whose number is the 32 byte input,
+// one of the 256 blocks before the current one like for BLOCKHASH; the
+// validators of the block checked its proof.  It fails for the other blocks
+// and the blocks without randomness, such as the blocks of the shards
+// generating none.
+func (c *blockRandomness) Run(input []byte) ([]byte, error) {
+	num := new(big.Int).SetBytes(getData(input, 0, 32))
+	lower := new(big.Int).Sub(c.evm.BlockNumber, common.Big257)
+	if num.Cmp(lower) <= 0 || num.Cmp(c.evm.BlockNumber) >= 0 || c.evm.GetRandomness == nil {
+		return nil, errNoBlockRandomness
+	}
+	randomness := c.evm.GetRandomness(num.Uint64())
+	if len(randomness) != 32 {
+		return nil, errNoBlockRandomness
+	}
+	return common.CopyBytes(randomness), nil
+}
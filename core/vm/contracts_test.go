@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/internal/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -481,3 +482,51 @@ func BenchmarkPrecompiledBn256Pairing(bench *testing.B) {
 		benchmarkPrecompiled("08", test, bench)
 	}
 }
+
+// Tests the randomness precompile returns the VRF output of the recent blocks only.
+func TestPrecompiledBlockRandomness(t *testing.T) {
+	ctx := Context{
+		BlockNumber: big.NewInt(300),
+		EpochNumber: big.NewInt(0),
+		GetRandomness: func(n uint64) []byte {
+			if n == 100 {
+				return nil
+			}
+			return common.LeftPadBytes(new(big.Int).SetUint64(n).Bytes(), 32)
+		},
+	}
+	evm := NewEVM(ctx, nil, params.TestChainConfig, Config{})
+	p := evm.precompiles[BlockRandomnessAddress]
+	if p == nil {
+		t.Fatal("randomness precompile not active")
+	}
+	for _, test := range []struct {
+		num uint64
+		ok  bool
+	}{
+		{299, true}, {44, true}, {43, false}, {300, false}, {301, false}, {100, false},
+	} {
+		in := common.LeftPadBytes(new(big.Int).SetUint64(test.num).Bytes(), 32)
+		contract := NewContract(AccountRef(common.HexToAddress("1337")),
+			nil, new(big.Int), p.RequiredGas(in))
+		res, err := RunPrecompiledContract(p, in, contract)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("block %d: expected no randomness, got %x", test.num, res)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("block %d: %v", test.num, err)
+		} else if new(big.Int).SetBytes(res).Uint64() != test.num {
+			t.Errorf("block %d: wrong randomness %x", test.num, res)
+		}
+	}
+
+	ctx.EpochNumber = big.NewInt(1)
+	config := *params.TestChainConfig
+	config.RandomnessPrecompileEpoch = big.NewInt(2)
+	if evm := NewEVM(ctx, nil, &config, Config{}); evm.precompiles[BlockRandomnessAddress] != nil {
+		t.Error("randomness precompile active before its epoch")
+	}
+}
@@ -42,16 +42,16 @@ type (
 	// GetHashFunc returns the nth block hash in the blockchain
 	// and is used by the BLOCKHASH EVM op code.
 	GetHashFunc func(uint64) common.Hash
+	// GetRandomnessFunc returns the VRF randomness of the nth block in the
+	// blockchain, nil if the block has none, and is used by the block
+	// randomness precompiled contract.
+	GetRandomnessFunc func(uint64) []byte
 )
 
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	if contract.CodeAddr != nil {
-		precompiles := PrecompiledContractsHomestead
-		if evm.ChainConfig().IsS3(evm.EpochNumber) {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if p := precompiles[*contract.CodeAddr]; p != nil {
+		if p := evm.precompiles[*contract.CodeAddr]; p != nil {
 			return RunPrecompiledContract(p, input, contract)
 		}
 	}
@@ -81,6 +81,8 @@ type Context struct {
 	Transfer TransferFunc
 	// GetHash returns the hash corresponding to n
 	GetHash GetHashFunc
+	// GetRandomness returns the VRF randomness of the block n
+	GetRandomness GetRandomnessFunc
 
 	// IsValidator determines whether the address corresponds to a validator or a smart contract
 	// true: is a validator address; false: is smart contract address
@@ -128,6 +130,8 @@ type EVM struct {
 	// used throughout the execution of the tx.
 	interpreters []Interpreter
 	interpreter  Interpreter
+	// precompiled contracts of the current epoch
+	precompiles map[common.Address]PrecompiledContract
 	// abort is used to abort the EVM calling operations
 	// NOTE: must be set atomically
 	abort int32
@@ -148,6 +152,14 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 		chainRules:   chainConfig.Rules(ctx.EpochNumber),
 		interpreters: make([]Interpreter, 0, 1),
 	}
+	switch {
+	case chainConfig.IsRandomnessPrecompile(ctx.EpochNumber):
+		evm.precompiles = precompiledContractsRandomness(evm)
+	case chainConfig.IsS3(ctx.EpochNumber):
+		evm.precompiles = PrecompiledContractsByzantium
+	default:
+		evm.precompiles = PrecompiledContractsHomestead
+	}
 
 	//if chainConfig.IsS3(ctx.EpochNumber) {
 	//	to be implemented by EVM-C and Wagon PRs.
@@ -215,11 +227,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		snapshot = evm.StateDB.Snapshot()
 	)
 	if !evm.StateDB.Exist(addr) {
-		precompiles := PrecompiledContractsHomestead
-		if evm.ChainConfig().IsS3(evm.EpochNumber) {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if precompiles[addr] == nil && evm.ChainConfig().IsS3(evm.EpochNumber) && value.Sign() == 0 {
+		if evm.precompiles[addr] == nil && evm.ChainConfig().IsS3(evm.EpochNumber) && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
 				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
@@ -0,0 +1,173 @@
+package bls
+
+import (
+	"crypto/rand"
+	"runtime"
+	"sync"
+
+	"github.com/harmony-one/bls/ffi/go/bls"
+)
+
+// minBatchSize is the smallest batch worth its own worker, smaller batches
+// spend more on the random scaling than they save on pairings
+const minBatchSize = 8
+
+// SignedHash is a signature on a hash by a public key, such as the aggregate
+// signature of a committee by the aggregate public key of its signers
+type SignedHash struct {
+	Sig    *bls.Sign
+	PubKey *bls.PublicKey
+	Hash   []byte
+}
+
+// Verify verifies the signature on its own
+func (s *SignedHash) Verify() bool {
+	if s.Sig == nil || s.PubKey == nil {
+		return false
+	}
+	return s.Sig.VerifyHash(s.PubKey, s.Hash)
+}
+
+// VerifyBatch verifies that all the signatures are valid.  Each signature and
+// the public key it is checked against are scaled by the same random factor,
+// so that invalid signatures cannot cancel out; then the sum of the signatures
+// is verified against the sum of the public keys of each hash, which takes a
+// pairing per distinct hash instead of two per signature.
+func VerifyBatch(signed []*SignedHash) bool {
+	switch len(signed) {
+	case 0:
+		return true
+	case 1:
+		return signed[0].Verify()
+	}
+	hashSize := bls.GetOpUnitSize() * 8
+	var aggSig bls.G1
+	pubKeys, hashes, index := []*bls.G2{}, [][]byte{}, map[string]int{}
+	for _, s := range signed {
+		// hashes are mapped to the curve from their first hashSize bytes in an
+		// aggregate verification
+		if s.Sig == nil || s.PubKey == nil || len(s.Hash) == 0 || len(s.Hash) > hashSize {
+			return false
+		}
+		var factor bls.Fr
+		if err := randomFactor(&factor); err != nil {
+			return false
+		}
+		var sig bls.G1
+		if err := sig.Deserialize(s.Sig.Serialize()); err != nil {
+			return false
+		}
+		bls.G1Mul(&sig, &sig, &factor)
+		bls.G1Add(&aggSig, &aggSig, &sig)
+
+		var pubKey bls.G2
+		if err := pubKey.Deserialize(s.PubKey.Serialize()); err != nil {
+			return false
+		}
+		bls.G2Mul(&pubKey, &pubKey, &factor)
+		i, ok := index[string(s.Hash)]
+		if !ok {
+			i = len(pubKeys)
+			index[string(s.Hash)] = i
+			pubKeys, hashes = append(pubKeys, &bls.G2{}), append(hashes, s.Hash)
+		}
+		bls.G2Add(pubKeys[i], pubKeys[i], &pubKey)
+	}
+
+	var sig bls.Sign
+	if err := sig.Deserialize(aggSig.Serialize()); err != nil {
+		return false
+	}
+	pubVec := make([]bls.PublicKey, len(pubKeys))
+	for i, pubKey := range pubKeys {
+		if err := pubVec[i].Deserialize(pubKey.Serialize()); err != nil {
+			return false
+		}
+	}
+	return sig.VerifyAggregateHashes(pubVec, hashes)
+}
+
+// randomFactor sets the factor to a random non-zero 64-bit number
+func randomFactor(factor *bls.Fr) error {
+	buf := make([]byte, 8)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		if err := factor.SetLittleEndian(buf); err != nil {
+			return err
+		}
+		if !factor.IsZero() {
+			return nil
+		}
+	}
+}
+
+// BatchVerifier verifies signatures in batches on a pool of workers shared by
+// its callers.  A batch which fails is verified one signature at a time to
+// tell the invalid signatures apart.
+type BatchVerifier struct {
+	workers chan struct{}
+}
+
+// NewBatchVerifier returns a batch verifier running at most the given number
+// of verifications at once
+func NewBatchVerifier(workers int) *BatchVerifier {
+	if workers < 1 {
+		workers = 1
+	}
+	return &BatchVerifier{workers: make(chan struct{}, workers)}
+}
+
+var (
+	defaultVerifier     *BatchVerifier
+	defaultVerifierOnce sync.Once
+)
+
+// DefaultBatchVerifier returns the batch verifier with a worker per CPU
+func DefaultBatchVerifier() *BatchVerifier {
+	defaultVerifierOnce.Do(func() {
+		defaultVerifier = NewBatchVerifier(runtime.NumCPU())
+	})
+	return defaultVerifier
+}
+
+// Verify verifies the signatures, split in batches among the workers, and
+// returns whether each of them is valid
+func (v *BatchVerifier) Verify(signed []*SignedHash) []bool {
+	valid := make([]bool, len(signed))
+	size := (len(signed) + cap(v.workers) - 1) / cap(v.workers)
+	if size < minBatchSize {
+		size = minBatchSize
+	}
+	var wg sync.WaitGroup
+	for start := 0; start < len(signed); start += size {
+		end := start + size
+		if end > len(signed) {
+			end = len(signed)
+		}
+		v.workers <- struct{}{}
+		wg.Add(1)
+		go func(signed []*SignedHash, valid []bool) {
+			defer func() {
+				<-v.workers
+				wg.Done()
+			}()
+			verifyBatch(signed, valid)
+		}(signed[start:end], valid[start:end])
+	}
+	wg.Wait()
+	return valid
+}
+
+func verifyBatch(signed []*SignedHash, valid []bool) {
+	if len(signed) > 1 && VerifyBatch(signed) {
+		for i := range valid {
+			valid[i] = true
+		}
+		return
+	}
+	for i, s := range signed {
+		valid[i] = s.Verify()
+	}
+}
@@ -0,0 +1,63 @@
+package bls
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestSignedHashes(n int, hashes ...[]byte) []*SignedHash {
+	signed := []*SignedHash{}
+	for i := 0; i < n; i++ {
+		key := RandPrivateKey()
+		hash := hashes[i%len(hashes)]
+		signed = append(signed, &SignedHash{
+			Sig: key.SignHash(hash), PubKey: key.GetPublicKey(), Hash: hash,
+		})
+	}
+	return signed
+}
+
+func TestVerifyBatch(test *testing.T) {
+	same := newTestSignedHashes(5, crypto.Keccak256([]byte("block")))
+	if !VerifyBatch(same) {
+		test.Error("signatures of the same hash failed to verify")
+	}
+	distinct := newTestSignedHashes(5,
+		crypto.Keccak256([]byte("a")), crypto.Keccak256([]byte("b")), append(make([]byte, 8), crypto.Keccak256([]byte("c"))...),
+	)
+	if !VerifyBatch(distinct) {
+		test.Error("signatures of distinct hashes failed to verify")
+	}
+
+	// a signature on another hash
+	distinct[2].Sig = distinct[3].Sig
+	if VerifyBatch(distinct) {
+		test.Error("batch with an invalid signature verified")
+	}
+}
+
+func TestVerifyBatchCancellingSignatures(test *testing.T) {
+	signed := newTestSignedHashes(2, crypto.Keccak256([]byte("block")))
+	// the sum of the two signatures is still valid for the sum of the keys
+	first, second := *signed[0].Sig, *signed[1].Sig
+	signed[0].Sig, signed[1].Sig = &second, &first
+	if VerifyBatch(signed) {
+		test.Error("swapped signatures verified")
+	}
+}
+
+func TestBatchVerifier(test *testing.T) {
+	signed := newTestSignedHashes(3*minBatchSize, crypto.Keccak256([]byte("block")))
+	invalid := map[int]bool{1: true, 2*minBatchSize + 3: true}
+	for i := range invalid {
+		signed[i].Hash = crypto.Keccak256([]byte("other block"))
+	}
+
+	valid := NewBatchVerifier(2).Verify(signed)
+	for i, ok := range valid {
+		if ok == invalid[i] {
+			test.Errorf("signature %d valid = %v", i, ok)
+		}
+	}
+}
@@ -38,6 +38,16 @@ func AggregateSig(sigs []*bls.Sign) *bls.Sign {
 	return &aggregatedSig
 }
 
+// AggregatePubKeys aggregates the BLS public keys into the key verifying their
+// multi-signature.
+func AggregatePubKeys(pubKeys []*bls.PublicKey) *bls.PublicKey {
+	var aggregatedPubKey bls.PublicKey
+	for _, pubKey := range pubKeys {
+		aggregatedPubKey.Add(pubKey)
+	}
+	return &aggregatedPubKey
+}
+
 // Mask represents a cosigning participation bitmask.
 type Mask struct {
 	Bitmap          []byte
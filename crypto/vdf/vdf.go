@@ -1,17 +1,62 @@
-// Package vdf is a proof-of-concept implementation of a delay function
-// and the security properties are not guaranteed.
-// A more secure implementation of the VDF by Wesolowski (https://eprint.iacr.org/2018/623.pdf)
-// will be done soon.
+// Package vdf implements the verifiable delay function of Wesolowski
+// (https://eprint.iacr.org/2018/623.pdf) over the class group of binary
+// quadratic forms with a 2048 bit discriminant derived from the input, which
+// needs no trusted setup.  Evaluating the function takes difficulty sequential
+// squarings in the group, while checking its proof takes two exponentiations,
+// so verifiers do not rerun the delay.
 package vdf
 
-import "golang.org/x/crypto/sha3"
+import (
+	"github.com/harmony-one/vdf/src/vdf_go"
+	"golang.org/x/crypto/sha3"
+)
 
-// VDF is the struct holding necessary state for a hash chain delay function.
+const (
+	// sizeInBits is the size of the discriminant of the class group
+	sizeInBits = 2048
+
+	// ElementSize is the size of a serialized element of the class group
+	ElementSize = 258
+	// Size is the size of the output of the VDF followed by its proof
+	Size = 2 * ElementSize
+)
+
+// Prove evaluates the VDF on the input, which takes difficulty sequential
+// squarings, and returns the output followed by the proof of the evaluation.
+func Prove(input [32]byte, difficulty int) [Size]byte {
+	output := [Size]byte{}
+	y, proof := vdf_go.GenerateVDF(input[:], difficulty, sizeInBits)
+	copy(output[:ElementSize], y)
+	copy(output[ElementSize:], proof)
+	return output
+}
+
+// Verify checks that the output is the evaluation of the VDF on the input
+// with the difficulty, by its proof.
+func Verify(input [32]byte, difficulty int, output [Size]byte) (ok bool) {
+	// the class group library panics on some malformed elements, such as a
+	// form with a zero coefficient
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return vdf_go.VerifyVDF(input[:], output[:], difficulty, sizeInBits)
+}
+
+// Randomness returns the randomness of the output of the VDF, the hash of the
+// result element without the proof.  The result is unique for the input, so
+// is the randomness.
+func Randomness(output [Size]byte) [32]byte {
+	return sha3.Sum256(output[:ElementSize])
+}
+
+// VDF is the struct holding necessary state for evaluating the delay function.
 type VDF struct {
 	difficulty int
 	input      [32]byte
-	output     [32]byte
-	outputChan chan [32]byte
+	output     [Size]byte
+	outputChan chan [Size]byte
 	finished   bool
 }
 
@@ -20,23 +65,20 @@ func New(difficulty int, input [32]byte) *VDF {
 	return &VDF{
 		difficulty: difficulty,
 		input:      input,
-		outputChan: make(chan [32]byte),
+		outputChan: make(chan [Size]byte),
 	}
 }
 
-// GetOutputChannel returns the vdf output channel.
-func (vdf *VDF) GetOutputChannel() chan [32]byte {
+// GetOutputChannel returns the vdf output channel.  The output is followed by
+// its proof, see Prove.
+func (vdf *VDF) GetOutputChannel() chan [Size]byte {
 	return vdf.outputChan
 }
 
 // Execute runs the VDF until it's finished and put the result into output channel.
 func (vdf *VDF) Execute() {
 	vdf.finished = false
-	tempResult := vdf.input
-	for i := 0; i < vdf.difficulty; i++ {
-		tempResult = sha3.Sum256(tempResult[:])
-	}
-	vdf.output = tempResult
+	vdf.output = Prove(vdf.input, vdf.difficulty)
 	// TODO ek – limit concurrency
 	go func() {
 		vdf.outputChan <- vdf.output
@@ -44,12 +86,17 @@ func (vdf *VDF) Execute() {
 	vdf.finished = true
 }
 
+// Verify checks the output and proof of the VDF on its input.
+func (vdf *VDF) Verify(output [Size]byte) bool {
+	return Verify(vdf.input, vdf.difficulty, output)
+}
+
 // IsFinished returns whether the vdf execution is finished or not.
 func (vdf *VDF) IsFinished() bool {
 	return vdf.finished
 }
 
 // GetOutput returns the vdf output, which can be bytes of 0s is the vdf is not finished.
-func (vdf *VDF) GetOutput() [32]byte {
+func (vdf *VDF) GetOutput() [Size]byte {
 	return vdf.output
 }
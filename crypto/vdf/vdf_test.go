@@ -0,0 +1,47 @@
+package vdf
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+func TestProveVerify(t *testing.T) {
+	input := sha3.Sum256([]byte("vrf"))
+	output := Prove(input, 100)
+	if !Verify(input, 100, output) {
+		t.Fatal("VDF output does not verify")
+	}
+	if Verify(input, 101, output) {
+		t.Error("VDF output verifies with another difficulty")
+	}
+	if Verify(sha3.Sum256([]byte("other")), 100, output) {
+		t.Error("VDF output verifies with another input")
+	}
+
+	tampered := output
+	tampered[ElementSize-1] ^= 1
+	if Verify(input, 100, tampered) {
+		t.Error("tampered VDF output verifies")
+	}
+	if Verify(input, 100, [Size]byte{}) {
+		t.Error("empty VDF output verifies")
+	}
+	if Randomness(output) != Randomness(Prove(input, 100)) {
+		t.Error("randomness of the input is not unique")
+	}
+}
+
+func TestExecute(t *testing.T) {
+	input := sha3.Sum256([]byte("vrf"))
+	vdf := New(100, input)
+	outputChannel := vdf.GetOutputChannel()
+	vdf.Execute()
+	output := <-outputChannel
+	if !vdf.IsFinished() || output != vdf.GetOutput() {
+		t.Fatal("VDF not finished after execution")
+	}
+	if !vdf.Verify(output) {
+		t.Error("VDF output does not verify")
+	}
+}
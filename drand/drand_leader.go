@@ -18,7 +18,7 @@ import (
 )
 
 const (
-	vdfDifficulty = 5000000 // This takes about 20s to finish the vdf
+	vdfDifficulty = 10000 // The class group VDF takes about 35s to prove on one core
 )
 
 // WaitForEpochBlock waits for the first epoch block to run DRG on
@@ -40,16 +40,14 @@ func (dRand *DRand) WaitForEpochBlock(blockChannel chan *types.Block, stopChan c
 					// The epoch block should contain the randomness preimage pRnd
 					// TODO ek – limit concurrency
 					go func() {
-						vdf := vdf.New(vdfDifficulty, pRnd)
-						outputChannel := vdf.GetOutputChannel()
 						start := time.Now()
-						vdf.Execute()
+						output := vdf.Prove(pRnd, vdfDifficulty)
 						duration := time.Now().Sub(start)
 						utils.Logger().Info().Dur("duration", duration).Msg("VDF computation finished")
-						output := <-outputChannel
+						rnd := vdf.Randomness(output)
 
 						rndBytes := [64]byte{} // The first 32 bytes are the randomness and the last 32 bytes are the hash of the block where the corresponding pRnd was generated
-						copy(rndBytes[:32], output[:])
+						copy(rndBytes[:32], rnd[:])
 
 						blockHash := newBlock.Hash()
 						copy(rndBytes[32:], blockHash[:])
@@ -14,13 +14,16 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/harmony-one/harmony/accounts"
 	"github.com/harmony-one/harmony/api/proto"
+	"github.com/harmony-one/harmony/api/service/syncing"
 	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/core/vm"
 	internal_common "github.com/harmony-one/harmony/internal/common"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
 	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
@@ -210,6 +213,11 @@ func (b *APIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscripti
 	return b.hmy.BlockChain().SubscribeLogsEvent(ch)
 }
 
+// SubscribeSyncEvent subscribes the sync events of the shard chain and the beacon chain.
+func (b *APIBackend) SubscribeSyncEvent(ch chan<- syncing.SyncEvent) event.Subscription {
+	return b.hmy.nodeAPI.SubscribeSyncEvent(ch)
+}
+
 // GetPoolTransactions returns pool transactions.
 // TODO: this is not implemented or verified yet for harmony.
 func (b *APIBackend) GetPoolTransactions() (types.PoolTransactions, error) {
@@ -500,6 +508,21 @@ func (b *APIBackend) GetPendingCXReceipts() []*types.CXReceiptsProof {
 	return b.hmy.nodeAPI.PendingCXReceipts()
 }
 
+// GetSyncProgress returns the progress of syncing the shard chain, or the beacon chain
+func (b *APIBackend) GetSyncProgress(isBeacon bool) syncing.Progress {
+	return b.hmy.nodeAPI.SyncProgress(isBeacon)
+}
+
+// GetConsensusTimings returns the timing of the consensus phases of the last blocks
+func (b *APIBackend) GetConsensusTimings() []consensus.BlockTiming {
+	return b.hmy.nodeAPI.ConsensusTimings()
+}
+
+// GetConsensusTimeouts returns the durations of the consensus and view change timeouts
+func (b *APIBackend) GetConsensusTimeouts() shardingconfig.ConsensusTimeouts {
+	return b.hmy.nodeAPI.ConsensusTimeouts()
+}
+
 // GetCurrentUtilityMetrics ..
 func (b *APIBackend) GetCurrentUtilityMetrics() (*network.UtilityMetric, error) {
 	return network.NewUtilityMetricSnapshot(b.hmy.BlockChain())
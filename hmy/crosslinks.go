@@ -0,0 +1,114 @@
+package hmy
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxCrossLinksRange is the max number of shard blocks in a crosslink range query
+	maxCrossLinksRange = 1000
+)
+
+var (
+	errInvalidCrossLinksRange = errors.New("invalid crosslinks block range")
+)
+
+// CrossLinkInclusion is a crosslink along with the beacon block which included it
+type CrossLinkInclusion struct {
+	CrossLink   *types.CrossLink
+	BlockNumber uint64
+	BlockHash   common.Hash
+	// Index is the position of the crosslink in the beacon block header
+	Index uint64
+}
+
+// PendingCrossLink is a crosslink waiting to be included in a beacon block
+type PendingCrossLink struct {
+	CrossLink types.CrossLink
+	// EpochAge is the number of beacon epochs since the epoch of the crosslink
+	EpochAge uint64
+	// BlocksAhead is the number of shard blocks between the crosslink and the
+	// last continuous crosslink of its shard
+	BlocksAhead uint64
+}
+
+// GetCrossLinks returns the crosslinks of a shard for the blocks in [fromBlock, toBlock],
+// shard blocks without crosslink on the beacon chain are skipped
+func (b *APIBackend) GetCrossLinks(
+	ctx context.Context, shardID uint32, fromBlock, toBlock uint64,
+) ([]*types.CrossLink, error) {
+	if toBlock < fromBlock || toBlock-fromBlock >= maxCrossLinksRange {
+		return nil, errors.Wrapf(
+			errInvalidCrossLinksRange,
+			"from %d to %d, max range %d", fromBlock, toBlock, maxCrossLinksRange,
+		)
+	}
+	crossLinks := []*types.CrossLink{}
+	for num := fromBlock; num <= toBlock; num++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		link, err := b.hmy.BlockChain().ReadCrossLink(shardID, num)
+		if err != nil || link == nil {
+			continue
+		}
+		crossLinks = append(crossLinks, link)
+	}
+	return crossLinks, nil
+}
+
+// GetCrossLinkInclusion returns the crosslink of a shard block along with the
+// beacon block which included it, nil if the shard block is not crosslinked
+func (b *APIBackend) GetCrossLinkInclusion(
+	ctx context.Context, shardID uint32, blockNum uint64,
+) (*CrossLinkInclusion, error) {
+	link, err := b.hmy.BlockChain().ReadCrossLink(shardID, blockNum)
+	if err != nil || link == nil {
+		return nil, nil
+	}
+	inclusion := &CrossLinkInclusion{CrossLink: link}
+	blockHash, blockNumber, index := b.hmy.BlockChain().ReadCrossLinkBeaconBlock(
+		shardID, blockNum,
+	)
+	if blockHash == (common.Hash{}) {
+		// Crosslinks committed before the lookup index was written
+		return inclusion, nil
+	}
+	inclusion.BlockHash, inclusion.BlockNumber, inclusion.Index = blockHash, blockNumber, index
+	return inclusion, nil
+}
+
+// GetPendingCrossLinks returns the crosslinks waiting to be included in a beacon block
+func (b *APIBackend) GetPendingCrossLinks() ([]PendingCrossLink, error) {
+	cls, err := b.hmy.BlockChain().ReadPendingCrossLinks()
+	if err != nil {
+		return nil, err
+	}
+	epoch := b.CurrentBlock().Epoch()
+	lastBlocks := map[uint32]uint64{}
+	pending := make([]PendingCrossLink, 0, len(cls))
+	for _, cl := range cls {
+		entry := PendingCrossLink{CrossLink: cl}
+		if cl.Epoch() != nil && epoch.Cmp(cl.Epoch()) > 0 {
+			entry.EpochAge = epoch.Uint64() - cl.Epoch().Uint64()
+		}
+		last, ok := lastBlocks[cl.ShardID()]
+		if !ok {
+			if link, err := b.hmy.BlockChain().ReadShardLastCrossLink(
+				cl.ShardID(),
+			); err == nil && link != nil {
+				last = link.BlockNum()
+			}
+			lastBlocks[cl.ShardID()] = last
+		}
+		if cl.BlockNum() > last {
+			entry.BlocksAhead = cl.BlockNum() - last
+		}
+		pending = append(pending, entry)
+	}
+	return pending, nil
+}
@@ -0,0 +1,156 @@
+package hmy
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+var (
+	errNotCrossShardTx = errors.New("transaction is not a cross-shard transaction")
+)
+
+// CXTransferStage is the furthest step of its lifecycle a cross-shard
+// transfer is known to have reached, as seen from the local node
+type CXTransferStage byte
+
+const (
+	// CXTransferUnknown means the transfer is not known to this node
+	CXTransferUnknown CXTransferStage = iota
+	// CXTransferSent means the transaction is committed on the source shard
+	// and its outgoing receipt is created
+	CXTransferSent
+	// CXTransferCrossLinked means the source block is crosslinked on the beacon chain
+	CXTransferCrossLinked
+	// CXTransferPending means the receipts proof was received by the destination
+	// shard and waits to be included in a block
+	CXTransferPending
+	// CXTransferApplied means the receipts proof is spent on the destination shard
+	// and the destination account is credited
+	CXTransferApplied
+)
+
+func (s CXTransferStage) String() string {
+	switch s {
+	case CXTransferSent:
+		return "sent"
+	case CXTransferCrossLinked:
+		return "crosslinked"
+	case CXTransferPending:
+		return "pending"
+	case CXTransferApplied:
+		return "applied"
+	}
+	return "unknown"
+}
+
+// CXBlockRef points to a block on a given shard
+type CXBlockRef struct {
+	ShardID     uint32
+	BlockNumber uint64
+	BlockHash   common.Hash
+}
+
+// CXTransferStatus is the lifecycle of a cross-shard transfer across shards.
+// A node only holds its own shard chain and the beacon chain, so Source is known
+// on the source shard (and on the destination shard once the proof arrived),
+// Destination only on the destination shard and CrossLink on every node.
+type CXTransferStatus struct {
+	TxHash  common.Hash
+	Stage   CXTransferStage
+	Receipt *types.CXReceipt
+	// Source is the source shard block which committed the transaction
+	Source *CXBlockRef
+	// CrossLink is the beacon block which included the crosslink of the source
+	// block, nil if not yet crosslinked, if the crosslink predates the crosslink
+	// lookup index or if the source is the beacon chain itself
+	CrossLink *CXBlockRef
+	// Destination is the destination shard block which spent the receipts proof
+	Destination *CXBlockRef
+}
+
+// GetCXTransferStatus reports the lifecycle of the cross-shard transfer
+// created by the source transaction with the given hash
+func (b *APIBackend) GetCXTransferStatus(
+	ctx context.Context, txHash common.Hash,
+) (*CXTransferStatus, error) {
+	status := &CXTransferStatus{TxHash: txHash, Stage: CXTransferUnknown}
+
+	// Source shard: the transaction and its outgoing receipt
+	if tx, blockHash, blockNum, _ := rawdb.ReadTransaction(b.hmy.chainDb, txHash); tx != nil {
+		if tx.ShardID() == tx.ToShardID() {
+			return nil, errors.Wrapf(errNotCrossShardTx, "hash %s", txHash.Hex())
+		}
+		status.Stage = CXTransferSent
+		status.Source = &CXBlockRef{tx.ShardID(), blockNum, blockHash}
+		cxs, _ := b.hmy.BlockChain().ReadCXReceipts(tx.ToShardID(), blockNum, blockHash)
+		for _, cx := range cxs {
+			if cx.TxHash == txHash {
+				status.Receipt = cx
+				break
+			}
+		}
+	}
+
+	// Destination shard: spent proof, or proof waiting in the pending pool
+	if cx, blockHash, blockNum, _ := rawdb.ReadCXReceipt(b.hmy.chainDb, txHash); cx != nil {
+		status.Stage = CXTransferApplied
+		status.Receipt = cx
+		status.Destination = &CXBlockRef{cx.ToShardID, blockNum, blockHash}
+		if blk := b.hmy.BlockChain().GetBlockByHash(blockHash); blk != nil {
+			status.setSourceFromProofs(blk.IncomingReceipts())
+		}
+	} else if status.setSourceFromProofs(b.hmy.nodeAPI.PendingCXReceipts()) {
+		status.Stage = CXTransferPending
+	}
+
+	if status.Source == nil || status.Source.ShardID == shard.BeaconChainShardID {
+		return status, nil
+	}
+
+	// Beacon chain: block which included the crosslink of the source block
+	beacon := b.hmy.BeaconChain()
+	cl, err := beacon.ReadCrossLink(status.Source.ShardID, status.Source.BlockNumber)
+	if err != nil || cl == nil || cl.Hash() != status.Source.BlockHash {
+		return status, nil
+	}
+	if status.Stage == CXTransferSent {
+		status.Stage = CXTransferCrossLinked
+	}
+	blockHash, blockNum, _ := beacon.ReadCrossLinkBeaconBlock(
+		status.Source.ShardID, status.Source.BlockNumber,
+	)
+	if blockHash != (common.Hash{}) {
+		status.CrossLink = &CXBlockRef{shard.BeaconChainShardID, blockNum, blockHash}
+	}
+	return status, nil
+}
+
+// setSourceFromProofs looks for the receipt of the transfer in the given proofs
+// and fills in the source block from its merkle proof, returns whether found
+func (s *CXTransferStatus) setSourceFromProofs(cxps []*types.CXReceiptsProof) bool {
+	for _, cxp := range cxps {
+		if cxp == nil || cxp.MerkleProof == nil {
+			continue
+		}
+		for _, cx := range cxp.Receipts {
+			if cx.TxHash != s.TxHash {
+				continue
+			}
+			s.Receipt = cx
+			if s.Source == nil {
+				s.Source = &CXBlockRef{
+					cxp.MerkleProof.ShardID,
+					cxp.MerkleProof.BlockNum.Uint64(),
+					cxp.MerkleProof.BlockHash,
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
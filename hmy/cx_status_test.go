@@ -0,0 +1,197 @@
+package hmy
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/core/vm"
+	"github.com/harmony-one/harmony/internal/chain"
+	"github.com/harmony-one/harmony/internal/params"
+)
+
+// pendingNodeAPI serves the receipts proofs waiting in the pending pool
+type pendingNodeAPI struct {
+	NodeAPI
+	pending []*types.CXReceiptsProof
+}
+
+func (n *pendingNodeAPI) PendingCXReceipts() []*types.CXReceiptsProof {
+	return n.pending
+}
+
+func TestGetCXTransferStatus(t *testing.T) {
+	const (
+		sourceShard, sourceNum = uint32(1), uint64(5)
+		destShard, destNum     = uint32(2), uint64(9)
+		beaconNum              = uint64(40)
+	)
+	to := common.HexToAddress("0x1234")
+	tx := types.NewCrossShardTransaction(
+		0, &to, sourceShard, destShard, big.NewInt(100), params.TxGas, big.NewInt(1), nil,
+	)
+	cx := &types.CXReceipt{
+		TxHash:    tx.Hash(),
+		To:        &to,
+		ShardID:   sourceShard,
+		ToShardID: destShard,
+		Amount:    big.NewInt(100),
+	}
+	sourceBlock := types.NewBlockWithHeader(
+		blockfactory.ForTest.NewHeader(big.NewInt(0)).With().
+			ShardID(sourceShard).Number(new(big.Int).SetUint64(sourceNum)).Header(),
+	).WithBody(types.Transactions{tx}, nil, nil, nil)
+	proof := &types.CXReceiptsProof{
+		Receipts: types.CXReceipts{cx},
+		MerkleProof: &types.CXMerkleProof{
+			BlockNum:  new(big.Int).SetUint64(sourceNum),
+			BlockHash: sourceBlock.Hash(),
+			ShardID:   sourceShard,
+		},
+		Header: sourceBlock.Header(),
+	}
+	destBlock := types.NewBlockWithHeader(
+		blockfactory.ForTest.NewHeader(big.NewInt(0)).With().
+			ShardID(destShard).Number(new(big.Int).SetUint64(destNum)).Header(),
+	).WithBody(nil, nil, nil, types.CXReceiptsProofs{proof})
+	crossLink := types.CrossLink{
+		HashF:        sourceBlock.Hash(),
+		BlockNumberF: new(big.Int).SetUint64(sourceNum),
+		ShardIDF:     sourceShard,
+		EpochF:       big.NewInt(0),
+	}
+	beaconHash := common.HexToHash("0xbeac0")
+
+	sourceRef := &CXBlockRef{sourceShard, sourceNum, sourceBlock.Hash()}
+	crossLinkRef := &CXBlockRef{0, beaconNum, beaconHash}
+	destRef := &CXBlockRef{destShard, destNum, destBlock.Hash()}
+
+	tests := []struct {
+		name string
+		// source writes the source block, its transaction and outgoing receipts
+		source bool
+		// crossLinked writes the crosslink of the source block, and lookup
+		// its beacon inclusion block
+		crossLinked, lookup bool
+		// pending puts the receipts proof in the pending pool
+		pending bool
+		// delivered writes the destination block which spent the proof
+		delivered bool
+
+		wantStage       CXTransferStage
+		wantSource      *CXBlockRef
+		wantCrossLink   *CXBlockRef
+		wantDestination *CXBlockRef
+	}{
+		{
+			name:      "unknown",
+			wantStage: CXTransferUnknown,
+		},
+		{
+			name:       "source only",
+			source:     true,
+			wantStage:  CXTransferSent,
+			wantSource: sourceRef,
+		},
+		{
+			name:          "crosslinked",
+			source:        true,
+			crossLinked:   true,
+			lookup:        true,
+			wantStage:     CXTransferCrossLinked,
+			wantSource:    sourceRef,
+			wantCrossLink: crossLinkRef,
+		},
+		{
+			name:        "crosslinked before the lookup index",
+			source:      true,
+			crossLinked: true,
+			wantStage:   CXTransferCrossLinked,
+			wantSource:  sourceRef,
+		},
+		{
+			name:          "pending",
+			crossLinked:   true,
+			lookup:        true,
+			pending:       true,
+			wantStage:     CXTransferPending,
+			wantSource:    sourceRef,
+			wantCrossLink: crossLinkRef,
+		},
+		{
+			name:            "delivered",
+			crossLinked:     true,
+			lookup:          true,
+			delivered:       true,
+			wantStage:       CXTransferApplied,
+			wantSource:      sourceRef,
+			wantCrossLink:   crossLinkRef,
+			wantDestination: destRef,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := ethdb.NewMemDatabase()
+			gspec := core.Genesis{Config: params.TestChainConfig, Factory: blockfactory.ForTest}
+			gspec.MustCommit(db)
+			bc, err := core.NewBlockChain(db, nil, gspec.Config, chain.Engine, vm.Config{}, nil)
+			if err != nil {
+				t.Fatalf("cannot create blockchain: %v", err)
+			}
+			nodeAPI := &pendingNodeAPI{}
+			b := &APIBackend{hmy: &Harmony{
+				blockchain:  bc,
+				beaconchain: bc,
+				chainDb:     db,
+				nodeAPI:     nodeAPI,
+			}}
+
+			if test.source {
+				rawdb.WriteBlock(db, sourceBlock)
+				rawdb.WriteTxLookupEntries(db, sourceBlock)
+				rawdb.WriteCXReceipts(db, destShard, sourceNum, sourceBlock.Hash(), types.CXReceipts{cx})
+			}
+			if test.crossLinked {
+				rawdb.WriteCrossLinkShardBlock(db, sourceShard, sourceNum, crossLink.Serialize())
+			}
+			if test.lookup {
+				rawdb.WriteCrossLinkLookupEntry(db, sourceShard, sourceNum, beaconHash, beaconNum, 0)
+			}
+			if test.pending {
+				nodeAPI.pending = []*types.CXReceiptsProof{proof}
+			}
+			if test.delivered {
+				rawdb.WriteBlock(db, destBlock)
+				rawdb.WriteCxLookupEntries(db, destBlock)
+			}
+
+			status, err := b.GetCXTransferStatus(context.Background(), tx.Hash())
+			if err != nil {
+				t.Fatalf("cannot get transfer status: %v", err)
+			}
+			if status.Stage != test.wantStage {
+				t.Errorf("stage: got %s, want %s", status.Stage, test.wantStage)
+			}
+			if !reflect.DeepEqual(status.Source, test.wantSource) {
+				t.Errorf("source: got %+v, want %+v", status.Source, test.wantSource)
+			}
+			if !reflect.DeepEqual(status.CrossLink, test.wantCrossLink) {
+				t.Errorf("crosslink: got %+v, want %+v", status.CrossLink, test.wantCrossLink)
+			}
+			if !reflect.DeepEqual(status.Destination, test.wantDestination) {
+				t.Errorf("destination: got %+v, want %+v", status.Destination, test.wantDestination)
+			}
+			if test.wantStage != CXTransferUnknown &&
+				(status.Receipt == nil || status.Receipt.TxHash != tx.Hash()) {
+				t.Errorf("receipt: got %+v, want the receipt of %s", status.Receipt, tx.Hash().Hex())
+			}
+		})
+	}
+}
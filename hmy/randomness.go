@@ -0,0 +1,185 @@
+package hmy
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/crypto/vdf"
+	vrf_bls "github.com/harmony-one/harmony/crypto/vrf/bls"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+const (
+	// vrfSize is the size of the VRF output in a header, before its proof
+	vrfSize = 32
+	// vrfProofSize is the size of the BLS VRF proof following the output
+	vrfProofSize = 96
+)
+
+var (
+	errBlockNotFound       = errors.New("block not found")
+	errNoBlockRandomness   = errors.New("block has no randomness")
+	errNoEpochRandomness   = errors.New("epoch has no randomness yet")
+	errUnverifiedVrfLeader = errors.New("randomness proof does not verify with the keys of the block leader")
+)
+
+// BlockRandomness is the VRF randomness of a block along with its proof, the
+// BLS VRF of the leader key over the parent hash
+type BlockRandomness struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	ParentHash  common.Hash
+	LeaderKey   shard.BlsPublicKey
+	Randomness  [vrfSize]byte
+	Proof       [vrfProofSize]byte
+}
+
+// EpochRandomness is the VDF randomness of an epoch along with the block
+// which included it and what its proof checks against: the VDF of the XOR of
+// the first VRFs of the epoch
+type EpochRandomness struct {
+	Epoch       *big.Int
+	BlockNumber uint64
+	BlockHash   common.Hash
+	// Output is the VDF output followed by its proof
+	Output     [vdf.Size]byte
+	Difficulty int
+	Seed       [32]byte
+	// SeedBlockNumbers are the blocks whose VRFs make the seed
+	SeedBlockNumbers []uint64
+	SeedVrfs         [][vrfSize]byte
+	Randomness       [32]byte
+}
+
+// GetBlockRandomness returns the VRF randomness of a block, once its proof
+// verifies with the key of the leader who proposed the block
+func (b *APIBackend) GetBlockRandomness(
+	ctx context.Context, blockNum uint64,
+) (*BlockRandomness, error) {
+	bc := b.hmy.BlockChain()
+	header := bc.GetHeaderByNumber(blockNum)
+	if header == nil {
+		return nil, errors.Wrapf(errBlockNotFound, "block %d", blockNum)
+	}
+	if len(header.Vrf()) != vrfSize+vrfProofSize {
+		return nil, errors.Wrapf(errNoBlockRandomness, "block %d", blockNum)
+	}
+	result := &BlockRandomness{
+		BlockNumber: blockNum,
+		BlockHash:   header.Hash(),
+		ParentHash:  header.ParentHash(),
+	}
+	copy(result.Randomness[:], header.Vrf()[:vrfSize])
+	copy(result.Proof[:], header.Vrf()[vrfSize:])
+
+	slots, err := leaderSlots(bc, header)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		key := &bls.PublicKey{}
+		if err := slot.BlsPublicKey.ToLibBLSPublicKey(key); err != nil {
+			continue
+		}
+		hash, err := vrf_bls.NewVRFVerifier(key).ProofToHash(
+			result.ParentHash[:], result.Proof[:],
+		)
+		if err == nil && bytes.Equal(hash[:], result.Randomness[:]) {
+			result.LeaderKey = slot.BlsPublicKey
+			return result, nil
+		}
+	}
+	return nil, errors.Wrapf(errUnverifiedVrfLeader, "block %d", blockNum)
+}
+
+// leaderSlots returns the slots of the committee of the header whose key may
+// have proposed it, the ones with the coinbase of the header.  Before staking
+// the keys of a node share its coinbase, so there may be several.
+func leaderSlots(bc *core.BlockChain, header *block.Header) ([]shard.Slot, error) {
+	shardState, err := bc.ReadShardState(header.Epoch())
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read shard state of epoch %s", header.Epoch())
+	}
+	subComm, err := shardState.FindCommitteeByID(header.ShardID())
+	if err != nil {
+		return nil, err
+	}
+	isStaking := bc.Config().IsStaking(header.Epoch())
+	slots := []shard.Slot{}
+	for _, slot := range subComm.Slots {
+		coinbase := slot.EcdsaAddress
+		if isStaking {
+			// After staking the coinbase address will be the address of bls public key
+			coinbase = utils.GetAddressFromBlsPubKeyBytes(slot.BlsPublicKey[:])
+		}
+		if coinbase == header.Coinbase() {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
+// GetEpochRandomness returns the VDF randomness of an epoch, once the block
+// including it is committed
+func (b *APIBackend) GetEpochRandomness(
+	ctx context.Context, epoch *big.Int,
+) (*EpochRandomness, error) {
+	bc := b.hmy.BlockChain()
+	blockNum, err := bc.ReadEpochVdfBlockNum(epoch)
+	if err != nil || blockNum == nil {
+		return nil, errors.Wrapf(errNoEpochRandomness, "epoch %s", epoch)
+	}
+	header := bc.GetHeaderByNumber(blockNum.Uint64())
+	if header == nil {
+		return nil, errors.Wrapf(errBlockNotFound, "block %d", blockNum.Uint64())
+	}
+	if len(header.Vdf()) != vdf.Size {
+		return nil, errors.Wrapf(errNoEpochRandomness, "epoch %s", epoch)
+	}
+	vrfBlockNumbers, err := bc.ReadEpochVrfBlockNums(epoch)
+	if err != nil {
+		return nil, errors.Wrapf(errNoEpochRandomness, "epoch %s", epoch)
+	}
+	shardState, err := bc.ReadShardState(epoch)
+	if err != nil {
+		return nil, err
+	}
+	subComm, err := shardState.FindCommitteeByID(shard.BeaconChainShardID)
+	if err != nil {
+		return nil, err
+	}
+	// the seed is made of the VRFs the leader generating the VDF used
+	seedSize := consensus.VdfSeedSize(len(subComm.Slots))
+	if len(vrfBlockNumbers) < seedSize {
+		return nil, errors.Wrapf(
+			errNoEpochRandomness, "epoch %s has %d VRFs, %d needed",
+			epoch, len(vrfBlockNumbers), seedSize,
+		)
+	}
+
+	result := &EpochRandomness{
+		Epoch:            epoch,
+		BlockNumber:      blockNum.Uint64(),
+		BlockHash:        header.Hash(),
+		Difficulty:       shard.Schedule.VdfDifficulty(),
+		SeedBlockNumbers: vrfBlockNumbers[:seedSize],
+		SeedVrfs:         make([][vrfSize]byte, seedSize),
+	}
+	copy(result.Output[:], header.Vdf())
+	for i, num := range result.SeedBlockNumbers {
+		copy(result.SeedVrfs[i][:], bc.GetVrfByNumber(num))
+		for j := range result.Seed {
+			result.Seed[j] ^= result.SeedVrfs[i][j]
+		}
+	}
+	result.Randomness = vdf.Randomness(result.Output)
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package hmyclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/crypto/vdf"
+	vrf_bls "github.com/harmony-one/harmony/crypto/vrf/bls"
+)
+
+var (
+	errInvalidLeaderKey     = errors.New("invalid leader key")
+	errInvalidVrfProof      = errors.New("VRF proof does not verify with the leader key")
+	errInvalidVdfSeed       = errors.New("VDF seed is not the XOR of the seed VRFs")
+	errInvalidVdfProof      = errors.New("VDF proof does not verify with the seed")
+	errInvalidVdfRandomness = errors.New("randomness is not the hash of the VDF output")
+)
+
+// BlockRandomness is the VRF randomness of a beacon block, the BLS VRF of the
+// leader key over the parent hash, along with its proof.
+type BlockRandomness struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	LeaderKey   string         `json:"leaderKey"`
+	Randomness  hexutil.Bytes  `json:"randomness"`
+	Proof       hexutil.Bytes  `json:"proof"`
+}
+
+// EpochRandomness is the VDF randomness of an epoch along with its proof and
+// the VRFs of the seed the proof checks against.
+type EpochRandomness struct {
+	Epoch            hexutil.Uint64   `json:"epoch"`
+	BlockNumber      hexutil.Uint64   `json:"blockNumber"`
+	BlockHash        common.Hash      `json:"blockHash"`
+	Vdf              hexutil.Bytes    `json:"vdf"`
+	Difficulty       int              `json:"difficulty"`
+	Seed             hexutil.Bytes    `json:"seed"`
+	SeedBlockNumbers []hexutil.Uint64 `json:"seedBlockNumbers"`
+	SeedVrfs         []hexutil.Bytes  `json:"seedVrfs"`
+	Randomness       hexutil.Bytes    `json:"randomness"`
+}
+
+// BlockRandomness returns the VRF randomness of a beacon block. The node only
+// returns it once the proof verifies with the key of the block leader, which
+// VerifyBlockRandomness checks again without trusting the node.
+func (c *Client) BlockRandomness(ctx context.Context, number *big.Int) (*BlockRandomness, error) {
+	var result *BlockRandomness
+	err := c.c.CallContext(ctx, &result, "hmy_getBlockRandomness", hexutil.Uint64(number.Uint64()))
+	if err != nil {
+		return nil, err
+	} else if result == nil {
+		return nil, ethereum.NotFound
+	}
+	return result, nil
+}
+
+// EpochRandomness returns the VDF randomness of an epoch, which
+// VerifyEpochRandomness checks.
+func (c *Client) EpochRandomness(ctx context.Context, epoch *big.Int) (*EpochRandomness, error) {
+	var result *EpochRandomness
+	err := c.c.CallContext(ctx, &result, "hmy_getEpochRandomness", hexutil.Uint64(epoch.Uint64()))
+	if err != nil {
+		return nil, err
+	} else if result == nil {
+		return nil, ethereum.NotFound
+	}
+	return result, nil
+}
+
+// VerifyBlockRandomness checks that the randomness of the block is the VRF of
+// the leader key over the parent hash. Whether the key is the one of the
+// block leader is up to the caller, e.g. against the committee of the epoch.
+func VerifyBlockRandomness(randomness *BlockRandomness) error {
+	key := &bls.PublicKey{}
+	if err := key.DeserializeHexStr(randomness.LeaderKey); err != nil {
+		return errInvalidLeaderKey
+	}
+	hash, err := vrf_bls.NewVRFVerifier(key).ProofToHash(
+		randomness.ParentHash[:], randomness.Proof,
+	)
+	if err != nil || !bytes.Equal(hash[:], randomness.Randomness) {
+		return errInvalidVrfProof
+	}
+	return nil
+}
+
+// VerifyEpochRandomness checks that the seed is made of the seed VRFs, that
+// the VDF proof verifies with the seed, and that the randomness is the one of
+// the VDF output. Each seed VRF can be checked with VerifyBlockRandomness on
+// the randomness of its block.
+func VerifyEpochRandomness(randomness *EpochRandomness) error {
+	seed := [32]byte{}
+	for _, vrf := range randomness.SeedVrfs {
+		for i := 0; i < len(seed) && i < len(vrf); i++ {
+			seed[i] ^= vrf[i]
+		}
+	}
+	if !bytes.Equal(seed[:], randomness.Seed) {
+		return errInvalidVdfSeed
+	}
+	if len(randomness.Vdf) != vdf.Size {
+		return errInvalidVdfProof
+	}
+	output := [vdf.Size]byte{}
+	copy(output[:], randomness.Vdf)
+	if !vdf.Verify(seed, randomness.Difficulty, output) {
+		return errInvalidVdfProof
+	}
+	if expected := vdf.Randomness(output); !bytes.Equal(expected[:], randomness.Randomness) {
+		return errInvalidVdfRandomness
+	}
+	return nil
+}
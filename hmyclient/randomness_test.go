@@ -0,0 +1,110 @@
+package hmyclient
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/crypto/vdf"
+	vrf_bls "github.com/harmony-one/harmony/crypto/vrf/bls"
+)
+
+func TestVerifyBlockRandomness(t *testing.T) {
+	key := bls.RandPrivateKey()
+	otherKey := bls.RandPrivateKey()
+	parentHash := common.HexToHash("0x1234")
+	randomness, proof := vrf_bls.NewVRFSigner(key).Evaluate(parentHash[:])
+	valid := func() *BlockRandomness {
+		return &BlockRandomness{
+			ParentHash: parentHash,
+			LeaderKey:  key.GetPublicKey().SerializeToHexStr(),
+			Randomness: randomness[:],
+			Proof:      proof,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*BlockRandomness)
+		err    error
+	}{
+		{"valid", func(*BlockRandomness) {}, nil},
+		{"invalid key", func(r *BlockRandomness) { r.LeaderKey = "0x12" }, errInvalidLeaderKey},
+		{"other key", func(r *BlockRandomness) {
+			r.LeaderKey = otherKey.GetPublicKey().SerializeToHexStr()
+		}, errInvalidVrfProof},
+		{"other parent", func(r *BlockRandomness) {
+			r.ParentHash = common.HexToHash("0x5678")
+		}, errInvalidVrfProof},
+		{"other randomness", func(r *BlockRandomness) {
+			r.Randomness = append(hexutil.Bytes{}, r.Randomness...)
+			r.Randomness[0] ^= 1
+		}, errInvalidVrfProof},
+		{"truncated proof", func(r *BlockRandomness) {
+			r.Proof = r.Proof[:len(r.Proof)-1]
+		}, errInvalidVrfProof},
+	}
+	for _, test := range tests {
+		randomness := valid()
+		test.modify(randomness)
+		if err := VerifyBlockRandomness(randomness); err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestVerifyEpochRandomness(t *testing.T) {
+	const difficulty = 100
+	seedVrfs := []hexutil.Bytes{
+		common.HexToHash("0x0102").Bytes(),
+		common.HexToHash("0x0300").Bytes(),
+		common.HexToHash("0xff").Bytes(),
+	}
+	seed := [32]byte{}
+	for _, vrf := range seedVrfs {
+		for i := range seed {
+			seed[i] ^= vrf[i]
+		}
+	}
+	output := vdf.Prove(seed, difficulty)
+	randomness := vdf.Randomness(output)
+	valid := func() *EpochRandomness {
+		return &EpochRandomness{
+			Vdf:        append(hexutil.Bytes{}, output[:]...),
+			Difficulty: difficulty,
+			Seed:       append(hexutil.Bytes{}, seed[:]...),
+			SeedVrfs:   seedVrfs,
+			Randomness: append(hexutil.Bytes{}, randomness[:]...),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*EpochRandomness)
+		err    error
+	}{
+		{"valid", func(*EpochRandomness) {}, nil},
+		{"missing seed VRF", func(r *EpochRandomness) {
+			r.SeedVrfs = r.SeedVrfs[1:]
+		}, errInvalidVdfSeed},
+		{"other seed", func(r *EpochRandomness) { r.Seed[0] ^= 1 }, errInvalidVdfSeed},
+		{"truncated VDF", func(r *EpochRandomness) {
+			r.Vdf = r.Vdf[:len(r.Vdf)-1]
+		}, errInvalidVdfProof},
+		{"other VDF", func(r *EpochRandomness) {
+			r.Vdf[vdf.ElementSize+1] ^= 1
+		}, errInvalidVdfProof},
+		{"other difficulty", func(r *EpochRandomness) { r.Difficulty++ }, errInvalidVdfProof},
+		{"other randomness", func(r *EpochRandomness) {
+			r.Randomness[0] ^= 1
+		}, errInvalidVdfRandomness},
+	}
+	for _, test := range tests {
+		randomness := valid()
+		test.modify(randomness)
+		if err := VerifyEpochRandomness(randomness); err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/harmony-one/harmony/consensus/reward"
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	"github.com/harmony-one/harmony/internal/ctxerror"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/multibls"
@@ -94,6 +95,9 @@ func (e *engineImpl) VerifyHeader(chain engine.ChainReader, header *block.Header
 	if parentHeader == nil {
 		return engine.ErrUnknownAncestor
 	}
+	if err := VerifyLeaderRotation(chain, parentHeader, header); err != nil {
+		return err
+	}
 	if seal {
 		if err := e.VerifySeal(chain, header); err != nil {
 			return err
@@ -502,31 +506,44 @@ func QuorumForBlock(
 // i.e. this header verification api is more flexible since the caller specifies which commit signature and bitmap to use
 // for verifying the block header, which is necessary for cross-shard block header verification. Example of such is cross-shard transaction.
 func (e *engineImpl) VerifyHeaderWithSignature(chain engine.ChainReader, header *block.Header, commitSig []byte, commitBitmap []byte, reCalculate bool) error {
+	signed, err := e.HeaderSignature(chain, header, commitSig, commitBitmap, reCalculate)
+	if err != nil {
+		return err
+	}
+	if !signed.Verify() {
+		return ctxerror.New("[VerifySeal] Unable to verify aggregated signature for block", "blockNum", header.Number().Uint64()-1, "blockHash", header.Hash())
+	}
+	return nil
+}
+
+// HeaderSignature checks the quorum of the commit signature and bitmap on the
+// header and returns the signature, to be verified alone or in a batch
+func (e *engineImpl) HeaderSignature(chain engine.ChainReader, header *block.Header, commitSig []byte, commitBitmap []byte, reCalculate bool) (*bls_cosi.SignedHash, error) {
 	if chain.Config().IsStaking(header.Epoch()) {
 		// Never recalculate after staking is enabled
 		reCalculate = false
 	}
 	publicKeys, err := GetPublicKeys(chain, header, reCalculate)
 	if err != nil {
-		return ctxerror.New("[VerifyHeaderWithSignature] Cannot get publickeys for block header").WithCause(err)
+		return nil, ctxerror.New("[VerifyHeaderWithSignature] Cannot get publickeys for block header").WithCause(err)
 	}
 
 	payload := append(commitSig[:], commitBitmap[:]...)
 	aggSig, mask, err := ReadSignatureBitmapByPublicKeys(payload, publicKeys)
 	if err != nil {
-		return ctxerror.New("[VerifyHeaderWithSignature] Unable to deserialize the commitSignature and commitBitmap in Block Header").WithCause(err)
+		return nil, ctxerror.New("[VerifyHeaderWithSignature] Unable to deserialize the commitSignature and commitBitmap in Block Header").WithCause(err)
 	}
 	hash := header.Hash()
 
 	if e := header.Epoch(); chain.Config().IsStaking(e) {
 		slotList, err := chain.ReadShardState(e)
 		if err != nil {
-			return errors.Wrapf(err, "cannot read shard state")
+			return nil, errors.Wrapf(err, "cannot read shard state")
 		}
 
 		subComm, err := slotList.FindCommitteeByID(header.ShardID())
 		if err != nil {
-			return err
+			return nil, err
 		}
 		// TODO(audit): reuse a singleton decider and not recreate it for every single block
 		d := quorum.NewDecider(quorum.SuperMajorityStake, subComm.ShardID)
@@ -535,21 +552,21 @@ func (e *engineImpl) VerifyHeaderWithSignature(chain engine.ChainReader, header
 		})
 
 		if _, err := d.SetVoters(subComm, e); err != nil {
-			return err
+			return nil, err
 		}
 		if !d.IsQuorumAchievedByMask(mask) {
-			return ctxerror.New(
+			return nil, ctxerror.New(
 				"[VerifySeal] Not enough voting power in commitSignature from Block Header",
 			)
 		}
 	} else {
 		quorumCount, err := QuorumForBlock(chain, header, reCalculate)
 		if err != nil {
-			return errors.Wrapf(err,
+			return nil, errors.Wrapf(err,
 				"cannot calculate quorum for block %s", header.Number())
 		}
 		if count := utils.CountOneBits(mask.Bitmap); count < int64(quorumCount) {
-			return ctxerror.New("[VerifyHeaderWithSignature] Not enough signature in commitSignature from Block Header",
+			return nil, ctxerror.New("[VerifyHeaderWithSignature] Not enough signature in commitSignature from Block Header",
 				"need", quorumCount, "got", count)
 		}
 	}
@@ -558,10 +575,9 @@ func (e *engineImpl) VerifyHeaderWithSignature(chain engine.ChainReader, header
 	binary.LittleEndian.PutUint64(blockNumHash, header.Number().Uint64())
 	commitPayload := append(blockNumHash, hash[:]...)
 
-	if !aggSig.VerifyHash(mask.AggregatePublic, commitPayload) {
-		return ctxerror.New("[VerifySeal] Unable to verify aggregated signature for block", "blockNum", header.Number().Uint64()-1, "blockHash", hash)
-	}
-	return nil
+	return &bls_cosi.SignedHash{
+		Sig: aggSig, PubKey: mask.AggregatePublic, Hash: commitPayload,
+	}, nil
 }
 
 // GetPublicKeys finds the public keys of the committee that signed the block header
@@ -0,0 +1,138 @@
+package chain
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus/engine"
+	"github.com/harmony-one/harmony/consensus/votepower"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+var (
+	errNoRotationLeader = errors.New("no key to lead the rotation turn")
+	errWrongLeader      = errors.New("block not proposed by the leader of its rotation turn")
+)
+
+// epochOfNextBlock returns the epoch of the block after the header
+func epochOfNextBlock(header *block.Header) *big.Int {
+	if len(header.ShardState()) > 0 {
+		return new(big.Int).Add(header.Epoch(), common.Big1)
+	}
+	return header.Epoch()
+}
+
+// IsLeaderRotationBlock returns whether the block after the parent starts a
+// turn of the leader rotation, which is every LeaderRotationBlocks blocks and
+// at the first block of an epoch.
+func IsLeaderRotationBlock(config *params.ChainConfig, parent *block.Header) bool {
+	if !config.IsLeaderRotation(epochOfNextBlock(parent)) {
+		return false
+	}
+	blockNum := parent.Number().Uint64() + 1
+	return blockNum%config.LeaderRotationBlocks == 0 || len(parent.ShardState()) > 0
+}
+
+// RotationLeader returns the slot of the key whose turn it is to lead from
+// the block after the parent, and the committee of the block.  The turns go
+// to the keys of the committee in order; from the weighted rotation epoch,
+// each turn is drawn among the keys by their voting power, seeded by the
+// hash of the committee and the turn.
+func RotationLeader(
+	chain engine.ChainReader, parent *block.Header,
+) (*shard.Slot, *shard.Committee, error) {
+	config, epoch := chain.Config(), epochOfNextBlock(parent)
+	shardState, err := chain.ReadShardState(epoch)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot read shard state of epoch %s", epoch)
+	}
+	subComm, err := shardState.FindCommitteeByID(parent.ShardID())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(subComm.Slots) == 0 {
+		return nil, nil, errNoRotationLeader
+	}
+	turn := (parent.Number().Uint64() + 1) / config.LeaderRotationBlocks
+	if !config.IsWeightedLeaderRotation(epoch) {
+		return &subComm.Slots[turn%uint64(len(subComm.Slots))], subComm, nil
+	}
+
+	roster, err := votepower.Compute(subComm, epoch)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the voting power of each slot, in the units of its decimal
+	total, powers := new(big.Int), make([]*big.Int, len(subComm.Slots))
+	for i := range subComm.Slots {
+		powers[i] = new(big.Int)
+		if voter, ok := roster.Voters[subComm.Slots[i].BlsPublicKey]; ok && voter.OverallPercent.IsPositive() {
+			powers[i].Set(voter.OverallPercent.Int)
+		}
+		total.Add(total, powers[i])
+	}
+	if total.Sign() == 0 {
+		return nil, nil, errNoRotationLeader
+	}
+	seed := subComm.Hash()
+	turnBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(turnBytes, turn)
+	draw := new(big.Int).SetBytes(crypto.Keccak256(seed[:], turnBytes))
+	draw.Mod(draw, total)
+	for i, power := range powers {
+		if draw.Cmp(power) < 0 {
+			return &subComm.Slots[i], subComm, nil
+		}
+		draw.Sub(draw, power)
+	}
+	return nil, nil, errNoRotationLeader
+}
+
+// leaderCoinbase returns the coinbase of the blocks the key of the slot
+// proposes in the epoch
+func leaderCoinbase(config *params.ChainConfig, epoch *big.Int, slot *shard.Slot) common.Address {
+	if config.IsStaking(epoch) {
+		// After staking the coinbase address will be the address of bls public key
+		return utils.GetAddressFromBlsPubKeyBytes(slot.BlsPublicKey[:])
+	}
+	return slot.EcdsaAddress
+}
+
+// VerifyLeaderRotation checks that a block starting a turn of the leader
+// rotation is proposed by the key whose turn it is, or by the key the view
+// changes during the block passed the leadership to, each to the next key
+// of the committee.
+func VerifyLeaderRotation(chain engine.ChainReader, parent, header *block.Header) error {
+	if !IsLeaderRotationBlock(chain.Config(), parent) {
+		return nil
+	}
+	leader, subComm, err := RotationLeader(chain, parent)
+	if err != nil {
+		return err
+	}
+	index := 0
+	for i := range subComm.Slots {
+		if subComm.Slots[i].BlsPublicKey == leader.BlsPublicKey {
+			index = i
+			break
+		}
+	}
+	viewChanges := uint64(0)
+	if viewID, parentViewID := header.ViewID().Uint64(), parent.ViewID().Uint64(); viewID > parentViewID+1 {
+		viewChanges = viewID - parentViewID - 1
+	}
+	expected := &subComm.Slots[(uint64(index)+viewChanges)%uint64(len(subComm.Slots))]
+	if coinbase := leaderCoinbase(chain.Config(), header.Epoch(), expected); header.Coinbase() != coinbase {
+		return errors.Wrapf(errWrongLeader,
+			"block %d coinbase %s, expected %s after %d view changes",
+			header.Number().Uint64(), header.Coinbase().Hex(), coinbase.Hex(), viewChanges,
+		)
+	}
+	return nil
+}
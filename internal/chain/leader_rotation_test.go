@@ -0,0 +1,167 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/block"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/consensus/engine"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// rotationChain serves the chain config and the same committee for every epoch
+type rotationChain struct {
+	engine.ChainReader
+	config    *params.ChainConfig
+	committee shard.Committee
+}
+
+func (c *rotationChain) Config() *params.ChainConfig {
+	return c.config
+}
+
+func (c *rotationChain) ReadShardState(epoch *big.Int) (*shard.State, error) {
+	return &shard.State{Epoch: epoch, Shards: []shard.Committee{c.committee}}, nil
+}
+
+// newRotationChain returns a chain rotating the leader every 4 blocks from
+// epoch 2, weighted from epoch 4, among keys with the given stakes
+func newRotationChain(stakes ...*numeric.Dec) *rotationChain {
+	config := *params.TestChainConfig
+	config.StakingEpoch = params.EpochTBD
+	config.LeaderRotationEpoch = big.NewInt(2)
+	config.WeightedLeaderRotationEpoch = big.NewInt(4)
+	config.LeaderRotationBlocks = 4
+	chain := &rotationChain{config: &config}
+	for i, stake := range stakes {
+		slot := shard.Slot{
+			EcdsaAddress:   common.BigToAddress(big.NewInt(int64(i + 1))),
+			EffectiveStake: stake,
+		}
+		slot.BlsPublicKey[0] = byte(i + 1)
+		chain.committee.Slots = append(chain.committee.Slots, slot)
+	}
+	return chain
+}
+
+// rotationHeader returns a header of the given number, epoch and view ID,
+// ending its epoch if last is set
+func rotationHeader(number, epoch, viewID uint64, last bool) *block.Header {
+	setter := blockfactory.ForTest.NewHeader(new(big.Int).SetUint64(epoch)).With().
+		Number(new(big.Int).SetUint64(number)).
+		ViewID(new(big.Int).SetUint64(viewID))
+	if last {
+		setter = setter.ShardState([]byte{1})
+	}
+	return setter.Header()
+}
+
+func TestIsLeaderRotationBlock(t *testing.T) {
+	config := newRotationChain().config
+	tests := []struct {
+		name   string
+		parent *block.Header
+		want   bool
+	}{
+		{"before activation", rotationHeader(7, 1, 7, false), false},
+		{"at a turn", rotationHeader(11, 2, 11, false), true},
+		{"before a turn", rotationHeader(10, 2, 10, false), false},
+		{"after a turn", rotationHeader(12, 2, 12, false), false},
+		{"first block of the activation epoch", rotationHeader(9, 1, 9, true), true},
+		{"first block of a later epoch", rotationHeader(13, 2, 13, true), true},
+		{"first block of an epoch before activation", rotationHeader(9, 0, 9, true), false},
+	}
+	for _, test := range tests {
+		if got := IsLeaderRotationBlock(config, test.parent); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRotationLeader(t *testing.T) {
+	chain := newRotationChain(nil, nil, nil)
+	tests := []struct {
+		parent uint64
+		want   int // index of the leader slot
+	}{
+		{parent: 7, want: 2},  // block 8, turn 2
+		{parent: 10, want: 2}, // block 11, still turn 2
+		{parent: 11, want: 0}, // block 12, turn 3 wraps around
+		{parent: 15, want: 1}, // block 16, turn 4
+	}
+	for _, test := range tests {
+		leader, subComm, err := RotationLeader(chain, rotationHeader(test.parent, 2, test.parent, false))
+		if err != nil {
+			t.Fatalf("parent %d: %v", test.parent, err)
+		}
+		if want := &subComm.Slots[test.want]; leader.BlsPublicKey != want.BlsPublicKey {
+			t.Errorf("parent %d: leader %x, want slot %d", test.parent, leader.BlsPublicKey[:1], test.want)
+		}
+	}
+}
+
+func TestRotationLeaderWeighted(t *testing.T) {
+	// all the voting power goes to the stakes
+	defer func(schedule shardingconfig.Schedule) { shard.Schedule = schedule }(shard.Schedule)
+	shard.Schedule = shardingconfig.NewFixedSchedule(
+		shardingconfig.MustNewInstance(1, 3, 0, numeric.ZeroDec(), nil, nil, nil, 16),
+	)
+	zero, low, high := numeric.ZeroDec(), numeric.NewDec(1), numeric.NewDec(9)
+	chain := newRotationChain(&zero, &low, &high)
+	turns := map[byte]int{}
+	for turn := uint64(0); turn < 1000; turn++ {
+		parent := rotationHeader(turn*4+3, 4, turn*4+3, false)
+		leader, _, err := RotationLeader(chain, parent)
+		if err != nil {
+			t.Fatalf("turn %d: %v", turn, err)
+		}
+		// every block of the turn draws the same leader
+		again, _, err := RotationLeader(chain, rotationHeader(turn*4+5, 4, turn*4+5, false))
+		if err != nil || again.BlsPublicKey != leader.BlsPublicKey {
+			t.Fatalf("turn %d: leader %x then %x", turn, leader.BlsPublicKey[:1], again.BlsPublicKey[:1])
+		}
+		turns[leader.BlsPublicKey[0]]++
+	}
+	if turns[1] != 0 {
+		t.Errorf("key without stake led %d turns", turns[1])
+	}
+	if turns[2] == 0 || turns[3] <= 4*turns[2] {
+		t.Errorf("turns %d and %d are not weighted by the stakes 1 and 9", turns[2], turns[3])
+	}
+}
+
+func TestVerifyLeaderRotation(t *testing.T) {
+	chain := newRotationChain(nil, nil, nil)
+	slots := chain.committee.Slots
+	tests := []struct {
+		name     string
+		parent   *block.Header
+		viewID   uint64
+		coinbase common.Address
+		err      error
+	}{
+		{"leader of the turn", rotationHeader(7, 2, 7, false), 8, slots[2].EcdsaAddress, nil},
+		{"other key", rotationHeader(7, 2, 7, false), 8, slots[1].EcdsaAddress, errWrongLeader},
+		{"one view change", rotationHeader(7, 2, 7, false), 9, slots[0].EcdsaAddress, nil},
+		{"one view change, turn leader", rotationHeader(7, 2, 7, false), 9, slots[2].EcdsaAddress, errWrongLeader},
+		{"two view changes", rotationHeader(7, 2, 7, false), 10, slots[1].EcdsaAddress, nil},
+		{"not a turn", rotationHeader(8, 2, 8, false), 9, slots[1].EcdsaAddress, nil},
+		{"before activation", rotationHeader(7, 1, 7, false), 8, slots[1].EcdsaAddress, nil},
+	}
+	for _, test := range tests {
+		header := blockfactory.ForTest.NewHeader(test.parent.Epoch()).With().
+			Number(new(big.Int).Add(test.parent.Number(), common.Big1)).
+			ViewID(new(big.Int).SetUint64(test.viewID)).
+			Coinbase(test.coinbase).
+			Header()
+		if err := VerifyLeaderRotation(chain, test.parent, header); errors.Cause(err) != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
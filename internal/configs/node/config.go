@@ -93,6 +93,7 @@ type ConfigType struct {
 	networkType      NetworkType
 	shardingSchedule shardingconfig.Schedule
 	DNSZone          string
+	SyncGRPC         bool // sync blocks over the legacy gRPC downloader instead of libp2p streams, both are served
 	isArchival       bool
 	WebHooks         struct {
 		Hooks *webhooks.Hooks
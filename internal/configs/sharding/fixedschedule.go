@@ -61,6 +61,11 @@ func (s fixedSchedule) GetShardingStructure(numShard, shardID int) []map[string]
 	return genShardingStructure(numShard, shardID, TestNetHTTPPattern, TestNetHTTPPattern)
 }
 
+// ConsensusTimeouts returns the consensus timeouts of the fixed schedule for the epoch.
+func (s fixedSchedule) ConsensusTimeouts(epoch *big.Int) ConsensusTimeouts {
+	return DefaultConsensusTimeouts
+}
+
 // NewFixedSchedule returns a sharding configuration schedule that uses the
 // given config instance for all epochs.  Useful for testing.
 func NewFixedSchedule(instance Instance) Schedule {
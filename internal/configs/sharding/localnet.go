@@ -3,6 +3,7 @@ package shardingconfig
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/numeric"
@@ -108,6 +109,11 @@ func (ls localnetSchedule) GetShardingStructure(numShard, shardID int) []map[str
 	return res
 }
 
+// ConsensusTimeouts returns the consensus timeouts of the localnet for the epoch.
+func (ls localnetSchedule) ConsensusTimeouts(epoch *big.Int) ConsensusTimeouts {
+	return localnetConsensusTimeouts
+}
+
 var (
 	localnetReshardingEpoch = []*big.Int{
 		big.NewInt(0), big.NewInt(localnetV1Epoch), params.LocalnetChainConfig.StakingEpoch,
@@ -117,3 +123,11 @@ var (
 	localnetV1 = MustNewInstance(2, 8, 5, numeric.OneDec(), genesis.LocalHarmonyAccountsV1, genesis.LocalFnAccountsV1, localnetReshardingEpoch, LocalnetSchedule.BlocksPerEpoch())
 	localnetV2 = MustNewInstance(2, 9, 6, numeric.MustNewDecFromStr("0.68"), genesis.LocalHarmonyAccountsV2, genesis.LocalFnAccountsV2, localnetReshardingEpoch, LocalnetSchedule.BlocksPerEpoch())
 )
+
+var localnetConsensusTimeouts = ConsensusTimeouts{
+	Phase:              20 * time.Second,
+	Bootstrap:          120 * time.Second,
+	ViewChange:         20 * time.Second,
+	MaxViewChange:      160 * time.Second,
+	ExponentialBackoff: true,
+}
@@ -2,10 +2,12 @@ package shardingconfig
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/harmony-one/harmony/numeric"
 
 	"github.com/harmony-one/harmony/internal/genesis"
+	"github.com/harmony-one/harmony/internal/params"
 )
 
 const (
@@ -137,6 +139,16 @@ func (ms mainnetSchedule) GetShardingStructure(numShard, shardID int) []map[stri
 	return genShardingStructure(numShard, shardID, MainNetHTTPPattern, MainNetWSPattern)
 }
 
+// ConsensusTimeouts returns the consensus timeouts of the mainnet for the epoch.
+func (ms mainnetSchedule) ConsensusTimeouts(epoch *big.Int) ConsensusTimeouts {
+	switch {
+	case epoch.Cmp(params.EpochTBD) >= 0:
+		return mainnetConsensusTimeoutsV1
+	default: // genesis
+		return DefaultConsensusTimeouts
+	}
+}
+
 var mainnetReshardingEpoch = []*big.Int{big.NewInt(0), big.NewInt(mainnetV0_1Epoch), big.NewInt(mainnetV0_2Epoch), big.NewInt(mainnetV0_3Epoch), big.NewInt(mainnetV0_4Epoch), big.NewInt(mainnetV1Epoch), big.NewInt(mainnetV1_1Epoch), big.NewInt(mainnetV1_2Epoch), big.NewInt(mainnetV1_3Epoch), big.NewInt(mainnetV1_4Epoch), big.NewInt(mainnetV1_5Epoch)}
 
 var (
@@ -152,3 +164,13 @@ var (
 	mainnetV1_4 = MustNewInstance(4, 250, 170, numeric.OneDec(), genesis.HarmonyAccounts, genesis.FoundationalNodeAccountsV1_4, mainnetReshardingEpoch, MainnetSchedule.BlocksPerEpoch())
 	mainnetV1_5 = MustNewInstance(4, 250, 170, numeric.OneDec(), genesis.HarmonyAccounts, genesis.FoundationalNodeAccountsV1_5, mainnetReshardingEpoch, MainnetSchedule.BlocksPerEpoch())
 )
+
+// mainnetConsensusTimeoutsV1 backs off the view changes exponentially, up to
+// about a quarter of an hour, instead of with the square of the views.
+var mainnetConsensusTimeoutsV1 = ConsensusTimeouts{
+	Phase:              60 * time.Second,
+	Bootstrap:          600 * time.Second,
+	ViewChange:         60 * time.Second,
+	MaxViewChange:      16 * time.Minute,
+	ExponentialBackoff: true,
+}
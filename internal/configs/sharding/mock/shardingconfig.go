@@ -176,6 +176,20 @@ func (mr *MockScheduleMockRecorder) GetShardingStructure(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShardingStructure", reflect.TypeOf((*MockSchedule)(nil).GetShardingStructure), arg0, arg1)
 }
 
+// ConsensusTimeouts mocks base method
+func (m *MockSchedule) ConsensusTimeouts(epoch *big.Int) sharding.ConsensusTimeouts {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsensusTimeouts", epoch)
+	ret0, _ := ret[0].(sharding.ConsensusTimeouts)
+	return ret0
+}
+
+// ConsensusTimeouts indicates an expected call of ConsensusTimeouts
+func (mr *MockScheduleMockRecorder) ConsensusTimeouts(epoch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsensusTimeouts", reflect.TypeOf((*MockSchedule)(nil).ConsensusTimeouts), epoch)
+}
+
 // MockInstance is a mock of Instance interface
 type MockInstance struct {
 	ctrl     *gomock.Controller
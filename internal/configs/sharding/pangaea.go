@@ -75,6 +75,11 @@ func (pangaeaSchedule) GetShardingStructure(numShard, shardID int) []map[string]
 	return genShardingStructure(numShard, shardID, PangaeaHTTPPattern, PangaeaWSPattern)
 }
 
+// ConsensusTimeouts returns the consensus timeouts of the pangaea for the epoch.
+func (pangaeaSchedule) ConsensusTimeouts(epoch *big.Int) ConsensusTimeouts {
+	return DefaultConsensusTimeouts
+}
+
 var pangaeaReshardingEpoch = []*big.Int{
 	big.NewInt(0),
 	params.PangaeaChainConfig.StakingEpoch,
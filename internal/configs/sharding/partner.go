@@ -77,6 +77,11 @@ func (ps partnerSchedule) GetShardingStructure(numShard, shardID int) []map[stri
 	return genShardingStructure(numShard, shardID, PartnerHTTPPattern, PartnerWSPattern)
 }
 
+// ConsensusTimeouts returns the consensus timeouts of the partner for the epoch.
+func (ps partnerSchedule) ConsensusTimeouts(epoch *big.Int) ConsensusTimeouts {
+	return DefaultConsensusTimeouts
+}
+
 var partnerReshardingEpoch = []*big.Int{
 	big.NewInt(0),
 	params.TestnetChainConfig.StakingEpoch,
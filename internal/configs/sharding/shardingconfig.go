@@ -45,6 +45,9 @@ type Schedule interface {
 
 	// GetShardingStructure returns sharding structure.
 	GetShardingStructure(int, int) []map[string]interface{}
+
+	// ConsensusTimeouts returns the consensus and view change timeouts of the epoch
+	ConsensusTimeouts(epoch *big.Int) ConsensusTimeouts
 }
 
 // Instance is one sharding configuration instance.
@@ -2,8 +2,10 @@ package shardingconfig
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"testing"
+	"time"
 )
 
 func TestMainnetInstanceForEpoch(t *testing.T) {
@@ -118,3 +120,30 @@ func TestGetShardingStructure(t *testing.T) {
 		}
 	}
 }
+
+func TestViewChangeDuration(t *testing.T) {
+	quadratic := DefaultConsensusTimeouts
+	exponential := ConsensusTimeouts{
+		ViewChange: 10 * time.Second, MaxViewChange: 60 * time.Second, ExponentialBackoff: true,
+	}
+	tests := []struct {
+		timeouts ConsensusTimeouts
+		diff     uint64
+		duration time.Duration
+	}{
+		{quadratic, 0, 60 * time.Second},
+		{quadratic, 1, 60 * time.Second},
+		{quadratic, 3, 9 * 60 * time.Second},
+		{quadratic, 1 << 40, math.MaxInt64},
+		{exponential, 1, 10 * time.Second},
+		{exponential, 2, 20 * time.Second},
+		{exponential, 3, 40 * time.Second},
+		{exponential, 4, 60 * time.Second},
+		{exponential, 1 << 40, 60 * time.Second},
+	}
+	for i, test := range tests {
+		if duration := test.timeouts.ViewChangeDuration(test.diff); duration != test.duration {
+			t.Errorf("ViewChangeDuration error: index %v, got %v, expect %v", i, duration, test.duration)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package shardingconfig
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/harmony-one/harmony/numeric"
 
@@ -77,6 +78,11 @@ func (ss stressnetSchedule) GetShardingStructure(numShard, shardID int) []map[st
 	return genShardingStructure(numShard, shardID, StressNetHTTPPattern, StressNetWSPattern)
 }
 
+// ConsensusTimeouts returns the consensus timeouts of the stressnet for the epoch.
+func (ss stressnetSchedule) ConsensusTimeouts(epoch *big.Int) ConsensusTimeouts {
+	return stressnetConsensusTimeouts
+}
+
 var stressnetReshardingEpoch = []*big.Int{
 	big.NewInt(0),
 	params.StressnetChainConfig.StakingEpoch,
@@ -84,3 +90,11 @@ var stressnetReshardingEpoch = []*big.Int{
 
 var stressnetV0 = MustNewInstance(2, 30, 30, numeric.OneDec(), genesis.TNHarmonyAccounts, genesis.TNFoundationalAccounts, stressnetReshardingEpoch, StressNetSchedule.BlocksPerEpoch())
 var stressnetV1 = MustNewInstance(2, 50, 30, numeric.MustNewDecFromStr("0.9"), genesis.TNHarmonyAccounts, genesis.TNFoundationalAccounts, stressnetReshardingEpoch, StressNetSchedule.BlocksPerEpoch())
+
+var stressnetConsensusTimeouts = ConsensusTimeouts{
+	Phase:              30 * time.Second,
+	Bootstrap:          300 * time.Second,
+	ViewChange:         30 * time.Second,
+	MaxViewChange:      8 * time.Minute,
+	ExponentialBackoff: true,
+}
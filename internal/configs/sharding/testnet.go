@@ -77,6 +77,11 @@ func (ts testnetSchedule) GetShardingStructure(numShard, shardID int) []map[stri
 	return genShardingStructure(numShard, shardID, TestNetHTTPPattern, TestNetWSPattern)
 }
 
+// ConsensusTimeouts returns the consensus timeouts of the testnet for the epoch.
+func (ts testnetSchedule) ConsensusTimeouts(epoch *big.Int) ConsensusTimeouts {
+	return DefaultConsensusTimeouts
+}
+
 var testnetReshardingEpoch = []*big.Int{
 	big.NewInt(0),
 	params.TestnetChainConfig.StakingEpoch,
@@ -0,0 +1,64 @@
+package shardingconfig
+
+import (
+	"math"
+	"time"
+)
+
+// ConsensusTimeouts are the durations a node waits on the phases of the
+// consensus before it starts a view change, and on the view changes.
+type ConsensusTimeouts struct {
+	// Phase is the timeout of the announce, prepare and commit phases
+	Phase time.Duration
+	// Bootstrap is the timeout of the first block after the node starts
+	Bootstrap time.Duration
+	// ViewChange is the timeout of the view change to the next view
+	ViewChange time.Duration
+	// MaxViewChange caps the timeout of a view change; zero means no cap
+	MaxViewChange time.Duration
+	// ExponentialBackoff doubles the timeout of each further view, instead
+	// of growing it with the square of the views
+	ExponentialBackoff bool
+}
+
+// DefaultConsensusTimeouts are the timeouts the networks started with.
+var DefaultConsensusTimeouts = ConsensusTimeouts{
+	Phase:      60 * time.Second,
+	Bootstrap:  600 * time.Second,
+	ViewChange: 60 * time.Second,
+}
+
+// ViewChangeDuration returns the timeout of the view change to the view diff
+// views ahead of the current one: ViewChange for the next view, then either
+// diff*diff*ViewChange, or ViewChange doubled for each further view with the
+// exponential backoff, up to MaxViewChange.
+func (t ConsensusTimeouts) ViewChangeDuration(diff uint64) time.Duration {
+	if t.ViewChange <= 0 {
+		return t.ViewChange
+	}
+	if diff == 0 {
+		diff = 1
+	}
+	limit := time.Duration(math.MaxInt64)
+	if t.MaxViewChange > 0 {
+		limit = t.MaxViewChange
+	}
+	duration := t.ViewChange
+	if t.ExponentialBackoff {
+		for i := uint64(1); i < diff && duration < limit; i++ {
+			if duration > limit/2 {
+				duration = limit
+				break
+			}
+			duration *= 2
+		}
+	} else if diff < 1<<32 && diff*diff <= uint64(limit/t.ViewChange) {
+		duration = time.Duration(diff*diff) * t.ViewChange
+	} else {
+		duration = limit
+	}
+	if duration > limit {
+		duration = limit
+	}
+	return duration
+}
@@ -0,0 +1,174 @@
+package apiv1
+
+import (
+	"strings"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/reputation"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// PrivateAdminAPI offers peer management RPC methods to the node operator,
+// served on a localhost-only endpoint
+type PrivateAdminAPI struct {
+	net p2p.Host
+}
+
+// NewPrivateAdminAPI creates a new admin API instance.
+func NewPrivateAdminAPI(net p2p.Host) *PrivateAdminAPI {
+	return &PrivateAdminAPI{net}
+}
+
+// RPCPeerInfo is the status of a connected peer
+type RPCPeerInfo struct {
+	ID        string   `json:"id"`
+	Addrs     []string `json:"addrs"`
+	Inbound   bool     `json:"inbound"`
+	Topics    []string `json:"topics"`
+	LatencyMs float64  `json:"latencyMs"`
+	Score     float64  `json:"score"`
+	Static    bool     `json:"static"`
+	Trusted   bool     `json:"trusted"`
+}
+
+// RPCNodeInfo is the p2p identity and the role of the node
+type RPCNodeInfo struct {
+	ID           string   `json:"id"`
+	Addrs        []string `json:"addrs"`
+	ShardID      uint32   `json:"shardID"`
+	Role         string   `json:"role"`
+	BLSPublicKey []string `json:"blsKeys"`
+	Version      string   `json:"version"`
+	NetworkType  string   `json:"network"`
+}
+
+// Peers returns the connected peers with their addresses, shared pubsub topics,
+// latency and reputation score
+func (s *PrivateAdminAPI) Peers() []RPCPeerInfo {
+	peers := []RPCPeerInfo{}
+	for _, peer := range s.net.Peers() {
+		info := RPCPeerInfo{
+			ID:        peer.PeerID.Pretty(),
+			Addrs:     []string{},
+			Inbound:   peer.Inbound,
+			Topics:    peer.Topics,
+			LatencyMs: float64(peer.Latency.Microseconds()) / 1000,
+			Score:     peer.Score,
+			Static:    peer.Static,
+			Trusted:   peer.Trusted,
+		}
+		if info.Topics == nil {
+			info.Topics = []string{}
+		}
+		for _, addr := range peer.Addrs {
+			info.Addrs = append(info.Addrs, addr.String())
+		}
+		peers = append(peers, info)
+	}
+	return peers
+}
+
+// AddPeer connects to the peer at the given /p2p multiaddr, and keeps it connected
+func (s *PrivateAdminAPI) AddPeer(url string) (bool, error) {
+	addr, err := ma.NewMultiaddr(url)
+	if err != nil {
+		return false, err
+	}
+	if err := s.net.AddStaticPeer(addr); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddTrustedPeer connects to the peer at the given /p2p multiaddr, keeps it
+// connected and never bans it for misbehaviour
+func (s *PrivateAdminAPI) AddTrustedPeer(url string) (bool, error) {
+	addr, err := ma.NewMultiaddr(url)
+	if err != nil {
+		return false, err
+	}
+	if err := s.net.AddTrustedPeer(addr); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemovePeer disconnects the peer with the given ID or /p2p multiaddr, and
+// forgets it as a static or trusted peer
+func (s *PrivateAdminAPI) RemovePeer(peer string) (bool, error) {
+	id, err := decodePeerID(peer)
+	if err != nil {
+		return false, err
+	}
+	if err := s.net.RemovePeer(id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NodeInfo returns the p2p identity and addresses of the node, with its shard,
+// role, BLS keys and version
+func (s *PrivateAdminAPI) NodeInfo() RPCNodeInfo {
+	cfg := nodeconfig.GetDefaultConfig()
+	host := s.net.GetP2PHost()
+	info := RPCNodeInfo{
+		ID:           host.ID().Pretty(),
+		Addrs:        []string{},
+		ShardID:      cfg.ShardID,
+		Role:         cfg.Role().String(),
+		BLSPublicKey: []string{},
+		Version:      nodeconfig.GetVersion(),
+		NetworkType:  string(cfg.GetNetworkType()),
+	}
+	for _, addr := range host.Addrs() {
+		info.Addrs = append(info.Addrs, addr.String()+"/p2p/"+info.ID)
+	}
+	if cfg.ConsensusPubKey != nil {
+		for _, key := range cfg.ConsensusPubKey.PublicKey {
+			info.BLSPublicKey = append(info.BLSPublicKey, key.SerializeToHexStr())
+		}
+	}
+	return info
+}
+
+// BannedPeers returns the peers banned at present, with the reason and the end of the ban
+func (s *PrivateAdminAPI) BannedPeers() []reputation.Ban {
+	return s.net.BannedPeers()
+}
+
+// BanPeer bans and disconnects the peer with the given ID for the configured ban duration
+func (s *PrivateAdminAPI) BanPeer(peerID string, reason string) error {
+	id, err := decodePeerID(peerID)
+	if err != nil {
+		return err
+	}
+	s.net.BanPeer(id, reason)
+	return nil
+}
+
+// UnbanPeer lifts the ban of the peer with the given ID, returning false if it is not banned
+func (s *PrivateAdminAPI) UnbanPeer(peerID string) (bool, error) {
+	id, err := decodePeerID(peerID)
+	if err != nil {
+		return false, err
+	}
+	return s.net.UnbanPeer(id), nil
+}
+
+// decodePeerID decodes a peer ID, or the peer ID of a /p2p multiaddr
+func decodePeerID(peer string) (libp2p_peer.ID, error) {
+	if !strings.HasPrefix(peer, "/") {
+		return libp2p_peer.IDB58Decode(peer)
+	}
+	addr, err := ma.NewMultiaddr(peer)
+	if err != nil {
+		return "", err
+	}
+	value, err := addr.ValueForProtocol(ma.P_P2P)
+	if err != nil {
+		return "", err
+	}
+	return libp2p_peer.IDB58Decode(value)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/harmony/common/denominations"
@@ -377,6 +378,63 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, addr string, key
 	return res[:], state.Error()
 }
 
+// GetProof returns the merkle proof of the given account and of the given storage
+// slots of the account in the state at the given block number.
+func (s *PublicBlockChainAPI) GetProof(
+	ctx context.Context, addr string, storageKeys []string, blockNr rpc.BlockNumber,
+) (*AccountResult, error) {
+	address := internal_common.ParseAddr(addr)
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	storageTrie := state.StorageTrie(address)
+	storageHash := types.EmptyRootHash
+	codeHash := state.GetCodeHash(address)
+	storageProof := make([]StorageResult, len(storageKeys))
+
+	// if we have a storageTrie, the account exists and we must update the storage root hash
+	if storageTrie != nil {
+		storageHash = storageTrie.Hash()
+	} else {
+		// no storageTrie means the account does not exist, so the codeHash is the hash of an empty bytearray
+		codeHash = crypto.Keccak256Hash(nil)
+	}
+
+	for i, key := range storageKeys {
+		if storageTrie == nil {
+			storageProof[i] = StorageResult{key, &hexutil.Big{}, []string{}}
+			continue
+		}
+		hashKey := common.HexToHash(key)
+		proof, err := state.GetStorageProof(address, hashKey)
+		if err != nil {
+			return nil, err
+		}
+		storageProof[i] = StorageResult{key, (*hexutil.Big)(state.GetState(address, hashKey).Big()), common.ToHexArray(proof)}
+	}
+
+	accountProof, err := state.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+	bech32Addr, err := internal_common.AddressToBech32(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountResult{
+		Address:      bech32Addr,
+		AccountProof: common.ToHexArray(accountProof),
+		Balance:      (*hexutil.Big)(state.GetBalance(address)),
+		CodeHash:     codeHash,
+		Nonce:        hexutil.Uint64(state.GetNonce(address)),
+		StorageHash:  storageHash,
+		StorageProof: storageProof,
+	}, state.Error()
+}
+
 // GetBalanceByBlockNumber returns balance by block number.
 func (s *PublicBlockChainAPI) GetBalanceByBlockNumber(ctx context.Context, address string, blockNr rpc.BlockNumber) (*hexutil.Big, error) {
 	addr := internal_common.ParseAddr(address)
@@ -884,3 +942,73 @@ func (s *PublicBlockChainAPI) GetLastCrossLinks() ([]*types.CrossLink, error) {
 	}
 	return nil, errNotBeaconChainShard
 }
+
+// GetCrossLinks returns the crosslinks of a shard for the shard blocks in [fromBlock, toBlock],
+// shard blocks which are not crosslinked yet are skipped
+func (s *PublicBlockChainAPI) GetCrossLinks(
+	ctx context.Context, shardID uint32, fromBlock, toBlock hexutil.Uint64,
+) ([]*types.CrossLink, error) {
+	if s.b.GetShardID() == shard.BeaconChainShardID {
+		return s.b.GetCrossLinks(ctx, shardID, uint64(fromBlock), uint64(toBlock))
+	}
+	return nil, errNotBeaconChainShard
+}
+
+// GetCrossLinkInclusion returns the crosslink of a shard block and the beacon block which included it
+func (s *PublicBlockChainAPI) GetCrossLinkInclusion(
+	ctx context.Context, shardID uint32, blockNum hexutil.Uint64,
+) (*RPCCrossLinkInclusion, error) {
+	if s.b.GetShardID() != shard.BeaconChainShardID {
+		return nil, errNotBeaconChainShard
+	}
+	inclusion, err := s.b.GetCrossLinkInclusion(ctx, shardID, uint64(blockNum))
+	if err != nil {
+		return nil, err
+	}
+	return newRPCCrossLinkInclusion(inclusion), nil
+}
+
+// GetPendingCrossLinks returns the crosslinks waiting to be included in a beacon block, with their age
+func (s *PublicBlockChainAPI) GetPendingCrossLinks() ([]RPCPendingCrossLink, error) {
+	if s.b.GetShardID() != shard.BeaconChainShardID {
+		return nil, errNotBeaconChainShard
+	}
+	pending, err := s.b.GetPendingCrossLinks()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]RPCPendingCrossLink, 0, len(pending))
+	for _, cl := range pending {
+		result = append(result, newRPCPendingCrossLink(cl))
+	}
+	return result, nil
+}
+
+// GetBlockRandomness returns the VRF randomness of a beacon block and its proof, the randomness
+// is only returned once the proof verifies with the key of the block leader
+func (s *PublicBlockChainAPI) GetBlockRandomness(
+	ctx context.Context, blockNum hexutil.Uint64,
+) (*RPCBlockRandomness, error) {
+	if s.b.GetShardID() != shard.BeaconChainShardID {
+		return nil, errNotBeaconChainShard
+	}
+	randomness, err := s.b.GetBlockRandomness(ctx, uint64(blockNum))
+	if err != nil {
+		return nil, err
+	}
+	return newRPCBlockRandomness(randomness), nil
+}
+
+// GetEpochRandomness returns the VDF randomness of an epoch, its proof and the VRFs of its seed
+func (s *PublicBlockChainAPI) GetEpochRandomness(
+	ctx context.Context, epoch hexutil.Uint64,
+) (*RPCEpochRandomness, error) {
+	if s.b.GetShardID() != shard.BeaconChainShardID {
+		return nil, errNotBeaconChainShard
+	}
+	randomness, err := s.b.GetEpochRandomness(ctx, new(big.Int).SetUint64(uint64(epoch)))
+	if err != nil {
+		return nil, err
+	}
+	return newRPCEpochRandomness(randomness), nil
+}
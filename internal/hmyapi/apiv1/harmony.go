@@ -28,15 +28,34 @@ func (s *PublicHarmonyAPI) ProtocolVersion() hexutil.Uint {
 }
 
 // Syncing returns false in case the node is currently not syncing with the network. It can be up to date or has not
-// yet received the latest block headers from its pears. In case it is synchronizing:
-// - startingBlock: block number this node started to synchronise from
-// - currentBlock:  block number this node is currently importing
-// - highestBlock:  block number of the highest block header this node has received from peers
-// - pulledStates:  number of state entries processed until now
-// - knownStates:   number of known state entries that still need to be pulled
+// yet received the latest block headers from its pears. In case it is synchronizing, it returns the sync progress
+// as GetSyncProgress does.
 func (s *PublicHarmonyAPI) Syncing() (interface{}, error) {
-	// TODO(ricl): find our Downloader module for syncing blocks
-	return false, nil
+	progress := s.GetSyncProgress()
+	if !progress.Syncing && !progress.BeaconSyncing {
+		return false, nil
+	}
+	return progress, nil
+}
+
+// GetSyncProgress returns the current block height, the highest block height known from
+// the peers of both the shard chain and the beacon chain, the sync rate and the estimated
+// time to catch up, along with the status of the sync peers of the shard chain.
+func (s *PublicHarmonyAPI) GetSyncProgress() *RPCSyncProgress {
+	return newRPCSyncProgress(s.b.GetSyncProgress(false), s.b.GetSyncProgress(true))
+}
+
+// GetConsensusTimings returns the timing of the consensus on the last blocks as seen by the node:
+// the time from the announce to the prepare quorum, from the prepare quorum to the commit quorum
+// and from the commit quorum to the committed message, the signers and voting power at the quorums,
+// the late signers and the view changes, oldest block first.
+func (s *PublicHarmonyAPI) GetConsensusTimings() []RPCConsensusTiming {
+	timings := s.b.GetConsensusTimings()
+	result := make([]RPCConsensusTiming, 0, len(timings))
+	for i := range timings {
+		result = append(result, newRPCConsensusTiming(&timings[i]))
+	}
+	return result
 }
 
 // GasPrice returns a suggestion for a gas price.
@@ -47,17 +66,18 @@ func (s *PublicHarmonyAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
 
 // NodeMetadata captures select metadata of the RPC answering node
 type NodeMetadata struct {
-	BLSPublicKey   []string           `json:"blskey"`
-	Version        string             `json:"version"`
-	NetworkType    string             `json:"network"`
-	ChainConfig    params.ChainConfig `json:"chain-config"`
-	IsLeader       bool               `json:"is-leader"`
-	ShardID        uint32             `json:"shard-id"`
-	CurrentEpoch   uint64             `json:"current-epoch"`
-	BlocksPerEpoch *uint64            `json:"blocks-per-epoch,omitempty"`
-	Role           string             `json:"role"`
-	DNSZone        string             `json:"dns-zone"`
-	Archival       bool               `json:"is-archival"`
+	BLSPublicKey   []string             `json:"blskey"`
+	Version        string               `json:"version"`
+	NetworkType    string               `json:"network"`
+	ChainConfig    params.ChainConfig   `json:"chain-config"`
+	IsLeader       bool                 `json:"is-leader"`
+	ShardID        uint32               `json:"shard-id"`
+	CurrentEpoch   uint64               `json:"current-epoch"`
+	BlocksPerEpoch *uint64              `json:"blocks-per-epoch,omitempty"`
+	Role           string               `json:"role"`
+	DNSZone        string               `json:"dns-zone"`
+	Archival       bool                 `json:"is-archival"`
+	Timeouts       RPCConsensusTimeouts `json:"consensus-timeouts"`
 }
 
 // GetNodeMetadata produces a NodeMetadata record, data is from the answering RPC node
@@ -91,5 +111,6 @@ func (s *PublicHarmonyAPI) GetNodeMetadata() NodeMetadata {
 		cfg.Role().String(),
 		cfg.DNSZone,
 		cfg.GetArchival(),
+		newRPCConsensusTimeouts(s.b.GetConsensusTimeouts()),
 	}
 }
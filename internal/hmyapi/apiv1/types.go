@@ -11,9 +11,13 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/harmony-one/harmony/api/service/syncing"
 	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/hmy"
 	internal_common "github.com/harmony-one/harmony/internal/common"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
 	"github.com/harmony-one/harmony/numeric"
 )
 
@@ -58,14 +62,161 @@ type RPCStakingTransaction struct {
 
 // RPCCXReceipt represents a CXReceipt that will serialize to the RPC representation of a CXReceipt
 type RPCCXReceipt struct {
-	BlockHash   common.Hash  `json:"blockHash"`
-	BlockNumber *hexutil.Big `json:"blockNumber"`
-	TxHash      common.Hash  `json:"hash"`
-	From        string       `json:"from"`
-	To          string       `json:"to"`
-	ShardID     uint32       `json:"shardID"`
-	ToShardID   uint32       `json:"toShardID"`
-	Amount      *hexutil.Big `json:"value"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	BlockNumber *hexutil.Big   `json:"blockNumber"`
+	TxHash      common.Hash    `json:"hash"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	ShardID     uint32         `json:"shardID"`
+	ToShardID   uint32         `json:"toShardID"`
+	Amount      *hexutil.Big   `json:"value"`
+	Input       hexutil.Bytes  `json:"input,omitempty"`
+	Gas         hexutil.Uint64 `json:"gas,omitempty"`
+	GasPrice    *hexutil.Big   `json:"gasPrice,omitempty"`
+}
+
+// RPCCXBlockRef points to a block of a given shard
+type RPCCXBlockRef struct {
+	ShardID     uint32         `json:"shardID"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+}
+
+// RPCCXTransferStatus represents the lifecycle of a cross-shard transfer
+type RPCCXTransferStatus struct {
+	TxHash      common.Hash    `json:"hash"`
+	Stage       string         `json:"stage"`
+	Receipt     *RPCCXReceipt  `json:"receipt"`
+	Source      *RPCCXBlockRef `json:"source"`
+	CrossLink   *RPCCXBlockRef `json:"crossLink"`
+	Destination *RPCCXBlockRef `json:"destination"`
+}
+
+// RPCCrossLinkInclusion represents a crosslink and the beacon block which included it
+type RPCCrossLinkInclusion struct {
+	CrossLink         *types.CrossLink `json:"crossLink"`
+	BeaconBlockHash   *common.Hash     `json:"beaconBlockHash"`
+	BeaconBlockNumber *hexutil.Uint64  `json:"beaconBlockNumber"`
+	Index             *hexutil.Uint64  `json:"index"`
+}
+
+// RPCPendingCrossLink represents a crosslink waiting to be included in a beacon block
+type RPCPendingCrossLink struct {
+	CrossLink   types.CrossLink `json:"crossLink"`
+	EpochAge    hexutil.Uint64  `json:"epochAge"`
+	BlocksAhead hexutil.Uint64  `json:"blocksAhead"`
+}
+
+// RPCBlockRandomness represents the VRF randomness of a block and its proof,
+// the BLS VRF of the leader key over the parent hash
+type RPCBlockRandomness struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	LeaderKey   string         `json:"leaderKey"`
+	Randomness  hexutil.Bytes  `json:"randomness"`
+	Proof       hexutil.Bytes  `json:"proof"`
+}
+
+// RPCEpochRandomness represents the VDF randomness of an epoch and its proof,
+// along with the VRFs of the seed the proof checks against
+type RPCEpochRandomness struct {
+	Epoch            hexutil.Uint64   `json:"epoch"`
+	BlockNumber      hexutil.Uint64   `json:"blockNumber"`
+	BlockHash        common.Hash      `json:"blockHash"`
+	Vdf              hexutil.Bytes    `json:"vdf"`
+	Difficulty       int              `json:"difficulty"`
+	Seed             hexutil.Bytes    `json:"seed"`
+	SeedBlockNumbers []hexutil.Uint64 `json:"seedBlockNumbers"`
+	SeedVrfs         []hexutil.Bytes  `json:"seedVrfs"`
+	Randomness       hexutil.Bytes    `json:"randomness"`
+}
+
+// RPCSyncPeer represents the status of a sync peer
+type RPCSyncPeer struct {
+	IP             string         `json:"ip"`
+	Port           string         `json:"port"`
+	Height         hexutil.Uint64 `json:"height"`
+	Errors         int            `json:"errors"`
+	Downloaded     int            `json:"downloaded"`
+	ItemsPerSecond float64        `json:"itemsPerSecond"`
+}
+
+// RPCSyncProgress represents the progress of syncing the shard chain and the beacon chain
+type RPCSyncProgress struct {
+	Syncing            bool           `json:"syncing"`
+	StartingBlock      hexutil.Uint64 `json:"startingBlock"`
+	CurrentBlock       hexutil.Uint64 `json:"currentBlock"`
+	HighestBlock       hexutil.Uint64 `json:"highestBlock"`
+	BlocksPerSecond    float64        `json:"blocksPerSecond"`
+	ETASeconds         float64        `json:"etaSeconds"`
+	BeaconSyncing      bool           `json:"beaconSyncing"`
+	BeaconCurrentBlock hexutil.Uint64 `json:"beaconCurrentBlock"`
+	BeaconHighestBlock hexutil.Uint64 `json:"beaconHighestBlock"`
+	Peers              []RPCSyncPeer  `json:"peers"`
+}
+
+// RPCConsensusTiming represents the timing of the consensus on a block, the
+// announce time in unix milliseconds and the phase durations in milliseconds
+type RPCConsensusTiming struct {
+	BlockNumber            hexutil.Uint64 `json:"blockNumber"`
+	ViewID                 hexutil.Uint64 `json:"viewID"`
+	BlockHash              common.Hash    `json:"blockHash"`
+	IsLeader               bool           `json:"isLeader"`
+	Finalized              bool           `json:"finalized"`
+	AnnounceTime           int64          `json:"announceTime"`
+	AnnounceToPreparedMs   int64          `json:"announceToPreparedMs"`
+	PreparedToCommittedMs  int64          `json:"preparedToCommittedMs"`
+	CommittedToFinalizedMs int64          `json:"committedToFinalizedMs"`
+	PrepareSigners         int64          `json:"prepareSigners"`
+	PreparePower           numeric.Dec    `json:"preparePower"`
+	CommitSigners          int64          `json:"commitSigners"`
+	CommitPower            numeric.Dec    `json:"commitPower"`
+	LateSigners            []string       `json:"lateSigners"`
+	ViewChanges            hexutil.Uint64 `json:"viewChanges"`
+}
+
+// RPCConsensusTimeouts represents the consensus and view change timeouts of
+// the node, in milliseconds
+type RPCConsensusTimeouts struct {
+	PhaseMs            int64 `json:"phaseMs"`
+	BootstrapMs        int64 `json:"bootstrapMs"`
+	ViewChangeMs       int64 `json:"viewChangeMs"`
+	MaxViewChangeMs    int64 `json:"maxViewChangeMs"`
+	ExponentialBackoff bool  `json:"exponentialBackoff"`
+}
+
+// AccountResult is the merkle proof of an account and of some of its storage slots
+type AccountResult struct {
+	Address      string          `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the merkle proof of a storage slot of an account
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// RPCReceiptProof is the merkle proof of a transaction receipt against the
+// receipt root of the block which includes the transaction
+type RPCReceiptProof struct {
+	BlockHash       common.Hash    `json:"blockHash"`
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	ReceiptsRoot    common.Hash    `json:"receiptsRoot"`
+	// Index is the position of the receipt in the receipt trie,
+	// Key is the trie key, i.e. the RLP encoding of the position
+	Index   hexutil.Uint64 `json:"index"`
+	Key     hexutil.Bytes  `json:"key"`
+	Receipt hexutil.Bytes  `json:"receipt"`
+	Proof   []string       `json:"proof"`
 }
 
 // HeaderInformation represents the latest consensus information
@@ -151,10 +302,172 @@ func newRPCCXReceipt(cx *types.CXReceipt, blockHash common.Hash, blockNumber uin
 	}
 	result.From = fromAddr
 	result.To = toAddr
+	if call := cx.Call(); call != nil {
+		result.Input = hexutil.Bytes(call.Data)
+		result.Gas = hexutil.Uint64(call.GasLimit)
+		result.GasPrice = (*hexutil.Big)(call.GasPrice)
+	}
 
 	return result
 }
 
+// newRPCCXBlockRef returns a block reference that will serialize to the RPC representation
+func newRPCCXBlockRef(ref *hmy.CXBlockRef) *RPCCXBlockRef {
+	if ref == nil {
+		return nil
+	}
+	return &RPCCXBlockRef{
+		ShardID:     ref.ShardID,
+		BlockNumber: hexutil.Uint64(ref.BlockNumber),
+		BlockHash:   ref.BlockHash,
+	}
+}
+
+// newRPCCXTransferStatus returns a cross-shard transfer status that will serialize to the RPC representation
+func newRPCCXTransferStatus(status *hmy.CXTransferStatus) *RPCCXTransferStatus {
+	result := &RPCCXTransferStatus{
+		TxHash:      status.TxHash,
+		Stage:       status.Stage.String(),
+		Source:      newRPCCXBlockRef(status.Source),
+		CrossLink:   newRPCCXBlockRef(status.CrossLink),
+		Destination: newRPCCXBlockRef(status.Destination),
+	}
+	if status.Receipt != nil {
+		if status.Destination != nil {
+			result.Receipt = newRPCCXReceipt(
+				status.Receipt, status.Destination.BlockHash, status.Destination.BlockNumber,
+			)
+		} else {
+			result.Receipt = newRPCCXReceipt(status.Receipt, common.Hash{}, 0)
+		}
+	}
+	return result
+}
+
+// newRPCCrossLinkInclusion returns a crosslink inclusion that will serialize to the RPC
+// representation, the beacon block is left empty when not known
+func newRPCCrossLinkInclusion(inclusion *hmy.CrossLinkInclusion) *RPCCrossLinkInclusion {
+	if inclusion == nil {
+		return nil
+	}
+	result := &RPCCrossLinkInclusion{CrossLink: inclusion.CrossLink}
+	if inclusion.BlockHash != (common.Hash{}) {
+		blockHash := inclusion.BlockHash
+		blockNumber := hexutil.Uint64(inclusion.BlockNumber)
+		index := hexutil.Uint64(inclusion.Index)
+		result.BeaconBlockHash = &blockHash
+		result.BeaconBlockNumber = &blockNumber
+		result.Index = &index
+	}
+	return result
+}
+
+// newRPCPendingCrossLink returns a pending crosslink that will serialize to the RPC representation
+func newRPCPendingCrossLink(pending hmy.PendingCrossLink) RPCPendingCrossLink {
+	return RPCPendingCrossLink{
+		CrossLink:   pending.CrossLink,
+		EpochAge:    hexutil.Uint64(pending.EpochAge),
+		BlocksAhead: hexutil.Uint64(pending.BlocksAhead),
+	}
+}
+
+// newRPCBlockRandomness returns the randomness of a block that will serialize
+// to the RPC representation
+func newRPCBlockRandomness(randomness *hmy.BlockRandomness) *RPCBlockRandomness {
+	return &RPCBlockRandomness{
+		BlockNumber: hexutil.Uint64(randomness.BlockNumber),
+		BlockHash:   randomness.BlockHash,
+		ParentHash:  randomness.ParentHash,
+		LeaderKey:   randomness.LeaderKey.Hex(),
+		Randomness:  randomness.Randomness[:],
+		Proof:       randomness.Proof[:],
+	}
+}
+
+// newRPCEpochRandomness returns the randomness of an epoch that will
+// serialize to the RPC representation
+func newRPCEpochRandomness(randomness *hmy.EpochRandomness) *RPCEpochRandomness {
+	result := &RPCEpochRandomness{
+		Epoch:            hexutil.Uint64(randomness.Epoch.Uint64()),
+		BlockNumber:      hexutil.Uint64(randomness.BlockNumber),
+		BlockHash:        randomness.BlockHash,
+		Vdf:              randomness.Output[:],
+		Difficulty:       randomness.Difficulty,
+		Seed:             randomness.Seed[:],
+		SeedBlockNumbers: make([]hexutil.Uint64, len(randomness.SeedBlockNumbers)),
+		SeedVrfs:         make([]hexutil.Bytes, len(randomness.SeedVrfs)),
+		Randomness:       randomness.Randomness[:],
+	}
+	for i, num := range randomness.SeedBlockNumbers {
+		result.SeedBlockNumbers[i] = hexutil.Uint64(num)
+	}
+	for i := range randomness.SeedVrfs {
+		result.SeedVrfs[i] = randomness.SeedVrfs[i][:]
+	}
+	return result
+}
+
+// newRPCSyncProgress returns the sync progress that will serialize to the RPC representation
+func newRPCSyncProgress(progress, beacon syncing.Progress) *RPCSyncProgress {
+	result := &RPCSyncProgress{
+		Syncing:            progress.Syncing,
+		StartingBlock:      hexutil.Uint64(progress.StartingBlock),
+		CurrentBlock:       hexutil.Uint64(progress.CurrentBlock),
+		HighestBlock:       hexutil.Uint64(progress.HighestBlock),
+		BlocksPerSecond:    progress.BlocksPerSecond,
+		ETASeconds:         progress.ETASeconds,
+		BeaconSyncing:      beacon.Syncing,
+		BeaconCurrentBlock: hexutil.Uint64(beacon.CurrentBlock),
+		BeaconHighestBlock: hexutil.Uint64(beacon.HighestBlock),
+		Peers:              make([]RPCSyncPeer, 0, len(progress.Peers)),
+	}
+	for _, peer := range progress.Peers {
+		result.Peers = append(result.Peers, RPCSyncPeer{
+			IP:             peer.IP,
+			Port:           peer.Port,
+			Height:         hexutil.Uint64(peer.Height),
+			Errors:         peer.Errors,
+			Downloaded:     peer.Downloaded,
+			ItemsPerSecond: peer.ItemsPerSecond,
+		})
+	}
+	return result
+}
+
+// newRPCConsensusTiming returns the consensus timing that will serialize to the RPC representation
+func newRPCConsensusTiming(timing *consensus.BlockTiming) RPCConsensusTiming {
+	result := RPCConsensusTiming{
+		BlockNumber:            hexutil.Uint64(timing.BlockNum),
+		ViewID:                 hexutil.Uint64(timing.ViewID),
+		BlockHash:              timing.BlockHash,
+		IsLeader:               timing.IsLeader,
+		Finalized:              !timing.Finalized.IsZero(),
+		AnnounceToPreparedMs:   int64(timing.AnnounceToPrepared() / time.Millisecond),
+		PreparedToCommittedMs:  int64(timing.PreparedToCommitted() / time.Millisecond),
+		CommittedToFinalizedMs: int64(timing.CommittedToFinalized() / time.Millisecond),
+		PrepareSigners:         timing.PrepareSigners,
+		PreparePower:           timing.PreparePower,
+		CommitSigners:          timing.CommitSigners,
+		CommitPower:            timing.CommitPower,
+		LateSigners:            append([]string{}, timing.LateSigners...),
+		ViewChanges:            hexutil.Uint64(timing.ViewChanges),
+	}
+	if !timing.Announce.IsZero() {
+		result.AnnounceTime = timing.Announce.UnixNano() / int64(time.Millisecond)
+	}
+	return result
+}
+
+func newRPCConsensusTimeouts(timeouts shardingconfig.ConsensusTimeouts) RPCConsensusTimeouts {
+	return RPCConsensusTimeouts{
+		PhaseMs:            int64(timeouts.Phase / time.Millisecond),
+		BootstrapMs:        int64(timeouts.Bootstrap / time.Millisecond),
+		ViewChangeMs:       int64(timeouts.ViewChange / time.Millisecond),
+		MaxViewChangeMs:    int64(timeouts.MaxViewChange / time.Millisecond),
+		ExponentialBackoff: timeouts.ExponentialBackoff,
+	}
+}
+
 // newRPCTransaction returns a transaction that will serialize to the RPC
 // representation, with the given location metadata set (if available).
 func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber uint64, timestamp uint64, index uint64) *RPCTransaction {
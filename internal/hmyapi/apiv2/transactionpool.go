@@ -268,6 +268,19 @@ func (s *PublicTransactionPoolAPI) fillTransactionFields(tx *types.Transaction,
 	return nil
 }
 
+func (s *PublicTransactionPoolAPI) fillCXCallFields(cx *types.CXReceipt, fields map[string]interface{}) error {
+	var err error
+	fields["shardID"] = cx.ShardID
+	fields["toShardID"] = cx.ToShardID
+	if fields["from"], err = internal_common.AddressToBech32(cx.From); err != nil {
+		return err
+	}
+	if fields["to"], err = internal_common.AddressToBech32(*cx.To); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *PublicTransactionPoolAPI) fillStakingTransactionFields(stx *staking.StakingTransaction, fields map[string]interface{}) error {
 	from, err := stx.SenderAddress()
 	if err != nil {
@@ -285,12 +298,18 @@ func (s *PublicTransactionPoolAPI) fillStakingTransactionFields(stx *staking.Sta
 func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
 	var tx *types.Transaction
 	var stx *staking.StakingTransaction
+	var cx *types.CXReceipt
 	var blockHash common.Hash
 	var blockNumber, index uint64
 	tx, blockHash, blockNumber, index = rawdb.ReadTransaction(s.b.ChainDb(), hash)
 	if tx == nil {
 		stx, blockHash, blockNumber, index = rawdb.ReadStakingTransaction(s.b.ChainDb(), hash)
-		if stx == nil {
+	}
+	if tx == nil && stx == nil {
+		// the receipt of a cross-shard call run on this shard
+		cx, _, _, _ = rawdb.ReadCXReceipt(s.b.ChainDb(), hash)
+		blockHash, blockNumber, index = rawdb.ReadCXCallLookupEntry(s.b.ChainDb(), hash)
+		if cx == nil || blockHash == (common.Hash{}) {
 			return nil, nil
 		}
 	}
@@ -317,10 +336,14 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 		if err = s.fillTransactionFields(tx, fields); err != nil {
 			return nil, err
 		}
-	} else { // stx not nil
+	} else if stx != nil {
 		if err = s.fillStakingTransactionFields(stx, fields); err != nil {
 			return nil, err
 		}
+	} else { // cx not nil
+		if err = s.fillCXCallFields(cx, fields); err != nil {
+			return nil, err
+		}
 	}
 	// Assign receipt status or post state.
 	if len(receipt.PostState) > 0 {
@@ -426,3 +449,65 @@ func (s *PublicTransactionPoolAPI) GetCXReceiptByHash(ctx context.Context, hash
 func (s *PublicTransactionPoolAPI) GetPendingCXReceipts(ctx context.Context) []*types.CXReceiptsProof {
 	return s.b.GetPendingCXReceipts()
 }
+
+// GetTransactionReceiptProof returns the merkle proof of the receipt of the given
+// transaction against the receipt root of the block which includes it.
+func (s *PublicTransactionPoolAPI) GetTransactionReceiptProof(
+	ctx context.Context, hash common.Hash,
+) (*RPCReceiptProof, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(s.b.ChainDb(), hash)
+	isStaking := false
+	if tx == nil {
+		var stx *staking.StakingTransaction
+		stx, blockHash, blockNumber, index = rawdb.ReadStakingTransaction(s.b.ChainDb(), hash)
+		if stx == nil {
+			return nil, nil
+		}
+		isStaking = true
+	}
+	block, err := s.b.GetBlock(ctx, blockHash)
+	if block == nil {
+		return nil, err
+	}
+	// Staking transaction receipts follow the plain transaction receipts
+	if isStaking {
+		index += uint64(len(block.Transactions()))
+	}
+	receipts, err := s.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) <= int(index) {
+		return nil, nil
+	}
+	proof, err := types.DeriveShaProof(uint(index), receipts)
+	if err != nil {
+		return nil, err
+	}
+	key, err := rlp.EncodeToBytes(uint(index))
+	if err != nil {
+		return nil, err
+	}
+	return &RPCReceiptProof{
+		BlockHash:       blockHash,
+		BlockNumber:     blockNumber,
+		TransactionHash: hash,
+		ReceiptsRoot:    block.ReceiptHash(),
+		Index:           index,
+		Key:             key,
+		Receipt:         receipts.GetRlp(int(index)),
+		Proof:           common.ToHexArray(proof),
+	}, nil
+}
+
+// GetCXTransferStatus returns the lifecycle of the cross-shard transfer created by the
+// source transaction with the given hash. A node only knows its own shard and the beacon
+// chain, so the source shard reports the source block and crosslink, while the destination
+// shard reports the pending proof or the block in which the transfer got credited.
+func (s *PublicTransactionPoolAPI) GetCXTransferStatus(ctx context.Context, hash common.Hash) (*RPCCXTransferStatus, error) {
+	status, err := s.b.GetCXTransferStatus(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCCXTransferStatus(status), nil
+}
@@ -9,12 +9,16 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/api/service/syncing"
 	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/core/vm"
+	"github.com/harmony-one/harmony/hmy"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
 	"github.com/harmony-one/harmony/internal/hmyapi/apiv1"
 	"github.com/harmony-one/harmony/internal/hmyapi/apiv2"
 	"github.com/harmony-one/harmony/internal/params"
@@ -85,11 +89,20 @@ type Backend interface {
 	GetCurrentTransactionErrorSink() []types.RPCTransactionError
 	GetMedianRawStakeSnapshot() (*committee.CompletedEPoSRound, error)
 	GetPendingCXReceipts() []*types.CXReceiptsProof
+	GetCXTransferStatus(ctx context.Context, txHash common.Hash) (*hmy.CXTransferStatus, error)
 	GetCurrentUtilityMetrics() (*network.UtilityMetric, error)
 	GetSuperCommittees() (*quorum.Transition, error)
 	GetTotalStakingSnapshot() *big.Int
 	GetCurrentBadBlocks() []core.BadBlock
 	GetLastCrossLinks() ([]*types.CrossLink, error)
+	GetCrossLinks(ctx context.Context, shardID uint32, fromBlock, toBlock uint64) ([]*types.CrossLink, error)
+	GetCrossLinkInclusion(ctx context.Context, shardID uint32, blockNum uint64) (*hmy.CrossLinkInclusion, error)
+	GetPendingCrossLinks() ([]hmy.PendingCrossLink, error)
+	GetSyncProgress(isBeacon bool) syncing.Progress
+	GetConsensusTimings() []consensus.BlockTiming
+	GetConsensusTimeouts() shardingconfig.ConsensusTimeouts
+	GetBlockRandomness(ctx context.Context, blockNum uint64) (*hmy.BlockRandomness, error)
+	GetEpochRandomness(ctx context.Context, epoch *big.Int) (*hmy.EpochRandomness, error)
 }
 
 // GetAPIs returns all the APIs.
@@ -12,7 +12,9 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/harmony-one/harmony/api/service/syncing"
 	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/rawdb"
 	"github.com/harmony-one/harmony/core/types"
 )
 
@@ -216,6 +218,104 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 	return rpcSub, nil
 }
 
+// Syncing sends a notification each time the shard chain or the beacon chain
+// starts syncing, makes progress and catches up with the peers
+func (api *PublicFilterAPI) Syncing(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan syncing.SyncEvent, syncEvChanSize)
+		eventsSub := api.backend.SubscribeSyncEvent(events)
+
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev)
+			case <-rpcSub.Err():
+				eventsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				eventsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// CXReceiptNotification is sent to subscribers when an incoming cross-shard
+// receipt is spent, i.e. its amount credited, on this (destination) shard.
+type CXReceiptNotification struct {
+	TxHash            common.Hash `json:"hash"`
+	ShardID           uint32      `json:"shardID"`
+	ToShardID         uint32      `json:"toShardID"`
+	SourceBlockNumber uint64      `json:"sourceBlockNumber"`
+	SourceBlockHash   common.Hash `json:"sourceBlockHash"`
+	BlockNumber       uint64      `json:"blockNumber"`
+	BlockHash         common.Hash `json:"blockHash"`
+}
+
+// NewCXReceipts send a notification each time an incoming cross-shard receipt is
+// applied to the chain. When hashes is not empty, only the receipts of the given
+// source transactions are notified.
+func (api *PublicFilterAPI) NewCXReceipts(ctx context.Context, hashes []common.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	watched := make(map[common.Hash]struct{}, len(hashes))
+	for _, hash := range hashes {
+		watched[hash] = struct{}{}
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		cxps := make(chan []*types.CXReceiptsProof)
+		cxpsSub := api.events.SubscribeCXReceipts(cxps)
+
+		for {
+			select {
+			case proofs := <-cxps:
+				for _, cxp := range proofs {
+					if cxp.Header == nil || cxp.MerkleProof == nil {
+						continue
+					}
+					for _, cx := range cxp.Receipts {
+						if _, ok := watched[cx.TxHash]; len(watched) > 0 && !ok {
+							continue
+						}
+						blockHash, blockNumber, _ := rawdb.ReadCxLookupEntry(api.chainDb, cx.TxHash)
+						notifier.Notify(rpcSub.ID, &CXReceiptNotification{
+							TxHash:            cx.TxHash,
+							ShardID:           cx.ShardID,
+							ToShardID:         cx.ToShardID,
+							SourceBlockNumber: cxp.MerkleProof.BlockNum.Uint64(),
+							SourceBlockHash:   cxp.MerkleProof.BlockHash,
+							BlockNumber:       blockNumber,
+							BlockHash:         blockHash,
+						})
+					}
+				}
+			case <-rpcSub.Err():
+				cxpsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				cxpsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // GetFilterChanges returns the logs for the filter with the given id since
 // last time it was called. This can be used for polling.
 //
@@ -54,6 +54,9 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// CXReceiptsSubscription queries incoming cross-shard receipts proofs
+	// spent by blocks that are imported
+	CXReceiptsSubscription
 	// LastIndexSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -69,6 +72,8 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// syncEvChanSize is the size of channel listening to SyncEvent.
+	syncEvChanSize = 10
 )
 
 type subscription struct {
@@ -79,6 +84,7 @@ type subscription struct {
 	logs      chan []*types.Log
 	hashes    chan []common.Hash
 	headers   chan *block.Header
+	cxps      chan []*types.CXReceiptsProof
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -311,6 +317,23 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscript
 	return es.subscribe(sub)
 }
 
+// SubscribeCXReceipts creates a subscription that writes the incoming cross-shard
+// receipts proofs of a block that is imported in the chain.
+func (es *EventSystem) SubscribeCXReceipts(cxps chan []*types.CXReceiptsProof) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       CXReceiptsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   make(chan *block.Header),
+		cxps:      cxps,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 type filterIndex map[Type]map[rpc.ID]*subscription
 
 // broadcast event to filters that match criteria.
@@ -356,6 +379,11 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 		for _, f := range filters[BlocksSubscription] {
 			f.headers <- e.Block.Header()
 		}
+		if cxps := e.Block.IncomingReceipts(); len(cxps) > 0 {
+			for _, f := range filters[CXReceiptsSubscription] {
+				f.cxps <- cxps
+			}
+		}
 		if es.lightMode && len(filters[LogsSubscription]) > 0 {
 			es.lightFilterNewHead(e.Block.Header(), func(header *block.Header, remove bool) {
 				for _, f := range filters[LogsSubscription] {
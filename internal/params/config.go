@@ -25,77 +25,131 @@ var EpochTBD = big.NewInt(10000000)
 var (
 	// MainnetChainConfig is the chain parameters to run a node on the main network.
 	MainnetChainConfig = &ChainConfig{
-		ChainID:         MainnetChainID,
-		CrossTxEpoch:    big.NewInt(28),
-		CrossLinkEpoch:  EpochTBD,
-		StakingEpoch:    EpochTBD,
-		PreStakingEpoch: EpochTBD,
-		EIP155Epoch:     big.NewInt(28),
-		S3Epoch:         big.NewInt(28),
-		ReceiptLogEpoch: big.NewInt(101),
+		ChainID:                     MainnetChainID,
+		CrossTxEpoch:                big.NewInt(28),
+		CrossLinkEpoch:              EpochTBD,
+		StakingEpoch:                EpochTBD,
+		PreStakingEpoch:             EpochTBD,
+		EIP155Epoch:                 big.NewInt(28),
+		S3Epoch:                     big.NewInt(28),
+		ReceiptLogEpoch:             big.NewInt(101),
+		CrossShardCallEpoch:         EpochTBD,
+		LeaderRotationEpoch:         EpochTBD,
+		WeightedLeaderRotationEpoch: EpochTBD,
+		LeaderRotationBlocks:        64,
+		RandomnessPrecompileEpoch:   EpochTBD,
+		CompactBlockEpoch:           EpochTBD,
+		TxAnnounceEpoch:             EpochTBD,
+		VdfProofEpoch:               EpochTBD,
+		AggregateVoteEpoch:          EpochTBD,
 	}
 
 	// TestnetChainConfig contains the chain parameters to run a node on the harmony test network.
 	TestnetChainConfig = &ChainConfig{
-		ChainID:         TestnetChainID,
-		CrossTxEpoch:    big.NewInt(0),
-		CrossLinkEpoch:  big.NewInt(4),
-		StakingEpoch:    big.NewInt(4),
-		PreStakingEpoch: big.NewInt(2),
-		EIP155Epoch:     big.NewInt(0),
-		S3Epoch:         big.NewInt(0),
-		ReceiptLogEpoch: big.NewInt(0),
+		ChainID:                     TestnetChainID,
+		CrossTxEpoch:                big.NewInt(0),
+		CrossLinkEpoch:              big.NewInt(4),
+		StakingEpoch:                big.NewInt(4),
+		PreStakingEpoch:             big.NewInt(2),
+		EIP155Epoch:                 big.NewInt(0),
+		S3Epoch:                     big.NewInt(0),
+		ReceiptLogEpoch:             big.NewInt(0),
+		CrossShardCallEpoch:         EpochTBD,
+		LeaderRotationEpoch:         EpochTBD,
+		WeightedLeaderRotationEpoch: EpochTBD,
+		LeaderRotationBlocks:        64,
+		RandomnessPrecompileEpoch:   EpochTBD,
+		CompactBlockEpoch:           EpochTBD,
+		TxAnnounceEpoch:             EpochTBD,
+		VdfProofEpoch:               EpochTBD,
+		AggregateVoteEpoch:          EpochTBD,
 	}
 
 	// PangaeaChainConfig contains the chain parameters for the Pangaea network.
 	// All features except for CrossLink are enabled at launch.
 	PangaeaChainConfig = &ChainConfig{
-		ChainID:         PangaeaChainID,
-		CrossTxEpoch:    big.NewInt(0),
-		CrossLinkEpoch:  big.NewInt(2),
-		StakingEpoch:    big.NewInt(2),
-		PreStakingEpoch: big.NewInt(1),
-		EIP155Epoch:     big.NewInt(0),
-		S3Epoch:         big.NewInt(0),
-		ReceiptLogEpoch: big.NewInt(0),
+		ChainID:                     PangaeaChainID,
+		CrossTxEpoch:                big.NewInt(0),
+		CrossLinkEpoch:              big.NewInt(2),
+		StakingEpoch:                big.NewInt(2),
+		PreStakingEpoch:             big.NewInt(1),
+		EIP155Epoch:                 big.NewInt(0),
+		S3Epoch:                     big.NewInt(0),
+		ReceiptLogEpoch:             big.NewInt(0),
+		CrossShardCallEpoch:         EpochTBD,
+		LeaderRotationEpoch:         EpochTBD,
+		WeightedLeaderRotationEpoch: EpochTBD,
+		LeaderRotationBlocks:        64,
+		RandomnessPrecompileEpoch:   EpochTBD,
+		CompactBlockEpoch:           EpochTBD,
+		TxAnnounceEpoch:             EpochTBD,
+		VdfProofEpoch:               EpochTBD,
+		AggregateVoteEpoch:          EpochTBD,
 	}
 
 	// PartnerChainConfig contains the chain parameters for the Partner network.
 	// All features except for CrossLink are enabled at launch.
 	PartnerChainConfig = &ChainConfig{
-		ChainID:         PartnerChainID,
-		CrossTxEpoch:    big.NewInt(0),
-		CrossLinkEpoch:  big.NewInt(2),
-		StakingEpoch:    big.NewInt(2),
-		PreStakingEpoch: big.NewInt(1),
-		EIP155Epoch:     big.NewInt(0),
-		S3Epoch:         big.NewInt(0),
-		ReceiptLogEpoch: big.NewInt(0),
+		ChainID:                     PartnerChainID,
+		CrossTxEpoch:                big.NewInt(0),
+		CrossLinkEpoch:              big.NewInt(2),
+		StakingEpoch:                big.NewInt(2),
+		PreStakingEpoch:             big.NewInt(1),
+		EIP155Epoch:                 big.NewInt(0),
+		S3Epoch:                     big.NewInt(0),
+		ReceiptLogEpoch:             big.NewInt(0),
+		CrossShardCallEpoch:         EpochTBD,
+		LeaderRotationEpoch:         EpochTBD,
+		WeightedLeaderRotationEpoch: EpochTBD,
+		LeaderRotationBlocks:        64,
+		RandomnessPrecompileEpoch:   EpochTBD,
+		CompactBlockEpoch:           EpochTBD,
+		TxAnnounceEpoch:             EpochTBD,
+		VdfProofEpoch:               EpochTBD,
+		AggregateVoteEpoch:          EpochTBD,
 	}
 
 	// StressnetChainConfig contains the chain parameters for the Stress test network.
 	// All features except for CrossLink are enabled at launch.
 	StressnetChainConfig = &ChainConfig{
-		ChainID:         StressnetChainID,
-		CrossTxEpoch:    big.NewInt(0),
-		CrossLinkEpoch:  big.NewInt(2),
-		StakingEpoch:    big.NewInt(2),
-		PreStakingEpoch: big.NewInt(1),
-		EIP155Epoch:     big.NewInt(0),
-		S3Epoch:         big.NewInt(0),
-		ReceiptLogEpoch: big.NewInt(0),
+		ChainID:                     StressnetChainID,
+		CrossTxEpoch:                big.NewInt(0),
+		CrossLinkEpoch:              big.NewInt(2),
+		StakingEpoch:                big.NewInt(2),
+		PreStakingEpoch:             big.NewInt(1),
+		EIP155Epoch:                 big.NewInt(0),
+		S3Epoch:                     big.NewInt(0),
+		ReceiptLogEpoch:             big.NewInt(0),
+		CrossShardCallEpoch:         EpochTBD,
+		LeaderRotationEpoch:         EpochTBD,
+		WeightedLeaderRotationEpoch: EpochTBD,
+		LeaderRotationBlocks:        64,
+		RandomnessPrecompileEpoch:   EpochTBD,
+		CompactBlockEpoch:           EpochTBD,
+		TxAnnounceEpoch:             EpochTBD,
+		VdfProofEpoch:               EpochTBD,
+		AggregateVoteEpoch:          EpochTBD,
 	}
 
 	// LocalnetChainConfig contains the chain parameters to run for local development.
 	LocalnetChainConfig = &ChainConfig{
-		ChainID:         TestnetChainID,
-		CrossTxEpoch:    big.NewInt(0),
-		CrossLinkEpoch:  big.NewInt(2),
-		StakingEpoch:    big.NewInt(2),
-		PreStakingEpoch: big.NewInt(0),
-		EIP155Epoch:     big.NewInt(0),
-		S3Epoch:         big.NewInt(0),
-		ReceiptLogEpoch: big.NewInt(0),
+		ChainID:                     TestnetChainID,
+		CrossTxEpoch:                big.NewInt(0),
+		CrossLinkEpoch:              big.NewInt(2),
+		StakingEpoch:                big.NewInt(2),
+		PreStakingEpoch:             big.NewInt(0),
+		EIP155Epoch:                 big.NewInt(0),
+		S3Epoch:                     big.NewInt(0),
+		ReceiptLogEpoch:             big.NewInt(0),
+		CrossShardCallEpoch:         big.NewInt(0),
+		LeaderRotationEpoch:         big.NewInt(3),
+		WeightedLeaderRotationEpoch: big.NewInt(4),
+		LeaderRotationBlocks:        16,
+		RandomnessPrecompileEpoch:   big.NewInt(0),
+		CompactBlockEpoch:           big.NewInt(0),
+		TxAnnounceEpoch:             big.NewInt(0),
+		VdfProofEpoch:               big.NewInt(0),
+		AggregateVoteEpoch:          big.NewInt(0),
 	}
 
 	// AllProtocolChanges ...
@@ -110,6 +164,15 @@ var (
 		big.NewInt(0),             // EIP155Epoch
 		big.NewInt(0),             // S3Epoch
 		big.NewInt(0),             // ReceiptLogEpoch
+		big.NewInt(0),             // CrossShardCallEpoch
+		big.NewInt(0),             // LeaderRotationEpoch
+		big.NewInt(0),             // WeightedLeaderRotationEpoch
+		0,                         // LeaderRotationBlocks, no rotation unless set
+		big.NewInt(0),             // RandomnessPrecompileEpoch
+		big.NewInt(0),             // CompactBlockEpoch
+		big.NewInt(0),             // TxAnnounceEpoch
+		big.NewInt(0),             // VdfProofEpoch
+		big.NewInt(0),             // AggregateVoteEpoch
 	}
 
 	// TestChainConfig ...
@@ -124,6 +187,15 @@ var (
 		big.NewInt(0), // EIP155Epoch
 		big.NewInt(0), // S3Epoch
 		big.NewInt(0), // ReceiptLogEpoch
+		big.NewInt(0), // CrossShardCallEpoch
+		big.NewInt(0), // LeaderRotationEpoch
+		big.NewInt(0), // WeightedLeaderRotationEpoch
+		0,             // LeaderRotationBlocks, no rotation unless set
+		big.NewInt(0), // RandomnessPrecompileEpoch
+		big.NewInt(0), // CompactBlockEpoch
+		big.NewInt(0), // TxAnnounceEpoch
+		big.NewInt(0), // VdfProofEpoch
+		big.NewInt(0), // AggregateVoteEpoch
 	}
 
 	// TestRules ...
@@ -173,6 +245,42 @@ type ChainConfig struct {
 
 	// ReceiptLogEpoch is the first epoch support receiptlog
 	ReceiptLogEpoch *big.Int `json:"receipt-log-epoch,omitempty"`
+
+	// CrossShardCallEpoch is the first epoch where cross-shard transactions
+	// carrying calldata are executed as contract calls on the destination shard
+	CrossShardCallEpoch *big.Int `json:"cross-shard-call-epoch,omitempty"`
+
+	// LeaderRotationEpoch is the first epoch where the leadership passes to
+	// the next key of the committee every LeaderRotationBlocks blocks
+	LeaderRotationEpoch *big.Int `json:"leader-rotation-epoch,omitempty"`
+
+	// WeightedLeaderRotationEpoch is the first epoch where the key leading
+	// each turn of the leader rotation is drawn by voting power instead
+	WeightedLeaderRotationEpoch *big.Int `json:"weighted-leader-rotation-epoch,omitempty"`
+
+	// LeaderRotationBlocks is the number of blocks of a leader's turn; zero
+	// keeps the leader rotation off
+	LeaderRotationBlocks uint64 `json:"leader-rotation-blocks,omitempty"`
+
+	// RandomnessPrecompileEpoch is the first epoch where contracts read the
+	// VRF randomness of the recent blocks from a precompiled contract
+	RandomnessPrecompileEpoch *big.Int `json:"randomness-precompile-epoch,omitempty"`
+
+	// CompactBlockEpoch is the first epoch where leaders send the block of the
+	// prepared message, and nodes broadcast new blocks, in compact form
+	CompactBlockEpoch *big.Int `json:"compact-block-epoch,omitempty"`
+
+	// TxAnnounceEpoch is the first epoch where nodes gossip the hashes of new
+	// transactions instead of their bodies, and peers fetch the ones they miss
+	TxAnnounceEpoch *big.Int `json:"tx-announce-epoch,omitempty"`
+
+	// VdfProofEpoch is the first epoch where validators check the proof of the
+	// VDF of prepared blocks, once all of them hold the VRFs of its seed
+	VdfProofEpoch *big.Int `json:"vdf-proof-epoch,omitempty"`
+
+	// AggregateVoteEpoch is the first epoch where validators may aggregate the
+	// votes of their keys into one message, and slash records carry such votes
+	AggregateVoteEpoch *big.Int `json:"aggregate-vote-epoch,omitempty"`
 }
 
 // String implements the fmt.Stringer interface.
@@ -237,6 +345,53 @@ func (c *ChainConfig) IsReceiptLog(epoch *big.Int) bool {
 	return isForked(c.ReceiptLogEpoch, epoch)
 }
 
+// IsCrossShardCall returns whether epoch is either equal to the CrossShardCall fork epoch or greater.
+func (c *ChainConfig) IsCrossShardCall(epoch *big.Int) bool {
+	return isForked(c.CrossShardCallEpoch, epoch)
+}
+
+// IsRandomnessPrecompile returns whether epoch is either equal to the
+// RandomnessPrecompile fork epoch or greater.
+func (c *ChainConfig) IsRandomnessPrecompile(epoch *big.Int) bool {
+	return isForked(c.RandomnessPrecompileEpoch, epoch)
+}
+
+// IsCompactBlock returns whether blocks are sent in compact form to the
+// validators and to the other shards in the epoch.
+func (c *ChainConfig) IsCompactBlock(epoch *big.Int) bool {
+	return isForked(c.CompactBlockEpoch, epoch)
+}
+
+// IsTxAnnounce returns whether new transactions are gossiped as hash
+// announcements in the epoch, rather than in full.
+func (c *ChainConfig) IsTxAnnounce(epoch *big.Int) bool {
+	return isForked(c.TxAnnounceEpoch, epoch)
+}
+
+// IsVdfProof returns whether validators check the VDF proof of prepared
+// blocks in the epoch.
+func (c *ChainConfig) IsVdfProof(epoch *big.Int) bool {
+	return isForked(c.VdfProofEpoch, epoch)
+}
+
+// IsAggregateVote returns whether the votes of several keys of a validator may
+// be aggregated into one message in the epoch.
+func (c *ChainConfig) IsAggregateVote(epoch *big.Int) bool {
+	return isForked(c.AggregateVoteEpoch, epoch)
+}
+
+// IsLeaderRotation returns whether the leader rotates every LeaderRotationBlocks
+// blocks in the epoch.
+func (c *ChainConfig) IsLeaderRotation(epoch *big.Int) bool {
+	return c.LeaderRotationBlocks > 0 && isForked(c.LeaderRotationEpoch, epoch)
+}
+
+// IsWeightedLeaderRotation returns whether epoch is either equal to the
+// WeightedLeaderRotation fork epoch or greater.
+func (c *ChainConfig) IsWeightedLeaderRotation(epoch *big.Int) bool {
+	return isForked(c.WeightedLeaderRotationEpoch, epoch)
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
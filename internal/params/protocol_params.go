@@ -126,4 +126,6 @@ const (
 	Bn256PairingBaseGas uint64 = 100000 // Base price for an elliptic curve pairing check
 	// Bn256PairingPerPointGas ...
 	Bn256PairingPerPointGas uint64 = 80000 // Per-point price for an elliptic curve pairing check
+	// BlockRandomnessGas ...
+	BlockRandomnessGas uint64 = 800 // Gas needed for reading the VRF randomness of a recent block
 )
@@ -0,0 +1,77 @@
+package replay
+
+import (
+	"sync"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/reputation"
+	libp2p_host "github.com/libp2p/go-libp2p-host"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+)
+
+// errNoNetwork is returned for the operations needing a network
+var errNoNetwork = errors.New("replay host has no network")
+
+// host is a p2p.Host without network.  It collects the messages sent while a
+// step is replayed, and drops the ones sent in between, such as retries.
+type host struct {
+	self p2p.Peer
+	id   libp2p_peer.ID
+
+	mux       sync.Mutex
+	capturing bool
+	sent      [][]byte
+}
+
+func newHost(self p2p.Peer, id libp2p_peer.ID) *host {
+	return &host{self: self, id: id}
+}
+
+// capture starts collecting the messages sent
+func (h *host) capture() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.capturing, h.sent = true, nil
+}
+
+// collect stops collecting and returns the messages sent since capture
+func (h *host) collect() [][]byte {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	sent := h.sent
+	h.capturing, h.sent = false, nil
+	return sent
+}
+
+func (h *host) SendMessageToGroups(groups []nodeconfig.GroupID, msg []byte) error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	if h.capturing {
+		h.sent = append(h.sent, append(msg[:0:0], msg...))
+	}
+	return nil
+}
+
+func (h *host) GetSelfPeer() p2p.Peer             { return h.self }
+func (h *host) Close() error                      { return nil }
+func (h *host) AddPeer(*p2p.Peer) error           { return nil }
+func (h *host) GetID() libp2p_peer.ID             { return h.id }
+func (h *host) GetP2PHost() libp2p_host.Host      { return nil }
+func (h *host) GetPeerCount() int                 { return 0 }
+func (h *host) ConnectHostPeer(p2p.Peer)          {}
+func (h *host) BannedPeers() []reputation.Ban     { return nil }
+func (h *host) Peers() []p2p.PeerInfo             { return nil }
+func (h *host) AddStaticPeer(ma.Multiaddr) error  { return errNoNetwork }
+func (h *host) AddTrustedPeer(ma.Multiaddr) error { return errNoNetwork }
+func (h *host) RemovePeer(libp2p_peer.ID) error   { return errNoNetwork }
+func (h *host) UnbanPeer(libp2p_peer.ID) bool     { return false }
+func (h *host) BanPeer(libp2p_peer.ID, string)    {}
+
+func (h *host) ReportPeer(libp2p_peer.ID, reputation.Misbehaviour) {}
+
+func (h *host) GroupReceiver(nodeconfig.GroupID) (p2p.GroupReceiver, error) {
+	return nil, errNoNetwork
+}
@@ -0,0 +1,269 @@
+// Package replay replays a consensus recording into a consensus instance wired
+// to a host without network and an in-memory chain, step by step, reproducing
+// the phase transitions, timeouts and view changes of the recorded node.
+//
+// The chain starts from the network genesis, so a recording replays from the
+// first block of the network; recordings of later blocks run into the out of
+// sync handling a real node would.  The recorded node is replayed as a
+// validator: the blocks it proposed as leader are not proposed again, its
+// recorded messages are reported as they were sent.
+package replay
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/harmony-one/harmony/api/proto"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	"github.com/harmony-one/harmony/consensus"
+	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/recorder"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/shardchain"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/multibls"
+	"github.com/harmony-one/harmony/node"
+	"github.com/harmony-one/harmony/p2p"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/pkg/errors"
+)
+
+// p2pMsgPrefixSize is the size of the header of a p2p message
+const p2pMsgPrefixSize = 5
+
+// DefaultTickInterval is the interval the consensus main loop checks the
+// timeouts at
+const DefaultTickInterval = 3 * time.Second
+
+// Config is the configuration of a Replayer
+type Config struct {
+	ShardID uint32
+	// Key is the BLS key of the replayed node, a random key if nil
+	Key *multibls.PrivateKey
+	// TickInterval is the interval of the virtual clock the timeouts are
+	// checked at
+	TickInterval time.Duration
+}
+
+// State is the consensus state of the replayed node
+type State struct {
+	Mode           consensus.Mode
+	Phase          consensus.FBFTPhase
+	BlockNum       uint64
+	ViewID         uint64
+	ViewChangingID uint64
+	// Leader is the public key of the leader in hex
+	Leader string
+}
+
+// Step is a replayed record, or a tick of the virtual clock which changed the
+// consensus state or sent messages
+type Step struct {
+	Time time.Time
+	// Record is the replayed record, nil for a tick
+	Record *recorder.Record
+	// Message is the recorded message, nil for a tick
+	Message       *msg_pb.Message
+	Before, After State
+	// Sent are the messages the consensus sent on the step
+	Sent []*msg_pb.Message
+}
+
+// Replayer replays recordings into a consensus
+type Replayer struct {
+	config    Config
+	host      *host
+	consensus *consensus.Consensus
+
+	clockMux sync.Mutex
+	now      time.Time
+
+	// rebuilt are the recorded blocks rebuilt from compact blocks
+	rebuilt map[common.Hash]*types.Block
+}
+
+// New returns a replayer with a consensus on the genesis of the network
+func New(config Config) (*Replayer, error) {
+	if config.TickInterval == 0 {
+		config.TickInterval = DefaultTickInterval
+	}
+	if config.Key == nil {
+		key := bls.RandPrivateKey()
+		config.Key = multibls.GetPrivateKey(key)
+	}
+	self := p2p.Peer{ConsensusPubKey: config.Key.PrivateKey[0].GetPublicKey()}
+	r := &Replayer{
+		config:  config,
+		host:    newHost(self, libp2p_peer.ID("replay")),
+		rebuilt: map[common.Hash]*types.Block{},
+	}
+
+	decider := quorum.NewDecider(quorum.SuperMajorityVote, config.ShardID)
+	c, err := consensus.New(r.host, config.ShardID, self, config.Key, decider)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create consensus")
+	}
+	c.Decider.SetMyPublicKeyProvider(func() (*multibls.PublicKey, error) {
+		return c.PubKey, nil
+	})
+	c.SetClock(r.clock)
+
+	n := node.New(r.host, c, &shardchain.MemDBFactory{}, nil, false)
+	c.ChainReader = n.Blockchain()
+	if err := n.InitConsensusWithValidators(); err != nil {
+		utils.Logger().Warn().Err(err).Msg("[Replay] InitConsensusWithValidators failed")
+	}
+	c.SetViewID(n.Blockchain().CurrentBlock().Header().ViewID().Uint64() + 1)
+	c.BlockVerifier = n.VerifyNewBlock
+	c.BlockReconstructor = r.reconstructBlock
+	c.OnConsensusDone = n.PostConsensusProcessing
+	c.SetMode(c.UpdateConsensusInformation())
+
+	r.consensus = c
+	go r.drain()
+	return r, nil
+}
+
+// Consensus returns the replayed consensus
+func (r *Replayer) Consensus() *consensus.Consensus {
+	return r.consensus
+}
+
+// Replay replays the records, sorted by time, calling step for every step
+func (r *Replayer) Replay(records []*recorder.Record, step func(*Step)) error {
+	for _, record := range records {
+		if record.Direction != recorder.Rebuilt {
+			continue
+		}
+		block := &types.Block{}
+		if err := rlp.DecodeBytes(record.Payload, block); err != nil {
+			return errors.Wrap(err, "cannot decode rebuilt block")
+		}
+		r.rebuilt[block.Hash()] = block
+	}
+
+	var last time.Time
+	for _, record := range records {
+		if record.Direction == recorder.Rebuilt {
+			continue
+		}
+		at := record.Timestamp()
+		if !last.IsZero() {
+			for tick := last.Add(r.config.TickInterval); tick.Before(at); tick = tick.Add(r.config.TickInterval) {
+				r.tick(tick, step)
+			}
+		}
+		last = at
+		r.setClock(at)
+
+		msg := &msg_pb.Message{}
+		if err := protobuf.Unmarshal(record.Payload, msg); err != nil {
+			utils.Logger().Warn().Err(err).Time("time", at).Msg("[Replay] cannot decode recorded message")
+			continue
+		}
+		s := &Step{Time: at, Record: record, Message: msg, Before: r.state()}
+		if record.Direction == recorder.Received {
+			r.host.capture()
+			r.consensus.HandleMessageUpdate(record.Payload, record.Sender)
+			s.Sent = decodeSent(r.host.collect())
+		}
+		s.After = r.state()
+		step(s)
+	}
+	return nil
+}
+
+// tick checks the timeouts at the given time, and reports the step if the
+// state changed or messages were sent
+func (r *Replayer) tick(at time.Time, step func(*Step)) {
+	r.setClock(at)
+	before := r.state()
+	r.host.capture()
+	r.consensus.CheckTimeouts()
+	sent := decodeSent(r.host.collect())
+	after := r.state()
+	if before != after || len(sent) > 0 {
+		step(&Step{Time: at, Before: before, After: after, Sent: sent})
+	}
+}
+
+func (r *Replayer) state() State {
+	c := r.consensus
+	state := State{
+		Mode:           c.Mode(),
+		Phase:          c.Phase(),
+		BlockNum:       c.BlockNum(),
+		ViewID:         c.GetViewID(),
+		ViewChangingID: c.ViewChangingID(),
+	}
+	if c.LeaderPubKey != nil {
+		state.Leader = c.LeaderPubKey.SerializeToHexStr()
+	}
+	return state
+}
+
+func (r *Replayer) clock() time.Time {
+	r.clockMux.Lock()
+	defer r.clockMux.Unlock()
+	return r.now
+}
+
+func (r *Replayer) setClock(now time.Time) {
+	r.clockMux.Lock()
+	defer r.clockMux.Unlock()
+	r.now = now
+}
+
+// reconstructBlock returns the block the recorded node rebuilt from the
+// compact block, the replayed node has no transaction pool
+func (r *Replayer) reconstructBlock(
+	compact *types.CompactBlock, sender libp2p_peer.ID,
+) (*types.Block, error) {
+	if block, ok := r.rebuilt[compact.Hash()]; ok {
+		return block, nil
+	}
+	if block, err := compact.Block(); err == nil {
+		return block, nil
+	}
+	return nil, errors.Errorf("block %s was not rebuilt in the recording", compact.Hash().Hex())
+}
+
+// drain consumes the signals the block proposal and syncing services of a
+// node consume, which the replayed node does not run
+func (r *Replayer) drain() {
+	for {
+		select {
+		case <-r.consensus.ReadySignal:
+		case <-r.consensus.BlockNumLowChan:
+		}
+	}
+}
+
+// decodeSent returns the consensus messages of the sent p2p messages
+func decodeSent(sent [][]byte) []*msg_pb.Message {
+	msgs := []*msg_pb.Message{}
+	for _, data := range sent {
+		if len(data) <= p2pMsgPrefixSize {
+			continue
+		}
+		content := data[p2pMsgPrefixSize:]
+		category, err := proto.GetMessageCategory(content)
+		if err != nil || category != proto.Consensus {
+			continue
+		}
+		payload, err := proto.GetConsensusMessagePayload(content)
+		if err != nil {
+			continue
+		}
+		msg := &msg_pb.Message{}
+		if err := protobuf.Unmarshal(payload, msg); err != nil {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
@@ -0,0 +1,429 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/harmony-one/harmony/api/proto"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/reputation"
+	libp2p_host "github.com/libp2p/go-libp2p-host"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+)
+
+const (
+	// p2pMsgPrefixSize is the size of the header of a p2p message
+	p2pMsgPrefixSize = 5
+	// receiverQueueSize is the number of messages a receiver queues before it
+	// drops new ones, like a pubsub subscription of a slow reader
+	receiverQueueSize = 4096
+)
+
+// errNoNetwork is returned for the operations needing a real network
+var errNoNetwork = errors.New("simulated host has no real network")
+
+// Filter selects the messages a fault applies to
+type Filter struct {
+	// From and To are the indexes of the sending and receiving nodes, any
+	// node if empty
+	From, To []int
+	// Types are the types of the consensus messages, any message if empty
+	Types []msg_pb.MessageType
+}
+
+func (f *Filter) matches(from, to int, msgType *msg_pb.MessageType) bool {
+	if len(f.From) > 0 && !containsIndex(f.From, from) {
+		return false
+	}
+	if len(f.To) > 0 && !containsIndex(f.To, to) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	if msgType == nil {
+		return false
+	}
+	for _, t := range f.Types {
+		if t == *msgType {
+			return true
+		}
+	}
+	return false
+}
+
+// Fault is a fault of the delivery of the messages a filter selects
+type Fault struct {
+	Filter
+	// Drop drops the messages
+	Drop bool
+	// Delay delays the messages
+	Delay time.Duration
+	// Jitter delays the messages by a random duration up to itself on top of
+	// Delay, which reorders them
+	Jitter time.Duration
+}
+
+// Network is an in-memory network delivering the messages its hosts send to
+// the groups the other hosts receive, subject to the faults injected
+type Network struct {
+	mux    sync.RWMutex
+	hosts  []*Host
+	faults []*Fault
+	// partitions maps the index of a host to its partition, nil if none
+	partitions map[int]int
+	isolated   map[int]bool
+	// tamperers rewrite the messages a host sends
+	tamperers map[int]func([]byte) [][]byte
+	// slowness is the time a host takes to handle a consensus message
+	slowness map[int]time.Duration
+}
+
+// NewNetwork returns an empty network
+func NewNetwork() *Network {
+	return &Network{
+		isolated:  map[int]bool{},
+		tamperers: map[int]func([]byte) [][]byte{},
+		slowness:  map[int]time.Duration{},
+	}
+}
+
+// NewHost returns a new host of the network
+func (net *Network) NewHost(self p2p.Peer) *Host {
+	net.mux.Lock()
+	defer net.mux.Unlock()
+	index := len(net.hosts)
+	h := &Host{
+		net:       net,
+		index:     index,
+		id:        libp2p_peer.ID(fmt.Sprintf("node-%d", index)),
+		receivers: map[nodeconfig.GroupID][]*receiver{},
+	}
+	self.PeerID = h.id
+	h.self = self
+	net.hosts = append(net.hosts, h)
+	return h
+}
+
+// AddFault injects the fault
+func (net *Network) AddFault(fault *Fault) {
+	net.mux.Lock()
+	defer net.mux.Unlock()
+	net.faults = append(net.faults, fault)
+}
+
+// RemoveFault removes the injected fault
+func (net *Network) RemoveFault(fault *Fault) {
+	net.mux.Lock()
+	defer net.mux.Unlock()
+	for i, f := range net.faults {
+		if f == fault {
+			net.faults = append(net.faults[:i], net.faults[i+1:]...)
+			return
+		}
+	}
+}
+
+// Partition splits the network into the given partitions of host indexes,
+// the hosts of different partitions do not reach each other.  The hosts in no
+// partition reach every host.
+func (net *Network) Partition(partitions ...[]int) {
+	net.mux.Lock()
+	defer net.mux.Unlock()
+	net.partitions = map[int]int{}
+	for p, indexes := range partitions {
+		for _, index := range indexes {
+			net.partitions[index] = p
+		}
+	}
+}
+
+// Heal removes the partitions
+func (net *Network) Heal() {
+	net.mux.Lock()
+	defer net.mux.Unlock()
+	net.partitions = nil
+}
+
+// Isolate cuts the host off the network, for good
+func (net *Network) Isolate(index int) {
+	net.mux.Lock()
+	defer net.mux.Unlock()
+	net.isolated[index] = true
+}
+
+// Tamper rewrites the messages the host sends into the messages returned by
+// tamper, nil to send them untouched
+func (net *Network) Tamper(index int, tamper func(msg []byte) [][]byte) {
+	net.mux.Lock()
+	defer net.mux.Unlock()
+	if tamper == nil {
+		delete(net.tamperers, index)
+	} else {
+		net.tamperers[index] = tamper
+	}
+}
+
+// SlowDown makes the host take the given time to handle each consensus
+// message it receives, as if it verified signatures slowly
+func (net *Network) SlowDown(index int, perMessage time.Duration) {
+	net.mux.Lock()
+	defer net.mux.Unlock()
+	net.slowness[index] = perMessage
+}
+
+// publish delivers the message the host sent to the groups
+func (net *Network) publish(from *Host, groups []nodeconfig.GroupID, msg []byte) {
+	net.mux.RLock()
+	tamper := net.tamperers[from.index]
+	net.mux.RUnlock()
+	msgs := [][]byte{msg}
+	if tamper != nil {
+		msgs = tamper(msg)
+	}
+	for _, msg := range msgs {
+		net.deliver(from, groups, msg)
+	}
+}
+
+func (net *Network) deliver(from *Host, groups []nodeconfig.GroupID, msg []byte) {
+	msgType := consensusMessageType(msg)
+
+	net.mux.RLock()
+	defer net.mux.RUnlock()
+	if net.isolated[from.index] {
+		return
+	}
+	for _, to := range net.hosts {
+		if to == from || net.isolated[to.index] || !net.connected(from.index, to.index) {
+			continue
+		}
+		drop, delay := false, time.Duration(0)
+		for _, fault := range net.faults {
+			if !fault.matches(from.index, to.index, msgType) {
+				continue
+			}
+			drop = drop || fault.Drop
+			delay += fault.Delay
+			if fault.Jitter > 0 {
+				delay += time.Duration(rand.Int63n(int64(fault.Jitter)))
+			}
+		}
+		if drop {
+			continue
+		}
+		for _, r := range to.groupReceivers(groups) {
+			r.deliver(append(msg[:0:0], msg...), from.id, delay)
+		}
+	}
+}
+
+// connected returns whether the partitions let the hosts reach each other
+func (net *Network) connected(from, to int) bool {
+	if net.partitions == nil {
+		return true
+	}
+	p1, ok1 := net.partitions[from]
+	p2, ok2 := net.partitions[to]
+	return !ok1 || !ok2 || p1 == p2
+}
+
+func (net *Network) slownessOf(index int) time.Duration {
+	net.mux.RLock()
+	defer net.mux.RUnlock()
+	return net.slowness[index]
+}
+
+// Host is a p2p.Host of an in-memory network
+type Host struct {
+	net   *Network
+	index int
+	self  p2p.Peer
+	id    libp2p_peer.ID
+
+	mux       sync.Mutex
+	receivers map[nodeconfig.GroupID][]*receiver
+}
+
+// Index returns the index of the host in the network
+func (h *Host) Index() int {
+	return h.index
+}
+
+// SendMessageToGroups sends the message to the hosts receiving the groups
+func (h *Host) SendMessageToGroups(groups []nodeconfig.GroupID, msg []byte) error {
+	h.net.publish(h, groups, msg)
+	return nil
+}
+
+// GroupReceiver returns a receiver of the messages sent to the group
+func (h *Host) GroupReceiver(group nodeconfig.GroupID) (p2p.GroupReceiver, error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	r := &receiver{
+		host:   h,
+		ch:     make(chan delivery, receiverQueueSize),
+		closed: make(chan struct{}),
+	}
+	h.receivers[group] = append(h.receivers[group], r)
+	return r, nil
+}
+
+func (h *Host) groupReceivers(groups []nodeconfig.GroupID) []*receiver {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	receivers := []*receiver{}
+	for _, group := range groups {
+		receivers = append(receivers, h.receivers[group]...)
+	}
+	return receivers
+}
+
+// GetSelfPeer returns the peer of the host
+func (h *Host) GetSelfPeer() p2p.Peer { return h.self }
+
+// GetID returns the peer ID of the host
+func (h *Host) GetID() libp2p_peer.ID { return h.id }
+
+// GetPeerCount returns the number of the other hosts of the network
+func (h *Host) GetPeerCount() int {
+	h.net.mux.RLock()
+	defer h.net.mux.RUnlock()
+	return len(h.net.hosts) - 1
+}
+
+// Close does nothing, a host leaves the network by being isolated
+func (h *Host) Close() error { return nil }
+
+// AddPeer does nothing, every host of the network reaches every other
+func (h *Host) AddPeer(*p2p.Peer) error { return nil }
+
+// ConnectHostPeer does nothing, every host of the network reaches every other
+func (h *Host) ConnectHostPeer(p2p.Peer) {}
+
+// GetP2PHost returns nil, there is no libp2p host
+func (h *Host) GetP2PHost() libp2p_host.Host { return nil }
+
+// ReportPeer does nothing, the simulated hosts are not scored
+func (h *Host) ReportPeer(libp2p_peer.ID, reputation.Misbehaviour) {}
+
+// BanPeer does nothing, the simulated hosts are not scored
+func (h *Host) BanPeer(libp2p_peer.ID, string) {}
+
+// UnbanPeer returns false, no host is banned
+func (h *Host) UnbanPeer(libp2p_peer.ID) bool { return false }
+
+// BannedPeers returns no peers, no host is banned
+func (h *Host) BannedPeers() []reputation.Ban { return nil }
+
+// Peers returns no peers, there are no connections
+func (h *Host) Peers() []p2p.PeerInfo { return nil }
+
+// AddStaticPeer is not supported
+func (h *Host) AddStaticPeer(ma.Multiaddr) error { return errNoNetwork }
+
+// AddTrustedPeer is not supported
+func (h *Host) AddTrustedPeer(ma.Multiaddr) error { return errNoNetwork }
+
+// RemovePeer is not supported
+func (h *Host) RemovePeer(libp2p_peer.ID) error { return errNoNetwork }
+
+type delivery struct {
+	msg    []byte
+	sender libp2p_peer.ID
+}
+
+// receiver is a p2p.GroupReceiver of a host
+type receiver struct {
+	host   *Host
+	ch     chan delivery
+	once   sync.Once
+	closed chan struct{}
+}
+
+// deliver queues the message after the delay, dropped if the queue is full
+func (r *receiver) deliver(msg []byte, sender libp2p_peer.ID, delay time.Duration) {
+	enqueue := func() {
+		select {
+		case r.ch <- delivery{msg: msg, sender: sender}:
+		default:
+		}
+	}
+	if delay > 0 {
+		time.AfterFunc(delay, enqueue)
+	} else {
+		enqueue()
+	}
+}
+
+// Receive returns the next message, after the time the host takes to handle
+// it if a consensus message
+func (r *receiver) Receive(ctx context.Context) ([]byte, libp2p_peer.ID, error) {
+	select {
+	case d := <-r.ch:
+		if slowness := r.host.net.slownessOf(r.host.index); slowness > 0 &&
+			consensusMessageType(d.msg) != nil {
+			time.Sleep(slowness)
+		}
+		return d.msg, d.sender, nil
+	case <-r.closed:
+		return nil, "", p2p.ErrReceiverClosed
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+}
+
+// Close closes the receiver
+func (r *receiver) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
+
+// consensusMessageType returns the type of the consensus message, or nil if
+// the p2p message is not a consensus message
+func consensusMessageType(msg []byte) *msg_pb.MessageType {
+	consensusMsg := decodeConsensusMessage(msg)
+	if consensusMsg == nil {
+		return nil
+	}
+	msgType := consensusMsg.GetType()
+	return &msgType
+}
+
+// decodeConsensusMessage returns the consensus message of the p2p message, or
+// nil if not a consensus message
+func decodeConsensusMessage(msg []byte) *msg_pb.Message {
+	if len(msg) <= p2pMsgPrefixSize {
+		return nil
+	}
+	content := msg[p2pMsgPrefixSize:]
+	if category, err := proto.GetMessageCategory(content); err != nil || category != proto.Consensus {
+		return nil
+	}
+	payload, err := proto.GetConsensusMessagePayload(content)
+	if err != nil {
+		return nil
+	}
+	consensusMsg := &msg_pb.Message{}
+	if err := protobuf.Unmarshal(payload, consensusMsg); err != nil {
+		return nil
+	}
+	return consensusMsg
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
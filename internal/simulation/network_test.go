@@ -0,0 +1,122 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/harmony-one/harmony/api/proto"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/host"
+)
+
+var testGroup = nodeconfig.NewGroupIDByShardID(0)
+
+func newTestNetwork(t *testing.T, numHosts int) (*Network, []p2p.GroupReceiver) {
+	net := NewNetwork()
+	receivers := []p2p.GroupReceiver{}
+	for i := 0; i < numHosts; i++ {
+		r, err := net.NewHost(p2p.Peer{}).GroupReceiver(testGroup)
+		if err != nil {
+			t.Fatalf("GroupReceiver() error = %v", err)
+		}
+		receivers = append(receivers, r)
+	}
+	return net, receivers
+}
+
+func newTestConsensusMessage(t *testing.T, msgType msg_pb.MessageType) []byte {
+	payload, err := protobuf.Marshal(&msg_pb.Message{
+		ServiceType: msg_pb.ServiceType_CONSENSUS,
+		Type:        msgType,
+		Request:     &msg_pb.Message_Consensus{Consensus: &msg_pb.ConsensusRequest{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host.ConstructP2pMessage(byte(17), proto.ConstructConsensusMessage(payload))
+}
+
+// received returns whether the receiver gets a message within the timeout
+func received(r p2p.GroupReceiver, timeout time.Duration) ([]byte, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	msg, _, err := r.Receive(ctx)
+	return msg, err == nil
+}
+
+func TestNetwork_Deliver(t *testing.T) {
+	net, receivers := newTestNetwork(t, 3)
+	msg := newTestConsensusMessage(t, msg_pb.MessageType_PREPARE)
+	net.hosts[0].SendMessageToGroups([]nodeconfig.GroupID{testGroup}, msg)
+
+	if _, ok := received(receivers[0], 50*time.Millisecond); ok {
+		t.Error("sender received its own message")
+	}
+	for i := 1; i < 3; i++ {
+		got, ok := received(receivers[i], time.Second)
+		if !ok || string(got) != string(msg) {
+			t.Errorf("host %d did not receive the message", i)
+		}
+	}
+}
+
+func TestNetwork_Partition(t *testing.T) {
+	net, receivers := newTestNetwork(t, 3)
+	net.Partition([]int{0, 1}, []int{2})
+	net.hosts[0].SendMessageToGroups([]nodeconfig.GroupID{testGroup}, newTestConsensusMessage(t, msg_pb.MessageType_COMMIT))
+	if _, ok := received(receivers[1], time.Second); !ok {
+		t.Error("host of the same partition did not receive the message")
+	}
+	if _, ok := received(receivers[2], 50*time.Millisecond); ok {
+		t.Error("host of another partition received the message")
+	}
+
+	net.Heal()
+	net.hosts[0].SendMessageToGroups([]nodeconfig.GroupID{testGroup}, newTestConsensusMessage(t, msg_pb.MessageType_COMMIT))
+	if _, ok := received(receivers[2], time.Second); !ok {
+		t.Error("host did not receive the message after healing")
+	}
+}
+
+func TestNetwork_Faults(t *testing.T) {
+	net, receivers := newTestNetwork(t, 2)
+	groups := []nodeconfig.GroupID{testGroup}
+	drop := &Fault{Filter: Filter{Types: []msg_pb.MessageType{msg_pb.MessageType_PREPARE}}, Drop: true}
+	net.AddFault(drop)
+
+	net.hosts[0].SendMessageToGroups(groups, newTestConsensusMessage(t, msg_pb.MessageType_PREPARE))
+	if _, ok := received(receivers[1], 50*time.Millisecond); ok {
+		t.Error("dropped message received")
+	}
+	net.hosts[0].SendMessageToGroups(groups, newTestConsensusMessage(t, msg_pb.MessageType_COMMIT))
+	if _, ok := received(receivers[1], time.Second); !ok {
+		t.Error("message of another type not received")
+	}
+
+	net.RemoveFault(drop)
+	net.AddFault(&Fault{Filter: Filter{From: []int{0}}, Delay: 200 * time.Millisecond})
+	net.hosts[0].SendMessageToGroups(groups, newTestConsensusMessage(t, msg_pb.MessageType_PREPARE))
+	if _, ok := received(receivers[1], 50*time.Millisecond); ok {
+		t.Error("delayed message received early")
+	}
+	if _, ok := received(receivers[1], time.Second); !ok {
+		t.Error("delayed message not received")
+	}
+}
+
+func TestNetwork_Isolate(t *testing.T) {
+	net, receivers := newTestNetwork(t, 2)
+	net.Isolate(1)
+	net.hosts[0].SendMessageToGroups([]nodeconfig.GroupID{testGroup}, newTestConsensusMessage(t, msg_pb.MessageType_PREPARE))
+	if _, ok := received(receivers[1], 50*time.Millisecond); ok {
+		t.Error("isolated host received a message")
+	}
+	receivers[1].Close()
+	if _, _, err := receivers[1].Receive(context.Background()); err != p2p.ErrReceiverClosed {
+		t.Errorf("Receive() error = %v after Close, want %v", err, p2p.ErrReceiverClosed)
+	}
+}
@@ -0,0 +1,455 @@
+// Package simulation runs a network of full nodes in one process, each with
+// its consensus, transaction pool and in-memory chain, over an in-memory
+// network whose faults are scripted: partitions, dropped, delayed and
+// reordered messages, crashed nodes, double signers and slow verifiers.
+//
+// The simulated network has one shard, the beacon chain, whose committee is
+// the simulated nodes, and stays in the genesis epoch.  The nodes neither
+// discover peers nor sync, a node left behind catches up through consensus
+// only.  The sharding schedule and the network type are global, so a process
+// runs one simulation at a time.
+package simulation
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/api/proto"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	"github.com/harmony-one/harmony/consensus"
+	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/crypto/hash"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/genesis"
+	"github.com/harmony-one/harmony/internal/shardchain"
+	"github.com/harmony-one/harmony/multibls"
+	"github.com/harmony-one/harmony/node"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/host"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/slash"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/pkg/errors"
+)
+
+// pollInterval is the interval the waits check their condition at
+const pollInterval = 100 * time.Millisecond
+
+// Config is the configuration of a simulation
+type Config struct {
+	// NumNodes is the number of nodes, all members of the committee
+	NumNodes int
+	// BlockPeriod is the time the leader waits between proposals, 1 second
+	// if zero
+	BlockPeriod time.Duration
+	// Speedup makes the consensus timeouts elapse this many times faster than
+	// real time, 1 if zero
+	Speedup int
+}
+
+// Node is a node of the simulation
+type Node struct {
+	*node.Node
+	Index int
+	Key   *bls.SecretKey
+	Host  *Host
+
+	stop    func()
+	crashed bool
+}
+
+// Simulation is a network of nodes run in the process
+type Simulation struct {
+	config  Config
+	Network *Network
+	Nodes   []*Node
+	start   time.Time
+
+	mux         sync.Mutex
+	started     bool
+	doubleSigns []slash.Record
+}
+
+// New returns a simulation of a new network, whose nodes are not started
+func New(config Config) (*Simulation, error) {
+	if config.NumNodes < 1 {
+		return nil, errors.New("a simulation needs at least one node")
+	}
+	if config.BlockPeriod == 0 {
+		config.BlockPeriod = time.Second
+	}
+	if config.Speedup == 0 {
+		config.Speedup = 1
+	}
+	sim := &Simulation{config: config, Network: NewNetwork(), start: time.Now()}
+
+	keys := make([]*bls.SecretKey, config.NumNodes)
+	accounts := make([]genesis.DeployAccount, config.NumNodes)
+	for i := range keys {
+		keys[i] = bls_cosi.RandPrivateKey()
+		ecdsaKey, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		accounts[i] = genesis.DeployAccount{
+			Index:        strconv.Itoa(i),
+			Address:      crypto.PubkeyToAddress(ecdsaKey.PublicKey).Hex(),
+			BlsPublicKey: keys[i].GetPublicKey().SerializeToHexStr(),
+			ShardID:      shard.BeaconChainShardID,
+		}
+	}
+	instance, err := shardingconfig.NewInstance(
+		1, config.NumNodes, config.NumNodes, numeric.OneDec(), accounts, nil, nil,
+		shardingconfig.VLBPE,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create sharding config")
+	}
+	shard.Schedule = shardingconfig.NewFixedSchedule(instance)
+	nodeconfig.SetNetworkType(nodeconfig.Localnet)
+	shardConfig := nodeconfig.GetShardConfig(shard.BeaconChainShardID)
+	shardConfig.SetRole(nodeconfig.Validator)
+	shardConfig.SetShardGroupID(nodeconfig.NewGroupIDByShardID(shard.BeaconChainShardID))
+	shardConfig.SetClientGroupID(nodeconfig.NewClientGroupIDByShardID(shard.BeaconChainShardID))
+	shardConfig.SetBeaconGroupID(nodeconfig.NewGroupIDByShardID(shard.BeaconChainShardID))
+
+	for i, key := range keys {
+		n, err := sim.newNode(i, key, common.HexToAddress(accounts[i].Address))
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot create node %d", i)
+		}
+		sim.Nodes = append(sim.Nodes, n)
+	}
+	return sim, nil
+}
+
+// newNode returns a node set up the way the harmony command sets it up
+func (sim *Simulation) newNode(index int, key *bls.SecretKey, address common.Address) (*Node, error) {
+	h := sim.Network.NewHost(p2p.Peer{
+		IP:              "127.0.0.1",
+		Port:            strconv.Itoa(9000 + index),
+		ConsensusPubKey: key.GetPublicKey(),
+	})
+	decider := quorum.NewDecider(quorum.SuperMajorityVote, shard.BeaconChainShardID)
+	c, err := consensus.New(
+		h, shard.BeaconChainShardID, h.GetSelfPeer(), multibls.GetPrivateKey(key), decider,
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.Decider.SetMyPublicKeyProvider(func() (*multibls.PublicKey, error) {
+		return c.PubKey, nil
+	})
+	c.SelfAddresses = map[string]common.Address{
+		key.GetPublicKey().SerializeToHexStr(): address,
+	}
+	c.MinPeers = sim.config.NumNodes - 1
+	c.SetClock(sim.clock)
+
+	n := node.New(h, c, &shardchain.MemDBFactory{}, nil, false)
+	n.BlockPeriod = sim.config.BlockPeriod
+	n.FirstProposalDelay = sim.config.BlockPeriod
+	n.OnDoubleSign = sim.addDoubleSign
+	c.ChainReader = n.Blockchain()
+	if err := n.InitConsensusWithValidators(); err != nil {
+		return nil, err
+	}
+	c.SetViewID(n.Blockchain().CurrentBlock().Header().ViewID().Uint64() + 1)
+	c.BlockVerifier = n.VerifyNewBlock
+	c.BlockReconstructor = sim.reconstructBlock
+	c.OnConsensusDone = n.PostConsensusProcessing
+	n.State = node.NodeWaitToJoin
+	c.SetMode(c.UpdateConsensusInformation())
+
+	return &Node{Node: n, Index: index, Key: key, Host: h}, nil
+}
+
+// Start starts the nodes
+func (sim *Simulation) Start() {
+	sim.mux.Lock()
+	defer sim.mux.Unlock()
+	if sim.started {
+		return
+	}
+	sim.started = true
+	for _, n := range sim.Nodes {
+		peers := []*p2p.Peer{}
+		for _, other := range sim.Nodes {
+			if other != n {
+				peer := other.Host.GetSelfPeer()
+				peers = append(peers, &peer)
+			}
+		}
+		n.AddPeers(peers)
+	}
+	for _, n := range sim.Nodes {
+		n.stop = n.StartInProcess()
+	}
+}
+
+// Stop stops the nodes which have not crashed
+func (sim *Simulation) Stop() {
+	for _, n := range sim.Nodes {
+		sim.Crash(n.Index)
+	}
+}
+
+// Crash stops the node and cuts it off the network
+func (sim *Simulation) Crash(index int) {
+	sim.mux.Lock()
+	defer sim.mux.Unlock()
+	n := sim.Nodes[index]
+	if n.crashed {
+		return
+	}
+	n.crashed = true
+	sim.Network.Isolate(index)
+	if n.stop != nil {
+		n.stop()
+	}
+}
+
+// CrashLeader crashes the leader and returns its index
+func (sim *Simulation) CrashLeader() (int, error) {
+	leader, err := sim.Leader()
+	if err != nil {
+		return -1, err
+	}
+	sim.Crash(leader)
+	return leader, nil
+}
+
+// Live returns the nodes which have not crashed
+func (sim *Simulation) Live() []*Node {
+	sim.mux.Lock()
+	defer sim.mux.Unlock()
+	live := []*Node{}
+	for _, n := range sim.Nodes {
+		if !n.crashed {
+			live = append(live, n)
+		}
+	}
+	return live
+}
+
+// Leader returns the index of the node most live nodes take as leader
+func (sim *Simulation) Leader() (int, error) {
+	votes := map[string]int{}
+	for _, n := range sim.Live() {
+		if leader := n.Consensus.LeaderPubKey; leader != nil {
+			votes[leader.SerializeToHexStr()]++
+		}
+	}
+	leader, most := "", 0
+	for key, count := range votes {
+		if count > most {
+			leader, most = key, count
+		}
+	}
+	for _, n := range sim.Nodes {
+		if n.Key.GetPublicKey().SerializeToHexStr() == leader {
+			return n.Index, nil
+		}
+	}
+	return -1, errors.New("no leader agreed on")
+}
+
+// DoubleSign makes the node sign a conflicting block hash whenever it commits
+// to a block, and send both commits
+func (sim *Simulation) DoubleSign(index int) {
+	key := sim.Nodes[index].Key
+	sim.Network.Tamper(index, func(msg []byte) [][]byte {
+		consensusMsg := decodeConsensusMessage(msg)
+		if consensusMsg == nil || consensusMsg.GetType() != msg_pb.MessageType_COMMIT {
+			return [][]byte{msg}
+		}
+		conflicting, err := conflictingCommit(consensusMsg, key)
+		if err != nil {
+			return [][]byte{msg}
+		}
+		return [][]byte{msg, conflicting}
+	})
+}
+
+// SlowDown makes the node take the given time to handle each consensus
+// message, as if it verified signatures slowly
+func (sim *Simulation) SlowDown(index int, perMessage time.Duration) {
+	sim.Network.SlowDown(index, perMessage)
+}
+
+// WaitForHeight waits until the live nodes reached the block height
+func (sim *Simulation) WaitForHeight(height uint64, timeout time.Duration) error {
+	return waitFor(timeout, func() bool {
+		for _, n := range sim.Live() {
+			if n.Blockchain().CurrentBlock().NumberU64() < height {
+				return false
+			}
+		}
+		return true
+	}, func() error {
+		heights := []uint64{}
+		for _, n := range sim.Live() {
+			heights = append(heights, n.Blockchain().CurrentBlock().NumberU64())
+		}
+		return errors.Errorf("live nodes at heights %v, not %d", heights, height)
+	})
+}
+
+// CheckFinality returns an error if two nodes, crashed or not, hold different
+// blocks of a height
+func (sim *Simulation) CheckFinality() error {
+	for num := uint64(1); ; num++ {
+		var (
+			finalized common.Hash
+			holder    int
+			found     bool
+		)
+		for _, n := range sim.Nodes {
+			header := n.Blockchain().GetHeaderByNumber(num)
+			if header == nil {
+				continue
+			}
+			if !found {
+				finalized, holder, found = header.Hash(), n.Index, true
+			} else if header.Hash() != finalized {
+				return errors.Errorf(
+					"block %d is %s on node %d but %s on node %d",
+					num, finalized.Hex(), holder, header.Hash().Hex(), n.Index,
+				)
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+}
+
+// ViewChanges returns the number of views the chain of the node skipped, the
+// view changes which led to a block
+func (sim *Simulation) ViewChanges(index int) uint64 {
+	chain := sim.Nodes[index].Blockchain()
+	changes := uint64(0)
+	prev := chain.GetHeaderByNumber(0).ViewID().Uint64()
+	for num := uint64(1); num <= chain.CurrentBlock().NumberU64(); num++ {
+		viewID := chain.GetHeaderByNumber(num).ViewID().Uint64()
+		if viewID > prev+1 {
+			changes += viewID - prev - 1
+		}
+		prev = viewID
+	}
+	return changes
+}
+
+// WaitForViewChange waits until the live nodes agreed on a block after a view
+// change
+func (sim *Simulation) WaitForViewChange(timeout time.Duration) error {
+	return waitFor(timeout, func() bool {
+		for _, n := range sim.Live() {
+			if sim.ViewChanges(n.Index) == 0 {
+				return false
+			}
+		}
+		return true
+	}, func() error {
+		return errors.New("no view change led to a block")
+	})
+}
+
+// DoubleSigns returns the double signs the leaders noticed
+func (sim *Simulation) DoubleSigns() []slash.Record {
+	sim.mux.Lock()
+	defer sim.mux.Unlock()
+	return append([]slash.Record{}, sim.doubleSigns...)
+}
+
+// WaitForDoubleSign waits until a leader noticed a double sign
+func (sim *Simulation) WaitForDoubleSign(timeout time.Duration) error {
+	return waitFor(timeout, func() bool {
+		return len(sim.DoubleSigns()) > 0
+	}, func() error {
+		return errors.New("no double sign noticed")
+	})
+}
+
+func (sim *Simulation) addDoubleSign(record slash.Record) {
+	sim.mux.Lock()
+	defer sim.mux.Unlock()
+	sim.doubleSigns = append(sim.doubleSigns, record)
+}
+
+// clock is the clock of the consensus timeouts, running Speedup times faster
+// than real time
+func (sim *Simulation) clock() time.Time {
+	elapsed := time.Since(sim.start)
+	return sim.start.Add(elapsed * time.Duration(sim.config.Speedup))
+}
+
+// reconstructBlock rebuilds a compact block with the block the sender holds,
+// instead of fetching the missing transactions over the network
+func (sim *Simulation) reconstructBlock(
+	compact *types.CompactBlock, sender libp2p_peer.ID,
+) (*types.Block, error) {
+	if block, err := compact.Block(); err == nil {
+		return block, nil
+	}
+	for _, n := range sim.Nodes {
+		if n.Host.GetID() != sender {
+			continue
+		}
+		if block := n.Consensus.FBFTLog.GetBlockByHash(compact.Hash()); block != nil {
+			return block, nil
+		}
+	}
+	return nil, errors.Errorf("sender %s does not hold block %s", sender, compact.Hash().Hex())
+}
+
+// conflictingCommit returns the commit of the message signed on another block
+// hash
+func conflictingCommit(msg *msg_pb.Message, key *bls.SecretKey) ([]byte, error) {
+	conflicting := protobuf.Clone(msg).(*msg_pb.Message)
+	request := conflicting.GetConsensus()
+	blockHash := hash.Keccak256(request.BlockHash)
+	request.BlockHash = blockHash
+
+	blockNumHash := make([]byte, 8)
+	binary.LittleEndian.PutUint64(blockNumHash, request.BlockNum)
+	request.Payload = key.SignHash(append(blockNumHash, blockHash...)).Serialize()
+
+	conflicting.Signature = nil
+	marshaled, err := protobuf.Marshal(conflicting)
+	if err != nil {
+		return nil, err
+	}
+	msgHash := hash.Keccak256(marshaled)
+	conflicting.Signature = key.SignHash(msgHash).Serialize()
+	if marshaled, err = protobuf.Marshal(conflicting); err != nil {
+		return nil, err
+	}
+	return host.ConstructP2pMessage(byte(17), proto.ConstructConsensusMessage(marshaled)), nil
+}
+
+// waitFor polls the condition until it holds, or returns the error of
+// failure after the timeout
+func waitFor(timeout time.Duration, condition func() bool, failure func() error) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if condition() {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrap(failure(), fmt.Sprintf("after %s", timeout))
+		}
+		time.Sleep(pollInterval)
+	}
+}
@@ -0,0 +1,93 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+)
+
+// the consensus timeouts elapse in seconds, the view change timeout in three
+const testSpeedup = 20
+
+func newTestSimulation(t *testing.T, numNodes int) *Simulation {
+	if testing.Short() {
+		t.Skip("simulation runs for tens of seconds")
+	}
+	sim, err := New(Config{NumNodes: numNodes, Speedup: testSpeedup})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sim.Start()
+	return sim
+}
+
+func TestSimulation_Finality(t *testing.T) {
+	sim := newTestSimulation(t, 4)
+	defer sim.Stop()
+
+	if err := sim.WaitForHeight(3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.CheckFinality(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSimulation_LeaderCrash(t *testing.T) {
+	sim := newTestSimulation(t, 4)
+	defer sim.Stop()
+
+	if err := sim.WaitForHeight(1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	leader, err := sim.CrashLeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.WaitForViewChange(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if newLeader, err := sim.Leader(); err != nil || newLeader == leader {
+		t.Errorf("Leader() = %d, %v after the crash of leader %d", newLeader, err, leader)
+	}
+	if err := sim.CheckFinality(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSimulation_DoubleSign(t *testing.T) {
+	sim := newTestSimulation(t, 4)
+	defer sim.Stop()
+
+	leader, err := sim.Leader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := (leader + 1) % len(sim.Nodes)
+	// the conflicting commit reaches the leader after the honest one
+	sim.DoubleSign(signer)
+
+	if err := sim.WaitForDoubleSign(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	offender, n := sim.DoubleSigns()[0].Offender, sim.Nodes[signer]
+	if want := n.Consensus.SelfAddresses[n.Key.GetPublicKey().SerializeToHexStr()]; offender != want {
+		t.Errorf("offender %s, want %s", offender.Hex(), want.Hex())
+	}
+	if err := sim.CheckFinality(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSimulation_DelayedMessages(t *testing.T) {
+	sim := newTestSimulation(t, 4)
+	defer sim.Stop()
+
+	sim.Network.AddFault(&Fault{Delay: 50 * time.Millisecond, Jitter: 100 * time.Millisecond})
+	sim.SlowDown(0, 10*time.Millisecond)
+	if err := sim.WaitForHeight(3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.CheckFinality(); err != nil {
+		t.Error(err)
+	}
+}
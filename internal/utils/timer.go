@@ -19,29 +19,36 @@ type Timeout struct {
 	state TimeoutState
 	d     time.Duration
 	start time.Time
+	now   func() time.Time
 }
 
 // NewTimeout creates a new timeout class
 func NewTimeout(d time.Duration) *Timeout {
-	timeout := Timeout{state: Inactive, d: d, start: time.Now()}
+	return NewTimeoutWithClock(d, time.Now)
+}
+
+// NewTimeoutWithClock creates a new timeout class measuring time by the given
+// clock, such as the virtual clock of a replay
+func NewTimeoutWithClock(d time.Duration, now func() time.Time) *Timeout {
+	timeout := Timeout{state: Inactive, d: d, start: now(), now: now}
 	return &timeout
 }
 
 // Start starts the timeout clock
 func (timeout *Timeout) Start() {
 	timeout.state = Active
-	timeout.start = time.Now()
+	timeout.start = timeout.now()
 }
 
 // Stop stops the timeout clock
 func (timeout *Timeout) Stop() {
 	timeout.state = Inactive
-	timeout.start = time.Now()
+	timeout.start = timeout.now()
 }
 
 // CheckExpire checks whether the timeout is reached/expired
 func (timeout *Timeout) CheckExpire() bool {
-	if timeout.state == Active && time.Since(timeout.start) > timeout.d {
+	if timeout.state == Active && timeout.now().Sub(timeout.start) > timeout.d {
 		timeout.state = Expired
 	}
 	if timeout.state == Expired {
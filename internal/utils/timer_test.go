@@ -29,3 +29,17 @@ func TestCheckExpire(t *testing.T) {
 	}
 
 }
+
+func TestCheckExpireWithClock(t *testing.T) {
+	now := time.Unix(1000, 0)
+	timer := NewTimeoutWithClock(time.Second, func() time.Time { return now })
+	timer.Start()
+	now = now.Add(time.Second)
+	if timer.CheckExpire() {
+		t.Fatalf("CheckExpire should be false at the deadline")
+	}
+	now = now.Add(time.Millisecond)
+	if !timer.CheckExpire() {
+		t.Fatalf("CheckExpire should be true past the deadline")
+	}
+}
@@ -0,0 +1,263 @@
+// Package light implements a header-only light client of the harmony chain.
+//
+// The client follows the beacon chain from a trusted header by headers only.
+// Each header is verified against the aggregated BLS commit signature of the
+// committee of its epoch, and the committees of all shards are tracked from the
+// shard state carried by the last header of every epoch. Shard chain headers are
+// verified through the crosslinks included in trusted beacon headers, and state
+// and receipt proofs are verified against the roots of trusted headers.
+//
+// The client keeps a bounded number of headers in memory and needs no database,
+// fetching the headers is left to the caller, e.g. through hmyclient.
+package light
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/shard"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+)
+
+const (
+	headerCacheLimit      = 8192
+	shardHeaderCacheLimit = 8192
+	crossLinkCacheLimit   = 32768
+	// maxCommittees is the number of past epochs whose committees are kept
+	maxCommittees = 16
+)
+
+var (
+	errNotBeaconHeader   = errors.New("header is not a beacon chain header")
+	errNotChildOfHead    = errors.New("header does not extend the trusted head")
+	errBrokenHeaderChain = errors.New("headers are not contiguous")
+	errUnknownCommittee  = errors.New("committee of the epoch is not known")
+	errUnknownHeader     = errors.New("header is not trusted")
+	errNoCrossLink       = errors.New("no trusted crosslink for the shard block")
+	errCrossLinkMismatch = errors.New("crosslink does not match the shard header")
+	errEpochMismatch     = errors.New("shard state epoch does not match the trusted header")
+)
+
+type crossLinkKey struct {
+	shardID  uint32
+	blockNum uint64
+}
+
+// Client is a header-only light client of the beacon chain.
+// It is safe for concurrent use.
+type Client struct {
+	config *params.ChainConfig
+
+	mu sync.RWMutex
+	// head is the last verified beacon header
+	head *block.Header
+	// pending extends head and waits for the commit signature of its successor
+	pending *block.Header
+	// committees are the shard states by epoch, learnt from epoch headers
+	committees map[uint64]*shard.State
+
+	headers      *lru.Cache // hash -> verified beacon header
+	numbers      *lru.Cache // number -> hash of verified beacon header
+	shardHeaders *lru.Cache // hash -> verified shard header
+	crossLinks   *lru.Cache // crossLinkKey -> crosslink in verified beacon header
+}
+
+// New creates a light client trusting the given beacon header and the given shard
+// state as the committees of the header's epoch. Both are the trust anchor of the
+// client and must come from a trusted source, e.g. a hard-coded checkpoint.
+func New(
+	config *params.ChainConfig, trusted *block.Header, shardState *shard.State,
+) (*Client, error) {
+	if trusted.ShardID() != shard.BeaconChainShardID {
+		return nil, errNotBeaconHeader
+	}
+	if shardState.Epoch != nil && shardState.Epoch.Cmp(trusted.Epoch()) != 0 {
+		return nil, errors.Wrapf(
+			errEpochMismatch, "shard state epoch %v, header epoch %v",
+			shardState.Epoch, trusted.Epoch(),
+		)
+	}
+	headers, _ := lru.New(headerCacheLimit)
+	numbers, _ := lru.New(headerCacheLimit)
+	shardHeaders, _ := lru.New(shardHeaderCacheLimit)
+	crossLinks, _ := lru.New(crossLinkCacheLimit)
+	c := &Client{
+		config:       config,
+		committees:   map[uint64]*shard.State{},
+		headers:      headers,
+		numbers:      numbers,
+		shardHeaders: shardHeaders,
+		crossLinks:   crossLinks,
+	}
+	c.committees[trusted.Epoch().Uint64()] = shardState
+	if err := c.accept(trusted); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Head returns the last verified beacon header
+func (c *Client) Head() *block.Header {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.head
+}
+
+// Pending returns the beacon header extending the head which waits for the
+// commit signature of its successor, nil if none
+func (c *Client) Pending() *block.Header {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pending
+}
+
+// GetHeaderByHash returns the verified beacon or shard header with the given hash
+func (c *Client) GetHeaderByHash(hash common.Hash) *block.Header {
+	if h, ok := c.headers.Get(hash); ok {
+		return h.(*block.Header)
+	}
+	if h, ok := c.shardHeaders.Get(hash); ok {
+		return h.(*block.Header)
+	}
+	return nil
+}
+
+// GetHeaderByNumber returns the verified beacon header with the given number
+func (c *Client) GetHeaderByNumber(number uint64) *block.Header {
+	if hash, ok := c.numbers.Get(number); ok {
+		return c.GetHeaderByHash(hash.(common.Hash))
+	}
+	return nil
+}
+
+// Committee returns the committee of the given shard for the given epoch
+func (c *Client) Committee(epoch *big.Int, shardID uint32) (*shard.Committee, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.committee(epoch, shardID)
+}
+
+func (c *Client) committee(epoch *big.Int, shardID uint32) (*shard.Committee, error) {
+	shardState, ok := c.committees[epoch.Uint64()]
+	if !ok {
+		return nil, errors.Wrapf(errUnknownCommittee, "epoch %v", epoch)
+	}
+	return shardState.FindCommitteeByID(shardID)
+}
+
+// InsertHeader verifies the beacon header extending the head against the given
+// commit signature and bitmap of its committee, and makes it the new head
+func (c *Client) InsertHeader(
+	header *block.Header, commitSig []byte, commitBitmap []byte,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.checkChild(c.head, header); err != nil {
+		return err
+	}
+	if err := c.verifyCommit(header, commitSig, commitBitmap); err != nil {
+		return err
+	}
+	if err := c.accept(header); err != nil {
+		return err
+	}
+	c.pending = nil
+	return nil
+}
+
+// InsertHeaders verifies a contiguous batch of beacon headers extending the head,
+// or the pending header, each header being verified by the last commit signature
+// carried by its successor. The last header of the batch becomes the pending header
+// until its successor is inserted. It returns the number of headers which were
+// verified and became the head, which may include the previously pending header.
+func (c *Client) InsertHeaders(headers []*block.Header) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(headers) == 0 {
+		return 0, nil
+	}
+	chain := headers
+	if c.pending != nil && headers[0].ParentHash() == c.pending.Hash() {
+		chain = append([]*block.Header{c.pending}, headers...)
+	}
+	if err := c.checkChild(c.head, chain[0]); err != nil {
+		return 0, err
+	}
+	for i := 1; i < len(chain); i++ {
+		if err := c.checkChild(chain[i-1], chain[i]); err != nil {
+			return 0, errors.Wrapf(errBrokenHeaderChain, "at header %d: %v", i, err)
+		}
+	}
+
+	verified := 0
+	for i := 0; i+1 < len(chain); i++ {
+		sig := chain[i+1].LastCommitSignature()
+		if err := c.verifyCommit(chain[i], sig[:], chain[i+1].LastCommitBitmap()); err != nil {
+			c.pending = nil
+			return verified, err
+		}
+		if err := c.accept(chain[i]); err != nil {
+			c.pending = nil
+			return verified, err
+		}
+		verified++
+	}
+	c.pending = chain[len(chain)-1]
+	return verified, nil
+}
+
+// checkChild checks that the header is a beacon header directly extending the parent
+func (c *Client) checkChild(parent, header *block.Header) error {
+	if header.ShardID() != shard.BeaconChainShardID {
+		return errNotBeaconHeader
+	}
+	if header.ParentHash() != parent.Hash() ||
+		header.Number().Uint64() != parent.Number().Uint64()+1 {
+		return errors.Wrapf(
+			errNotChildOfHead, "parent %d %s, header %d with parent %s",
+			parent.Number().Uint64(), parent.Hash().Hex(),
+			header.Number().Uint64(), header.ParentHash().Hex(),
+		)
+	}
+	return nil
+}
+
+// accept makes the verified beacon header the head, and records the committees
+// and crosslinks it carries
+func (c *Client) accept(header *block.Header) error {
+	if len(header.ShardState()) > 0 {
+		shardState, err := header.GetShardState()
+		if err != nil {
+			return errors.Wrapf(err, "cannot decode shard state of header %d", header.Number())
+		}
+		epoch := new(big.Int).Add(header.Epoch(), common.Big1)
+		if shardState.Epoch != nil {
+			epoch = shardState.Epoch
+		}
+		c.committees[epoch.Uint64()] = &shardState
+		for e := range c.committees {
+			if e+maxCommittees <= epoch.Uint64() {
+				delete(c.committees, e)
+			}
+		}
+	}
+	if len(header.CrossLinks()) > 0 {
+		crossLinks := types.CrossLinks{}
+		if err := rlp.DecodeBytes(header.CrossLinks(), &crossLinks); err != nil {
+			return errors.Wrapf(err, "cannot decode crosslinks of header %d", header.Number())
+		}
+		for _, cl := range crossLinks {
+			c.crossLinks.Add(crossLinkKey{cl.ShardID(), cl.BlockNum()}, cl)
+		}
+	}
+	c.head = header
+	c.headers.Add(header.Hash(), header)
+	c.numbers.Add(header.Number().Uint64(), header.Hash())
+	return nil
+}
@@ -0,0 +1,216 @@
+package light
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/block"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+const testCommitteeSize = 4
+
+type testCommittee struct {
+	keys []*bls.SecretKey
+	pubs []*bls.PublicKey
+}
+
+func newTestCommittee(t *testing.T) (*testCommittee, *shard.State) {
+	tc := &testCommittee{}
+	slots := shard.SlotList{}
+	for i := 0; i < testCommitteeSize; i++ {
+		key := bls_cosi.RandPrivateKey()
+		pub := key.GetPublicKey()
+		blsPub := shard.BlsPublicKey{}
+		if err := blsPub.FromLibBLSPublicKey(pub); err != nil {
+			t.Fatal(err)
+		}
+		slots = append(slots, shard.Slot{
+			EcdsaAddress: common.BigToAddress(big.NewInt(int64(i + 1))),
+			BlsPublicKey: blsPub,
+		})
+		tc.keys = append(tc.keys, key)
+		tc.pubs = append(tc.pubs, pub)
+	}
+	shardState := &shard.State{
+		Epoch: big.NewInt(0),
+		Shards: []shard.Committee{
+			{ShardID: 0, Slots: slots},
+			{ShardID: 1, Slots: slots},
+		},
+	}
+	return tc, shardState
+}
+
+// sign returns the aggregated signature and bitmap of the first signers
+// of the committee on |blockNum|hash|
+func (tc *testCommittee) sign(
+	t *testing.T, signers int, blockNum uint64, hash common.Hash,
+) ([96]byte, []byte) {
+	mask, err := bls_cosi.NewMask(tc.pubs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, 8)
+	binary.LittleEndian.PutUint64(payload, blockNum)
+	payload = append(payload, hash[:]...)
+	sigs := []*bls.Sign{}
+	for i := 0; i < signers; i++ {
+		sigs = append(sigs, tc.keys[i].SignHash(payload))
+		if err := mask.SetKey(tc.pubs[i], true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig := [96]byte{}
+	copy(sig[:], bls_cosi.AggregateSig(sigs).Serialize())
+	return sig, mask.Bitmap
+}
+
+func testConfig() *params.ChainConfig {
+	config := *params.TestChainConfig
+	config.StakingEpoch = big.NewInt(1000)
+	return &config
+}
+
+func newTestHeader(shardID uint32, number uint64, parentHash common.Hash) *block.Header {
+	return blockfactory.NewTestHeader().With().
+		ShardID(shardID).
+		Number(new(big.Int).SetUint64(number)).
+		Epoch(big.NewInt(0)).
+		ParentHash(parentHash).
+		Header()
+}
+
+// newTestChild returns the child of the parent carrying the commit of the parent
+// by the first signers of the committee
+func (tc *testCommittee) newTestChild(
+	t *testing.T, parent *block.Header, signers int, crossLinks []byte,
+) *block.Header {
+	sig, bitmap := tc.sign(t, signers, parent.Number().Uint64(), parent.Hash())
+	return newTestHeader(0, parent.Number().Uint64()+1, parent.Hash()).With().
+		LastCommitSignature(sig).
+		LastCommitBitmap(bitmap).
+		CrossLinks(crossLinks).
+		Header()
+}
+
+func TestNewRejectsShardHeader(t *testing.T) {
+	_, shardState := newTestCommittee(t)
+	if _, err := New(testConfig(), newTestHeader(1, 10, common.Hash{}), shardState); errors.Cause(err) != errNotBeaconHeader {
+		t.Errorf("expected %v, got %v", errNotBeaconHeader, err)
+	}
+}
+
+func TestInsertHeaders(t *testing.T) {
+	tc, shardState := newTestCommittee(t)
+	trusted := newTestHeader(0, 10, common.Hash{})
+	client, err := New(testConfig(), trusted, shardState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h11 := tc.newTestChild(t, trusted, testCommitteeSize, nil)
+	h12 := tc.newTestChild(t, h11, testCommitteeSize, nil)
+	h13 := tc.newTestChild(t, h12, testCommitteeSize, nil)
+	verified, err := client.InsertHeaders([]*block.Header{h11, h12, h13})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified != 2 {
+		t.Errorf("expected 2 verified headers, got %d", verified)
+	}
+	if client.Head().Hash() != h12.Hash() || client.Pending().Hash() != h13.Hash() {
+		t.Errorf("unexpected head %d or pending header", client.Head().Number())
+	}
+
+	// The next batch confirms the pending header
+	h14 := tc.newTestChild(t, h13, testCommitteeSize, nil)
+	if verified, err = client.InsertHeaders([]*block.Header{h14}); err != nil || verified != 1 {
+		t.Fatalf("expected pending header to be verified, got %d, %v", verified, err)
+	}
+	if client.GetHeaderByNumber(13) == nil || client.Head().Hash() != h13.Hash() {
+		t.Error("pending header not verified")
+	}
+
+	// Headers which do not extend the chain are rejected
+	orphan := newTestHeader(0, 20, common.Hash{})
+	if _, err := client.InsertHeaders([]*block.Header{orphan}); errors.Cause(err) != errNotChildOfHead {
+		t.Errorf("expected %v, got %v", errNotChildOfHead, err)
+	}
+}
+
+func TestInsertHeaderWithoutQuorum(t *testing.T) {
+	tc, shardState := newTestCommittee(t)
+	trusted := newTestHeader(0, 10, common.Hash{})
+	client, err := New(testConfig(), trusted, shardState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h11 := newTestHeader(0, 11, trusted.Hash())
+	sig, bitmap := tc.sign(t, 2, 11, h11.Hash())
+	if err := client.InsertHeader(h11, sig[:], bitmap); errors.Cause(err) != errNotEnoughSigners {
+		t.Errorf("expected %v, got %v", errNotEnoughSigners, err)
+	}
+	sig, bitmap = tc.sign(t, 3, 11, h11.Hash())
+	if err := client.InsertHeader(h11, sig[:], bitmap); err != nil {
+		t.Fatal(err)
+	}
+	if client.Head().Hash() != h11.Hash() {
+		t.Error("header not inserted")
+	}
+}
+
+func TestVerifyShardHeader(t *testing.T) {
+	tc, shardState := newTestCommittee(t)
+	trusted := newTestHeader(0, 10, common.Hash{})
+	client, err := New(testConfig(), trusted, shardState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shardHeader := newTestHeader(1, 5, common.Hash{})
+	if err := client.VerifyShardHeader(shardHeader); errors.Cause(err) != errNoCrossLink {
+		t.Errorf("expected %v, got %v", errNoCrossLink, err)
+	}
+
+	sig, bitmap := tc.sign(t, testCommitteeSize, 5, shardHeader.Hash())
+	cl := types.CrossLink{
+		HashF:        shardHeader.Hash(),
+		BlockNumberF: big.NewInt(5),
+		SignatureF:   sig,
+		BitmapF:      bitmap,
+		ShardIDF:     1,
+		EpochF:       big.NewInt(0),
+	}
+	if err := client.VerifyCrossLink(cl); err != nil {
+		t.Fatal(err)
+	}
+	crossLinks, err := rlp.EncodeToBytes(types.CrossLinks{cl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h11 := tc.newTestChild(t, trusted, testCommitteeSize, crossLinks)
+	h12 := tc.newTestChild(t, h11, testCommitteeSize, nil)
+	if _, err := client.InsertHeaders([]*block.Header{h11, h12}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.VerifyShardHeader(shardHeader); err != nil {
+		t.Fatal(err)
+	}
+	if client.GetHeaderByHash(shardHeader.Hash()) == nil {
+		t.Error("shard header not trusted")
+	}
+	forged := newTestHeader(1, 5, common.Hash{1})
+	if err := client.VerifyShardHeader(forged); errors.Cause(err) != errCrossLinkMismatch {
+		t.Errorf("expected %v, got %v", errCrossLinkMismatch, err)
+	}
+}
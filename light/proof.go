@@ -0,0 +1,116 @@
+package light
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	errNotInTrie = errors.New("proof shows the key is not in the trie")
+)
+
+// VerifyAccountProof verifies the merkle proof of an account against the state
+// root of the trusted header with the given hash. It returns nil without error
+// if the proof shows the account does not exist.
+func (c *Client) VerifyAccountProof(
+	headerHash common.Hash, address common.Address, proof [][]byte,
+) (*state.Account, error) {
+	header := c.GetHeaderByHash(headerHash)
+	if header == nil {
+		return nil, errors.Wrapf(errUnknownHeader, "hash %s", headerHash.Hex())
+	}
+	return VerifyAccountProof(header.Root(), address, proof)
+}
+
+// VerifyReceiptProof verifies the merkle proof of the receipt at the given
+// position against the receipt root of the trusted header with the given hash
+func (c *Client) VerifyReceiptProof(
+	headerHash common.Hash, index uint, proof [][]byte,
+) (*types.Receipt, error) {
+	header := c.GetHeaderByHash(headerHash)
+	if header == nil {
+		return nil, errors.Wrapf(errUnknownHeader, "hash %s", headerHash.Hex())
+	}
+	return VerifyReceiptProof(header.ReceiptHash(), index, proof)
+}
+
+// VerifyAccountProof verifies the merkle proof of an account against a state root.
+// It returns nil without error if the proof shows the account does not exist.
+func VerifyAccountProof(
+	stateRoot common.Hash, address common.Address, proof [][]byte,
+) (*state.Account, error) {
+	value, err := verifyProof(stateRoot, crypto.Keccak256(address.Bytes()), proof)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	account := &state.Account{}
+	if err := rlp.DecodeBytes(value, account); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode account %s", address.Hex())
+	}
+	return account, nil
+}
+
+// VerifyStorageProof verifies the merkle proof of a storage slot against the
+// storage root of an account, as returned by VerifyAccountProof. Slots which
+// the proof shows are not set have the zero value.
+func VerifyStorageProof(
+	storageRoot common.Hash, key common.Hash, proof [][]byte,
+) (common.Hash, error) {
+	value, err := verifyProof(storageRoot, crypto.Keccak256(key.Bytes()), proof)
+	if err != nil || value == nil {
+		return common.Hash{}, err
+	}
+	_, content, _, err := rlp.Split(value)
+	if err != nil {
+		return common.Hash{}, errors.Wrapf(err, "cannot decode storage slot %s", key.Hex())
+	}
+	return common.BytesToHash(content), nil
+}
+
+// VerifyReceiptProof verifies the merkle proof of the receipt at the given
+// position, as created by types.DeriveShaProof, against a receipt root
+func VerifyReceiptProof(
+	receiptRoot common.Hash, index uint, proof [][]byte,
+) (*types.Receipt, error) {
+	key, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		return nil, err
+	}
+	value, err := verifyProof(receiptRoot, key, proof)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errors.Wrapf(errNotInTrie, "receipt %d", index)
+	}
+	receipt := &types.Receipt{}
+	if err := rlp.DecodeBytes(value, receipt); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode receipt %d", index)
+	}
+	return receipt, nil
+}
+
+// verifyProof returns the value proven by the merkle proof for the key, or nil
+// if the proof shows the key is not in the trie
+func verifyProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	db := ethdb.NewMemDatabase()
+	for _, node := range proof {
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+	value, _, err := trie.VerifyProof(root, key, db)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proof against root %s", root.Hex())
+	}
+	return value, nil
+}
@@ -0,0 +1,88 @@
+package light
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+func TestVerifyAccountAndStorageProof(t *testing.T) {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	addr := common.BigToAddress(big.NewInt(0x1234))
+	key, value := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(42))
+	db.AddBalance(addr, big.NewInt(1000))
+	db.SetNonce(addr, 7)
+	db.SetState(addr, key, value)
+	root, err := db.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, _ = state.New(root, db.Database())
+
+	proof, err := db.GetProof(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := VerifyAccountProof(root, addr, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account == nil || account.Nonce != 7 || account.Balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("unexpected account %+v", account)
+	}
+
+	storageProof, err := db.GetStorageProof(addr, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := VerifyStorageProof(account.Root, key, storageProof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != value {
+		t.Errorf("expected storage value %s, got %s", value.Hex(), got.Hex())
+	}
+
+	// Proof of absence
+	missing := common.BigToAddress(big.NewInt(0x5678))
+	proof, err = db.GetProof(missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account, err = VerifyAccountProof(root, missing, proof); err != nil || account != nil {
+		t.Errorf("expected no account, got %+v, %v", account, err)
+	}
+
+	// Proof against another root
+	if _, err := VerifyAccountProof(common.Hash{1}, addr, proof); err == nil {
+		t.Error("expected proof to fail against a wrong root")
+	}
+}
+
+func TestVerifyReceiptProof(t *testing.T) {
+	receipts := types.Receipts{}
+	for i := 0; i < 10; i++ {
+		receipts = append(receipts, types.NewReceipt(nil, false, uint64(21000*(i+1))))
+	}
+	root := types.DeriveSha(receipts)
+	for i := range receipts {
+		proof, err := types.DeriveShaProof(uint(i), receipts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		receipt, err := VerifyReceiptProof(root, uint(i), proof)
+		if err != nil {
+			t.Fatalf("receipt %d: %v", i, err)
+		}
+		if receipt.CumulativeGasUsed != receipts[i].CumulativeGasUsed {
+			t.Errorf("receipt %d: unexpected cumulative gas %d", i, receipt.CumulativeGasUsed)
+		}
+		if _, err := VerifyReceiptProof(root, uint(i+1), proof); err == nil {
+			t.Errorf("receipt %d: expected proof to fail for another index", i)
+		}
+	}
+}
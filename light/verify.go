@@ -0,0 +1,141 @@
+package light
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/verify"
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidCommitSig = errors.New("invalid commit signature")
+	errNotEnoughSigners = errors.New("not enough signers in commit bitmap")
+)
+
+// verifyCommit verifies the commit signature and bitmap of a header against the
+// committee of the header's shard for the header's epoch
+func (c *Client) verifyCommit(
+	header *block.Header, commitSig []byte, commitBitmap []byte,
+) error {
+	committee, err := c.committee(header.Epoch(), header.ShardID())
+	if err != nil {
+		return err
+	}
+	if err := c.verifyCommitSig(
+		committee, header.Epoch(), header.Hash(), header.Number().Uint64(),
+		commitSig, commitBitmap,
+	); err != nil {
+		return errors.Wrapf(
+			err, "shard %d block %d %s",
+			header.ShardID(), header.Number().Uint64(), header.Hash().Hex(),
+		)
+	}
+	return nil
+}
+
+// verifyCommitSig verifies that the aggregated signature on |blockNum|hash| is
+// signed by a quorum of the committee, the quorum is by voting power after
+// staking is enabled and by number of slots before.
+func (c *Client) verifyCommitSig(
+	committee *shard.Committee, epoch *big.Int,
+	hash common.Hash, blockNum uint64, commitSig []byte, commitBitmap []byte,
+) error {
+	if len(commitSig) != 96 {
+		return errors.Wrapf(errInvalidCommitSig, "signature length %d", len(commitSig))
+	}
+	aggSig := &bls.Sign{}
+	if err := aggSig.Deserialize(commitSig); err != nil {
+		return errors.Wrap(errInvalidCommitSig, err.Error())
+	}
+	if c.config.IsStaking(epoch) {
+		return verify.AggregateSigForCommittee(
+			committee, aggSig, hash, blockNum, epoch, commitBitmap,
+		)
+	}
+
+	publicKeys, err := committee.BLSPublicKeys()
+	if err != nil {
+		return err
+	}
+	mask, err := bls_cosi.NewMask(publicKeys, nil)
+	if err != nil {
+		return err
+	}
+	if err := mask.SetMask(commitBitmap); err != nil {
+		return err
+	}
+	need := int64(len(committee.Slots)*2/3 + 1)
+	if count := utils.CountOneBits(mask.Bitmap); count < need {
+		return errors.Wrapf(errNotEnoughSigners, "need %d, got %d", need, count)
+	}
+	blockNumBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(blockNumBytes, blockNum)
+	commitPayload := append(blockNumBytes, hash[:]...)
+	if !aggSig.VerifyHash(mask.AggregatePublic, commitPayload) {
+		return errInvalidCommitSig
+	}
+	return nil
+}
+
+// VerifyCrossLink verifies the signature of the crosslink against the
+// committee of its shard for its epoch
+func (c *Client) VerifyCrossLink(cl types.CrossLink) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	committee, err := c.committee(cl.Epoch(), cl.ShardID())
+	if err != nil {
+		return err
+	}
+	sig := cl.Signature()
+	return c.verifyCommitSig(
+		committee, cl.Epoch(), cl.Hash(), cl.BlockNum(), sig[:], cl.Bitmap(),
+	)
+}
+
+// VerifyShardHeader verifies a shard chain header against the crosslink of the
+// shard block included in a trusted beacon header. Beacon chain headers are
+// verified against the trusted beacon headers.
+func (c *Client) VerifyShardHeader(header *block.Header) error {
+	if header.ShardID() == shard.BeaconChainShardID {
+		if c.GetHeaderByHash(header.Hash()) == nil {
+			return errors.Wrapf(errUnknownHeader, "beacon block %d", header.Number().Uint64())
+		}
+		return nil
+	}
+	v, ok := c.crossLinks.Get(crossLinkKey{header.ShardID(), header.Number().Uint64()})
+	if !ok {
+		return errors.Wrapf(
+			errNoCrossLink, "shard %d block %d", header.ShardID(), header.Number().Uint64(),
+		)
+	}
+	if cl := v.(types.CrossLink); cl.Hash() != header.Hash() {
+		return errors.Wrapf(
+			errCrossLinkMismatch, "crosslink %s, header %s", cl.Hash().Hex(), header.Hash().Hex(),
+		)
+	}
+	c.shardHeaders.Add(header.Hash(), header)
+	return nil
+}
+
+// VerifyShardHeaderWithSignature verifies a shard chain header which is not yet
+// crosslinked against the given commit signature and bitmap of its committee
+func (c *Client) VerifyShardHeaderWithSignature(
+	header *block.Header, commitSig []byte, commitBitmap []byte,
+) error {
+	c.mu.RLock()
+	err := c.verifyCommit(header, commitSig, commitBitmap)
+	c.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	c.shardHeaders.Add(header.Hash(), header)
+	return nil
+}
@@ -1,5 +1,6 @@
-// Package msgq implements a simple, finite-sized message queue.  It can be used
-// as a building block for a message processor pool.
+// Package msgq implements a simple, finite-sized message queue, and a priority
+// message queue with one such queue per message category.  They can be used as
+// building blocks for a message processor pool.
 package msgq
 
 import (
@@ -0,0 +1,279 @@
+package msgq
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+)
+
+// Category is the category of a message.  Each category has its own queue,
+// so a burst of messages of one category cannot crowd out the others.
+type Category int
+
+// Message categories, from the highest priority to the lowest.
+const (
+	Consensus Category = iota
+	ViewChange
+	Block
+	CrossShard
+	Transaction
+	Other
+	// NumCategories is the number of message categories.
+	NumCategories
+)
+
+func (c Category) String() string {
+	switch c {
+	case Consensus:
+		return "consensus"
+	case ViewChange:
+		return "viewchange"
+	case Block:
+		return "block"
+	case CrossShard:
+		return "crossshard"
+	case Transaction:
+		return "transaction"
+	case Other:
+		return "other"
+	}
+	return "unknown"
+}
+
+// Classifier returns the category of a received message.  It must be cheap,
+// as it runs on the receiving goroutine before the message is queued.
+type Classifier func(content []byte) Category
+
+// CategoryConfig is the queueing and dispatch configuration of a category.
+type CategoryConfig struct {
+	// Size is the number of messages to queue before tail-dropping.
+	Size int
+	// Weight is the number of messages dispatched per round while messages of
+	// other weighted categories are pending.  Categories of zero weight are
+	// dispatched first, ahead of all the weighted ones.
+	Weight int
+	// Workers is the maximum number of messages of the category handled
+	// concurrently, so one category cannot take up the whole worker pool.
+	Workers int
+}
+
+// Stats is a snapshot of the counters of a category queue.
+type Stats struct {
+	Category Category `json:"category"`
+	Len      int      `json:"len"`
+	Busy     int      `json:"busy"`
+	Added    uint64   `json:"added"`
+	Dropped  uint64   `json:"dropped"`
+	Handled  uint64   `json:"handled"`
+}
+
+// categoryQueue is the queue of a category along with its dispatch state.
+type categoryQueue struct {
+	CategoryConfig
+	messages []message
+	busy     int
+	credits  int
+	added    uint64
+	dropped  uint64
+	handled  uint64
+}
+
+// PriorityQueue is a set of finite-sized message queues, one per category,
+// drained by a shared pool of workers.  The categories of zero weight, e.g.
+// consensus, are always dispatched first; the others share the workers by
+// weighted round robin.  The worker limit of each category keeps some workers
+// free for the higher priority messages.
+type PriorityQueue struct {
+	classify Classifier
+	mux      sync.Mutex
+	cond     *sync.Cond
+	queues   [NumCategories]*categoryQueue
+	closed   bool
+}
+
+// NewPriorityQueue returns a new priority message queue classifying the
+// messages with the given classifier.
+func NewPriorityQueue(
+	classify Classifier, configs [NumCategories]CategoryConfig,
+) (*PriorityQueue, error) {
+	q := &PriorityQueue{classify: classify}
+	q.cond = sync.NewCond(&q.mux)
+	for c, config := range configs {
+		if config.Size < 0 || config.Weight < 0 || config.Workers <= 0 {
+			return nil, errors.Wrapf(
+				ErrInvalidConfig, "%s: %+v", Category(c), config,
+			)
+		}
+		q.queues[c] = &categoryQueue{CategoryConfig: config, credits: config.Weight}
+	}
+	return q, nil
+}
+
+// AddMessage enqueues a received message for processing.  It returns without
+// blocking, and returns a queue overrun error if the queue of the message
+// category is full.
+func (q *PriorityQueue) AddMessage(content []byte, sender peer.ID) error {
+	c := q.classify(content)
+	if c < 0 || c >= NumCategories {
+		c = Other
+	}
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	cq := q.queues[c]
+	if len(cq.messages) >= cq.Size {
+		cq.dropped++
+		return ErrRxOverrun
+	}
+	cq.messages = append(cq.messages, message{content, sender})
+	cq.added++
+	q.cond.Signal()
+	return nil
+}
+
+// HandleMessages dequeues and dispatches incoming messages using the given
+// message handler, until the message queue is closed and drained.  This
+// function is spawned as a background goroutine once per pool worker.
+func (q *PriorityQueue) HandleMessages(h MessageHandler) {
+	for {
+		msg, c, ok := q.next()
+		if !ok {
+			return
+		}
+		h.HandleMessage(msg.content, msg.sender)
+		q.done(c)
+	}
+}
+
+// Close closes the queue.  Queued messages are still handled.
+func (q *PriorityQueue) Close() error {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+	return nil
+}
+
+// Stats returns the counters of the category queues.
+func (q *PriorityQueue) Stats() []Stats {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	stats := make([]Stats, 0, NumCategories)
+	for c, cq := range q.queues {
+		stats = append(stats, Stats{
+			Category: Category(c),
+			Len:      len(cq.messages),
+			Busy:     cq.busy,
+			Added:    cq.added,
+			Dropped:  cq.dropped,
+			Handled:  cq.handled,
+		})
+	}
+	return stats
+}
+
+// next waits for the next message to dispatch, returning false once the
+// queue is closed and drained
+func (q *PriorityQueue) next() (message, Category, bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	for {
+		if c, ok := q.pick(); ok {
+			cq := q.queues[c]
+			msg := cq.messages[0]
+			cq.messages[0] = message{}
+			cq.messages = cq.messages[1:]
+			cq.busy++
+			// wake another worker if there is more to dispatch
+			if _, more := q.peek(); more {
+				q.cond.Signal()
+			}
+			return msg, c, true
+		}
+		if q.closed && q.empty() {
+			return message{}, 0, false
+		}
+		q.cond.Wait()
+	}
+}
+
+// done marks a message of the category handled, the caller must not hold q.mux
+func (q *PriorityQueue) done(c Category) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	cq := q.queues[c]
+	cq.busy--
+	cq.handled++
+	// a worker may be waiting for the category to fall below its worker limit
+	q.cond.Signal()
+	if q.closed && q.empty() {
+		q.cond.Broadcast()
+	}
+}
+
+// ready returns whether a message of the category can be dispatched now
+func (cq *categoryQueue) ready() bool {
+	return len(cq.messages) > 0 && cq.busy < cq.Workers
+}
+
+// peek returns the category of the next message to dispatch without taking
+// its weight credit, the caller must hold q.mux
+func (q *PriorityQueue) peek() (Category, bool) {
+	for c, cq := range q.queues {
+		if cq.ready() {
+			return Category(c), true
+		}
+	}
+	return 0, false
+}
+
+// pick returns the category of the next message to dispatch, the caller must
+// hold q.mux
+func (q *PriorityQueue) pick() (Category, bool) {
+	for c, cq := range q.queues {
+		if cq.Weight == 0 && cq.ready() {
+			return Category(c), true
+		}
+	}
+	for round := 0; round < 2; round++ {
+		pending := false
+		for c, cq := range q.queues {
+			if cq.Weight == 0 || !cq.ready() {
+				continue
+			}
+			pending = true
+			if cq.credits > 0 {
+				cq.credits--
+				return Category(c), true
+			}
+		}
+		if !pending {
+			break
+		}
+		// every pending category used up its weight, start a new round
+		for _, cq := range q.queues {
+			cq.credits = cq.Weight
+		}
+	}
+	return 0, false
+}
+
+// empty returns whether all the category queues are empty, the caller must
+// hold q.mux
+func (q *PriorityQueue) empty() bool {
+	for _, cq := range q.queues {
+		if len(cq.messages) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Errors of the priority message queue.
+var (
+	ErrClosed        = errors.New("message queue closed")
+	ErrInvalidConfig = errors.New("invalid message queue config")
+)
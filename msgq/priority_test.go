@@ -0,0 +1,167 @@
+package msgq
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// classifyFirstByte classifies the test messages by their first byte
+func classifyFirstByte(content []byte) Category {
+	if len(content) == 0 {
+		return Other
+	}
+	return Category(content[0])
+}
+
+func testConfigs(size, workers int) [NumCategories]CategoryConfig {
+	var configs [NumCategories]CategoryConfig
+	for c := range configs {
+		configs[c] = CategoryConfig{Size: size, Weight: 1, Workers: workers}
+	}
+	configs[Consensus].Weight = 0
+	configs[ViewChange].Weight = 0
+	return configs
+}
+
+type recordingHandler struct {
+	mux  sync.Mutex
+	seen []Category
+}
+
+func (h *recordingHandler) HandleMessage(content []byte, sender peer.ID) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.seen = append(h.seen, Category(content[0]))
+}
+
+func TestNewPriorityQueue_InvalidConfig(t *testing.T) {
+	configs := testConfigs(10, 1)
+	configs[Block].Workers = 0
+	if _, err := NewPriorityQueue(classifyFirstByte, configs); err == nil {
+		t.Error("expected an error for a category without workers")
+	}
+}
+
+func TestPriorityQueue_AddMessage(t *testing.T) {
+	q, err := NewPriorityQueue(classifyFirstByte, testConfigs(10, 1))
+	if err != nil {
+		t.Fatalf("NewPriorityQueue() error = %v", err)
+	}
+	for i := 0; i < 15; i++ {
+		wantErr := error(nil)
+		if i >= 10 {
+			wantErr = ErrRxOverrun
+		}
+		if err := q.AddMessage([]byte{byte(Transaction)}, peer.ID("")); err != wantErr {
+			t.Fatalf("AddMessage() iter %d, error = %v, want %v", i, err, wantErr)
+		}
+	}
+	// a full transaction queue does not drop consensus messages
+	if err := q.AddMessage([]byte{byte(Consensus)}, peer.ID("")); err != nil {
+		t.Errorf("AddMessage() consensus error = %v, want nil", err)
+	}
+	stats := q.Stats()
+	if s := stats[Transaction]; s.Len != 10 || s.Added != 10 || s.Dropped != 5 {
+		t.Errorf("unexpected transaction stats %+v", s)
+	}
+	if s := stats[Consensus]; s.Len != 1 || s.Dropped != 0 {
+		t.Errorf("unexpected consensus stats %+v", s)
+	}
+	q.Close()
+	if err := q.AddMessage([]byte{byte(Consensus)}, peer.ID("")); err != ErrClosed {
+		t.Errorf("AddMessage() after Close error = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestPriorityQueue_Dispatch(t *testing.T) {
+	configs := testConfigs(100, 1)
+	configs[Block].Weight = 2
+	q, err := NewPriorityQueue(classifyFirstByte, configs)
+	if err != nil {
+		t.Fatalf("NewPriorityQueue() error = %v", err)
+	}
+	for _, c := range []Category{
+		Transaction, Transaction, Transaction, Block, Block, Block, Block, ViewChange, Consensus,
+	} {
+		if err := q.AddMessage([]byte{byte(c)}, peer.ID("")); err != nil {
+			t.Fatalf("AddMessage() error = %v", err)
+		}
+	}
+	q.Close()
+	h := &recordingHandler{}
+	q.HandleMessages(h)
+
+	want := []Category{
+		Consensus, ViewChange,
+		Block, Block, Transaction,
+		Block, Block, Transaction,
+		Transaction,
+	}
+	if len(h.seen) != len(want) {
+		t.Fatalf("handled %v, want %v", h.seen, want)
+	}
+	for i := range want {
+		if h.seen[i] != want[i] {
+			t.Fatalf("handled %v, want %v", h.seen, want)
+		}
+	}
+	for _, s := range q.Stats() {
+		if s.Len != 0 || s.Busy != 0 || s.Handled != s.Added {
+			t.Errorf("unexpected stats after drain %+v", s)
+		}
+	}
+}
+
+type blockingHandler struct {
+	release chan struct{}
+	handled chan Category
+}
+
+func (h *blockingHandler) HandleMessage(content []byte, sender peer.ID) {
+	c := Category(content[0])
+	if c == Transaction {
+		<-h.release
+	}
+	h.handled <- c
+}
+
+func TestPriorityQueue_WorkerLimit(t *testing.T) {
+	configs := testConfigs(100, 4)
+	configs[Transaction].Workers = 2
+	q, err := NewPriorityQueue(classifyFirstByte, configs)
+	if err != nil {
+		t.Fatalf("NewPriorityQueue() error = %v", err)
+	}
+	h := &blockingHandler{release: make(chan struct{}), handled: make(chan Category, 100)}
+	for i := 0; i < 4; i++ {
+		go q.HandleMessages(h)
+	}
+	defer q.Close()
+	for i := 0; i < 10; i++ {
+		q.AddMessage([]byte{byte(Transaction)}, peer.ID(""))
+	}
+	// transactions hold at most two workers, consensus gets through
+	q.AddMessage([]byte{byte(Consensus)}, peer.ID(""))
+	select {
+	case c := <-h.handled:
+		if c != Consensus {
+			t.Fatalf("handled %v, want %v", c, Consensus)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("consensus message starved by transactions")
+	}
+	if busy := q.Stats()[Transaction].Busy; busy > 2 {
+		t.Errorf("transaction workers busy %d, want at most 2", busy)
+	}
+	close(h.release)
+	for i := 0; i < 10; i++ {
+		select {
+		case <-h.handled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d transactions handled", i)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/harmony-one/harmony/accounts"
 	"github.com/harmony-one/harmony/api/client"
 	clientService "github.com/harmony-one/harmony/api/client/service"
@@ -19,6 +20,7 @@ import (
 	"github.com/harmony-one/harmony/api/service/syncing"
 	"github.com/harmony-one/harmony/api/service/syncing/downloader"
 	"github.com/harmony-one/harmony/consensus"
+	"github.com/harmony-one/harmony/consensus/recorder"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/rawdb"
 	"github.com/harmony-one/harmony/core/types"
@@ -30,6 +32,7 @@ import (
 	"github.com/harmony-one/harmony/internal/shardchain"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/msgq"
+	"github.com/harmony-one/harmony/node/txfetcher"
 	"github.com/harmony-one/harmony/node/worker"
 	"github.com/harmony-one/harmony/p2p"
 	p2p_host "github.com/harmony-one/harmony/p2p/host"
@@ -38,6 +41,7 @@ import (
 	"github.com/harmony-one/harmony/staking/slash"
 	staking "github.com/harmony-one/harmony/staking/types"
 	"github.com/harmony-one/harmony/webhooks"
+	lru "github.com/hashicorp/golang-lru"
 )
 
 // State is a state of a node.
@@ -57,20 +61,25 @@ const (
 const (
 	// NumTryBroadCast is the number of times trying to broadcast
 	NumTryBroadCast = 3
-	// ClientRxQueueSize is the number of client messages to queue before tail-dropping.
-	ClientRxQueueSize = 16384
-	// ShardRxQueueSize is the number of shard messages to queue before tail-dropping.
-	ShardRxQueueSize = 16384
-	// GlobalRxQueueSize is the number of global messages to queue before tail-dropping.
-	GlobalRxQueueSize = 16384
-	// ClientRxWorkers is the number of concurrent client message handlers.
-	ClientRxWorkers = 8
-	// ShardRxWorkers is the number of concurrent shard message handlers.
-	ShardRxWorkers = 32
-	// GlobalRxWorkers is the number of concurrent global message handlers.
-	GlobalRxWorkers = 32
+	// RxWorkers is the number of concurrent message handlers, shared by all
+	// the message categories.
+	RxWorkers = 64
 )
 
+// RxQueueConfigs are the queue sizes, dispatch weights and worker limits of
+// the message categories.  Consensus and view change messages are always
+// dispatched first, but together take at most three quarters of the workers
+// so that the other categories keep some; transactions, the bulk of the
+// gossip, are dropped first.
+var RxQueueConfigs = [msgq.NumCategories]msgq.CategoryConfig{
+	msgq.Consensus:   {Size: 4096, Weight: 0, Workers: RxWorkers * 3 / 8},
+	msgq.ViewChange:  {Size: 4096, Weight: 0, Workers: RxWorkers * 3 / 8},
+	msgq.Block:       {Size: 1024, Weight: 4, Workers: 16},
+	msgq.CrossShard:  {Size: 4096, Weight: 4, Workers: 16},
+	msgq.Transaction: {Size: 16384, Weight: 2, Workers: 24},
+	msgq.Other:       {Size: 1024, Weight: 1, Workers: 8},
+}
+
 func (state State) String() string {
 	switch state {
 	case NodeInit:
@@ -132,6 +141,9 @@ type Node struct {
 	BeaconNeighbors sync.Map // All the neighbor nodes, key is the sha256 of Peer IP/Port, value is the p2p.Peer
 
 	TxPool *core.TxPool
+	// arrival time of the recently pooled transactions, for prefilling the
+	// compact blocks the node sends
+	recentTxs *lru.Cache
 
 	CxPool *core.CxPool // pool for missing cross shard receipts resend
 
@@ -152,14 +164,18 @@ type Node struct {
 	// syncing frequency parameters
 	syncFreq       int
 	beaconSyncFreq int
+	// syncFeed is the feed of the sync events of the shard chain and the beacon chain
+	syncFeed event.Feed
+	// syncCheckpoint is the trusted epoch block a new node syncs from, nil once synced
+	syncCheckpoint *syncing.Checkpoint
 
 	// The p2p host used to send/receive p2p messages
 	host p2p.Host
 
 	// Incoming messages to process.
-	clientRxQueue *msgq.Queue
-	shardRxQueue  *msgq.Queue
-	globalRxQueue *msgq.Queue
+	rxQueue *msgq.PriorityQueue
+	// fetches the bodies of the transactions announced by peers
+	txFetcher *txfetcher.Fetcher
 
 	// Service manager.
 	serviceManager *service.Manager
@@ -212,6 +228,16 @@ type Node struct {
 	isFirstTime bool // the node was started with a fresh database
 	// How long in second the leader needs to wait to propose a new block.
 	BlockPeriod time.Duration
+	// FirstProposalDelay is how long the leader waits for the other nodes to
+	// be ready before proposing the first block
+	FirstProposalDelay time.Duration
+	// OnDoubleSign is called with the double signs the consensus notices, if
+	// set
+	OnDoubleSign func(slash.Record)
+
+	// ConsensusRecorder records the consensus messages received, nil to not
+	// record
+	ConsensusRecorder *recorder.Recorder
 
 	// last time consensus reached for metrics
 	lastConsensusTime int64
@@ -245,9 +271,22 @@ func (node *Node) Beaconchain() *core.BlockChain {
 	return bc
 }
 
+// isTxAnnounce returns whether new transactions are gossiped as hash
+// announcements in the current epoch
+func (node *Node) isTxAnnounce() bool {
+	bc := node.Blockchain()
+	return bc.Config().IsTxAnnounce(bc.CurrentHeader().Epoch())
+}
+
+// tryBroadcast announces the transaction to the shard, the nodes which miss it
+// fetch it from this node. Before the announcement epoch, the nodes which only
+// take transactions in full are still served so.
 // TODO: make this batch more transactions
 func (node *Node) tryBroadcast(tx *types.Transaction) {
 	msg := proto_node.ConstructTransactionListMessageAccount(types.Transactions{tx})
+	if node.isTxAnnounce() {
+		msg = proto_node.ConstructTransactionAnnounceMessage([]common.Hash{tx.Hash()})
+	}
 
 	shardGroupID := nodeconfig.NewGroupIDByShardID(nodeconfig.ShardID(tx.ShardID()))
 	utils.Logger().Info().Str("shardGroupID", string(shardGroupID)).Msg("tryBroadcast")
@@ -261,8 +300,16 @@ func (node *Node) tryBroadcast(tx *types.Transaction) {
 	}
 }
 
+// tryBroadcastStaking announces the staking transaction to the beacon chain,
+// the nodes which miss it fetch it from this node, or sends it in full before
+// the announcement epoch
 func (node *Node) tryBroadcastStaking(stakingTx *staking.StakingTransaction) {
-	msg := proto_node.ConstructStakingTransactionListMessageAccount(staking.StakingTransactions{stakingTx})
+	msg := proto_node.ConstructStakingTransactionListMessageAccount(
+		staking.StakingTransactions{stakingTx},
+	)
+	if node.isTxAnnounce() {
+		msg = proto_node.ConstructStakingTransactionAnnounceMessage([]common.Hash{stakingTx.Hash()})
+	}
 
 	shardGroupID := nodeconfig.NewGroupIDByShardID(
 		nodeconfig.ShardID(shard.BeaconChainShardID),
@@ -285,6 +332,7 @@ func (node *Node) addPendingTransactions(newTxs types.Transactions) []error {
 		poolTxs = append(poolTxs, tx)
 	}
 	errs := node.TxPool.AddRemotes(poolTxs)
+	node.markRecentTxs(pooledHashes(poolTxs, errs))
 
 	pendingCount, queueCount := node.TxPool.Stats()
 	utils.Logger().Info().
@@ -304,6 +352,7 @@ func (node *Node) addPendingStakingTransactions(newStakingTxs staking.StakingTra
 			poolTxs = append(poolTxs, tx)
 		}
 		errs := node.TxPool.AddRemotes(poolTxs)
+		node.markRecentTxs(pooledHashes(poolTxs, errs))
 		pendingCount, queueCount := node.TxPool.Stats()
 		utils.Logger().Info().
 			Int("length of newStakingTxs", len(poolTxs)).
@@ -413,29 +462,28 @@ func (node *Node) AddPendingReceipts(receipts *types.CXReceiptsProof) {
 		Msg("Got ONE more receipt message")
 }
 
-func (node *Node) startRxPipeline(
-	receiver p2p.GroupReceiver, queue *msgq.Queue, numWorkers int,
-) {
-	// consumers
-	for i := 0; i < numWorkers; i++ {
-		go queue.HandleMessages(node)
-	}
-	// provider
-	go node.receiveGroupMessage(receiver, queue)
-}
-
 // StartServer starts a server and process the requests by a handler.
 func (node *Node) StartServer() {
+	// consumers, shared by all the receivers so messages are prioritized
+	// across the topics
+	for i := 0; i < RxWorkers; i++ {
+		go node.rxQueue.HandleMessages(node)
+	}
 
 	// client messages are sent by clients, like txgen, wallet
-	node.startRxPipeline(node.clientReceiver, node.clientRxQueue, ClientRxWorkers)
+	go node.receiveGroupMessage(node.clientReceiver, node.rxQueue)
 
 	// start the goroutine to receive group message
-	node.startRxPipeline(node.shardGroupReceiver, node.shardRxQueue, ShardRxWorkers)
+	go node.receiveGroupMessage(node.shardGroupReceiver, node.rxQueue)
 
 	// start the goroutine to receive global message, used for cross-shard TX
 	// FIXME (leo): we use beacon client topic as the global topic for now
-	node.startRxPipeline(node.globalGroupReceiver, node.globalRxQueue, GlobalRxWorkers)
+	go node.receiveGroupMessage(node.globalGroupReceiver, node.rxQueue)
+
+	// answer the transactions peers miss to rebuild compact blocks, and the
+	// announced transactions they fetch
+	node.startBlockTxsServer()
+	node.startTxsServer()
 
 	select {}
 }
@@ -472,6 +520,7 @@ func New(
 	}{sync.Mutex{}, ring.New(sinkSize), ring.New(sinkSize)}
 	node.syncFreq = SyncFrequency
 	node.beaconSyncFreq = SyncFrequency
+	node.FirstProposalDelay = DefaultFirstProposalDelay
 
 	// Get the node config that's created in the harmony.go program.
 	if consensusObj != nil {
@@ -522,6 +571,7 @@ func New(
 		node.BlockChannel = make(chan *types.Block)
 		node.ConfirmedBlockChannel = make(chan *types.Block)
 		node.BeaconBlockChannel = make(chan *types.Block)
+		node.recentTxs, _ = lru.New(recentTxsCacheSize)
 		txPoolConfig := core.DefaultTxPoolConfig
 		txPoolConfig.Blacklist = blacklist
 		node.TxPool = core.NewTxPool(txPoolConfig, node.Blockchain().Config(), blockchain,
@@ -581,9 +631,13 @@ func New(
 		Interface("genesis block header", node.Blockchain().GetHeaderByNumber(0)).
 		Msg("Genesis block hash")
 
-	node.clientRxQueue = msgq.New(ClientRxQueueSize)
-	node.shardRxQueue = msgq.New(ShardRxQueueSize)
-	node.globalRxQueue = msgq.New(GlobalRxQueueSize)
+	rxQueue, err := msgq.NewPriorityQueue(classifyMessage, RxQueueConfigs)
+	if err != nil {
+		// RxQueueConfigs is static, so this is a programming error
+		panic(err)
+	}
+	node.rxQueue = rxQueue
+	node.txFetcher = txfetcher.New(txfetcher.DefaultConfig(), node.hasTransaction, node.fetchTransactions)
 
 	// Setup initial state of syncing.
 	node.peerRegistrationRecord = map[string]*syncConfig{}
@@ -598,11 +652,14 @@ func New(
 					utils.Logger().Info().
 						RawJSON("double-sign-candidate", []byte(doubleSign.String())).
 						Msg("double sign notified by consensus leader")
+					if node.OnDoubleSign != nil {
+						node.OnDoubleSign(doubleSign)
+					}
 					// no point to broadcast the slash if we aren't even in the right epoch yet
 					if !node.Blockchain().Config().IsStaking(
 						node.Blockchain().CurrentHeader().Epoch(),
 					) {
-						return
+						continue
 					}
 					if hooks := node.NodeConfig.WebHooks.Hooks; hooks != nil {
 						if s := hooks.Slashing; s != nil {
@@ -779,6 +836,11 @@ func (node *Node) SetBeaconSyncFreq(syncFreq int) {
 	node.beaconSyncFreq = syncFreq
 }
 
+// SetSyncCheckpoint sets the trusted epoch block to sync a new node from
+func (node *Node) SetSyncCheckpoint(checkpoint *syncing.Checkpoint) {
+	node.syncCheckpoint = checkpoint
+}
+
 // ShutDown gracefully shut down the node server and dump the in-memory blockchain state into DB.
 func (node *Node) ShutDown() {
 	node.Blockchain().Stop()
@@ -0,0 +1,255 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/p2p/reputation"
+	"github.com/harmony-one/harmony/shard"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/pkg/errors"
+)
+
+// Constants for rebuilding compact blocks.
+const (
+	// blockTxsFetchTimeout bounds a request for the missing transactions of a
+	// compact block, which holds up the consensus of a validator
+	blockTxsFetchTimeout = 2 * time.Second
+	// blockTxsFetchPeers is the number of peers asked for the missing
+	// transactions, the sender of the compact block first
+	blockTxsFetchPeers = 3
+	// recentTxsCacheSize is the number of pooled transactions whose arrival
+	// time is kept for prefilling compact blocks
+	recentTxsCacheSize = 16384
+	// recentTxsWindow is how long a pooled transaction is deemed not to have
+	// reached the peers yet, and is prefilled in the compact blocks sent
+	recentTxsWindow = 2 * time.Second
+)
+
+// BlockTxsProtocolID returns the ID of the libp2p protocol answering the
+// transactions of the blocks of the given shard by index, for rebuilding
+// compact blocks.
+func BlockTxsProtocolID(shardID uint32) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/harmony/blocktxs/%d/0.0.1", shardID))
+}
+
+// blockTxsRequest asks a peer for the transactions of a block by index
+type blockTxsRequest struct {
+	BlockHash        common.Hash
+	TxIndexes        []uint32
+	StakingTxIndexes []uint32
+}
+
+// blockTxsResponse holds the transactions asked by a blockTxsRequest, in order
+type blockTxsResponse struct {
+	Txs        []*types.Transaction
+	StakingTxs []*staking.StakingTransaction
+}
+
+// markRecentTxs records the arrival time of transactions entering the pool
+func (node *Node) markRecentTxs(txHashes []common.Hash) {
+	if node.recentTxs == nil {
+		return
+	}
+	now := time.Now()
+	for _, txHash := range txHashes {
+		node.recentTxs.ContainsOrAdd(txHash, now)
+	}
+}
+
+// pooledHashes returns the hashes of the transactions the pool accepted
+func pooledHashes(txs types.PoolTransactions, errs []error) []common.Hash {
+	hashes := make([]common.Hash, 0, len(txs))
+	for i, tx := range txs {
+		if i < len(errs) && errs[i] != nil {
+			continue
+		}
+		hashes = append(hashes, tx.Hash())
+	}
+	return hashes
+}
+
+// PrefillTx returns whether the transaction of the hash is sent in full in
+// the compact blocks of the node, which is the case for the transactions
+// that entered the pool too recently for the peers to hold them.
+func (node *Node) PrefillTx(txHash common.Hash) bool {
+	if node.recentTxs == nil {
+		return false
+	}
+	arrival, ok := node.recentTxs.Get(txHash)
+	return ok && time.Since(arrival.(time.Time)) < recentTxsWindow
+}
+
+// ReconstructBlock rebuilds the block of a compact block from the transaction
+// pool.  The missing transactions are fetched from the sender of the compact
+// block, or from other peers serving the shard of the block.
+func (node *Node) ReconstructBlock(
+	compact *types.CompactBlock, sender libp2p_peer.ID,
+) (*types.Block, error) {
+	missingTxs, missingStakingTxs := compact.Fill(node.pooledTransactions())
+	block, err := compact.Block()
+	if err == nil {
+		return block, nil
+	}
+	request := &blockTxsRequest{
+		BlockHash:        compact.Hash(),
+		TxIndexes:        missingTxs,
+		StakingTxIndexes: missingStakingTxs,
+	}
+	if errors.Cause(err) == types.ErrInvalidCompactBlock {
+		// a short ID collision, ask for all the transactions
+		request.TxIndexes, request.StakingTxIndexes = allIndexes(compact)
+	}
+	shardID := compact.Header.ShardID()
+	for _, peer := range node.blockTxsPeers(shardID, sender) {
+		response, err := node.fetchBlockTxs(peer, shardID, request)
+		if err != nil {
+			utils.Logger().Debug().Err(err).Str("peer", peer.Pretty()).
+				Msg("[CompactBlock] cannot fetch missing transactions")
+			continue
+		}
+		if err := compact.FillMissing(
+			request.TxIndexes, response.Txs, request.StakingTxIndexes, response.StakingTxs,
+		); err != nil {
+			node.host.ReportPeer(peer, reputation.MalformedMessage)
+			continue
+		}
+		if block, err = compact.Block(); err == nil {
+			utils.Logger().Debug().
+				Uint64("block", block.NumberU64()).
+				Int("missing", len(request.TxIndexes)+len(request.StakingTxIndexes)).
+				Str("peer", peer.Pretty()).
+				Msg("[CompactBlock] rebuilt block with fetched transactions")
+			return block, nil
+		}
+		// the pooled transactions may collide too, ask the next peer for all
+		request.TxIndexes, request.StakingTxIndexes = allIndexes(compact)
+	}
+	return nil, errors.Wrapf(err, "cannot rebuild compact block %d", compact.Header.Number())
+}
+
+// pooledTransactions returns the pending and queued transactions of the pool
+func (node *Node) pooledTransactions() []types.PoolTransaction {
+	pooled := []types.PoolTransaction{}
+	pending, queued := node.TxPool.Content()
+	for _, txs := range pending {
+		pooled = append(pooled, txs...)
+	}
+	for _, txs := range queued {
+		pooled = append(pooled, txs...)
+	}
+	return pooled
+}
+
+// allIndexes returns the indexes of all the transactions of the compact block
+func allIndexes(compact *types.CompactBlock) (txIndexes, stakingTxIndexes []uint32) {
+	for i := range compact.ShortIDs {
+		txIndexes = append(txIndexes, uint32(i))
+	}
+	for i := range compact.StakingTxHashes {
+		stakingTxIndexes = append(stakingTxIndexes, uint32(i))
+	}
+	return txIndexes, stakingTxIndexes
+}
+
+// blockTxsPeers returns the peers to ask for the missing transactions of a
+// block of the shard, the sender of the block first
+func (node *Node) blockTxsPeers(shardID uint32, sender libp2p_peer.ID) []libp2p_peer.ID {
+	p2pHost := node.host.GetP2PHost()
+	peers := []libp2p_peer.ID{}
+	if sender != "" && sender != p2pHost.ID() {
+		peers = append(peers, sender)
+	}
+	protocolID := string(BlockTxsProtocolID(shardID))
+	for _, id := range p2pHost.Network().Peers() {
+		if len(peers) >= blockTxsFetchPeers {
+			break
+		}
+		if id == sender {
+			continue
+		}
+		if supported, err := p2pHost.Peerstore().SupportsProtocols(id, protocolID); err != nil ||
+			len(supported) == 0 {
+			continue
+		}
+		peers = append(peers, id)
+	}
+	return peers
+}
+
+// fetchBlockTxs asks the peer for the transactions of a block
+func (node *Node) fetchBlockTxs(
+	peer libp2p_peer.ID, shardID uint32, request *blockTxsRequest,
+) (*blockTxsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), blockTxsFetchTimeout)
+	defer cancel()
+	response := &blockTxsResponse{}
+	if err := node.streamRequest(ctx, peer, BlockTxsProtocolID(shardID), request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// startBlockTxsServer answers the transactions of the blocks of the shard and
+// of the beacon chain, which the node holds too
+func (node *Node) startBlockTxsServer() {
+	p2pHost := node.host.GetP2PHost()
+	p2pHost.SetStreamHandler(BlockTxsProtocolID(node.Blockchain().ShardID()), node.handleBlockTxsStream)
+	if node.Blockchain().ShardID() != shard.BeaconChainShardID {
+		p2pHost.SetStreamHandler(BlockTxsProtocolID(shard.BeaconChainShardID), node.handleBlockTxsStream)
+	}
+}
+
+// handleBlockTxsStream answers a request for the transactions of a block from
+// the blocks under consensus or in the chains
+func (node *Node) handleBlockTxsStream(stream network.Stream) {
+	stream.SetDeadline(time.Now().Add(blockTxsFetchTimeout))
+	remote := stream.Conn().RemotePeer()
+	request := &blockTxsRequest{}
+	if err := readStreamRequest(stream, request); err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote.Pretty()).
+			Msg("[CompactBlock] cannot read block transactions request")
+		stream.Reset()
+		return
+	}
+	block := node.Consensus.FBFTLog.GetBlockByHash(request.BlockHash)
+	if block == nil {
+		block = node.Blockchain().GetBlockByHash(request.BlockHash)
+	}
+	if block == nil {
+		block = node.Beaconchain().GetBlockByHash(request.BlockHash)
+	}
+	if block == nil {
+		stream.Reset()
+		return
+	}
+	response := &blockTxsResponse{}
+	txs, stakingTxs := block.Transactions(), block.StakingTransactions()
+	for _, index := range request.TxIndexes {
+		if int(index) >= len(txs) {
+			node.host.ReportPeer(remote, reputation.MalformedMessage)
+			stream.Reset()
+			return
+		}
+		response.Txs = append(response.Txs, txs[index])
+	}
+	for _, index := range request.StakingTxIndexes {
+		if int(index) >= len(stakingTxs) {
+			node.host.ReportPeer(remote, reputation.MalformedMessage)
+			stream.Reset()
+			return
+		}
+		response.StakingTxs = append(response.StakingTxs, stakingTxs[index])
+	}
+	if err := writeStreamResponse(stream, response); err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote.Pretty()).
+			Msg("[CompactBlock] cannot write block transactions response")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	"github.com/harmony-one/harmony/internal/ctxerror"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/shard"
@@ -38,20 +39,32 @@ func (node *Node) VerifyBlockCrossLinks(block *types.Block) error {
 		)
 	}
 
+	cannotVerify := func(crossLink types.CrossLink, err error) error {
+		return ctxerror.New("cannot VerifyBlockCrossLinks",
+			"blockHash", block.Hash(),
+			"blockNum", block.Number(),
+			"crossLinkShard", crossLink.ShardID(),
+			"crossLinkBlock", crossLink.BlockNum(),
+			"numTx", len(block.Transactions()),
+		).WithCause(err)
+	}
+	signed := []*bls_cosi.SignedHash{}
 	for _, crossLink := range *crossLinks {
 		cl, err := node.Blockchain().ReadCrossLink(crossLink.ShardID(), crossLink.BlockNum())
 		if err == nil && cl != nil {
 			// Add slash for exist same blocknum but different crosslink
 			return ctxerror.New("crosslink already exist!")
 		}
-		if err = node.VerifyCrossLink(crossLink); err != nil {
-			return ctxerror.New("cannot VerifyBlockCrossLinks",
-				"blockHash", block.Hash(),
-				"blockNum", block.Number(),
-				"crossLinkShard", crossLink.ShardID(),
-				"crossLinkBlock", crossLink.BlockNum(),
-				"numTx", len(block.Transactions()),
-			).WithCause(err)
+		sig, err := node.crossLinkSignature(crossLink)
+		if err != nil {
+			return cannotVerify(crossLink, err)
+		}
+		signed = append(signed, sig)
+	}
+	// the signatures of the cross links are verified in batches
+	for i, valid := range bls_cosi.DefaultBatchVerifier().Verify(signed) {
+		if !valid {
+			return cannotVerify((*crossLinks)[i], ErrCrosslinkVerificationFail)
 		}
 	}
 	return nil
@@ -75,7 +88,7 @@ func (node *Node) ProcessCrossLinkMessage(msgPayload []byte) {
 			return
 		}
 
-		candidates := []types.CrossLink{}
+		candidates, signed := []types.CrossLink{}, []*bls_cosi.SignedHash{}
 		utils.Logger().Debug().
 			Msgf("[ProcessingCrossLink] Received crosslinks: %d", len(crosslinks))
 
@@ -90,20 +103,33 @@ func (node *Node) ProcessCrossLinkMessage(msgPayload []byte) {
 				continue
 			}
 
-			if err = node.VerifyCrossLink(cl); err != nil {
+			sig, err := node.crossLinkSignature(cl)
+			if err != nil {
 				utils.Logger().Info().
 					Str("cross-link-issue", err.Error()).
 					Msgf("[ProcessingCrossLink] Failed to verify new cross link for blockNum %d epochNum %d shard %d skipped: %v", cl.BlockNum(), cl.Epoch().Uint64(), cl.ShardID(), cl)
 				continue
 			}
+			candidates, signed = append(candidates, cl), append(signed, sig)
+		}
 
-			candidates = append(candidates, cl)
+		// the signatures of the cross links are verified in batches
+		verified := []types.CrossLink{}
+		for i, valid := range bls_cosi.DefaultBatchVerifier().Verify(signed) {
+			cl := candidates[i]
+			if !valid {
+				utils.Logger().Info().
+					Str("cross-link-issue", ErrCrosslinkVerificationFail.Error()).
+					Msgf("[ProcessingCrossLink] Failed to verify new cross link for blockNum %d epochNum %d shard %d skipped: %v", cl.BlockNum(), cl.Epoch().Uint64(), cl.ShardID(), cl)
+				continue
+			}
+			verified = append(verified, cl)
 			utils.Logger().Debug().
 				Msgf("[ProcessingCrossLink] Committing for shardID %d, blockNum %d",
 					cl.ShardID(), cl.Number().Uint64(),
 				)
 		}
-		Len, _ := node.Blockchain().AddPendingCrossLinks(candidates)
+		Len, _ := node.Blockchain().AddPendingCrossLinks(verified)
 		utils.Logger().Debug().
 			Msgf("[ProcessingCrossLink] Add pending crosslinks,  total pending: %d", Len)
 	}
@@ -111,16 +137,29 @@ func (node *Node) ProcessCrossLinkMessage(msgPayload []byte) {
 
 // VerifyCrossLink verifies the header is valid
 func (node *Node) VerifyCrossLink(cl types.CrossLink) error {
+	signed, err := node.crossLinkSignature(cl)
+	if err != nil {
+		return err
+	}
+	if !signed.Verify() {
+		return ErrCrosslinkVerificationFail
+	}
+	return nil
+}
+
+// crossLinkSignature checks the cross link and the quorum of its signers, and
+// returns its signature to be verified alone or in a batch
+func (node *Node) crossLinkSignature(cl types.CrossLink) (*bls_cosi.SignedHash, error) {
 	if node.Blockchain().ShardID() != shard.BeaconChainShardID {
-		return ctxerror.New("[VerifyCrossLink] Shard chains should not verify cross links")
+		return nil, ctxerror.New("[VerifyCrossLink] Shard chains should not verify cross links")
 	}
 
 	if cl.BlockNum() <= 1 {
-		return ctxerror.New("[VerifyCrossLink] CrossLink BlockNumber should greater than 1")
+		return nil, ctxerror.New("[VerifyCrossLink] CrossLink BlockNumber should greater than 1")
 	}
 
 	if !node.Blockchain().Config().IsCrossLink(cl.Epoch()) {
-		return ctxerror.New(
+		return nil, ctxerror.New(
 			"[VerifyCrossLink] CrossLink Epoch should >= cross link starting epoch",
 			"crossLinkEpoch", cl.Epoch(), "cross_link_starting_eoch",
 			node.Blockchain().Config().CrossLinkEpoch,
@@ -131,24 +170,24 @@ func (node *Node) VerifyCrossLink(cl types.CrossLink) error {
 	// TODO: check whether to recalculate shard state
 	shardState, err := node.Blockchain().ReadShardState(cl.Epoch())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	committee, err := shardState.FindCommitteeByID(cl.ShardID())
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	aggSig := &bls.Sign{}
 	sig := cl.Signature()
 	if err = aggSig.Deserialize(sig[:]); err != nil {
-		return ctxerror.New(
+		return nil, ctxerror.New(
 			"[VerifyCrossLink] unable to deserialize multi-signature from payload",
 		).WithCause(err)
 	}
 
-	return verify.AggregateSigForCommittee(
+	return verify.CommitteeSignature(
 		committee, aggSig, cl.Hash(), cl.BlockNum(), cl.Epoch(), cl.Bitmap(),
 	)
 }
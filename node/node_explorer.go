@@ -13,12 +13,13 @@ import (
 	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/internal/utils"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
 )
 
 var once sync.Once
 
 // ExplorerMessageHandler passes received message in node_handler to explorer service
-func (node *Node) ExplorerMessageHandler(payload []byte) {
+func (node *Node) ExplorerMessageHandler(payload []byte, sender libp2p_peer.ID) {
 	if len(payload) == 0 {
 		utils.Logger().Error().Msg("Payload is empty")
 		return
@@ -83,10 +84,13 @@ func (node *Node) ExplorerMessageHandler(payload []byte) {
 			utils.Logger().Error().Err(err).Msg("[Explorer] Unable to parse Prepared msg")
 			return
 		}
-		block := recvMsg.Block
-
-		blockObj := &types.Block{}
-		err = rlp.DecodeBytes(block, blockObj)
+		blockObj, err := node.Consensus.DecodeBlock(recvMsg.Block, sender)
+		if err != nil {
+			utils.Logger().Error().Err(err).
+				Uint64("msgBlock", recvMsg.BlockNum).
+				Msg("[Explorer] Unable to decode the block of the Prepared msg")
+			return
+		}
 		// Add the block into FBFT log.
 		node.Consensus.FBFTLog.AddBlock(blockObj)
 		// Try to search for MessageType_COMMITTED message from pbft log.
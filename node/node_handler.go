@@ -8,21 +8,26 @@ import (
 	"time"
 
 	"github.com/harmony-one/harmony/consensus"
+	"github.com/harmony-one/harmony/consensus/recorder"
 
 	"github.com/ethereum/go-ethereum/rlp"
+	protobuf "github.com/golang/protobuf/proto"
 	"github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/harmony/api/proto"
 	proto_discovery "github.com/harmony-one/harmony/api/proto/discovery"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	proto_node "github.com/harmony-one/harmony/api/proto/node"
 	"github.com/harmony-one/harmony/block"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/chain"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/ctxerror"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/msgq"
 	"github.com/harmony-one/harmony/p2p"
 	"github.com/harmony-one/harmony/p2p/host"
+	"github.com/harmony-one/harmony/p2p/reputation"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/staking/availability"
 	"github.com/harmony-one/harmony/staking/slash"
@@ -41,6 +46,9 @@ func (node *Node) receiveGroupMessage(
 	// TODO ek – infinite loop; add shutdown/cleanup logic
 	for {
 		msg, sender, err := receiver.Receive(ctx)
+		if err == p2p.ErrReceiverClosed {
+			return
+		}
 		if err != nil {
 			utils.Logger().Warn().Err(err).
 				Msg("cannot receive from group")
@@ -54,16 +62,118 @@ func (node *Node) receiveGroupMessage(
 		if len(msg) < p2pMsgPrefixSize {
 			utils.Logger().Warn().Err(err).Int("msg size", len(msg)).
 				Msg("invalid p2p message size")
+			node.host.ReportPeer(sender, reputation.MalformedMessage)
 			continue
 		}
 		if err := rxQueue.AddMessage(msg[p2pMsgPrefixSize:], sender); err != nil {
 			utils.Logger().Warn().Err(err).
 				Str("sender", sender.Pretty()).
 				Msg("cannot enqueue incoming message for processing")
+			// only floods of transactions and other gossip are blamed on
+			// the originator, the consensus, block and cross-shard queues
+			// overrun under the load of the network, led by the leader
+			if err == msgq.ErrRxOverrun {
+				switch classifyMessage(msg[p2pMsgPrefixSize:]) {
+				case msgq.Transaction, msgq.Other:
+					node.host.ReportPeer(sender, reputation.RxOverrun)
+				}
+			}
 		}
 	}
 }
 
+// classifyMessage returns the queue category of a message received from the
+// network, reading only the headers.  Unparsable messages are left to
+// HandleMessage to reject.
+func classifyMessage(content []byte) msgq.Category {
+	msgCategory, err := proto.GetMessageCategory(content)
+	if err != nil {
+		return msgq.Other
+	}
+	switch msgCategory {
+	case proto.Consensus:
+		msgPayload, _ := proto.GetConsensusMessagePayload(content)
+		switch consensusMessageType(msgPayload) {
+		case msg_pb.MessageType_VIEWCHANGE, msg_pb.MessageType_NEWVIEW:
+			return msgq.ViewChange
+		}
+		return msgq.Consensus
+	case proto.Node:
+		msgType, _ := proto.GetMessageType(content)
+		msgPayload, _ := proto.GetMessagePayload(content)
+		switch proto_node.MessageType(msgType) {
+		case proto_node.Transaction, proto_node.Staking:
+			return msgq.Transaction
+		case proto_node.Block:
+			if len(msgPayload) < 1 {
+				return msgq.Other
+			}
+			switch proto_node.BlockMessageType(msgPayload[0]) {
+			case proto_node.Sync, proto_node.CompactSync:
+				return msgq.Block
+			case proto_node.CrossLink, proto_node.Receipt, proto_node.SlashCandidate:
+				return msgq.CrossShard
+			}
+		}
+	}
+	return msgq.Other
+}
+
+// consensusMessageType returns the type of a serialized consensus message by
+// decoding its leading varint fields, without unmarshaling the whole message
+func consensusMessageType(payload []byte) msg_pb.MessageType {
+	const (
+		serviceTypeTag = 1<<3 | 0 // field 1, varint
+		typeTag        = 2<<3 | 0 // field 2, varint
+	)
+	buf := protobuf.NewBuffer(payload)
+	for {
+		tag, err := buf.DecodeVarint()
+		if err != nil {
+			break
+		}
+		value, err := buf.DecodeVarint()
+		if err != nil {
+			break
+		}
+		if tag == typeTag {
+			return msg_pb.MessageType(value)
+		}
+		// fields are serialized in order, and a zero type is left out
+		if tag != serviceTypeTag {
+			break
+		}
+	}
+	return msg_pb.MessageType_NEWNODE_BEACON_STAKING
+}
+
+// syncBlocksHandler handles the new blocks broadcast by the beacon chain
+func (node *Node) syncBlocksHandler(blocks []*types.Block, sender libp2p_peer.ID) {
+	myShard := node.Blockchain().ShardID()
+	for _, block := range blocks {
+		if s := block.ShardID(); s != myShard && s != shard.BeaconChainShardID {
+			node.host.ReportPeer(sender, reputation.WrongShard)
+			break
+		}
+	}
+	// for non-beaconchain node, subscribe to beacon block broadcast
+	if node.Blockchain().ShardID() != shard.BeaconChainShardID &&
+		node.NodeConfig.Role() != nodeconfig.ExplorerNode {
+		for _, block := range blocks {
+			if block.ShardID() == 0 {
+				utils.Logger().Info().
+					Uint64("block", blocks[0].NumberU64()).
+					Msgf("Beacon block being handled by block channel: %d", block.NumberU64())
+				node.BeaconBlockChannel <- block
+			}
+		}
+	}
+	if node.Client != nil && node.Client.UpdateBlocks != nil && blocks != nil {
+		utils.Logger().Info().Msg("Block being handled by client")
+		node.Client.UpdateBlocks(blocks)
+	}
+}
+
 // some messages have uninteresting fields in header, slash, receipt and crosslink are
 // such messages. This function assumes that input bytes are a slice which already
 // past those not relevant header bytes.
@@ -97,6 +207,7 @@ func (node *Node) HandleMessage(content []byte, sender libp2p_peer.ID) {
 		utils.Logger().Error().
 			Err(err).
 			Msg("HandleMessage get message category failed")
+		node.host.ReportPeer(sender, reputation.MalformedMessage)
 		return
 	}
 	msgType, err := proto.GetMessageType(content)
@@ -104,6 +215,7 @@ func (node *Node) HandleMessage(content []byte, sender libp2p_peer.ID) {
 		utils.Logger().Error().
 			Err(err).
 			Msg("HandleMessage get message type failed")
+		node.host.ReportPeer(sender, reputation.MalformedMessage)
 		return
 	}
 
@@ -112,6 +224,7 @@ func (node *Node) HandleMessage(content []byte, sender libp2p_peer.ID) {
 		utils.Logger().Error().
 			Err(err).
 			Msg("HandleMessage get message payload failed")
+		node.host.ReportPeer(sender, reputation.MalformedMessage)
 		return
 	}
 
@@ -119,9 +232,9 @@ func (node *Node) HandleMessage(content []byte, sender libp2p_peer.ID) {
 	case proto.Consensus:
 		msgPayload, _ := proto.GetConsensusMessagePayload(content)
 		if node.NodeConfig.Role() == nodeconfig.ExplorerNode {
-			node.ExplorerMessageHandler(msgPayload)
+			node.ExplorerMessageHandler(msgPayload, sender)
 		} else {
-			node.ConsensusMessageHandler(msgPayload)
+			node.ConsensusMessageHandler(msgPayload, sender)
 		}
 	case proto.DRand:
 		msgPayload, _ := proto.GetDRandMessagePayload(content)
@@ -137,14 +250,15 @@ func (node *Node) HandleMessage(content []byte, sender libp2p_peer.ID) {
 		switch actionType {
 		case proto_node.Transaction:
 			utils.Logger().Debug().Msg("NET: received message: Node/Transaction")
-			node.transactionMessageHandler(msgPayload)
+			node.transactionMessageHandler(msgPayload, sender)
 		case proto_node.Staking:
 			utils.Logger().Debug().Msg("NET: received message: Node/Staking")
-			node.stakingMessageHandler(msgPayload)
+			node.stakingMessageHandler(msgPayload, sender)
 		case proto_node.Block:
 			utils.Logger().Debug().Msg("NET: received message: Node/Block")
 			if len(msgPayload) < 1 {
 				utils.Logger().Debug().Msgf("Invalid block message size")
+				node.host.ReportPeer(sender, reputation.MalformedMessage)
 				return
 			}
 
@@ -157,24 +271,34 @@ func (node *Node) HandleMessage(content []byte, sender libp2p_peer.ID) {
 					utils.Logger().Error().
 						Err(err).
 						Msg("block sync")
+					node.host.ReportPeer(sender, reputation.MalformedMessage)
 				} else {
-					// for non-beaconchain node, subscribe to beacon block broadcast
-					if node.Blockchain().ShardID() != shard.BeaconChainShardID &&
-						node.NodeConfig.Role() != nodeconfig.ExplorerNode {
-						for _, block := range blocks {
-							if block.ShardID() == 0 {
-								utils.Logger().Info().
-									Uint64("block", blocks[0].NumberU64()).
-									Msgf("Beacon block being handled by block channel: %d", block.NumberU64())
-								node.BeaconBlockChannel <- block
-							}
-						}
-					}
-					if node.Client != nil && node.Client.UpdateBlocks != nil && blocks != nil {
-						utils.Logger().Info().Msg("Block being handled by client")
-						node.Client.UpdateBlocks(blocks)
-					}
+					node.syncBlocksHandler(blocks, sender)
 				}
+			case proto_node.CompactSync:
+				utils.Logger().Debug().Msg("NET: received message: Node/CompactSync")
+				compact, err := types.DecodeCompactBlock(msgPayload[1:])
+				if err != nil {
+					utils.Logger().Error().
+						Err(err).
+						Msg("compact block sync")
+					node.host.ReportPeer(sender, reputation.MalformedMessage)
+					return
+				}
+				if s := compact.Header.ShardID(); s != node.Blockchain().ShardID() &&
+					s != shard.BeaconChainShardID {
+					node.host.ReportPeer(sender, reputation.WrongShard)
+					return
+				}
+				block, err := node.ReconstructBlock(compact, sender)
+				if err != nil {
+					utils.Logger().Info().
+						Err(err).
+						Uint64("block", compact.Header.Number().Uint64()).
+						Msg("cannot rebuild compact block, leaving it to block syncing")
+					return
+				}
+				node.syncBlocksHandler([]*types.Block{block}, sender)
 			case
 				proto_node.SlashCandidate,
 				proto_node.Receipt,
@@ -191,7 +315,7 @@ func (node *Node) HandleMessage(content []byte, sender libp2p_peer.ID) {
 	}
 }
 
-func (node *Node) transactionMessageHandler(msgPayload []byte) {
+func (node *Node) transactionMessageHandler(msgPayload []byte, sender libp2p_peer.ID) {
 	if len(msgPayload) >= types.MaxEncodedPoolTransactionSize {
 		utils.Logger().Warn().Err(core.ErrOversizedData).Msgf("encoded tx size: %d", len(msgPayload))
 		return
@@ -210,13 +334,24 @@ func (node *Node) transactionMessageHandler(msgPayload []byte) {
 			utils.Logger().Error().
 				Err(err).
 				Msg("Failed to deserialize transaction list")
+			node.host.ReportPeer(sender, reputation.MalformedMessage)
 			return
 		}
 		node.addPendingTransactions(txs)
+	case proto_node.Announce:
+		hashes, err := decodeAnnouncedHashes(msgPayload[1:])
+		if err != nil {
+			utils.Logger().Error().
+				Err(err).
+				Msg("Failed to deserialize transaction announcement")
+			node.host.ReportPeer(sender, reputation.MalformedMessage)
+			return
+		}
+		node.txFetcher.Notify(sender, hashes)
 	}
 }
 
-func (node *Node) stakingMessageHandler(msgPayload []byte) {
+func (node *Node) stakingMessageHandler(msgPayload []byte, sender libp2p_peer.ID) {
 	if len(msgPayload) >= types.MaxEncodedPoolTransactionSize {
 		utils.Logger().Warn().Err(core.ErrOversizedData).Msgf("encoded tx size: %d", len(msgPayload))
 		return
@@ -235,9 +370,23 @@ func (node *Node) stakingMessageHandler(msgPayload []byte) {
 			utils.Logger().Error().
 				Err(err).
 				Msg("Failed to deserialize staking transaction list")
+			node.host.ReportPeer(sender, reputation.MalformedMessage)
 			return
 		}
 		node.addPendingStakingTransactions(txs)
+	case proto_node.Announce:
+		hashes, err := decodeAnnouncedHashes(msgPayload[1:])
+		if err != nil {
+			utils.Logger().Error().
+				Err(err).
+				Msg("Failed to deserialize staking transaction announcement")
+			node.host.ReportPeer(sender, reputation.MalformedMessage)
+			return
+		}
+		// only the beacon chain takes staking transactions
+		if node.Blockchain().ShardID() == shard.BeaconChainShardID {
+			node.txFetcher.Notify(sender, hashes)
+		}
 	}
 }
 
@@ -250,9 +399,21 @@ func (node *Node) BroadcastNewBlock(newBlock *types.Block) {
 		Msgf(
 			"broadcasting new block %d, group %s", newBlock.NumberU64(), groups[0],
 		)
-	msg := host.ConstructP2pMessage(byte(0),
-		proto_node.ConstructBlocksSyncMessage([]*types.Block{newBlock}),
-	)
+	// receivers rebuild the block from their pools, or fetch the transactions
+	// they miss from a peer holding the block, once the epoch allows it
+	var syncMsg []byte
+	if node.Blockchain().Config().IsCompactBlock(newBlock.Epoch()) {
+		var err error
+		syncMsg, err = proto_node.ConstructCompactBlockSyncMessage(newBlock, node.PrefillTx)
+		if err != nil {
+			utils.Logger().Warn().Err(err).Msg("cannot compact new block, broadcasting it in full")
+			syncMsg = nil
+		}
+	}
+	if syncMsg == nil {
+		syncMsg = proto_node.ConstructBlocksSyncMessage([]*types.Block{newBlock})
+	}
+	msg := host.ConstructP2pMessage(byte(0), syncMsg)
 	if err := node.host.SendMessageToGroups(groups, msg); err != nil {
 		utils.Logger().Warn().Err(err).Msg("cannot broadcast new block")
 	}
@@ -484,7 +645,8 @@ func (node *Node) PostConsensusProcessing(
 	node.BroadcastMissingCXReceipts()
 
 	// Update consensus keys at last so the change of leader status doesn't mess up normal flow
-	if len(newBlock.Header().ShardState()) > 0 {
+	if len(newBlock.Header().ShardState()) > 0 ||
+		chain.IsLeaderRotationBlock(node.Blockchain().Config(), newBlock.Header()) {
 		node.Consensus.SetMode(node.Consensus.UpdateConsensusInformation())
 	}
 	if h := node.NodeConfig.WebHooks.Hooks; h != nil {
@@ -521,6 +683,7 @@ func (node *Node) pingMessageHandler(msgPayload []byte, sender libp2p_peer.ID) i
 		utils.Logger().Error().
 			Err(err).
 			Msg("Can't get Ping Message")
+		node.host.ReportPeer(sender, reputation.MalformedMessage)
 		return -1
 	}
 
@@ -606,6 +769,15 @@ func (node *Node) bootstrapConsensus() {
 }
 
 // ConsensusMessageHandler passes received message in node_handler to consensus
-func (node *Node) ConsensusMessageHandler(msgPayload []byte) {
-	node.Consensus.MsgChan <- msgPayload
+func (node *Node) ConsensusMessageHandler(msgPayload []byte, sender libp2p_peer.ID) {
+	if node.ConsensusRecorder != nil {
+		node.ConsensusRecorder.Record(recorder.Received, sender, msgPayload)
+	}
+	// the compact block of a prepared message is rebuilt here, so fetching
+	// the transactions the node misses does not hold up the consensus loop
+	node.Consensus.MsgChan <- consensus.IncomingMessage{
+		Payload: msgPayload,
+		Sender:  sender,
+		Block:   node.Consensus.RebuildBlock(msgPayload, sender),
+	}
 }
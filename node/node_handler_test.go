@@ -1,20 +1,31 @@
 package node
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/harmony-one/harmony/api/proto"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	proto_node "github.com/harmony-one/harmony/api/proto/node"
 	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/crypto/bls"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/msgq"
 	"github.com/harmony-one/harmony/multibls"
 	"github.com/harmony-one/harmony/p2p"
+	p2p_host "github.com/harmony-one/harmony/p2p/host"
+	mock_p2p "github.com/harmony-one/harmony/p2p/host/mock"
 	"github.com/harmony-one/harmony/p2p/p2pimpl"
+	"github.com/harmony-one/harmony/p2p/reputation"
 	"github.com/harmony-one/harmony/shard"
 	staking "github.com/harmony-one/harmony/staking/types"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
 )
 
 func TestAddNewBlock(t *testing.T) {
@@ -90,3 +101,103 @@ func TestVerifyNewBlock(t *testing.T) {
 		t.Error("New block is not verified successfully:", err)
 	}
 }
+
+func TestClassifyMessage(t *testing.T) {
+	consensusMessage := func(msgType msg_pb.MessageType) []byte {
+		payload, err := protobuf.Marshal(&msg_pb.Message{
+			ServiceType: msg_pb.ServiceType_CONSENSUS,
+			Type:        msgType,
+			Signature:   []byte{1, 2, 3},
+		})
+		if err != nil {
+			t.Fatalf("cannot marshal consensus message: %v", err)
+		}
+		return proto.ConstructConsensusMessage(payload)
+	}
+	tests := []struct {
+		name    string
+		content []byte
+		want    msgq.Category
+	}{
+		{"empty", []byte{}, msgq.Other},
+		{"announce", consensusMessage(msg_pb.MessageType_ANNOUNCE), msgq.Consensus},
+		{"prepare", consensusMessage(msg_pb.MessageType_PREPARE), msgq.Consensus},
+		{"viewchange", consensusMessage(msg_pb.MessageType_VIEWCHANGE), msgq.ViewChange},
+		{"newview", consensusMessage(msg_pb.MessageType_NEWVIEW), msgq.ViewChange},
+		{"transactions", proto_node.ConstructTransactionListMessageAccount(types.Transactions{}), msgq.Transaction},
+		{"blocks", proto_node.ConstructBlocksSyncMessage(nil), msgq.Block},
+		{"compact block", []byte{byte(proto.Node), byte(proto_node.Block), byte(proto_node.CompactSync)}, msgq.Block},
+		{"receipts", []byte{byte(proto.Node), byte(proto_node.Block), byte(proto_node.Receipt)}, msgq.CrossShard},
+		{"drand", proto.ConstructDRandMessage([]byte{1}), msgq.Other},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyMessage(tt.content); got != tt.want {
+				t.Errorf("classifyMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// queuedReceiver returns its messages in turn, then reports itself closed
+type queuedReceiver struct {
+	msgs   [][]byte
+	sender libp2p_peer.ID
+}
+
+func (r *queuedReceiver) Close() error { return nil }
+
+func (r *queuedReceiver) Receive(ctx context.Context) ([]byte, libp2p_peer.ID, error) {
+	if len(r.msgs) == 0 {
+		return nil, "", p2p.ErrReceiverClosed
+	}
+	msg := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return msg, r.sender, nil
+}
+
+// overrunQueue is a receive queue which is always full
+type overrunQueue struct{}
+
+func (overrunQueue) AddMessage(content []byte, sender libp2p_peer.ID) error {
+	return msgq.ErrRxOverrun
+}
+
+func TestReceiveGroupMessageReportsOverrun(t *testing.T) {
+	consensusPayload, err := protobuf.Marshal(&msg_pb.Message{
+		ServiceType: msg_pb.ServiceType_CONSENSUS,
+		Type:        msg_pb.MessageType_PREPARED,
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal consensus message: %v", err)
+	}
+	tests := []struct {
+		name    string
+		content []byte
+		report  bool
+	}{
+		{"consensus", proto.ConstructConsensusMessage(consensusPayload), false},
+		{"blocks", proto_node.ConstructBlocksSyncMessage(nil), false},
+		{"receipts", []byte{byte(proto.Node), byte(proto_node.Block), byte(proto_node.Receipt)}, false},
+		{"transactions", proto_node.ConstructTransactionListMessageAccount(types.Transactions{}), true},
+		{"drand", proto.ConstructDRandMessage([]byte{1}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			sender := libp2p_peer.ID("sender")
+			host := mock_p2p.NewMockHost(ctrl)
+			host.EXPECT().GetID().Return(libp2p_peer.ID("self")).AnyTimes()
+			if tt.report {
+				host.EXPECT().ReportPeer(sender, reputation.RxOverrun)
+			}
+			node := &Node{host: host}
+			receiver := &queuedReceiver{
+				msgs:   [][]byte{p2p_host.ConstructP2pMessage(byte(0), tt.content)},
+				sender: sender,
+			}
+			node.receiveGroupMessage(receiver, overrunQueue{})
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package node
+
+import (
+	"github.com/harmony-one/harmony/p2p"
+)
+
+// StartInProcess starts a validator whose host is an in-process network: the
+// message handling, the consensus and the block proposal.  The discovery,
+// syncing and RPC services, which need a real network, are not started.
+//
+// The returned function stops the node like a crash: it stops receiving and
+// proposing at once, without waiting for the rounds in progress.
+func (node *Node) StartInProcess() (stop func()) {
+	node.initNodeConfiguration()
+
+	for i := 0; i < RxWorkers; i++ {
+		go node.rxQueue.HandleMessages(node)
+	}
+	receivers := []p2p.GroupReceiver{}
+	for _, receiver := range []p2p.GroupReceiver{
+		node.clientReceiver, node.shardGroupReceiver, node.globalGroupReceiver,
+	} {
+		if receiver == nil {
+			continue
+		}
+		receivers = append(receivers, receiver)
+		go node.receiveGroupMessage(receiver, node.rxQueue)
+	}
+
+	consensusStop, proposalStop := make(chan struct{}), make(chan struct{})
+	node.Consensus.Start(node.BlockChannel, consensusStop, make(chan struct{}), node.startConsensus)
+	node.Consensus.WaitForNewRandomness()
+	node.WaitForConsensusReadyV2(node.Consensus.ReadySignal, proposalStop, make(chan struct{}))
+
+	return func() {
+		for _, receiver := range receivers {
+			receiver.Close()
+		}
+		node.rxQueue.Close()
+		close(proposalStop)
+		close(consensusStop)
+	}
+}
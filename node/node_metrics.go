@@ -5,6 +5,7 @@ import (
 
 	metrics "github.com/harmony-one/harmony/api/service/metrics"
 	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/msgq"
 )
 
 // UpdateBlockHeightForMetrics updates block height for metrics service.
@@ -75,12 +76,44 @@ func (node *Node) UpdateIsLeaderForMetrics() {
 	}
 }
 
-// CollectMetrics collects metrics: block height, connections number, node balance, block reward, last consensus, accepted blocks.
+// UpdateRxQueueForMetrics updates the incoming message queue lengths and drops for metrics service.
+func (node *Node) UpdateRxQueueForMetrics(prevStats []msgq.Stats) []msgq.Stats {
+	curStats := node.rxQueue.Stats()
+	for i, stats := range curStats {
+		if prevStats != nil && stats.Len == prevStats[i].Len && stats.Dropped == prevStats[i].Dropped {
+			continue
+		}
+		metrics.UpdateRxQueue(stats.Category.String(), stats.Len, stats.Dropped)
+	}
+	return curStats
+}
+
+// UpdateConsensusTimingForMetrics updates the consensus timing of the last block for metrics service.
+func (node *Node) UpdateConsensusTimingForMetrics(prevBlockNum uint64) uint64 {
+	timing, ok := node.Consensus.Timings().Latest()
+	if !ok || timing.BlockNum == prevBlockNum {
+		return prevBlockNum
+	}
+	metrics.UpdateConsensusTiming(map[string]time.Duration{
+		"announce_to_prepared":   timing.AnnounceToPrepared(),
+		"prepared_to_committed":  timing.PreparedToCommitted(),
+		"committed_to_finalized": timing.CommittedToFinalized(),
+	}, map[string]int64{
+		"prepare": timing.PrepareSigners,
+		"commit":  timing.CommitSigners,
+		"late":    int64(len(timing.LateSigners)),
+	}, timing.ViewChanges)
+	return timing.BlockNum
+}
+
+// CollectMetrics collects metrics: block height, connections number, node balance, block reward, last consensus, accepted blocks, consensus timing.
 func (node *Node) CollectMetrics() {
 	utils.Logger().Info().Msg("[Metrics Service] Update metrics")
 	prevNumPeers := 0
 	prevBlockHeight := uint64(0)
 	prevLastConsensusTime := int64(0)
+	prevTimingBlockNum := uint64(0)
+	var prevRxQueueStats []msgq.Stats
 	for range time.Tick(100 * time.Millisecond) {
 		prevBlockHeight = node.UpdateBlockHeightForMetrics(prevBlockHeight)
 		prevNumPeers = node.UpdateConnectionsNumberForMetrics(prevNumPeers)
@@ -88,5 +121,7 @@ func (node *Node) CollectMetrics() {
 		node.UpdateBalanceForMetrics()
 		node.UpdateTxPoolSizeForMetrics(node.TxPool.GetTxPoolSize())
 		node.UpdateIsLeaderForMetrics()
+		prevRxQueueStats = node.UpdateRxQueueForMetrics(prevRxQueueStats)
+		prevTimingBlockNum = node.UpdateConsensusTimingForMetrics(prevTimingBlockNum)
 	}
 }
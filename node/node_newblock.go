@@ -18,6 +18,9 @@ import (
 const (
 	SleepPeriod           = 20 * time.Millisecond
 	IncomingReceiptsLimit = 6000 // 2000 * (numShards - 1)
+	// DefaultFirstProposalDelay is the default time the leader waits for the
+	// other nodes to be ready before proposing the first block
+	DefaultFirstProposalDelay = 30 * time.Second
 )
 
 // WaitForConsensusReadyV2 listen for the readiness signal from consensus and generate new block for consensus.
@@ -31,7 +34,7 @@ func (node *Node) WaitForConsensusReadyV2(readySignal chan struct{}, stopChan ch
 		utils.Logger().Debug().
 			Msg("Waiting for Consensus ready")
 		// TODO: make local net start faster
-		time.Sleep(30 * time.Second) // Wait for other nodes to be ready (test-only)
+		time.Sleep(node.FirstProposalDelay) // Wait for other nodes to be ready (test-only)
 
 		// Set up the very first deadline.
 		deadline := time.Now().Add(node.BlockPeriod)
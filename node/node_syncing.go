@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/pkg/errors"
 
@@ -19,6 +20,9 @@ import (
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/node/worker"
 	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/shard"
+	libp2p_host "github.com/libp2p/go-libp2p-host"
+	ma "github.com/multiformats/go-multiaddr"
 )
 
 // Constants related to doing syncing.
@@ -51,11 +55,44 @@ func (node *Node) DoSyncWithoutConsensus() {
 // IsSameHeight tells whether node is at same bc height as a peer
 func (node *Node) IsSameHeight() (uint64, bool) {
 	if node.stateSync == nil {
-		node.stateSync = syncing.CreateStateSync(node.SelfPeer.IP, node.SelfPeer.Port, node.GetSyncID())
+		node.stateSync = node.newStateSync(node.Blockchain().ShardID())
 	}
 	return node.stateSync.IsSameBlockchainHeight(node.Blockchain())
 }
 
+// newStateSync creates a state sync posting its events to the sync event feed of the node,
+// and downloading over the libp2p sync protocol of the given shard, unless gRPC syncing is configured
+func (node *Node) newStateSync(shardID uint32) *syncing.StateSync {
+	stateSync := syncing.CreateStateSync(node.SelfPeer.IP, node.SelfPeer.Port, node.GetSyncID())
+	stateSync.SetSyncEventFeed(&node.syncFeed)
+	if !node.NodeConfig.SyncGRPC {
+		stateSync.SetStreamHost(node.host.GetP2PHost(), shardID)
+	}
+	return stateSync
+}
+
+// SyncProgress returns the progress of syncing the shard chain, or the beacon
+// chain if isBeacon is true and the node is not in the beacon shard
+func (node *Node) SyncProgress(isBeacon bool) syncing.Progress {
+	stateSync, bc := node.stateSync, node.Blockchain()
+	if isBeacon && bc.ShardID() != shard.BeaconChainShardID {
+		stateSync, bc = node.beaconSync, node.Beaconchain()
+	}
+	if stateSync == nil {
+		current := bc.CurrentBlock().NumberU64()
+		return syncing.Progress{
+			CurrentBlock: current, HighestBlock: current, Peers: []syncing.PeerProgress{},
+		}
+	}
+	return stateSync.Progress(bc)
+}
+
+// SubscribeSyncEvent subscribes the channel to the sync events of the shard
+// chain and the beacon chain
+func (node *Node) SubscribeSyncEvent(ch chan<- syncing.SyncEvent) event.Subscription {
+	return node.syncFeed.Subscribe(ch)
+}
+
 // SyncingPeerProvider is an interface for getting the peers in the given shard.
 type SyncingPeerProvider interface {
 	SyncingPeers(shardID uint32) (peers []p2p.Peer, err error)
@@ -121,6 +158,37 @@ func (p *DNSSyncingPeerProvider) SyncingPeers(shardID uint32) (peers []p2p.Peer,
 	return peers, nil
 }
 
+// StreamSyncingPeerProvider finds syncing peers among the libp2p peers
+// connected to the host, by the sync protocol of the shard they serve.
+type StreamSyncingPeerProvider struct {
+	host libp2p_host.Host
+}
+
+// NewStreamSyncingPeerProvider returns a provider that finds syncing peers
+// among the peers connected to the given host.
+func NewStreamSyncingPeerProvider(host libp2p_host.Host) *StreamSyncingPeerProvider {
+	return &StreamSyncingPeerProvider{host: host}
+}
+
+// SyncingPeers returns the connected peers serving the sync protocol of the shard.
+func (p *StreamSyncingPeerProvider) SyncingPeers(shardID uint32) (peers []p2p.Peer, err error) {
+	protocolID := string(downloader.ProtocolID(shardID))
+	for _, id := range p.host.Network().Peers() {
+		supported, err := p.host.Peerstore().SupportsProtocols(id, protocolID)
+		if err != nil || len(supported) == 0 {
+			continue
+		}
+		peer := p2p.Peer{PeerID: id, Addrs: p.host.Peerstore().Addrs(id)}
+		if conns := p.host.Network().ConnsToPeer(id); len(conns) > 0 {
+			// ip and port are only used to log the peer
+			peer.IP, _ = conns[0].RemoteMultiaddr().ValueForProtocol(ma.P_IP4)
+			peer.Port, _ = conns[0].RemoteMultiaddr().ValueForProtocol(ma.P_TCP)
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
 // LocalSyncingPeerProvider uses localnet deployment convention to synthesize
 // syncing peers.
 type LocalSyncingPeerProvider struct {
@@ -179,7 +247,7 @@ func (node *Node) DoBeaconSyncing() {
 	for {
 		if node.beaconSync == nil {
 			utils.Logger().Info().Msg("initializing beacon sync")
-			node.beaconSync = syncing.CreateStateSync(node.SelfPeer.IP, node.SelfPeer.Port, node.GetSyncID())
+			node.beaconSync = node.newStateSync(shard.BeaconChainShardID)
 		}
 		if node.beaconSync.GetActivePeerNumber() == 0 {
 			utils.Logger().Info().Msg("no peers; bootstrapping beacon sync config")
@@ -218,7 +286,7 @@ func (node *Node) DoSyncing(bc *core.BlockChain, worker *worker.Worker, willJoin
 // doSync keep the node in sync with other peers, willJoinConsensus means the node will try to join consensus after catch up
 func (node *Node) doSync(bc *core.BlockChain, worker *worker.Worker, willJoinConsensus bool) {
 	if node.stateSync == nil {
-		node.stateSync = syncing.CreateStateSync(node.SelfPeer.IP, node.SelfPeer.Port, node.GetSyncID())
+		node.stateSync = node.newStateSync(bc.ShardID())
 		utils.Logger().Debug().Msg("[SYNC] initialized state sync")
 	}
 	if node.stateSync.GetActivePeerNumber() < MinConnectedPeers {
@@ -240,6 +308,16 @@ func (node *Node) doSync(bc *core.BlockChain, worker *worker.Worker, willJoinCon
 		}
 		utils.Logger().Debug().Int("len", node.stateSync.GetActivePeerNumber()).Msg("[SYNC] Get Active Peers")
 	}
+	if node.syncCheckpoint != nil {
+		if err := node.stateSync.CheckpointSync(bc, node.syncCheckpoint); err != nil {
+			utils.Logger().Warn().
+				Err(err).
+				Str("checkpoint", node.syncCheckpoint.Hash.Hex()).
+				Msg("[SYNC] checkpoint sync failed")
+			return
+		}
+		node.syncCheckpoint = nil
+	}
 	// TODO: treat fake maximum height
 	if node.stateSync.IsOutOfSync(bc) {
 		node.stateMutex.Lock()
@@ -294,9 +372,12 @@ func (node *Node) InitSyncingServer() {
 	}
 }
 
-// StartSyncingServer starts syncing server.
+// StartSyncingServer starts syncing server. Both the libp2p sync protocol of
+// the shard and the gRPC one are served, whichever one the node syncs over,
+// so that peers still syncing over gRPC keep finding servers.
 func (node *Node) StartSyncingServer() {
 	utils.Logger().Info().Msg("[SYNC] support_syncing: StartSyncingServer")
+	node.downloaderServer.StartStream(node.host.GetP2PHost(), node.Blockchain().ShardID())
 	if node.downloaderServer.GrpcServer == nil {
 		node.downloaderServer.Start(node.SelfPeer.IP, syncing.GetSyncingPort(node.SelfPeer.Port))
 	}
@@ -407,6 +488,45 @@ func (node *Node) CalculateResponse(request *downloader_pb.DownloaderRequest, in
 			}
 		}
 
+	case downloader_pb.DownloaderRequest_NODEDATA:
+		var hash common.Hash
+		for _, bytes := range request.Hashes {
+			hash.SetBytes(bytes)
+			data, err := node.Blockchain().TrieNode(hash)
+			if err != nil || len(data) == 0 {
+				continue
+			}
+			response.Payload = append(response.Payload, data)
+		}
+
+	case downloader_pb.DownloaderRequest_VALIDATORLIST:
+		addrs, err := node.Blockchain().ReadValidatorList()
+		if err != nil {
+			return response, err
+		}
+		for _, addr := range addrs {
+			response.Payload = append(response.Payload, addr.Bytes())
+		}
+
+	case downloader_pb.DownloaderRequest_EPOCHSTATE:
+		var hash common.Hash
+		hash.SetBytes(request.BlockHash)
+		header := node.Blockchain().GetHeaderByHash(hash)
+		if header == nil {
+			return response, fmt.Errorf("[SYNC] GetEpochState Request cannot find block %s", hash.Hex())
+		}
+		shardState, err := node.Blockchain().ReadShardState(header.Epoch())
+		if err != nil {
+			return response, err
+		}
+		encoded, err := shard.EncodeWrapper(
+			*shardState, node.Blockchain().Config().IsStaking(header.Epoch()),
+		)
+		if err != nil {
+			return response, err
+		}
+		response.Payload = append(response.Payload, encoded)
+
 	case downloader_pb.DownloaderRequest_BLOCKHEIGHT:
 		response.BlockHeight = node.Blockchain().CurrentBlock().NumberU64()
 
@@ -425,12 +545,21 @@ func (node *Node) CalculateResponse(request *downloader_pb.DownloaderRequest, in
 			utils.Logger().Warn().Msg("[SYNC] unable to decode received new block")
 			return response, err
 		}
-		node.stateSync.AddNewBlock(request.PeerHash, &blockObj)
+		peerHash := request.PeerHash
+		if streamPeer, ok := downloader.StreamPeerID(incomingPeer); ok {
+			// the sender of a libp2p stream is authenticated by its peer ID
+			peerHash = []byte(streamPeer)
+		}
+		node.stateSync.AddNewBlock(peerHash, &blockObj)
 
 	case downloader_pb.DownloaderRequest_REGISTER:
 		peerID := string(request.PeerHash[:])
 		ip := request.Ip
 		port := request.Port
+		streamPeer, isStream := downloader.StreamPeerID(incomingPeer)
+		if isStream {
+			peerID = string(streamPeer)
+		}
 		node.stateMutex.Lock()
 		defer node.stateMutex.Unlock()
 		if _, ok := node.peerRegistrationRecord[peerID]; ok {
@@ -449,8 +578,14 @@ func (node *Node) CalculateResponse(request *downloader_pb.DownloaderRequest, in
 			return response, nil
 		} else {
 			response.Type = downloader_pb.DownloaderResponse_FAIL
-			syncPort := syncing.GetSyncingPort(port)
-			client := downloader.ClientSetup(ip, syncPort)
+			var client *downloader.Client
+			if isStream {
+				client = downloader.StreamClientSetup(
+					node.host.GetP2PHost(), streamPeer, node.Blockchain().ShardID(),
+				)
+			} else {
+				client = downloader.ClientSetup(ip, syncing.GetSyncingPort(port))
+			}
 			if client == nil {
 				utils.Logger().Warn().
 					Str("ip", ip).
@@ -1,6 +1,7 @@
 package node
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	proto_discovery "github.com/harmony-one/harmony/api/proto/discovery"
+	"github.com/harmony-one/harmony/api/service/syncing/downloader"
 	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	bls2 "github.com/harmony-one/harmony/crypto/bls"
@@ -20,6 +22,10 @@ import (
 	"github.com/harmony-one/harmony/p2p"
 	"github.com/harmony-one/harmony/p2p/p2pimpl"
 	"github.com/harmony-one/harmony/shard"
+	libp2p "github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/network"
+	libp2p_host "github.com/libp2p/go-libp2p-host"
+	libp2p_peerstore "github.com/libp2p/go-libp2p-peerstore"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -178,6 +184,44 @@ func makeLocalSyncingPeerProvider() *LocalSyncingPeerProvider {
 	return NewLocalSyncingPeerProvider(6000, 6001, 2, 3)
 }
 
+func TestStreamSyncingPeerProvider(t *testing.T) {
+	ctx := context.Background()
+	newHost := func() libp2p_host.Host {
+		host, err := libp2p.New(ctx, libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+		if err != nil {
+			t.Fatalf("cannot create host: %v", err)
+		}
+		return host
+	}
+	self, shard0, shard1 := newHost(), newHost(), newHost()
+	defer self.Close()
+	defer shard0.Close()
+	defer shard1.Close()
+	shard0.SetStreamHandler(downloader.ProtocolID(0), func(s network.Stream) { s.Reset() })
+	shard1.SetStreamHandler(downloader.ProtocolID(1), func(s network.Stream) { s.Reset() })
+	for _, peer := range []libp2p_host.Host{shard0, shard1} {
+		info := libp2p_peerstore.PeerInfo{ID: peer.ID(), Addrs: peer.Addrs()}
+		if err := self.Connect(ctx, info); err != nil {
+			t.Fatalf("cannot connect to peer: %v", err)
+		}
+	}
+
+	p := NewStreamSyncingPeerProvider(self)
+	var peers []p2p.Peer
+	// the supported protocols are learned asynchronously after connecting
+	for i := 0; i < 50 && len(peers) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+		peers, _ = p.SyncingPeers(1)
+	}
+	if assert.Len(t, peers, 1) {
+		assert.Equal(t, shard1.ID(), peers[0].PeerID)
+		assert.Equal(t, "127.0.0.1", peers[0].IP)
+	}
+	peers, err := p.SyncingPeers(2)
+	assert.NoError(t, err)
+	assert.Empty(t, peers)
+}
+
 func TestAddPeers(t *testing.T) {
 	pubKey1 := pki.GetBLSPrivateKeyFromInt(333).GetPublicKey()
 	pubKey2 := pki.GetBLSPrivateKeyFromInt(444).GetPublicKey()
@@ -303,3 +347,16 @@ func exitServer() {
 
 	os.Exit(0)
 }
+
+func TestRxQueueConfigsKeepWorkers(t *testing.T) {
+	// the categories dispatched first must leave workers to the weighted ones
+	firstWorkers := 0
+	for _, config := range RxQueueConfigs {
+		if config.Weight == 0 {
+			firstWorkers += config.Workers
+		}
+	}
+	if firstWorkers >= RxWorkers {
+		t.Errorf("zero weight categories take %d of the %d workers", firstWorkers, RxWorkers)
+	}
+}
@@ -0,0 +1,141 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/p2p/reputation"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/pkg/errors"
+)
+
+// Constants for fetching announced transactions.
+const (
+	// MaxAnnouncedHashes is the maximum number of transaction hashes in an
+	// announcement or in a request for transactions
+	MaxAnnouncedHashes = 256
+	// txsStreamTimeout bounds a request for transactions served over a stream
+	txsStreamTimeout = 10 * time.Second
+)
+
+// TxsProtocolID returns the ID of the libp2p protocol answering the pooled
+// transactions of the given shard by hash, for fetching announced transactions.
+func TxsProtocolID(shardID uint32) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/harmony/txs/%d/0.0.1", shardID))
+}
+
+// txsRequest asks a peer for pooled transactions by hash
+type txsRequest struct {
+	Hashes []common.Hash
+}
+
+// txsResponse holds the transactions of a txsRequest the peer has in its pool
+type txsResponse struct {
+	Txs        []*types.Transaction
+	StakingTxs []*staking.StakingTransaction
+}
+
+// hasTransaction returns whether the transaction is in the pool
+func (node *Node) hasTransaction(hash common.Hash) bool {
+	return node.TxPool.Get(hash) != nil
+}
+
+// fetchTransactions asks the peer for the announced transactions and adds
+// them to the pool, returning the hashes of the transactions delivered
+func (node *Node) fetchTransactions(
+	ctx context.Context, peer libp2p_peer.ID, hashes []common.Hash,
+) ([]common.Hash, error) {
+	response := &txsResponse{}
+	if err := node.streamRequest(
+		ctx, peer, TxsProtocolID(node.Blockchain().ShardID()), &txsRequest{Hashes: hashes}, response,
+	); err != nil {
+		return nil, err
+	}
+	asked := make(map[common.Hash]bool, len(hashes))
+	for _, hash := range hashes {
+		asked[hash] = true
+	}
+	delivered := []common.Hash{}
+	txs := types.Transactions{}
+	for _, tx := range response.Txs {
+		if hash := tx.Hash(); asked[hash] {
+			txs = append(txs, tx)
+			delivered = append(delivered, hash)
+		}
+	}
+	stakingTxs := staking.StakingTransactions{}
+	for _, tx := range response.StakingTxs {
+		if hash := tx.Hash(); asked[hash] {
+			stakingTxs = append(stakingTxs, tx)
+			delivered = append(delivered, hash)
+		}
+	}
+	if len(delivered) < len(response.Txs)+len(response.StakingTxs) {
+		node.host.ReportPeer(peer, reputation.MalformedMessage)
+	}
+	if len(txs) > 0 {
+		node.addPendingTransactions(txs)
+	}
+	if len(stakingTxs) > 0 {
+		node.addPendingStakingTransactions(stakingTxs)
+	}
+	return delivered, nil
+}
+
+// decodeAnnouncedHashes decodes the transaction hashes of an announcement
+func decodeAnnouncedHashes(payload []byte) ([]common.Hash, error) {
+	hashes := []common.Hash{}
+	if err := rlp.DecodeBytes(payload, &hashes); err != nil {
+		return nil, err
+	}
+	if len(hashes) > MaxAnnouncedHashes {
+		return nil, errors.Errorf("%d transactions announced", len(hashes))
+	}
+	return hashes, nil
+}
+
+// startTxsServer answers the pooled transactions of the shard
+func (node *Node) startTxsServer() {
+	node.host.GetP2PHost().SetStreamHandler(
+		TxsProtocolID(node.Blockchain().ShardID()), node.handleTxsStream,
+	)
+}
+
+// handleTxsStream answers a request for pooled transactions
+func (node *Node) handleTxsStream(stream network.Stream) {
+	stream.SetDeadline(time.Now().Add(txsStreamTimeout))
+	remote := stream.Conn().RemotePeer()
+	request := &txsRequest{}
+	if err := readStreamRequest(stream, request); err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote.Pretty()).
+			Msg("[TxFetcher] cannot read transactions request")
+		stream.Reset()
+		return
+	}
+	if len(request.Hashes) > MaxAnnouncedHashes {
+		node.host.ReportPeer(remote, reputation.MalformedMessage)
+		stream.Reset()
+		return
+	}
+	response := &txsResponse{}
+	for _, hash := range request.Hashes {
+		switch tx := node.TxPool.Get(hash).(type) {
+		case *types.Transaction:
+			response.Txs = append(response.Txs, tx)
+		case *staking.StakingTransaction:
+			response.StakingTxs = append(response.StakingTxs, tx)
+		}
+	}
+	if err := writeStreamResponse(stream, response); err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote.Pretty()).
+			Msg("[TxFetcher] cannot write transactions response")
+	}
+}
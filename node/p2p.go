@@ -1,10 +1,62 @@
 package node
 
 import (
+	"bufio"
+	"context"
+
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/harmony/p2p"
+	"github.com/libp2p/go-libp2p-core/helpers"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	libp2p_peer "github.com/libp2p/go-libp2p-peer"
 )
 
+// maxStreamMessageSize is the maximum size of an RLP message read from a
+// request/response stream
+const maxStreamMessageSize = 32 << 20
+
 // GetHost returns the p2p host
 func (node *Node) GetHost() p2p.Host {
 	return node.host
 }
+
+// streamRequest sends the RLP encoded request to the peer over a new stream of
+// the protocol, and decodes its response
+func (node *Node) streamRequest(
+	ctx context.Context, peer libp2p_peer.ID, protocolID protocol.ID,
+	request interface{}, response interface{},
+) error {
+	stream, err := node.host.GetP2PHost().NewStream(ctx, peer, protocolID)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	}
+	if err := rlp.Encode(stream, request); err != nil {
+		stream.Reset()
+		return err
+	}
+	if err := rlp.NewStream(bufio.NewReader(stream), maxStreamMessageSize).Decode(response); err != nil {
+		stream.Reset()
+		return err
+	}
+	go helpers.FullClose(stream)
+	return nil
+}
+
+// readStreamRequest decodes the RLP encoded request of an incoming stream
+func readStreamRequest(stream network.Stream, request interface{}) error {
+	return rlp.NewStream(bufio.NewReader(stream), maxStreamMessageSize).Decode(request)
+}
+
+// writeStreamResponse writes the RLP encoded response to an incoming stream
+// and closes it
+func writeStreamResponse(stream network.Stream, response interface{}) error {
+	if err := rlp.Encode(stream, response); err != nil {
+		stream.Reset()
+		return err
+	}
+	return helpers.FullClose(stream)
+}
@@ -8,9 +8,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/hmy"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
 	"github.com/harmony-one/harmony/internal/hmyapi"
 	"github.com/harmony-one/harmony/internal/hmyapi/apiv1"
 	"github.com/harmony-one/harmony/internal/hmyapi/apiv2"
@@ -20,8 +22,9 @@ import (
 )
 
 const (
-	rpcHTTPPortOffset = 500
-	rpcWSPortOffset   = 800
+	rpcHTTPPortOffset  = 500
+	rpcAdminPortOffset = 700
+	rpcWSPortOffset    = 800
 )
 
 var (
@@ -39,6 +42,11 @@ var (
 	httpOrigins      = []string{"*"}
 	wsModules        = []string{"hmy", "hmyv2", "net", "netv2", "web3"}
 	wsOrigins        = []string{"*"}
+	adminListener    net.Listener
+	adminHandler     *rpc.Server
+	adminEndpoint    = ""
+	adminModules     = []string{"admin"}
+	adminVirtualHost = []string{"localhost"}
 	harmony          *hmy.Harmony
 )
 
@@ -47,6 +55,17 @@ func (node *Node) IsCurrentlyLeader() bool {
 	return node.Consensus.IsLeader()
 }
 
+// ConsensusTimings returns the timing of the consensus phases of the last blocks
+func (node *Node) ConsensusTimings() []consensus.BlockTiming {
+	return node.Consensus.Timings().History()
+}
+
+// ConsensusTimeouts returns the durations of the consensus and view change
+// timeouts the node runs with
+func (node *Node) ConsensusTimeouts() shardingconfig.ConsensusTimeouts {
+	return node.Consensus.Timeouts()
+}
+
 // PendingCXReceipts returns node.pendingCXReceiptsProof
 func (node *Node) PendingCXReceipts() []*types.CXReceiptsProof {
 	cxReceipts := make([]*types.CXReceiptsProof, len(node.pendingCXReceipts))
@@ -113,6 +132,13 @@ func (node *Node) StartRPC(nodePort string) error {
 		node.stopHTTP()
 		return err
 	}
+	// the admin endpoint is never public
+	adminEndpoint = fmt.Sprintf("127.0.0.1:%v", port+rpcAdminPortOffset)
+	if err := node.startAdmin(adminEndpoint, node.AdminAPIs()); err != nil {
+		node.stopHTTP()
+		node.stopWS()
+		return err
+	}
 
 	rpcAPIs = apis
 	return nil
@@ -154,6 +180,31 @@ func (node *Node) stopHTTP() {
 	}
 }
 
+// startAdmin initializes and starts the localhost-only HTTP endpoint of the admin RPC.
+func (node *Node) startAdmin(endpoint string, apis []rpc.API) error {
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, adminModules, nil, adminVirtualHost, httpTimeouts)
+	if err != nil {
+		return err
+	}
+	utils.Logger().Info().Str("url", fmt.Sprintf("http://%s", endpoint)).Msg("Admin HTTP endpoint opened")
+	adminListener = listener
+	adminHandler = handler
+	return nil
+}
+
+// stopAdmin terminates the admin RPC endpoint.
+func (node *Node) stopAdmin() {
+	if adminListener != nil {
+		adminListener.Close()
+		adminListener = nil
+		utils.Logger().Info().Str("url", fmt.Sprintf("http://%s", adminEndpoint)).Msg("Admin HTTP endpoint closed")
+	}
+	if adminHandler != nil {
+		adminHandler.Stop()
+		adminHandler = nil
+	}
+}
+
 // startWS initializes and starts the websocket RPC endpoint.
 func (node *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool) error {
 	// Short circuit if the WS endpoint isn't being exposed
@@ -211,3 +262,16 @@ func (node *Node) APIs() []rpc.API {
 		},
 	}...)
 }
+
+// AdminAPIs returns the private RPC services served only on the localhost
+// admin endpoint, never on the public HTTP and WebSocket endpoints.
+func (node *Node) AdminAPIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   apiv1.NewPrivateAdminAPI(node.host),
+			Public:    false,
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/consensus"
+	"github.com/harmony-one/harmony/consensus/quorum"
+	bls2 "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/hmy"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/multibls"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/p2pimpl"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestAdminAPINotServedOverWS(t *testing.T) {
+	blsKey := bls2.RandPrivateKey()
+	pubKey := blsKey.GetPublicKey()
+	leader := p2p.Peer{IP: "127.0.0.1", Port: "8886", ConsensusPubKey: pubKey}
+	priKey, _, _ := utils.GenKeyP2P("127.0.0.1", "9906")
+	host, err := p2pimpl.NewHost(&leader, priKey)
+	if err != nil {
+		t.Fatalf("newhost failure: %v", err)
+	}
+	decider := quorum.NewDecider(
+		quorum.SuperMajorityVote, shard.BeaconChainShardID,
+	)
+	consensus, err := consensus.New(
+		host, shard.BeaconChainShardID, leader, multibls.GetPrivateKey(blsKey), decider,
+	)
+	if err != nil {
+		t.Fatalf("Cannot craeate consensus: %v", err)
+	}
+	node := New(host, consensus, testDBFactory, nil, false)
+	harmony, _ = hmy.New(
+		node, node.TxPool, node.CxPool, new(event.TypeMux), node.Consensus.ShardID,
+	)
+
+	for _, api := range node.APIs() {
+		if api.Namespace == "admin" {
+			t.Errorf("admin API is in the public API list")
+		}
+	}
+
+	if err := node.startWS("127.0.0.1:0", node.APIs(), wsModules, wsOrigins, true); err != nil {
+		t.Fatalf("cannot start WebSocket endpoint: %v", err)
+	}
+	defer node.stopWS()
+	client, err := rpc.Dial("ws://" + wsListener.Addr().String())
+	if err != nil {
+		t.Fatalf("cannot dial WebSocket endpoint: %v", err)
+	}
+	defer client.Close()
+
+	var version string
+	if err := client.Call(&version, "net_version"); err != nil {
+		t.Errorf("net_version is not served over WebSocket: %v", err)
+	}
+	for _, method := range []string{"admin_peers", "admin_bannedPeers", "admin_nodeInfo"} {
+		var result interface{}
+		if err := client.Call(&result, method); err == nil {
+			t.Errorf("%s is served over WebSocket", method)
+		}
+	}
+
+	if err := node.startAdmin("127.0.0.1:0", node.AdminAPIs()); err != nil {
+		t.Fatalf("cannot start admin endpoint: %v", err)
+	}
+	defer node.stopAdmin()
+	admin, err := rpc.Dial("http://" + adminListener.Addr().String())
+	if err != nil {
+		t.Fatalf("cannot dial admin endpoint: %v", err)
+	}
+	defer admin.Close()
+	var result interface{}
+	if err := admin.Call(&result, "admin_bannedPeers"); err != nil {
+		t.Errorf("admin_bannedPeers is not served on the admin endpoint: %v", err)
+	}
+}
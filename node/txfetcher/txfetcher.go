@@ -0,0 +1,202 @@
+// Package txfetcher fetches the bodies of the transactions announced by hash.
+// Peers gossip the hashes of their new transactions, and a node requests the
+// ones it does not hold from one of the peers which announced them.
+package txfetcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/internal/utils"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+// Config is the configuration of a Fetcher
+type Config struct {
+	// BatchSize is the maximum number of transactions asked in one request
+	BatchSize int
+	// Timeout bounds a request, after which its transactions are asked from
+	// another announcer
+	Timeout time.Duration
+	// MaxPending is the maximum number of announced transactions tracked until
+	// fetched, the announcements of further transactions are dropped
+	MaxPending int
+	// MaxAnnouncers is the maximum number of announcers kept per transaction
+	MaxAnnouncers int
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() Config {
+	return Config{
+		BatchSize:     256,
+		Timeout:       5 * time.Second,
+		MaxPending:    16384,
+		MaxAnnouncers: 4,
+	}
+}
+
+// HasFunc returns whether the node already holds the transaction
+type HasFunc func(hash common.Hash) bool
+
+// FetchFunc requests the transactions from the peer and hands them over to
+// the node, returning the hashes of those the peer delivered
+type FetchFunc func(
+	ctx context.Context, peer libp2p_peer.ID, hashes []common.Hash,
+) (delivered []common.Hash, err error)
+
+// Fetcher tracks the announced transactions and fetches the unknown ones,
+// asking each from a single announcer at a time and at most one request per
+// peer in flight.  A transaction whose announcers all fail is dropped; it
+// still reaches the node in a block.
+type Fetcher struct {
+	config Config
+	has    HasFunc
+	fetch  FetchFunc
+
+	mux sync.Mutex
+	// the announcers not asked yet of each tracked transaction; a transaction
+	// is tracked from its first announcement until fetched or given up on
+	announcers map[common.Hash][]libp2p_peer.ID
+	// the peer each transaction is being fetched from
+	fetching map[common.Hash]libp2p_peer.ID
+	// the transactions announced by each peer, in announce order
+	queues map[libp2p_peer.ID][]common.Hash
+	// the peers a request is in flight to
+	busy map[libp2p_peer.ID]bool
+	// wait tracks the requests in flight, for tests
+	wait sync.WaitGroup
+}
+
+// New returns a fetcher using the given functions to check for and to fetch
+// the transactions
+func New(config Config, has HasFunc, fetch FetchFunc) *Fetcher {
+	return &Fetcher{
+		config:     config,
+		has:        has,
+		fetch:      fetch,
+		announcers: map[common.Hash][]libp2p_peer.ID{},
+		fetching:   map[common.Hash]libp2p_peer.ID{},
+		queues:     map[libp2p_peer.ID][]common.Hash{},
+		busy:       map[libp2p_peer.ID]bool{},
+	}
+}
+
+// Notify records the transactions announced by the peer, and fetches the
+// unknown ones.  It does not block.
+func (f *Fetcher) Notify(peer libp2p_peer.ID, hashes []common.Hash) {
+	unknown := make([]common.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		if !f.has(hash) {
+			unknown = append(unknown, hash)
+		}
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, hash := range unknown {
+		announcers, tracked := f.announcers[hash]
+		switch {
+		case !tracked && len(f.announcers) >= f.config.MaxPending:
+			continue
+		case len(announcers) >= f.config.MaxAnnouncers:
+			continue
+		case contains(announcers, peer) || f.fetching[hash] == peer:
+			continue
+		}
+		f.announcers[hash] = append(announcers, peer)
+		f.queues[peer] = append(f.queues[peer], hash)
+	}
+	f.schedule()
+}
+
+// Pending returns the number of transactions tracked, and of those being fetched
+func (f *Fetcher) Pending() (tracked, fetching int) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return len(f.announcers), len(f.fetching)
+}
+
+// schedule sends a request to every idle peer with transactions to fetch, the
+// caller must hold f.mux
+func (f *Fetcher) schedule() {
+	for peer, queue := range f.queues {
+		if f.busy[peer] {
+			continue
+		}
+		hashes, rest := []common.Hash{}, []common.Hash{}
+		for _, hash := range queue {
+			announcers := f.announcers[hash]
+			if !contains(announcers, peer) {
+				// fetched or given up on meanwhile, or already asked
+				continue
+			}
+			if _, ok := f.fetching[hash]; ok || len(hashes) >= f.config.BatchSize {
+				rest = append(rest, hash)
+				continue
+			}
+			hashes = append(hashes, hash)
+			f.announcers[hash] = remove(announcers, peer)
+			f.fetching[hash] = peer
+		}
+		if len(rest) == 0 {
+			delete(f.queues, peer)
+		} else {
+			f.queues[peer] = rest
+		}
+		if len(hashes) > 0 {
+			f.busy[peer] = true
+			f.wait.Add(1)
+			go f.request(peer, hashes)
+		}
+	}
+}
+
+// request fetches the transactions from the peer
+func (f *Fetcher) request(peer libp2p_peer.ID, hashes []common.Hash) {
+	defer f.wait.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), f.config.Timeout)
+	delivered, err := f.fetch(ctx, peer, hashes)
+	cancel()
+	if err != nil {
+		utils.Logger().Debug().Err(err).
+			Str("peer", peer.Pretty()).
+			Int("count", len(hashes)).
+			Msg("[TxFetcher] cannot fetch announced transactions")
+	}
+	got := make(map[common.Hash]bool, len(delivered))
+	for _, hash := range delivered {
+		got[hash] = true
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	delete(f.busy, peer)
+	for _, hash := range hashes {
+		delete(f.fetching, hash)
+		if got[hash] || len(f.announcers[hash]) == 0 {
+			// fetched, or no other announcer to ask
+			delete(f.announcers, hash)
+		}
+	}
+	f.schedule()
+}
+
+func contains(peers []libp2p_peer.ID, peer libp2p_peer.ID) bool {
+	for _, p := range peers {
+		if p == peer {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(peers []libp2p_peer.ID, peer libp2p_peer.ID) []libp2p_peer.ID {
+	rest := make([]libp2p_peer.ID, 0, len(peers))
+	for _, p := range peers {
+		if p != peer {
+			rest = append(rest, p)
+		}
+	}
+	return rest
+}
@@ -0,0 +1,158 @@
+package txfetcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+)
+
+type fetchCall struct {
+	peer   libp2p_peer.ID
+	hashes []common.Hash
+}
+
+// testNode holds the transactions fetched, from the peers serving them
+type testNode struct {
+	mux     sync.Mutex
+	known   map[common.Hash]bool
+	serving map[libp2p_peer.ID]bool
+	calls   []fetchCall
+	release chan struct{}
+}
+
+func newTestNode(serving ...libp2p_peer.ID) *testNode {
+	n := &testNode{known: map[common.Hash]bool{}, serving: map[libp2p_peer.ID]bool{}}
+	for _, peer := range serving {
+		n.serving[peer] = true
+	}
+	return n
+}
+
+func (n *testNode) has(hash common.Hash) bool {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	return n.known[hash]
+}
+
+func (n *testNode) fetch(
+	ctx context.Context, peer libp2p_peer.ID, hashes []common.Hash,
+) ([]common.Hash, error) {
+	if n.release != nil {
+		<-n.release
+	}
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	n.calls = append(n.calls, fetchCall{peer, hashes})
+	if !n.serving[peer] {
+		return nil, errors.New("not serving")
+	}
+	for _, hash := range hashes {
+		n.known[hash] = true
+	}
+	return hashes, nil
+}
+
+func testHashes(n int) []common.Hash {
+	hashes := make([]common.Hash, n)
+	for i := range hashes {
+		hashes[i] = common.BytesToHash([]byte{byte(i + 1)})
+	}
+	return hashes
+}
+
+func TestFetcher_Dedup(t *testing.T) {
+	n := newTestNode("a", "b")
+	n.release = make(chan struct{})
+	f := New(DefaultConfig(), n.has, n.fetch)
+	hashes := testHashes(3)
+	f.Notify("a", hashes)
+	f.Notify("b", hashes)
+	close(n.release)
+	f.wait.Wait()
+
+	if len(n.calls) != 1 || n.calls[0].peer != "a" || len(n.calls[0].hashes) != 3 {
+		t.Fatalf("fetched %+v, want the 3 transactions once from a", n.calls)
+	}
+	if tracked, fetching := f.Pending(); tracked != 0 || fetching != 0 {
+		t.Errorf("pending %d and %d after fetching, want none", tracked, fetching)
+	}
+	// known transactions are not fetched again
+	f.Notify("b", hashes)
+	f.wait.Wait()
+	if len(n.calls) != 1 {
+		t.Errorf("fetched %+v, want no more fetches", n.calls)
+	}
+}
+
+func TestFetcher_Failover(t *testing.T) {
+	n := newTestNode("b")
+	n.release = make(chan struct{})
+	f := New(DefaultConfig(), n.has, n.fetch)
+	hashes := testHashes(2)
+	f.Notify("a", hashes)
+	f.Notify("b", hashes[:1])
+	close(n.release)
+	f.wait.Wait()
+
+	if len(n.calls) != 2 || n.calls[1].peer != "b" || len(n.calls[1].hashes) != 1 {
+		t.Fatalf("fetched %+v, want the first transaction from b after a failed", n.calls)
+	}
+	if !n.has(hashes[0]) || n.has(hashes[1]) {
+		t.Errorf("unexpected transactions fetched")
+	}
+	if tracked, _ := f.Pending(); tracked != 0 {
+		t.Errorf("%d transactions tracked, want the unfetchable one given up", tracked)
+	}
+}
+
+func TestFetcher_Limits(t *testing.T) {
+	n := newTestNode("a")
+	n.release = make(chan struct{})
+	config := DefaultConfig()
+	config.BatchSize = 2
+	config.MaxPending = 5
+	f := New(config, n.has, n.fetch)
+	f.Notify("a", testHashes(8))
+	if tracked, fetching := f.Pending(); tracked != 5 || fetching != 2 {
+		t.Fatalf("pending %d and %d, want 5 tracked and 2 fetching", tracked, fetching)
+	}
+	close(n.release)
+	f.wait.Wait()
+
+	// one request in flight per peer, in batches
+	if len(n.calls) != 3 {
+		t.Fatalf("fetched %+v, want 3 batches", n.calls)
+	}
+	for i, call := range n.calls {
+		if want := []int{2, 2, 1}[i]; len(call.hashes) != want {
+			t.Errorf("batch %d of %d transactions, want %d", i, len(call.hashes), want)
+		}
+	}
+}
+
+func TestFetcher_Timeout(t *testing.T) {
+	config := DefaultConfig()
+	config.Timeout = 10 * time.Millisecond
+	n := newTestNode("b")
+	f := New(config, n.has, func(
+		ctx context.Context, peer libp2p_peer.ID, hashes []common.Hash,
+	) ([]common.Hash, error) {
+		if peer == "a" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return n.fetch(ctx, peer, hashes)
+	})
+	hashes := testHashes(1)
+	f.Notify("a", hashes)
+	f.Notify("b", hashes)
+	f.wait.Wait()
+	if !n.has(hashes[0]) {
+		t.Error("transaction not fetched from b after a timed out")
+	}
+}
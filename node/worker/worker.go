@@ -235,11 +235,22 @@ func (w *Worker) CommitReceipts(receiptsList []*types.CXReceiptsProof) error {
 		)
 	}
 
+	// Cross-shard call fees go to the same beneficiary as in block processing
+	beneficiary, err := w.chain.GetECDSAFromCoinbase(w.current.header)
+	if err != nil {
+		return err
+	}
 	for _, cx := range receiptsList {
-		err := core.ApplyIncomingReceipt(w.config, w.current.state, w.current.header, cx)
+		gasUsed := w.current.header.GasUsed()
+		receipts, err := core.ApplyIncomingReceipt(
+			w.config, w.chain, &beneficiary, w.current.gasPool, w.current.state,
+			w.current.header, common.Hash{}, len(w.current.receipts), cx, &gasUsed, vm.Config{},
+		)
+		w.current.header.SetGasUsed(gasUsed)
 		if err != nil {
 			return ctxerror.New("Failed applying cross-shard receipts").WithCause(err)
 		}
+		w.current.receipts = append(w.current.receipts, receipts...)
 	}
 
 	for _, cx := range receiptsList {
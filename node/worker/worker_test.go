@@ -11,6 +11,7 @@ import (
 	blockfactory "github.com/harmony-one/harmony/block/factory"
 	"github.com/harmony-one/harmony/common/denominations"
 	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/rawdb"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/core/vm"
 	chain2 "github.com/harmony-one/harmony/internal/chain"
@@ -92,3 +93,175 @@ func TestCommitTransactions(t *testing.T) {
 		t.Error("Transaction is not committed")
 	}
 }
+
+func TestCommitReceiptsCrossShardCall(t *testing.T) {
+	var (
+		sender   = common.HexToAddress("0x1000")
+		coinbase = common.HexToAddress("0x2000")
+		contract = common.HexToAddress("0x3000")
+		amount   = big.NewInt(denominations.One)
+		gasPrice = big.NewInt(denominations.Nano)
+		gasLimit = uint64(100000)
+		prepaid  = new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice)
+	)
+	tests := []struct {
+		name     string
+		code     []byte // code of the called contract
+		gasPool  uint64 // gas left in the block, the block gas limit if zero
+		forkAt   int64  // cross-shard call fork epoch, the block is in epoch 0
+		receipts int    // receipts of the calls run
+		failed   bool
+		stored   bool // whether the call stored 1 in the first slot
+		paid     bool // whether the value reached the contract
+		fee      bool // whether a fee went to the coinbase
+	}{
+		{
+			name: "call succeeds", code: sstoreCode,
+			receipts: 1, stored: true, paid: true, fee: true,
+		},
+		{
+			name: "call reverts and refunds the value", code: revertCode,
+			receipts: 1, failed: true, fee: true,
+		},
+		{
+			name: "block gas pool exhausted", code: sstoreCode, gasPool: gasLimit - 1,
+			receipts: 1, failed: true,
+		},
+		{
+			name: "receipt delivered before the fork", code: sstoreCode, forkAt: 1,
+			paid: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := *chainConfig
+			config.StakingEpoch = params.EpochTBD
+			config.CrossShardCallEpoch = big.NewInt(test.forkAt)
+			database := ethdb.NewMemDatabase()
+			gspec := core.Genesis{
+				Config:  &config,
+				Factory: blockFactory,
+				Alloc:   core.GenesisAlloc{contract: {Balance: common.Big0, Code: test.code}},
+				ShardID: 0,
+			}
+			gspec.MustCommit(database)
+			chain, _ := core.NewBlockChain(database, nil, gspec.Config, chain2.Engine, vm.Config{}, nil)
+			chain2.Engine.SetBeaconchain(chain)
+			worker := New(&config, chain, chain2.Engine)
+			worker.current.header.SetCoinbase(coinbase)
+			if test.gasPool > 0 {
+				worker.current.gasPool = new(core.GasPool).AddGas(test.gasPool)
+			}
+
+			cx := &types.CXReceipt{
+				TxHash:    common.HexToHash("0x01"),
+				From:      sender,
+				To:        &contract,
+				ShardID:   1,
+				ToShardID: 0,
+				Amount:    amount,
+				Calls: []*types.CXCall{{
+					Data: []byte{0x01}, GasLimit: gasLimit, GasPrice: gasPrice,
+				}},
+			}
+			source := blockFactory.NewHeader(common.Big0).With().ShardID(1).Header()
+			err := worker.CommitReceipts([]*types.CXReceiptsProof{
+				{
+					Receipts: types.CXReceipts{cx},
+					MerkleProof: &types.CXMerkleProof{
+						BlockNum: source.Number(), BlockHash: source.Hash(), ShardID: 1,
+					},
+					Header: source,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			receipts := worker.GetCurrentReceipts()
+			if len(receipts) != test.receipts {
+				t.Fatalf("got %d receipts, want %d", len(receipts), test.receipts)
+			}
+			gasUsed := uint64(0)
+			if len(receipts) > 0 {
+				gasUsed = receipts[0].GasUsed
+				if failed := receipts[0].Status == types.ReceiptStatusFailed; failed != test.failed {
+					t.Errorf("receipt failed %v, want %v", failed, test.failed)
+				}
+				if receipts[0].TxHash != cx.TxHash {
+					t.Errorf("receipt of tx %x, want %x", receipts[0].TxHash, cx.TxHash)
+				}
+			}
+			if got := worker.current.header.GasUsed(); got != gasUsed {
+				t.Errorf("block used %d gas, want %d", got, gasUsed)
+			}
+
+			state := worker.GetCurrentState()
+			stored := state.GetState(contract, common.Hash{}) == common.BigToHash(common.Big1)
+			if stored != test.stored {
+				t.Errorf("contract storage set %v, want %v", stored, test.stored)
+			}
+			wantContract := new(big.Int)
+			if test.paid {
+				wantContract.Set(amount)
+			}
+			if got := state.GetBalance(contract); got.Cmp(wantContract) != 0 {
+				t.Errorf("contract balance %v, want %v", got, wantContract)
+			}
+			fee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice)
+			if got := state.GetBalance(coinbase); got.Cmp(fee) != 0 {
+				t.Errorf("coinbase balance %v, want the fee %v", got, fee)
+			}
+			if (fee.Sign() > 0) != test.fee {
+				t.Errorf("fee paid %v, want %v", fee.Sign() > 0, test.fee)
+			}
+			// the sender gets back what the call did not spend
+			wantSender := new(big.Int).Sub(prepaid, fee)
+			if !test.paid {
+				wantSender.Add(wantSender, amount)
+			}
+			if got := state.GetBalance(sender); got.Cmp(wantSender) != 0 {
+				t.Errorf("sender balance %v, want %v", got, wantSender)
+			}
+
+			block, err := worker.FinalizeNewBlock(nil, nil, 0, coinbase, nil, nil)
+			if err != nil {
+				t.Fatalf("cannot finalize block: %v", err)
+			}
+			if block == nil {
+				t.Fatal("finalized block is nil")
+			}
+			if test.gasPool > 0 {
+				// the validators run the call with the full block gas limit
+				return
+			}
+			if _, err := chain.InsertChain(types.Blocks{block}, false); err != nil {
+				t.Fatalf("cannot insert block: %v", err)
+			}
+			receipt, blockHash, _, index := rawdb.ReadReceipt(database, cx.TxHash)
+			if test.receipts == 0 {
+				if receipt != nil {
+					t.Errorf("got a receipt of the call delivered before the fork")
+				}
+				return
+			}
+			if receipt == nil || blockHash != block.Hash() || index != 0 {
+				t.Fatalf("receipt of the call not found in block %x", block.Hash())
+			}
+			if receipt.Status != receipts[0].Status || receipt.GasUsed != receipts[0].GasUsed {
+				t.Errorf("stored receipt %+v, want %+v", receipt, receipts[0])
+			}
+		})
+	}
+}
+
+var (
+	// sstoreCode stores 1 in the first storage slot
+	sstoreCode = []byte{
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP),
+	}
+	// revertCode reverts the call
+	revertCode = []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.REVERT),
+	}
+)
@@ -7,4 +7,6 @@ var (
 	ErrNewStream    = errors.New("[HOST]: new stream error")
 	ErrMsgWrite     = errors.New("[HOST]: send message write error")
 	ErrAddProtocols = errors.New("[HOST]: cannot add protocols")
+	// ErrReceiverClosed is returned by GroupReceiver.Receive once closed
+	ErrReceiverClosed = errors.New("[HOST]: group receiver closed")
 )
@@ -2,8 +2,10 @@ package p2p
 
 import (
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/p2p/reputation"
 	libp2p_host "github.com/libp2p/go-libp2p-host"
 	libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
 )
 
 //go:generate mockgen -source host.go -destination=host/mock/host_mock.go
@@ -29,4 +31,31 @@ type Host interface {
 	// If multiple receivers are created for the same group,
 	// a message sent to the group will be delivered to all of the receivers.
 	GroupReceiver(nodeconfig.GroupID) (receiver GroupReceiver, err error)
+
+	// ReportPeer lowers the score of the peer for the misbehaviour, and bans
+	// and disconnects the peer if its score falls below the ban threshold.
+	ReportPeer(id libp2p_peer.ID, misbehaviour reputation.Misbehaviour)
+
+	// BanPeer bans and disconnects the peer for the configured ban duration.
+	BanPeer(id libp2p_peer.ID, reason string)
+
+	// UnbanPeer lifts the ban of the peer, returning false if it is not banned.
+	UnbanPeer(id libp2p_peer.ID) bool
+
+	// BannedPeers returns the peers banned at present.
+	BannedPeers() []reputation.Ban
+
+	// Peers returns the status of the connected peers.
+	Peers() []PeerInfo
+
+	// AddStaticPeer connects to the peer at the given /p2p multiaddr and
+	// reconnects whenever the connection drops.
+	AddStaticPeer(addr ma.Multiaddr) error
+
+	// AddTrustedPeer adds the peer at the given /p2p multiaddr as a static peer
+	// which is never banned for misbehaviour nor trimmed by the connection manager.
+	AddTrustedPeer(addr ma.Multiaddr) error
+
+	// RemovePeer forgets the peer as a static or trusted peer and disconnects it.
+	RemovePeer(id libp2p_peer.ID) error
 }
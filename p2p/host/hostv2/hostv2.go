@@ -6,7 +6,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -14,8 +16,10 @@ import (
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/reputation"
 
 	libp2p "github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/network"
 	libp2p_crypto "github.com/libp2p/go-libp2p-crypto"
 	libp2p_host "github.com/libp2p/go-libp2p-host"
 	libp2p_peer "github.com/libp2p/go-libp2p-peer"
@@ -29,6 +33,13 @@ const (
 	BatchSizeInByte = 1 << 16
 	// ProtocolID The ID of protocol used in stream handling.
 	ProtocolID = "/harmony/0.0.1"
+	// keepPeersInterval is how often the static and trusted peers are redialed
+	// when disconnected
+	keepPeersInterval = 30 * time.Second
+	// connectTimeout bounds a dial to a static or trusted peer
+	connectTimeout = 30 * time.Second
+	// trustedPeerTag tags the trusted peers in the connection manager
+	trustedPeerTag = "harmony-trusted"
 
 	// Constants for discovery service.
 	//numIncoming = 128
@@ -77,6 +88,13 @@ type HostV2 struct {
 	self   p2p.Peer
 	priKey libp2p_crypto.PrivKey
 	lock   sync.Mutex
+	// reputation scores and bans the peers
+	reputation *reputation.Tracker
+	pubsub     *libp2p_pubsub.PubSub
+	// static and trusted peers, kept connected
+	keptLock sync.Mutex
+	kept     map[libp2p_peer.ID]keptPeer
+	quit     chan struct{}
 
 	//incomingPeers []p2p.Peer // list of incoming Peers. TODO: fixed number incoming
 	//outgoingPeers []p2p.Peer // list of outgoing Peers. TODO: fixed number of outgoing
@@ -85,6 +103,12 @@ type HostV2 struct {
 	logger *zerolog.Logger
 }
 
+// keptPeer is a static or trusted peer
+type keptPeer struct {
+	info    libp2p_peerstore.PeerInfo
+	trusted bool
+}
+
 func (host *HostV2) getTopic(topic string) (topicHandle, error) {
 	host.lock.Lock()
 	defer host.lock.Unlock()
@@ -140,7 +164,7 @@ func (r *GroupReceiverImpl) Receive(ctx context.Context) (
 	msg []byte, sender libp2p_peer.ID, err error,
 ) {
 	if r.sub == nil {
-		return nil, libp2p_peer.ID(""), fmt.Errorf("GroupReceiver has been closed")
+		return nil, libp2p_peer.ID(""), p2p.ErrReceiverClosed
 	}
 	m, err := r.sub.Next(ctx)
 	if err == nil {
@@ -210,8 +234,12 @@ func (host *HostV2) Peerstore() libp2p_peerstore.Peerstore {
 	return host.h.Peerstore()
 }
 
-// New creates a host for p2p communication
-func New(self *p2p.Peer, priKey libp2p_crypto.PrivKey) (*HostV2, error) {
+// New creates a host for p2p communication, banning misbehaving peers as
+// configured by reputationConfig.  The extra libp2p options are applied last.
+func New(
+	self *p2p.Peer, priKey libp2p_crypto.PrivKey, reputationConfig reputation.Config,
+	opts ...libp2p.Option,
+) (*HostV2, error) {
 	// TODO: Convert to zerolog or internal logger interface
 	logger := utils.Logger()
 	listenAddr, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/0.0.0.0/tcp/%s", self.Port))
@@ -219,11 +247,18 @@ func New(self *p2p.Peer, priKey libp2p_crypto.PrivKey) (*HostV2, error) {
 		return nil, errors.Wrapf(err,
 			"cannot create listen multiaddr from port %#v", self.Port)
 	}
+	tracker, err := reputation.New(reputationConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot initialize peer reputation")
+	}
 	// TODO – use WithCancel for orderly host teardown (which we don't have yet)
 	ctx := context.Background()
-	p2pHost, err := libp2p.New(ctx,
-		libp2p.ListenAddrs(listenAddr), libp2p.Identity(priKey),
-	)
+	hostOpts := []libp2p.Option{libp2p.ListenAddrs(listenAddr), libp2p.Identity(priKey)}
+	// advertise the node version to the peers over the identify protocol
+	if version := nodeconfig.GetVersion(); version != "" {
+		hostOpts = append(hostOpts, libp2p.UserAgent(version))
+	}
+	p2pHost, err := libp2p.New(ctx, append(hostOpts, opts...)...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot initialize libp2p host")
 	}
@@ -231,6 +266,8 @@ func New(self *p2p.Peer, priKey libp2p_crypto.PrivKey) (*HostV2, error) {
 	var options = make([]libp2p_pubsub.Option, 0, 0)
 	// increase the peer outbound queue size from default 32 to 64
 	options = append(options, libp2p_pubsub.WithPeerOutboundQueueSize(64))
+	// ignore the peers banned for misbehaviour
+	options = append(options, libp2p_pubsub.WithBlacklist(tracker))
 
 	if len(traceFile) > 0 {
 		tracer, _ := libp2p_pubsub.NewJSONTracer(traceFile)
@@ -246,13 +283,32 @@ func New(self *p2p.Peer, priKey libp2p_crypto.PrivKey) (*HostV2, error) {
 	subLogger := logger.With().Str("hostID", p2pHost.ID().Pretty()).Logger()
 	// has to save the private key for host
 	h := &HostV2{
-		h:      p2pHost,
-		joiner: topicJoinerImpl{pubsub},
-		joined: map[string]topicHandle{},
-		self:   *self,
-		priKey: priKey,
-		logger: &subLogger,
+		h:          p2pHost,
+		joiner:     topicJoinerImpl{pubsub},
+		joined:     map[string]topicHandle{},
+		self:       *self,
+		priKey:     priKey,
+		reputation: tracker,
+		pubsub:     pubsub,
+		kept:       map[libp2p_peer.ID]keptPeer{},
+		quit:       make(chan struct{}),
+		logger:     &subLogger,
 	}
+	// disconnect the peers when banned, and when they connect while banned
+	tracker.SetBanHandler(func(id libp2p_peer.ID) {
+		if err := p2pHost.Network().ClosePeer(id); err != nil {
+			h.logger.Warn().Err(err).Str("peer", id.Pretty()).Msg("cannot disconnect banned peer")
+		}
+	})
+	p2pHost.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(_ network.Network, conn network.Conn) {
+			if tracker.IsBanned(conn.RemotePeer()) {
+				go conn.Close()
+			}
+		},
+	})
+	tracker.Start()
+	go h.keepPeers()
 
 	h.logger.Debug().
 		Str("port", self.Port).
@@ -276,9 +332,162 @@ func (host *HostV2) GetSelfPeer() p2p.Peer {
 
 // Close closes the host
 func (host *HostV2) Close() error {
+	close(host.quit)
+	host.reputation.Stop()
 	return host.h.Close()
 }
 
+// ReportPeer lowers the score of the peer for the misbehaviour, and bans
+// and disconnects the peer if its score falls below the ban threshold.
+func (host *HostV2) ReportPeer(id libp2p_peer.ID, misbehaviour reputation.Misbehaviour) {
+	if host.isTrusted(id) {
+		return
+	}
+	host.reputation.Report(id, misbehaviour)
+}
+
+// BanPeer bans and disconnects the peer for the configured ban duration.
+func (host *HostV2) BanPeer(id libp2p_peer.ID, reason string) {
+	host.reputation.Ban(id, reason)
+}
+
+// UnbanPeer lifts the ban of the peer, returning false if it is not banned.
+func (host *HostV2) UnbanPeer(id libp2p_peer.ID) bool {
+	return host.reputation.Unban(id)
+}
+
+// BannedPeers returns the peers banned at present.
+func (host *HostV2) BannedPeers() []reputation.Ban {
+	return host.reputation.Bans()
+}
+
+// Peers returns the status of the connected peers.
+func (host *HostV2) Peers() []p2p.PeerInfo {
+	topics := map[libp2p_peer.ID][]string{}
+	host.lock.Lock()
+	for topic := range host.joined {
+		for _, id := range host.pubsub.ListPeers(topic) {
+			topics[id] = append(topics[id], topic)
+		}
+	}
+	host.lock.Unlock()
+	host.keptLock.Lock()
+	kept := make(map[libp2p_peer.ID]keptPeer, len(host.kept))
+	for id, k := range host.kept {
+		kept[id] = k
+	}
+	host.keptLock.Unlock()
+
+	peers := []p2p.PeerInfo{}
+	for _, id := range host.h.Network().Peers() {
+		sort.Strings(topics[id])
+		info := p2p.PeerInfo{
+			PeerID:  id,
+			Topics:  topics[id],
+			Latency: host.h.Peerstore().LatencyEWMA(id),
+			Score:   host.reputation.Score(id),
+		}
+		for _, conn := range host.h.Network().ConnsToPeer(id) {
+			info.Addrs = append(info.Addrs, conn.RemoteMultiaddr())
+			if conn.Stat().Direction == network.DirInbound {
+				info.Inbound = true
+			}
+		}
+		if k, ok := kept[id]; ok {
+			info.Static, info.Trusted = true, k.trusted
+		}
+		peers = append(peers, info)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].PeerID < peers[j].PeerID })
+	return peers
+}
+
+// AddStaticPeer connects to the peer at the given /p2p multiaddr and
+// reconnects whenever the connection drops.
+func (host *HostV2) AddStaticPeer(addr ma.Multiaddr) error {
+	return host.addKeptPeer(addr, false)
+}
+
+// AddTrustedPeer adds the peer at the given /p2p multiaddr as a static peer
+// which is never banned for misbehaviour nor trimmed by the connection manager.
+func (host *HostV2) AddTrustedPeer(addr ma.Multiaddr) error {
+	return host.addKeptPeer(addr, true)
+}
+
+// RemovePeer forgets the peer as a static or trusted peer and disconnects it.
+func (host *HostV2) RemovePeer(id libp2p_peer.ID) error {
+	host.keptLock.Lock()
+	delete(host.kept, id)
+	host.keptLock.Unlock()
+	host.h.ConnManager().Unprotect(id, trustedPeerTag)
+	return host.h.Network().ClosePeer(id)
+}
+
+// addKeptPeer adds a static or trusted peer and connects to it
+func (host *HostV2) addKeptPeer(addr ma.Multiaddr, trusted bool) error {
+	info, err := libp2p_peerstore.InfoFromP2pAddr(addr)
+	if err != nil {
+		return errors.Wrapf(err, "invalid peer multiaddr %s", addr)
+	}
+	if info.ID == host.h.ID() {
+		return errors.Errorf("cannot add self as a peer")
+	}
+	host.h.Peerstore().AddAddrs(info.ID, info.Addrs, libp2p_peerstore.PermanentAddrTTL)
+	host.keptLock.Lock()
+	// adding a trusted peer again as a static one keeps it trusted
+	trusted = trusted || host.kept[info.ID].trusted
+	host.kept[info.ID] = keptPeer{info: *info, trusted: trusted}
+	host.keptLock.Unlock()
+	if trusted {
+		host.h.ConnManager().Protect(info.ID, trustedPeerTag)
+	}
+	host.logger.Info().
+		Str("peer", info.ID.Pretty()).
+		Bool("trusted", trusted).
+		Msg("added static peer")
+	go host.connect(*info)
+	return nil
+}
+
+// isTrusted returns whether the peer is a trusted peer
+func (host *HostV2) isTrusted(id libp2p_peer.ID) bool {
+	host.keptLock.Lock()
+	defer host.keptLock.Unlock()
+	return host.kept[id].trusted
+}
+
+// keepPeers redials the disconnected static and trusted peers until the host is closed
+func (host *HostV2) keepPeers() {
+	ticker := time.NewTicker(keepPeersInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			host.keptLock.Lock()
+			for id, k := range host.kept {
+				if host.h.Network().Connectedness(id) != network.Connected &&
+					!host.reputation.IsBanned(id) {
+					go host.connect(k.info)
+				}
+			}
+			host.keptLock.Unlock()
+		case <-host.quit:
+			return
+		}
+	}
+}
+
+// connect dials a static or trusted peer
+func (host *HostV2) connect(info libp2p_peerstore.PeerInfo) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err := host.h.Connect(ctx, info); err != nil {
+		host.logger.Debug().Err(err).
+			Str("peer", info.ID.Pretty()).
+			Msg("cannot connect to static peer")
+	}
+}
+
 // GetP2PHost returns the p2p.Host
 func (host *HostV2) GetP2PHost() libp2p_host.Host {
 	return host.h
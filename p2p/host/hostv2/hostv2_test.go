@@ -5,13 +5,19 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/harmony-one/bls/ffi/go/bls"
 	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	libp2p_crypto "github.com/libp2p/go-libp2p-crypto"
 	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
 	libp2p_pubsub_pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	ma "github.com/multiformats/go-multiaddr"
 
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/p2p/reputation"
 )
 
 func TestHostV2_SendMessageToGroups(t *testing.T) {
@@ -297,3 +303,54 @@ func TestHostV2_getTopic(t *testing.T) {
 		}
 	})
 }
+
+func newTestHost(t *testing.T) *HostV2 {
+	priKey, _, err := libp2p_crypto.GenerateKeyPair(libp2p_crypto.Ed25519, 0)
+	if err != nil {
+		t.Fatalf("cannot generate key: %v", err)
+	}
+	host, err := New(&p2p.Peer{IP: "127.0.0.1", Port: "0", ConsensusPubKey: &bls.PublicKey{}}, priKey, reputation.DefaultConfig())
+	if err != nil {
+		t.Fatalf("cannot create host: %v", err)
+	}
+	return host
+}
+
+func TestHostV2_StaticPeers(t *testing.T) {
+	host, other := newTestHost(t), newTestHost(t)
+	defer host.Close()
+	defer other.Close()
+	addr, err := ma.NewMultiaddr(other.h.Addrs()[0].String() + "/p2p/" + other.GetID().Pretty())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := host.AddTrustedPeer(addr); err != nil {
+		t.Fatalf("AddTrustedPeer() error = %v", err)
+	}
+	var peers []p2p.PeerInfo
+	for i := 0; i < 50 && len(peers) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+		peers = host.Peers()
+	}
+	if len(peers) != 1 || peers[0].PeerID != other.GetID() || !peers[0].Trusted || peers[0].Inbound {
+		t.Fatalf("unexpected peers %+v", peers)
+	}
+	// trusted peers are not banned for misbehaviour
+	for i := 0; i < 10; i++ {
+		host.ReportPeer(other.GetID(), reputation.InvalidSignature)
+	}
+	if len(host.BannedPeers()) != 0 || host.reputation.Score(other.GetID()) != 0 {
+		t.Error("trusted peer penalized")
+	}
+
+	if err := host.RemovePeer(other.GetID()); err != nil {
+		t.Fatalf("RemovePeer() error = %v", err)
+	}
+	if host.isTrusted(other.GetID()) || len(host.Peers()) != 0 {
+		t.Errorf("peer not removed, peers %+v", host.Peers())
+	}
+	if err := host.AddStaticPeer(other.h.Addrs()[0]); err == nil {
+		t.Error("expected an error for a multiaddr without peer ID")
+	}
+}
@@ -8,8 +8,10 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	node "github.com/harmony-one/harmony/internal/configs/node"
 	p2p "github.com/harmony-one/harmony/p2p"
+	reputation "github.com/harmony-one/harmony/p2p/reputation"
 	go_libp2p_host "github.com/libp2p/go-libp2p-host"
 	go_libp2p_peer "github.com/libp2p/go-libp2p-peer"
+	go_multiaddr "github.com/multiformats/go-multiaddr"
 	reflect "reflect"
 )
 
@@ -160,3 +162,111 @@ func (mr *MockHostMockRecorder) GroupReceiver(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupReceiver", reflect.TypeOf((*MockHost)(nil).GroupReceiver), arg0)
 }
+
+// ReportPeer mocks base method
+func (m *MockHost) ReportPeer(id go_libp2p_peer.ID, misbehaviour reputation.Misbehaviour) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportPeer", id, misbehaviour)
+}
+
+// ReportPeer indicates an expected call of ReportPeer
+func (mr *MockHostMockRecorder) ReportPeer(id, misbehaviour interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportPeer", reflect.TypeOf((*MockHost)(nil).ReportPeer), id, misbehaviour)
+}
+
+// BanPeer mocks base method
+func (m *MockHost) BanPeer(id go_libp2p_peer.ID, reason string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BanPeer", id, reason)
+}
+
+// BanPeer indicates an expected call of BanPeer
+func (mr *MockHostMockRecorder) BanPeer(id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanPeer", reflect.TypeOf((*MockHost)(nil).BanPeer), id, reason)
+}
+
+// UnbanPeer mocks base method
+func (m *MockHost) UnbanPeer(id go_libp2p_peer.ID) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanPeer", id)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// UnbanPeer indicates an expected call of UnbanPeer
+func (mr *MockHostMockRecorder) UnbanPeer(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanPeer", reflect.TypeOf((*MockHost)(nil).UnbanPeer), id)
+}
+
+// BannedPeers mocks base method
+func (m *MockHost) BannedPeers() []reputation.Ban {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BannedPeers")
+	ret0, _ := ret[0].([]reputation.Ban)
+	return ret0
+}
+
+// BannedPeers indicates an expected call of BannedPeers
+func (mr *MockHostMockRecorder) BannedPeers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BannedPeers", reflect.TypeOf((*MockHost)(nil).BannedPeers))
+}
+
+// Peers mocks base method
+func (m *MockHost) Peers() []p2p.PeerInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peers")
+	ret0, _ := ret[0].([]p2p.PeerInfo)
+	return ret0
+}
+
+// Peers indicates an expected call of Peers
+func (mr *MockHostMockRecorder) Peers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peers", reflect.TypeOf((*MockHost)(nil).Peers))
+}
+
+// AddStaticPeer mocks base method
+func (m *MockHost) AddStaticPeer(addr go_multiaddr.Multiaddr) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddStaticPeer", addr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddStaticPeer indicates an expected call of AddStaticPeer
+func (mr *MockHostMockRecorder) AddStaticPeer(addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStaticPeer", reflect.TypeOf((*MockHost)(nil).AddStaticPeer), addr)
+}
+
+// AddTrustedPeer mocks base method
+func (m *MockHost) AddTrustedPeer(addr go_multiaddr.Multiaddr) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTrustedPeer", addr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTrustedPeer indicates an expected call of AddTrustedPeer
+func (mr *MockHostMockRecorder) AddTrustedPeer(addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTrustedPeer", reflect.TypeOf((*MockHost)(nil).AddTrustedPeer), addr)
+}
+
+// RemovePeer mocks base method
+func (m *MockHost) RemovePeer(id go_libp2p_peer.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePeer", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePeer indicates an expected call of RemovePeer
+func (mr *MockHostMockRecorder) RemovePeer(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePeer", reflect.TypeOf((*MockHost)(nil).RemovePeer), id)
+}
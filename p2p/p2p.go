@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"time"
 
 	"github.com/harmony-one/bls/ffi/go/bls"
 	libp2p_peer "github.com/libp2p/go-libp2p-peer"
@@ -39,3 +40,15 @@ type GroupReceiver interface {
 	// Receive a message.
 	Receive(ctx context.Context) (msg []byte, sender libp2p_peer.ID, err error)
 }
+
+// PeerInfo is the status of a connected peer
+type PeerInfo struct {
+	PeerID  libp2p_peer.ID // PeerID of the peer
+	Addrs   []ma.Multiaddr // MultiAddress of the open connections to the peer
+	Inbound bool           // whether the peer dialed us
+	Topics  []string       // pubsub topics shared with the peer
+	Latency time.Duration  // moving average of the latency to the peer, zero if unknown
+	Score   float64        // reputation score of the peer
+	Static  bool           // whether the peer is kept connected
+	Trusted bool           // whether the peer is kept connected and never banned for misbehaviour
+}
@@ -3,18 +3,29 @@ package p2pimpl
 import (
 	"net"
 
+	libp2p "github.com/libp2p/go-libp2p"
 	libp2p_crypto "github.com/libp2p/go-libp2p-crypto"
 
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/p2p"
 	"github.com/harmony-one/harmony/p2p/host/hostv2"
+	"github.com/harmony-one/harmony/p2p/reputation"
 )
 
 // NewHost starts the host for p2p
 // for hostv2, it generates multiaddress, keypair and add PeerID to peer, add priKey to host
 // TODO (leo) The peerstore has to be persisted on disk.
 func NewHost(self *p2p.Peer, key libp2p_crypto.PrivKey) (p2p.Host, error) {
-	h, err := hostv2.New(self, key)
+	return NewHostWithReputation(self, key, reputation.DefaultConfig())
+}
+
+// NewHostWithReputation starts the host for p2p, banning misbehaving peers as configured.
+// The extra libp2p options, e.g. a persistent peerstore, are passed on to libp2p.
+func NewHostWithReputation(
+	self *p2p.Peer, key libp2p_crypto.PrivKey, reputationConfig reputation.Config,
+	opts ...libp2p.Option,
+) (p2p.Host, error) {
+	h, err := hostv2.New(self, key, reputationConfig, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,290 @@
+// Package reputation scores p2p peers by the misbehaviour reported for them,
+// and bans the peers whose score falls below a threshold for a while.
+package reputation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/harmony-one/harmony/internal/utils"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+)
+
+// Misbehaviour is a kind of misbehaviour of a peer, lowering its score by the penalty
+type Misbehaviour struct {
+	Reason  string
+	Penalty float64
+}
+
+// Misbehaviours reported by the message handlers
+var (
+	InvalidSignature = Misbehaviour{Reason: "invalid signature", Penalty: 25}
+	MalformedMessage = Misbehaviour{Reason: "malformed message", Penalty: 10}
+	WrongShard       = Misbehaviour{Reason: "wrong shard", Penalty: 10}
+	RxOverrun        = Misbehaviour{Reason: "rx overrun", Penalty: 1}
+)
+
+// Config is the configuration of a Tracker
+type Config struct {
+	// BanThreshold is the score below which a peer is banned
+	BanThreshold float64
+	// BanDuration is how long a peer stays banned
+	BanDuration time.Duration
+	// DecayInterval is how often the scores decay towards zero
+	DecayInterval time.Duration
+	// DecayFactor is what the scores are multiplied by every DecayInterval
+	DecayFactor float64
+	// BanFile is the file the bans are persisted to, not persisted if empty
+	BanFile string
+}
+
+// DefaultConfig returns the default configuration, which bans a peer sending
+// a handful of invalid messages within a minute for an hour
+func DefaultConfig() Config {
+	return Config{
+		BanThreshold:  -100,
+		BanDuration:   time.Hour,
+		DecayInterval: time.Minute,
+		DecayFactor:   0.5,
+	}
+}
+
+// Ban is a banned peer
+type Ban struct {
+	PeerID libp2p_peer.ID `json:"peerID"`
+	Reason string         `json:"reason"`
+	Until  time.Time      `json:"until"`
+}
+
+// Tracker keeps the scores and the bans of the peers.
+// It also implements the pubsub Blacklist interface, so pubsub ignores banned peers.
+type Tracker struct {
+	config Config
+	mux    sync.Mutex
+	scores map[libp2p_peer.ID]float64
+	bans   map[libp2p_peer.ID]Ban
+	onBan  func(libp2p_peer.ID)
+	now    func() time.Time
+	quit   chan struct{}
+}
+
+// New creates a tracker, loading the bans persisted in the ban file if any.
+func New(config Config) (*Tracker, error) {
+	t := &Tracker{
+		config: config,
+		scores: map[libp2p_peer.ID]float64{},
+		bans:   map[libp2p_peer.ID]Ban{},
+		now:    time.Now,
+		quit:   make(chan struct{}),
+	}
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Start decays the scores and expires the bans in the background until Stop is called
+func (t *Tracker) Start() {
+	if t.config.DecayInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(t.config.DecayInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.decay()
+			case <-t.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background decay
+func (t *Tracker) Stop() {
+	close(t.quit)
+}
+
+// SetBanHandler sets the function called with a peer when it gets banned,
+// e.g. to disconnect it
+func (t *Tracker) SetBanHandler(f func(libp2p_peer.ID)) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.onBan = f
+}
+
+// Report lowers the score of the peer for the misbehaviour, and bans the peer
+// if its score falls below the threshold. It returns whether the peer got banned.
+func (t *Tracker) Report(id libp2p_peer.ID, m Misbehaviour) bool {
+	t.mux.Lock()
+	if ban, ok := t.bans[id]; ok && t.now().Before(ban.Until) {
+		t.mux.Unlock()
+		return false
+	}
+	score := t.scores[id] - m.Penalty
+	t.scores[id] = score
+	t.mux.Unlock()
+
+	utils.Logger().Debug().
+		Str("peer", id.Pretty()).
+		Str("reason", m.Reason).
+		Float64("score", score).
+		Msg("[reputation] peer misbehaved")
+	if score >= t.config.BanThreshold {
+		return false
+	}
+	t.Ban(id, m.Reason)
+	return true
+}
+
+// Score returns the score of the peer, zero unless misbehaviour was reported lately
+func (t *Tracker) Score(id libp2p_peer.ID) float64 {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.scores[id]
+}
+
+// Ban bans the peer for the configured ban duration
+func (t *Tracker) Ban(id libp2p_peer.ID, reason string) {
+	t.mux.Lock()
+	t.bans[id] = Ban{PeerID: id, Reason: reason, Until: t.now().Add(t.config.BanDuration)}
+	delete(t.scores, id)
+	t.save()
+	onBan := t.onBan
+	t.mux.Unlock()
+
+	utils.Logger().Info().
+		Str("peer", id.Pretty()).
+		Str("reason", reason).
+		Dur("duration", t.config.BanDuration).
+		Msg("[reputation] peer banned")
+	if onBan != nil {
+		onBan(id)
+	}
+}
+
+// Unban lifts the ban of the peer. It returns false if the peer is not banned.
+func (t *Tracker) Unban(id libp2p_peer.ID) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if _, ok := t.bans[id]; !ok {
+		return false
+	}
+	delete(t.bans, id)
+	t.save()
+	return true
+}
+
+// IsBanned returns whether the peer is banned at present
+func (t *Tracker) IsBanned(id libp2p_peer.ID) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	ban, ok := t.bans[id]
+	return ok && t.now().Before(ban.Until)
+}
+
+// Bans returns the peers banned at present, ending soonest first
+func (t *Tracker) Bans() []Ban {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	now := t.now()
+	bans := []Ban{}
+	for _, ban := range t.bans {
+		if now.Before(ban.Until) {
+			bans = append(bans, ban)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Until.Before(bans[j].Until) })
+	return bans
+}
+
+// Add bans the peer, implementing the pubsub Blacklist interface
+func (t *Tracker) Add(id libp2p_peer.ID) {
+	t.Ban(id, "blacklisted")
+}
+
+// Contains returns whether the peer is banned, implementing the pubsub Blacklist interface
+func (t *Tracker) Contains(id libp2p_peer.ID) bool {
+	return t.IsBanned(id)
+}
+
+// decay moves the scores towards zero, forgetting the small ones, and drops the expired bans
+func (t *Tracker) decay() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	for id, score := range t.scores {
+		if score *= t.config.DecayFactor; score > -1 {
+			delete(t.scores, id)
+		} else {
+			t.scores[id] = score
+		}
+	}
+	now, expired := t.now(), false
+	for id, ban := range t.bans {
+		if !now.Before(ban.Until) {
+			delete(t.bans, id)
+			expired = true
+		}
+	}
+	if expired {
+		t.save()
+	}
+}
+
+// load reads the bans from the ban file
+func (t *Tracker) load() error {
+	if t.config.BanFile == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(t.config.BanFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "cannot read ban file %s", t.config.BanFile)
+	}
+	var bans []Ban
+	if err := json.Unmarshal(data, &bans); err != nil {
+		return errors.Wrapf(err, "cannot parse ban file %s", t.config.BanFile)
+	}
+	now := t.now()
+	for _, ban := range bans {
+		if now.Before(ban.Until) {
+			t.bans[ban.PeerID] = ban
+		}
+	}
+	return nil
+}
+
+// save writes the bans to the ban file, the caller must hold t.mux
+func (t *Tracker) save() {
+	if t.config.BanFile == "" {
+		return
+	}
+	bans := make([]Ban, 0, len(t.bans))
+	for _, ban := range t.bans {
+		bans = append(bans, ban)
+	}
+	data, err := json.MarshalIndent(bans, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(t.config.BanFile), 0700)
+	}
+	if err == nil {
+		tmp := t.config.BanFile + ".tmp"
+		if err = ioutil.WriteFile(tmp, data, 0600); err == nil {
+			err = os.Rename(tmp, t.config.BanFile)
+		}
+	}
+	if err != nil {
+		utils.Logger().Warn().Err(err).
+			Str("file", t.config.BanFile).
+			Msg("[reputation] cannot persist peer bans")
+	}
+}
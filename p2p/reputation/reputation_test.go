@@ -0,0 +1,118 @@
+package reputation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+var (
+	peerA, _ = libp2p_peer.IDB58Decode("QmayB8NwxmfGE4Usb4H61M8uwbfc7LRbmXb3ChseJgbVuf")
+	peerB, _ = libp2p_peer.IDB58Decode("QmS374uzJ9yEEoWcEQ6JcbSUaVUj29SKakcmVvr3HVAjKP")
+)
+
+func newTestTracker(t *testing.T, banFile string) (*Tracker, *time.Time) {
+	config := DefaultConfig()
+	config.BanFile = banFile
+	tracker, err := New(config)
+	if err != nil {
+		t.Fatalf("cannot create tracker: %v", err)
+	}
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestReportBans(t *testing.T) {
+	tracker, _ := newTestTracker(t, "")
+	banned := []libp2p_peer.ID{}
+	tracker.SetBanHandler(func(id libp2p_peer.ID) { banned = append(banned, id) })
+
+	for i := 0; i < 4; i++ {
+		if tracker.Report(peerA, InvalidSignature) {
+			t.Fatalf("peer banned after %d reports", i+1)
+		}
+	}
+	if score := tracker.Score(peerA); score != -100 {
+		t.Errorf("expected score -100, got %v", score)
+	}
+	if !tracker.Report(peerA, RxOverrun) {
+		t.Fatal("peer not banned below the threshold")
+	}
+	if !tracker.IsBanned(peerA) || !tracker.Contains(peerA) || tracker.IsBanned(peerB) {
+		t.Error("unexpected ban state")
+	}
+	if len(banned) != 1 || banned[0] != peerA {
+		t.Errorf("ban handler called with %v", banned)
+	}
+	if tracker.Report(peerA, InvalidSignature) || len(banned) != 1 {
+		t.Error("banned peer banned again")
+	}
+	bans := tracker.Bans()
+	if len(bans) != 1 || bans[0].PeerID != peerA || bans[0].Reason != RxOverrun.Reason {
+		t.Errorf("unexpected bans %v", bans)
+	}
+	if !tracker.Unban(peerA) || tracker.IsBanned(peerA) || tracker.Unban(peerA) {
+		t.Error("cannot unban peer")
+	}
+}
+
+func TestDecay(t *testing.T) {
+	tracker, now := newTestTracker(t, "")
+	tracker.Report(peerA, InvalidSignature)
+	tracker.Report(peerB, RxOverrun)
+	tracker.decay()
+	if score := tracker.Score(peerA); score != -12.5 {
+		t.Errorf("expected decayed score -12.5, got %v", score)
+	}
+	if score := tracker.Score(peerB); score != 0 {
+		t.Errorf("expected small score forgotten, got %v", score)
+	}
+
+	tracker.Ban(peerB, "test")
+	*now = now.Add(tracker.config.BanDuration)
+	if tracker.IsBanned(peerB) {
+		t.Error("ban not expired")
+	}
+	tracker.decay()
+	if _, ok := tracker.bans[peerB]; ok {
+		t.Error("expired ban not dropped")
+	}
+}
+
+func TestBanFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reputation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	banFile := filepath.Join(dir, "db", "banned_peers.json")
+
+	tracker, _ := newTestTracker(t, banFile)
+	tracker.Ban(peerA, "test")
+
+	loaded, err := New(tracker.config)
+	if err != nil {
+		t.Fatalf("cannot load ban file: %v", err)
+	}
+	bans := loaded.Bans()
+	if len(bans) != 1 || bans[0].PeerID != peerA || bans[0].Reason != "test" {
+		t.Errorf("unexpected loaded bans %v", bans)
+	}
+
+	tracker.Unban(peerA)
+	if loaded, err = New(tracker.config); err != nil || len(loaded.Bans()) != 0 {
+		t.Errorf("unban not persisted, bans %v, err %v", loaded.Bans(), err)
+	}
+
+	if err := ioutil.WriteFile(banFile, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(tracker.config); err == nil {
+		t.Error("expected an error for a corrupt ban file")
+	}
+}
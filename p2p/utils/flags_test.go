@@ -2,6 +2,9 @@ package p2putils
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -65,3 +68,36 @@ func TestStringsToPeers(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadPeersFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "peers.json")
+
+	if static, trusted, err := LoadPeersFile(file); err != nil || static != nil || trusted != nil {
+		t.Errorf("missing peers file loaded %v %v, err %v", static, trusted, err)
+	}
+
+	const staticPeer = "/ip4/127.0.0.1/tcp/9999/p2p/QmayB8NwxmfGE4Usb4H61M8uwbfc7LRbmXb3ChseJgbVuf"
+	data := `{"static": ["` + staticPeer + `"], "trusted": []}`
+	if err := ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	static, trusted, err := LoadPeersFile(file)
+	if err != nil {
+		t.Fatalf("cannot load peers file: %v", err)
+	}
+	if len(static) != 1 || static[0].String() != staticPeer || len(trusted) != 0 {
+		t.Errorf("unexpected peers %v %v", static, trusted)
+	}
+
+	if err := ioutil.WriteFile(file, []byte(`{"trusted": ["not a multiaddr"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadPeersFile(file); err == nil {
+		t.Error("expected an error for an invalid peer")
+	}
+}
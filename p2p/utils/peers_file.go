@@ -0,0 +1,46 @@
+package p2putils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+)
+
+// PeersFile is the file of the static and trusted peers a node keeps
+// connected to.  The peers are /p2p multiaddrs, e.g.
+//
+//	{
+//	  "static": ["/ip4/1.2.3.4/tcp/9000/p2p/QmayB8NwxmfGE4Usb4H61M8uwbfc7LRbmXb3ChseJgbVuf"],
+//	  "trusted": []
+//	}
+//
+// Trusted peers are also never banned for misbehaviour.
+type PeersFile struct {
+	Static  []string `json:"static"`
+	Trusted []string `json:"trusted"`
+}
+
+// LoadPeersFile reads the static and trusted peers from the given file.
+// A missing file holds no peers.
+func LoadPeersFile(file string) (static, trusted []ma.Multiaddr, err error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot read peers file %s", file)
+	}
+	var peers PeersFile
+	if err := json.Unmarshal(data, &peers); err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot parse peers file %s", file)
+	}
+	if static, err = StringsToAddrs(peers.Static); err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid static peer in %s", file)
+	}
+	if trusted, err = StringsToAddrs(peers.Trusted); err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid trusted peer in %s", file)
+	}
+	return static, trusted, nil
+}
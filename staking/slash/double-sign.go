@@ -12,8 +12,10 @@ import (
 	"github.com/harmony-one/harmony/consensus/votepower"
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	"github.com/harmony-one/harmony/crypto/hash"
 	common2 "github.com/harmony-one/harmony/internal/common"
+	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
@@ -143,6 +145,7 @@ func (r Record) String() string {
 
 // CommitteeReader ..
 type CommitteeReader interface {
+	Config() *params.ChainConfig
 	ReadShardState(epoch *big.Int) (*shard.State, error)
 	CurrentBlock() *types.Block
 }
@@ -176,6 +179,12 @@ func Verify(
 			errSignerKeyNotRightSize, "cast key %d double-signed key %d", k1, k2,
 		)
 	}
+	aggregateVote := chain.Config().IsAggregateVote(candidate.Evidence.Epoch)
+	for _, ballot := range [...]votepower.Ballot{first, second} {
+		if err := checkAggregateSigners(ballot, aggregateVote); err != nil {
+			return err
+		}
+	}
 
 	if first.ViewID != second.ViewID ||
 		first.Height != second.Height ||
@@ -213,10 +222,19 @@ func Verify(
 		)
 	}
 
-	if addr, err := subCommittee.AddressForBLSKey(
-		second.SignerPubKey,
-	); err != nil || *addr != candidate.Offender {
-		return err
+	// every key of the ballots is slashed along, so must be the offender's
+	for _, ballot := range [...]votepower.Ballot{first, second} {
+		for _, key := range ballot.SignerKeys() {
+			addr, err := subCommittee.AddressForBLSKey(key)
+			if err != nil {
+				return err
+			}
+			if *addr != candidate.Offender {
+				return errors.Wrapf(
+					errSignerNotOffender, "key %s of %s", key.Hex(), addr.Hex(),
+				)
+			}
+		}
 	}
 
 	// last ditch check
@@ -239,13 +257,21 @@ func Verify(
 	} {
 		// now the only real assurance, cryptography
 		signature := &bls.Sign{}
-		publicKey := &bls.PublicKey{}
+		publicKeys := []*bls.PublicKey{}
 
 		if err := signature.Deserialize(ballot.Signature); err != nil {
 			return err
 		}
-		if err := ballot.SignerPubKey.ToLibBLSPublicKey(publicKey); err != nil {
-			return err
+		for _, key := range ballot.SignerKeys() {
+			publicKey := &bls.PublicKey{}
+			if err := key.ToLibBLSPublicKey(publicKey); err != nil {
+				return err
+			}
+			publicKeys = append(publicKeys, publicKey)
+		}
+		publicKey := publicKeys[0]
+		if len(publicKeys) > 1 {
+			publicKey = bls_cosi.AggregatePubKeys(publicKeys)
 		}
 
 		blockNumBytes := make([]byte, 8)
@@ -260,6 +286,36 @@ func Verify(
 	return nil
 }
 
+// checkAggregateSigners checks that the signer key of a ballot signed along
+// with other keys is one of them, each once, and that the ballot is from an
+// epoch where votes may be aggregated
+func checkAggregateSigners(ballot votepower.Ballot, aggregateVote bool) error {
+	if len(ballot.AggregateSigners) == 0 {
+		return nil
+	}
+	if !aggregateVote {
+		return errors.Wrapf(
+			errAggregateVoteBeforeEpoch, "key %s", ballot.SignerPubKey.Hex(),
+		)
+	}
+	seen, hasSigner := map[shard.BlsPublicKey]struct{}{}, false
+	for _, key := range ballot.AggregateSigners {
+		if _, ok := seen[key]; ok {
+			return errors.Wrapf(errAggregateSignerTwice, "key %s", key.Hex())
+		}
+		seen[key] = struct{}{}
+		if key == ballot.SignerPubKey {
+			hasSigner = true
+		}
+	}
+	if !hasSigner {
+		return errors.Wrapf(
+			errSignerNotAggregated, "key %s", ballot.SignerPubKey.Hex(),
+		)
+	}
+	return nil
+}
+
 var (
 	errBLSKeysNotEqual = errors.New(
 		"bls keys in ballots accompanying slash evidence not equal ",
@@ -268,6 +324,10 @@ var (
 	errValidatorNotFoundDuringSlash = errors.New("validator not found")
 	errFailVerifySlash              = errors.New("could not verify bls key signature on slash")
 	errBallotsNotDiff               = errors.New("ballots submitted must be different")
+	errSignerNotOffender            = errors.New("ballot signed by a key of another validator than the offender")
+	errSignerNotAggregated          = errors.New("signer key not among the aggregate signers of its ballot")
+	errAggregateSignerTwice         = errors.New("key comes twice in the aggregate signers of a ballot")
+	errAggregateVoteBeforeEpoch     = errors.New("ballot of aggregated votes before the aggregate vote epoch")
 	zero                            = numeric.ZeroDec()
 	oneDoubleSignerRate             = numeric.MustNewDecFromStr("0.02")
 )
@@ -498,14 +558,16 @@ func Rate(votingPower *votepower.Roster, records Records) numeric.Dec {
 	rate := numeric.ZeroDec()
 
 	for i := range records {
-		key := records[i].Evidence.DoubleSignedBallot.SignerPubKey
-		if card, exists := votingPower.Voters[key]; exists {
-			rate = rate.Add(card.GroupPercent)
-		} else {
-			utils.Logger().Debug().
-				RawJSON("roster", []byte(votingPower.String())).
-				RawJSON("double-sign-record", []byte(records[i].String())).
-				Msg("did not have offenders voter card in roster as expected")
+		// the keys of an aggregated vote double signed together
+		for _, key := range records[i].Evidence.DoubleSignedBallot.SignerKeys() {
+			if card, exists := votingPower.Voters[key]; exists {
+				rate = rate.Add(card.GroupPercent)
+			} else {
+				utils.Logger().Debug().
+					RawJSON("roster", []byte(votingPower.String())).
+					RawJSON("double-sign-record", []byte(records[i].String())).
+					Msg("did not have offenders voter card in roster as expected")
+			}
 		}
 	}
 
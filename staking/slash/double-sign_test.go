@@ -1,6 +1,7 @@
 package slash
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -16,10 +17,12 @@ import (
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
 	common2 "github.com/harmony-one/harmony/internal/common"
+	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/staking/effective"
 	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -420,6 +423,10 @@ func (m mockOutSnapshotReader) ReadValidatorSnapshotAtEpoch(
 
 type mockOutChainReader struct{}
 
+func (mockOutChainReader) Config() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
 func (mockOutChainReader) CurrentBlock() *types.Block {
 	b := types.Block{}
 	b.Header().SetEpoch(doubleSignEpochBig)
@@ -561,6 +568,66 @@ func TestRoundTripSlashRecord(t *testing.T) {
 	}
 }
 
+// Ballots of a key voting alone encode as before the aggregate signers, and
+// ballots of aggregated votes round trip with their keys
+func TestRoundTripAggregateSigners(t *testing.T) {
+	type legacyBallot struct {
+		SignerPubKey    shard.BlsPublicKey
+		BlockHeaderHash common.Hash
+		Signature       []byte
+		Height          uint64
+		ViewID          uint64
+	}
+	ballot := defaultSlashRecord().Evidence.DoubleSignedBallot
+	legacy, err := rlp.EncodeToBytes(legacyBallot{
+		ballot.SignerPubKey, ballot.BlockHeaderHash, ballot.Signature,
+		ballot.Height, ballot.ViewID,
+	})
+	if err != nil {
+		t.Fatalf("encoding legacy ballot failed %s", err.Error())
+	}
+	if data, _ := rlp.EncodeToBytes(ballot); !bytes.Equal(data, legacy) {
+		t.Error("ballot of a single key does not encode as before")
+	}
+
+	slash := defaultSlashRecord()
+	slash.Evidence.DoubleSignedBallot.AggregateSigners = []shard.BlsPublicKey{blsWrapB, blsWrapA}
+	data, err := rlp.EncodeToBytes(Records{slash})
+	if err != nil {
+		t.Fatalf("encoding slash records failed %s", err.Error())
+	}
+	roundTrip := Records{}
+	if err := rlp.DecodeBytes(data, &roundTrip); err != nil {
+		t.Fatalf("decoding slash records failed %s", err.Error())
+	}
+	signers := roundTrip[0].Evidence.DoubleSignedBallot.SignerKeys()
+	if len(signers) != 2 || signers[0] != blsWrapB || signers[1] != blsWrapA {
+		t.Errorf("aggregate signers did not round trip: %v", signers)
+	}
+}
+
+func TestCheckAggregateSigners(t *testing.T) {
+	tests := []struct {
+		signers       []shard.BlsPublicKey
+		aggregateVote bool
+		err           error
+	}{
+		{nil, true, nil},
+		{nil, false, nil},
+		{[]shard.BlsPublicKey{blsWrapB, blsWrapA}, true, nil},
+		{[]shard.BlsPublicKey{blsWrapB, blsWrapA}, false, errAggregateVoteBeforeEpoch},
+		{[]shard.BlsPublicKey{blsWrapA}, true, errSignerNotAggregated},
+		{[]shard.BlsPublicKey{blsWrapB, blsWrapB}, true, errAggregateSignerTwice},
+	}
+	for i, test := range tests {
+		ballot := defaultSlashRecord().Evidence.DoubleSignedBallot
+		ballot.AggregateSigners = test.signers
+		if err := checkAggregateSigners(ballot, test.aggregateVote); errors.Cause(err) != test.err {
+			t.Errorf("test %d: expected %v, got %v", i, test.err, err)
+		}
+	}
+}
+
 func TestSetDifference(t *testing.T) {
 	setA, setB := exampleSlashRecords(), exampleSlashRecords()
 	additionalSlash := defaultSlashRecord()
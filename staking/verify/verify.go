@@ -27,16 +27,40 @@ func AggregateSigForCommittee(
 	epoch *big.Int,
 	bitmap []byte,
 ) error {
-	committerKeys, err := committee.BLSPublicKeys()
+	signed, err := CommitteeSignature(
+		committee, aggSignature, hash, blockNum, epoch, bitmap,
+	)
 	if err != nil {
 		return err
 	}
+	if !signed.Verify() {
+		return errAggregateSigFail
+	}
+
+	return nil
+}
+
+// CommitteeSignature checks that the signers of the bitmap have the voting
+// power of a quorum of the committee and returns their aggregate signature
+// on the commit payload of the block, to be verified alone or in a batch
+func CommitteeSignature(
+	committee *shard.Committee,
+	aggSignature *bls.Sign,
+	hash common.Hash,
+	blockNum uint64,
+	epoch *big.Int,
+	bitmap []byte,
+) (*bls_cosi.SignedHash, error) {
+	committerKeys, err := committee.BLSPublicKeys()
+	if err != nil {
+		return nil, err
+	}
 	mask, err := bls_cosi.NewMask(committerKeys, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := mask.SetMask(bitmap); err != nil {
-		return err
+		return nil, err
 	}
 
 	decider := quorum.NewDecider(
@@ -46,18 +70,16 @@ func AggregateSigForCommittee(
 		return nil, nil
 	})
 	if _, err := decider.SetVoters(committee, epoch); err != nil {
-		return err
+		return nil, err
 	}
 	if !decider.IsQuorumAchievedByMask(mask) {
-		return errQuorumVerifyAggSign
+		return nil, errQuorumVerifyAggSign
 	}
 
 	blockNumBytes := make([]byte, 8)
 	binary.LittleEndian.PutUint64(blockNumBytes, blockNum)
 	commitPayload := append(blockNumBytes, hash[:]...)
-	if !aggSignature.VerifyHash(mask.AggregatePublic, commitPayload) {
-		return errAggregateSigFail
-	}
-
-	return nil
+	return &bls_cosi.SignedHash{
+		Sig: aggSignature, PubKey: mask.AggregatePublic, Hash: commitPayload,
+	}, nil
 }